@@ -47,7 +47,10 @@ replicated app hostname ls --app myapp --output json`,
 			}
 
 			// Resolve app using full precedence: --app flag > REPLICATED_APP > .replicated > cache default
-			resolved := resolveAppSlugOrID(appSlugOrID)
+			resolved, err := resolveAppSlugOrID(appSlugOrID)
+			if err != nil {
+				return err
+			}
 			if resolved != "" {
 				r.appSlug = resolved
 			}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/replicated/pkg/tools"
+	"github.com/spf13/cobra"
+)
+
+func (r *runners) InitConfigSchema(parent *cobra.Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema of the .replicated config file",
+		Long: `Print the JSON Schema of the .replicated config file.
+
+Editors with YAML language support can use the schema to complete and check .replicated files. The same schema is published as docs/replicated-config.schema.json.`,
+		Example: `# Save the schema for an editor
+replicated config schema > replicated-config.schema.json`,
+		Args:              cobra.NoArgs,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
+		RunE:              r.configSchema,
+		SilenceUsage:      true,
+	}
+	parent.AddCommand(cmd)
+
+	return cmd
+}
+
+func (r *runners) configSchema(cmd *cobra.Command, args []string) error {
+	data, err := tools.ConfigSchemaJSON()
+	if err != nil {
+		return errors.Wrap(err, "failed to generate config schema")
+	}
+	if _, err := r.w.Write(data); err != nil {
+		return err
+	}
+	return r.w.Flush()
+}
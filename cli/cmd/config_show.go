@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/replicated/cli/print"
+	"github.com/replicatedhq/replicated/pkg/tools"
+	"github.com/spf13/cobra"
+)
+
+func (r *runners) InitConfigShow(parent *cobra.Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show [PATH]",
+		Short: "Show the .replicated config that applies to a directory",
+		Long: `Show the .replicated config files that apply to a directory, from the outermost parent to the closest child, with the files each one includes before it.
+
+With --resolved, print the single config the other commands use instead: the files merged with children overriding their parents and appending charts, preflights and manifests, with the environment selected by --env or the credentials profile applied, defaults applied and duplicate resources removed. Each value is annotated with the file it came from, or "(default)" for defaults.
+
+PATH may be a directory (the current directory by default) or a single config file. The resolved config is printed as YAML, or with --output json as JSON with a "sources" object mapping each field to its file.`,
+		Example: `# Print the config files that apply to the current directory
+replicated config show
+
+# Print the merged config and where each value came from
+replicated config show --resolved
+
+# Print the merged config with the staging environment applied
+replicated config show --resolved --env staging
+
+# Print the merged config as JSON
+replicated config show --resolved --output json`,
+		Args:              cobra.MaximumNArgs(1),
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
+		RunE:              r.configShow,
+		SilenceUsage:      true,
+	}
+	parent.AddCommand(cmd)
+
+	cmd.Flags().BoolVar(&r.args.configShowResolved, "resolved", false, "Print the merged config with the file each value came from")
+
+	return cmd
+}
+
+func (r *runners) configShow(cmd *cobra.Command, args []string) error {
+	r.resolveOutputFormat(cmd)
+
+	startPath := "."
+	if len(args) > 0 {
+		startPath = args[0]
+	}
+
+	parser := tools.NewConfigParser()
+
+	if r.args.configShowResolved {
+		config, provenance, err := parser.ResolveConfig(startPath, envNameFlag, activeProfileName())
+		if err != nil {
+			return errors.Wrap(err, "failed to resolve config")
+		}
+		return print.ResolvedConfig(r.outputFormat, r.w, config, provenance)
+	}
+
+	paths, err := parser.FindConfigFiles(startPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to find config files")
+	}
+	if len(paths) == 0 {
+		return errors.Errorf("no .replicated config found in %s or its parent directories", startPath)
+	}
+
+	contents := make([][]byte, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %s", path)
+		}
+		contents = append(contents, data)
+	}
+	return print.ConfigFiles(r.outputFormat, r.w, paths, contents)
+}
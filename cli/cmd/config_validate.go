@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/replicated/cli/print"
+	"github.com/replicatedhq/replicated/pkg/tools"
+	"github.com/spf13/cobra"
+)
+
+func (r *runners) InitConfigValidate(parent *cobra.Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate [PATH]",
+		Short: "Validate .replicated config files",
+		Long: `Validate the .replicated config files that apply to a directory against the config schema.
+
+Every file in the parent/child chain, and every file they include, is checked on its own, from the outermost parent to the closest child. Unknown keys, values of the wrong type and invalid glob patterns are reported with their line and column. PATH may be a directory (the current directory by default) or a single config file.
+
+The schema is printed by "replicated config schema".`,
+		Example: `# Validate the config files for the current directory
+replicated config validate
+
+# Validate a single file and print the problems as JSON
+replicated config validate ./.replicated --output json`,
+		Args:              cobra.MaximumNArgs(1),
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
+		RunE:              r.configValidate,
+		SilenceUsage:      true,
+	}
+	parent.AddCommand(cmd)
+
+	return cmd
+}
+
+func (r *runners) configValidate(cmd *cobra.Command, args []string) error {
+	r.resolveOutputFormat(cmd)
+
+	startPath := "."
+	if len(args) > 0 {
+		startPath = args[0]
+	}
+
+	parser := tools.NewConfigParser()
+	paths, err := parser.FindConfigFiles(startPath)
+	var syntaxErr *tools.ConfigSyntaxError
+	if errors.As(err, &syntaxErr) {
+		// The includes of a file that is not valid YAML can't be read; report where
+		// the syntax error is
+		paths, err = []string{syntaxErr.Path}, nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to find config files")
+	}
+	if len(paths) == 0 {
+		return errors.Errorf("no .replicated config found in %s or its parent directories", startPath)
+	}
+
+	var issues []tools.ConfigIssue
+	for _, path := range paths {
+		fileIssues, err := parser.ValidateConfigFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to validate %s", path)
+		}
+		issues = append(issues, fileIssues...)
+	}
+
+	if err := print.ConfigValidation(r.outputFormat, r.w, paths, issues); err != nil {
+		return errors.Wrap(err, "failed to print validation results")
+	}
+	if len(issues) > 0 {
+		return newExitError(1, errors.Errorf("found %d problem(s) in .replicated config", len(issues)))
+	}
+	return nil
+}
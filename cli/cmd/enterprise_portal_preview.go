@@ -106,7 +106,10 @@ func (r *runners) enterprisePortalPreview(cmd *cobra.Command, args []string) err
 	//   2. REPLICATED_APP env var
 	//   3. .replicated file in cwd (or parent directories)
 	//   4. default app set via `replicated default app <slug>` (cache.DefaultApp)
-	appSlug := resolveAppSlugOrID(appSlugOrID)
+	appSlug, err := resolveAppSlugOrID(appSlugOrID)
+	if err != nil {
+		return err
+	}
 	if appSlug == "" {
 		return errors.New("app required: pass --app, set REPLICATED_APP, create a .replicated file, or set a default with `replicated default app <slug>`")
 	}
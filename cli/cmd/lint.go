@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/manifoldco/promptui"
 	"github.com/pkg/errors"
@@ -21,6 +22,15 @@ import (
 // release-validation-v2 feature flag. The runLint function below is still used
 // internally by the release lint command.
 
+// newLintResolver returns the resolver local lint runs tools with, downloading them
+// through the mirrors in the config
+func newLintResolver(config *tools.Config) *tools.Resolver {
+	return tools.NewResolver(
+		tools.WithMirrors(config.ReplLint.ToolsMirror),
+		tools.WithKubeSchemaDownload(config.ReplLint.Linters.KubeSchema.IsDownloadEnabled()),
+	)
+}
+
 // resolveToolVersion extracts and resolves a tool version from config.
 // If the version is "latest" or empty, it resolves to an actual version using the resolver.
 // Falls back to the provided default version if resolution fails.
@@ -106,25 +116,88 @@ func extractAllPathsAndMetadata(ctx context.Context, config *tools.Config, verbo
 	return result, nil
 }
 
+// validLintOutputFormats are the --output values supported by local linting
+var validLintOutputFormats = map[string]struct{}{
+	"table": {},
+	"json":  {},
+	"junit": {},
+	"sarif": {},
+}
+
+// errLintFailed is returned when linting completes with failures
+var errLintFailed = errors.New("linting failed")
+
 func (r *runners) runLint(cmd *cobra.Command, args []string) error {
 	// Validate output format
-	if r.outputFormat != "table" && r.outputFormat != "json" {
-		return errors.Errorf("invalid output: %s. Supported output formats: json, table", r.outputFormat)
+	if _, ok := validLintOutputFormats[r.outputFormat]; !ok {
+		return errors.Errorf("invalid output: %s. Supported output formats: json, junit, sarif, table", r.outputFormat)
+	}
+	if _, ok := validFailOnValues[r.args.lintReleaseFailOn]; !ok {
+		return errors.Errorf("fail-on value %q not supported, supported values are [info, warn, error, none]", r.args.lintReleaseFailOn)
+	}
+	if err := r.validateLintFixFlags(); err != nil {
+		return err
 	}
 
-	// Load .replicated config using tools parser (supports monorepos)
-	parser := tools.NewConfigParser()
-	config, err := parser.FindAndParseConfig(".")
+	if r.args.lintWatch {
+		return r.watchLint(cmd)
+	}
+	return r.lintOnce(cmd)
+}
+
+// lintOnce runs a single local lint pass and displays the results
+func (r *runners) lintOnce(cmd *cobra.Command) error {
+	// In watch mode, watch the files the config includes as well. When they can't
+	// be listed, e.g. an included file is not valid YAML, the last ones are kept so
+	// that fixing it is picked up.
+	if r.lintWatcher != nil {
+		if configFiles, err := tools.NewConfigParser().FindConfigFiles("."); err == nil {
+			r.lintWatcher.watchConfigFiles(configFiles)
+		}
+	}
 
+	// Load .replicated config using tools parser (supports monorepos), with the
+	// environment selected by --env or the active profile applied
+	config, err := findAndParseEnvironmentConfig(".")
 	if err != nil {
 		return errors.Wrap(err, "failed to load .replicated config")
 	}
 
+	// Load the lint baseline, if any, so known findings are suppressed as each linter runs
+	var baselinePath string
+	if config.ReplLint != nil {
+		baselinePath = config.ReplLint.Baseline
+	}
+	r.lintBaseline, err = newLintBaselineFilter(baselinePath, r.args.lintWriteBaseline != "")
+	if err != nil {
+		return err
+	}
+	defer func() { r.lintBaseline = nil }()
+
+	// Resolve tools through the mirrors in the config, if any
+	resolver := newLintResolver(config)
+
+	// Compile per-linter ignore rules and strict settings; inline ignore comments apply regardless
+	r.linterOptions, err = newLinterOptions(config, resolver)
+	if err != nil {
+		return err
+	}
+	defer func() { r.linterOptions = nil }()
+
+	// Reuse results for resources whose files, values, tool version and config are unchanged.
+	// --watch keeps a single cache for the whole session.
+	if r.lintCache != nil {
+		r.lintCache.useConfig(config)
+		r.lintCache.resetHits()
+	} else if !r.args.lintNoCache {
+		r.lintCache = newLintResultCache(config)
+		defer func() { r.lintCache = nil }()
+	}
+
 	// Initialize JSON output structure
 	output := &JSONLintOutput{}
 
 	// Resolve all tool versions (including "latest" to actual versions)
-	resolver := tools.NewResolver()
 	helmVersion := resolveToolVersion(cmd.Context(), config, resolver, tools.ToolHelm, tools.DefaultHelmVersion)
 	preflightVersion := resolveToolVersion(cmd.Context(), config, resolver, tools.ToolPreflight, tools.DefaultPreflightVersion)
 	supportBundleVersion := resolveToolVersion(cmd.Context(), config, resolver, tools.ToolSupportBundle, tools.DefaultSupportBundleVersion)
@@ -193,23 +266,35 @@ func (r *runners) runLint(cmd *cobra.Command, args []string) error {
 			r.w.Flush()
 		}
 
-		// If nothing was found and EC linting is not enabled, exit early.
-		// EC linting runs after this block, so don't bail out when it's enabled.
-		if len(chartPaths) == 0 && len(preflightPaths) == 0 && len(sbPaths) == 0 && !config.ReplLint.Linters.EmbeddedCluster.IsEnabled() {
+		// If nothing was found and neither EC nor KOTS linting is enabled, exit early.
+		// EC and KOTS linting run after this block, so don't bail out when they're enabled.
+		if len(chartPaths) == 0 && len(preflightPaths) == 0 && len(sbPaths) == 0 &&
+			!config.ReplLint.Linters.EmbeddedCluster.IsEnabled() && !config.ReplLint.Linters.Kots.IsEnabled() {
 			if showAutoDiscoveryMessages {
 				fmt.Fprintf(r.w, "No lintable resources found in current directory.\n")
 				r.w.Flush()
 			}
-			if r.outputFormat == "json" {
+			if r.outputFormat != "table" {
 				output.Summary = r.calculateOverallSummary(output)
-				if err := print.LintResults(r.outputFormat, r.w, output); err != nil {
-					return errors.Wrap(err, "failed to print JSON output to stdout")
+				if err := r.printLintOutput(output); err != nil {
+					return err
 				}
 			}
 			return nil
 		}
 	}
 
+	// Apply fixes before any linter runs, so only the remaining findings are reported
+	if r.args.lintFix {
+		output.Fixes, err = r.fixLintFindings(config)
+		if err != nil {
+			return err
+		}
+		if r.args.lintFixDryRun {
+			return nil
+		}
+	}
+
 	// Display tool versions if verbose mode is enabled
 	if r.args.lintVerbose {
 		fmt.Fprintln(r.w, "Tool versions:")
@@ -223,6 +308,28 @@ func (r *runners) runLint(cmd *cobra.Command, args []string) error {
 		r.w.Flush()
 	}
 
+	// Check how charts, HelmCharts, preflights and support bundles reference each other
+	// first: extraction stops at the first duplicate, so inconsistencies in the release
+	// graph are reported together here and stop the run before any other linter starts
+	runReleaseGraph := config.ReplLint.Linters.ReleaseGraph.IsEnabled()
+	if runReleaseGraph {
+		output.ReleaseGraphResults, err = r.lintReleaseGraph(config)
+		if err != nil {
+			return err
+		}
+		for _, resource := range output.ReleaseGraphResults.Resources {
+			if resource.Success {
+				continue
+			}
+			if r.outputFormat == "table" {
+				fmt.Fprintf(r.w, "Release graph linting failed (skipping the remaining linters)\n\n")
+			}
+			// With --watch, fixing any resource in the graph must trigger a re-lint
+			r.lintWatcher.watchResources(releaseGraphWatchPaths(output.ReleaseGraphResults))
+			return r.finishLint(output)
+		}
+	}
+
 	// Extract all paths and metadata once (consolidates extraction logic across linters)
 	extracted, err := extractAllPathsAndMetadata(cmd.Context(), config, r.args.lintVerbose, helmVersion, preflightVersion, supportBundleVersion)
 	if err != nil {
@@ -250,8 +357,8 @@ func (r *runners) runLint(cmd *cobra.Command, args []string) error {
 			return errors.Wrap(err, "chart validation failed")
 		}
 
-		// Display warnings (orphaned HelmChart manifests)
-		if r.outputFormat == "table" && len(validationResult.Warnings) > 0 {
+		// Display warnings (orphaned HelmChart manifests), unless the release graph linter reported them
+		if r.outputFormat == "table" && len(validationResult.Warnings) > 0 && !runReleaseGraph {
 			for _, warning := range validationResult.Warnings {
 				fmt.Fprintf(r.w, "Warning: %s\n", warning)
 			}
@@ -284,15 +391,202 @@ func (r *runners) runLint(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Lint Helm charts if enabled
-	if config.ReplLint.Linters.Helm.IsEnabled() {
-		if len(extracted.ChartPaths) == 0 {
+	linters := config.ReplLint.Linters
+
+	// Expand Embedded Cluster, KOTS and policy manifest globs
+	var ecPaths, kotsPaths, policyManifestPaths []string
+	if linters.EmbeddedCluster.IsEnabled() || linters.Kots.IsEnabled() || linters.Policy.IsEnabled() {
+		manifestPatterns := config.Manifests
+		if autoDiscoveryMode {
+			manifestPatterns = []string{"./**"}
+		}
+		manifestPaths, err := lint2.ExpandManifestGlobs(manifestPatterns)
+		if err != nil {
+			return errors.Wrap(err, "expanding manifest globs for ec, kots and policy lint")
+		}
+		if linters.EmbeddedCluster.IsEnabled() {
+			ecPaths = manifestPaths
+		}
+		if linters.Kots.IsEnabled() {
+			kotsPaths = manifestPaths
+		}
+		if linters.Policy.IsEnabled() {
+			policyManifestPaths = manifestPaths
+		}
+	}
+	runEC := linters.EmbeddedCluster.IsEnabled()
+	runKots := linters.Kots.IsEnabled()
+
+	// Policies are evaluated against charts, manifests and preflight specs
+	policyChartPaths := extracted.ChartPaths
+	policyPreflights := extracted.Preflights
+	policiesDir := config.ReplLint.Policies
+
+	// With --watch, watch every resource being linted, including those outside the current directory
+	watchPaths := lintWatchPaths(extracted, ecPaths, kotsPaths)
+	if linters.Policy.IsEnabled() && policiesDir != "" {
+		watchPaths = append(watchPaths, policiesDir)
+	}
+	r.lintWatcher.watchResources(watchPaths)
+
+	// Restrict linting to resources touched since --changed-since
+	if r.args.lintChangedSince != "" {
+		changed, err := lint2.ChangedSince(".", r.args.lintChangedSince)
+		if err != nil {
+			return errors.Wrap(err, "failed to find changed files")
+		}
+		r.lintChanged = changed
+		defer func() { r.lintChanged = nil }()
+
+		// Preflights are filtered first: they depend on the unfiltered chart list
+		extracted.Preflights = filterChangedPreflights(extracted.Preflights, extracted.ChartsWithMetadata, extracted.HelmChartManifests, changed)
+		extracted.ChartPaths = filterChangedCharts(extracted.ChartPaths, extracted.ChartsWithMetadata, extracted.HelmChartManifests, changed)
+		extracted.SupportBundles = filterChangedPaths(extracted.SupportBundles, changed)
+
+		// EC and KOTS lint every manifest together (KOTS resolves config references
+		// across files), so they run whenever any manifest changed. KOTS results are
+		// then limited to the changed files.
+		runEC = runEC && changed.ContainsAny(ecPaths)
+		runKots = runKots && changed.ContainsAny(kotsPaths)
+
+		// Changed policies apply to every resource, so nothing is filtered then
+		if !changed.Contains(policiesDir) {
+			policyChartPaths = extracted.ChartPaths
+			policyPreflights = extracted.Preflights
+			policyManifestPaths = filterChangedPaths(policyManifestPaths, changed)
+		}
+
+		if r.outputFormat == "table" {
+			fmt.Fprintf(r.w, "Linting resources changed since %s (%d changed file(s))\n\n", r.args.lintChangedSince, changed.Len())
+			r.w.Flush()
+		}
+	}
+
+	// Resolve the EC binary up front so errors stop the run before any linter starts
+	var ecBinaryPath string
+	if runEC {
+		ecBinaryPath, err = resolveECBinaryPath(cmd.Context(), resolver, linters.EmbeddedCluster, ecPaths)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Read the Kubernetes schemas up front as well, downloading any that are not
+	// cached yet unless the config turns downloads off. Cached schemas are used offline.
+	runKubeSchema := linters.KubeSchema.IsEnabled() && len(extracted.ChartPaths) > 0
+	var kubeSchemas [][]byte
+	if linters.KubeSchema.IsEnabled() && len(linters.KubeSchema.Versions) == 0 {
+		return errors.New("kube-schema linting is enabled but no Kubernetes versions are configured\n\n" +
+			"Add the versions to validate against to your .replicated config:\n\n" +
+			"repl-lint:\n" +
+			"  linters:\n" +
+			"    kube-schema:\n" +
+			"      versions: [\"1.31\"]")
+	}
+	if runKubeSchema {
+		for _, version := range linters.KubeSchema.Versions {
+			schema, err := resolver.ReadKubeSchema(cmd.Context(), version)
+			if err != nil {
+				return errors.Wrapf(err, "resolving Kubernetes %s schema", version)
+			}
+			kubeSchemas = append(kubeSchemas, schema)
+		}
+	}
+
+	// Load the policies up front too, so invalid rules stop the run
+	runPolicy := linters.Policy.IsEnabled() && len(policyChartPaths)+len(policyManifestPaths)+len(policyPreflights) > 0
+	var policies *lint2.Policies
+	if linters.Policy.IsEnabled() && policiesDir == "" {
+		return errors.New("policy linting is enabled but no policies directory is configured\n\n" +
+			"Add the directory containing your policy rules to your .replicated config:\n\n" +
+			"repl-lint:\n" +
+			"  policies: ./policies")
+	}
+	if runPolicy {
+		policies, err = lint2.LoadPolicies(policiesDir)
+		if err != nil {
+			return errors.Wrap(err, "failed to load policies")
+		}
+	}
+
+	// Download the tools the enabled linters need before starting them concurrently
+	toolVersions := map[string]string{}
+	if linters.Helm.IsEnabled() && len(extracted.ChartPaths) > 0 {
+		toolVersions[tools.ToolHelm] = extracted.HelmVersion
+	}
+	if linters.Preflight.IsEnabled() && len(extracted.Preflights) > 0 {
+		toolVersions[tools.ToolPreflight] = extracted.PreflightVersion
+	}
+	if linters.SupportBundle.IsEnabled() && len(extracted.SupportBundles) > 0 {
+		toolVersions[tools.ToolSupportBundle] = extracted.SBVersion
+	}
+	prefetchLintTools(cmd.Context(), resolver, toolVersions)
+
+	// Start every enabled linter on a shared pool bounded by --parallel. Results are
+	// collected and displayed below in a fixed order, so output does not depend on
+	// which tasks finish first. Returning early cancels any tasks still running.
+	ctx, cancel := context.WithCancel(cmd.Context())
+	defer cancel()
+	pool := newLintPool(r.args.lintParallel)
+
+	// Sample config values are used to render the HelmChart values each chart is linted
+	// with, and the KOTS templates in manifests policies are evaluated against
+	var configValues map[string]string
+	runImage := linters.Image.IsEnabled() && len(extracted.ChartPaths) > 0
+	if (len(extracted.ChartPaths) > 0 && (linters.Helm.IsEnabled() || runKubeSchema || runImage)) || runPolicy {
+		configValues, err = lint2.DiscoverConfigSampleValues(config.Manifests)
+		if err != nil {
+			return errors.Wrap(err, "failed to discover config values")
+		}
+	}
+
+	var collectHelm func() (*HelmLintResults, error)
+	if linters.Helm.IsEnabled() && len(extracted.ChartPaths) > 0 {
+		collectHelm = r.lintHelmCharts(ctx, pool, extracted.ChartPaths, extracted.ChartsWithMetadata, extracted.HelmChartManifests, configValues, extracted.HelmVersion)
+	}
+	var collectPreflight func() (*PreflightLintResults, error)
+	if linters.Preflight.IsEnabled() && len(extracted.Preflights) > 0 {
+		collectPreflight = r.lintPreflightSpecs(ctx, pool, extracted.Preflights, extracted.HelmChartManifests, extracted.PreflightVersion)
+	}
+	var collectSupportBundle func() (*SupportBundleLintResults, error)
+	if linters.SupportBundle.IsEnabled() {
+		collectSupportBundle = r.lintSupportBundleSpecs(ctx, pool, extracted.SupportBundles, extracted.SBVersion)
+	}
+	var collectEmbeddedCluster func() (*EmbeddedClusterLintResults, error)
+	if runEC {
+		collectEmbeddedCluster = r.lintEmbeddedClusterManifests(ctx, pool, ecPaths, ecBinaryPath, linters.EmbeddedCluster.GetDisableChecks())
+	}
+	var collectKots func() (*KotsLintResults, error)
+	if runKots {
+		collectKots = r.lintKotsManifests(pool, kotsPaths, extracted.ChartsWithMetadata)
+	}
+	var collectKubeSchema func() (*KubeSchemaLintResults, error)
+	if runKubeSchema {
+		collectKubeSchema = r.lintKubeSchema(pool, extracted.ChartPaths, extracted.ChartsWithMetadata, extracted.HelmChartManifests, configValues, linters.KubeSchema.Versions, kubeSchemas)
+	}
+	var collectPolicy func() (*PolicyLintResults, error)
+	if runPolicy {
+		collectPolicy = r.lintPolicies(pool, policies, policiesDir, policyChartPaths, policyManifestPaths, policyPreflights, extracted.ChartsWithMetadata, extracted.HelmChartManifests, configValues)
+	}
+
+	var collectImage func() (*ImageLintResults, error)
+	if runImage {
+		collectImage = r.lintImages(ctx, pool, extracted.ChartPaths, extracted.ChartsWithMetadata, extracted.HelmChartManifests, configValues, linters.Image)
+	}
+
+	// Collect Helm chart results if enabled
+	if linters.Helm.IsEnabled() {
+		if collectHelm == nil {
 			output.HelmResults = &HelmLintResults{Enabled: true, Charts: []ChartLintResult{}}
 			if r.outputFormat == "table" {
-				fmt.Fprintf(r.w, "No Helm charts configured (skipping Helm linting)\n\n")
+				if r.lintChanged != nil {
+					fmt.Fprintf(r.w, "No Helm charts changed since %s (skipping Helm linting)\n\n", r.lintChanged.Ref)
+				} else {
+					fmt.Fprintf(r.w, "No Helm charts configured (skipping Helm linting)\n\n")
+				}
 			}
 		} else {
-			helmResults, err := r.lintHelmCharts(cmd, extracted.ChartPaths, extracted.HelmVersion)
+			helmResults, err := collectHelm()
 			if err != nil {
 				return err
 			}
@@ -305,15 +599,19 @@ func (r *runners) runLint(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Lint Preflight specs if enabled
-	if config.ReplLint.Linters.Preflight.IsEnabled() {
-		if len(extracted.Preflights) == 0 {
+	// Collect Preflight spec results if enabled
+	if linters.Preflight.IsEnabled() {
+		if collectPreflight == nil {
 			output.PreflightResults = &PreflightLintResults{Enabled: true, Specs: []PreflightLintResult{}}
 			if r.outputFormat == "table" {
-				fmt.Fprintf(r.w, "No preflight specs configured (skipping preflight linting)\n\n")
+				if r.lintChanged != nil {
+					fmt.Fprintf(r.w, "No preflight specs changed since %s (skipping preflight linting)\n\n", r.lintChanged.Ref)
+				} else {
+					fmt.Fprintf(r.w, "No preflight specs configured (skipping preflight linting)\n\n")
+				}
 			}
 		} else {
-			preflightResults, err := r.lintPreflightSpecs(cmd, extracted.Preflights, extracted.HelmChartManifests, extracted.PreflightVersion)
+			preflightResults, err := collectPreflight()
 			if err != nil {
 				return err
 			}
@@ -326,9 +624,9 @@ func (r *runners) runLint(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Lint Support Bundle specs if enabled
-	if config.ReplLint.Linters.SupportBundle.IsEnabled() {
-		sbResults, err := r.lintSupportBundleSpecs(cmd, extracted.SupportBundles, extracted.SBVersion)
+	// Collect Support Bundle spec results if enabled
+	if linters.SupportBundle.IsEnabled() {
+		sbResults, err := collectSupportBundle()
 		if err != nil {
 			return err
 		}
@@ -340,52 +638,136 @@ func (r *runners) runLint(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Lint Embedded Cluster manifests if enabled
-	if config.ReplLint.Linters.EmbeddedCluster.IsEnabled() {
-		// Expand manifest glob patterns to the actual YAML files to pass to the EC CLI
-		manifestPatterns := config.Manifests
-		if autoDiscoveryMode {
-			manifestPatterns = []string{"./**"}
+	// Collect Embedded Cluster results if enabled
+	if runEC {
+		ecResults, err := collectEmbeddedCluster()
+		if err != nil {
+			return err
+		}
+		output.EmbeddedClusterResults = ecResults
+	} else if linters.EmbeddedCluster.IsEnabled() {
+		output.EmbeddedClusterResults = &EmbeddedClusterLintResults{Enabled: true, Specs: []EmbeddedClusterLintResult{}}
+		if r.outputFormat == "table" {
+			fmt.Fprintf(r.w, "No manifests changed since %s (skipping Embedded Cluster linting)\n\n", r.lintChanged.Ref)
 		}
-		ecPaths, err := lint2.ExpandManifestGlobs(manifestPatterns)
+	} else {
+		output.EmbeddedClusterResults = &EmbeddedClusterLintResults{Enabled: false, Specs: []EmbeddedClusterLintResult{}}
+		if r.outputFormat == "table" {
+			fmt.Fprintf(r.w, "Embedded Cluster linting is disabled in .replicated config\n\n")
+		}
+	}
+
+	// Collect KOTS manifest results if enabled
+	if runKots {
+		kotsResults, err := collectKots()
 		if err != nil {
-			return errors.Wrap(err, "expanding manifest globs for ec lint")
+			return err
+		}
+		output.KotsResults = kotsResults
+	} else if linters.Kots.IsEnabled() {
+		output.KotsResults = &KotsLintResults{Enabled: true, Manifests: []KotsLintResult{}}
+		if r.outputFormat == "table" {
+			fmt.Fprintf(r.w, "No manifests changed since %s (skipping KOTS linting)\n\n", r.lintChanged.Ref)
+		}
+	} else {
+		output.KotsResults = &KotsLintResults{Enabled: false, Manifests: []KotsLintResult{}}
+		if r.outputFormat == "table" {
+			fmt.Fprintf(r.w, "KOTS linting is disabled in .replicated config\n\n")
 		}
+	}
 
-		binaryPath := config.ReplLint.Linters.EmbeddedCluster.BinaryPath
-		if val := os.Getenv("REPLICATED_EMBEDDED_CLUSTER_BINARY_PATH"); val != "" {
-			binaryPath = val
+	// Collect Kubernetes schema results if enabled
+	if runKubeSchema {
+		kubeSchemaResults, err := collectKubeSchema()
+		if err != nil {
+			return err
+		}
+		output.KubeSchemaResults = kubeSchemaResults
+	} else if linters.KubeSchema.IsEnabled() {
+		output.KubeSchemaResults = &KubeSchemaLintResults{Enabled: true, Versions: linters.KubeSchema.Versions, Charts: []KubeSchemaLintResult{}}
+		if r.outputFormat == "table" {
+			if r.lintChanged != nil {
+				fmt.Fprintf(r.w, "No Helm charts changed since %s (skipping Kubernetes schema linting)\n\n", r.lintChanged.Ref)
+			} else {
+				fmt.Fprintf(r.w, "No Helm charts configured (skipping Kubernetes schema linting)\n\n")
+			}
+		}
+	} else {
+		output.KubeSchemaResults = &KubeSchemaLintResults{Enabled: false, Charts: []KubeSchemaLintResult{}}
+		if r.outputFormat == "table" {
+			fmt.Fprintf(r.w, "Kubernetes schema linting is disabled in .replicated config\n\n")
 		}
+	}
 
-		// Version discovery is only needed when no binary path is provided, since
-		// the version is used solely to download the binary via the resolver.
-		var ecVersion string
-		if binaryPath == "" {
-			ecVersion, err = lint2.DiscoverECVersion(ecPaths)
-			if err != nil {
-				return errors.Wrap(err, "discovering embedded-cluster version")
+	// Collect policy results if enabled
+	if runPolicy {
+		policyResults, err := collectPolicy()
+		if err != nil {
+			return err
+		}
+		output.PolicyResults = policyResults
+	} else if linters.Policy.IsEnabled() {
+		output.PolicyResults = &PolicyLintResults{Enabled: true, Resources: []PolicyLintResult{}}
+		if r.outputFormat == "table" {
+			if r.lintChanged != nil {
+				fmt.Fprintf(r.w, "No resources changed since %s (skipping policy linting)\n\n", r.lintChanged.Ref)
+			} else {
+				fmt.Fprintf(r.w, "No resources configured (skipping policy linting)\n\n")
 			}
 		}
+	} else {
+		output.PolicyResults = &PolicyLintResults{Enabled: false, Resources: []PolicyLintResult{}}
+		if r.outputFormat == "table" {
+			fmt.Fprintf(r.w, "Policy linting is disabled in .replicated config\n\n")
+		}
+	}
 
-		ecResults, err := r.lintEmbeddedClusterManifests(cmd, ecPaths, ecVersion, binaryPath, config.ReplLint.Linters.EmbeddedCluster.GetDisableChecks())
+	// Collect image results if enabled
+	if runImage {
+		imageResults, err := collectImage()
 		if err != nil {
 			return err
 		}
-		output.EmbeddedClusterResults = ecResults
+		output.ImageResults = imageResults
+	} else if linters.Image.IsEnabled() {
+		output.ImageResults = &ImageLintResults{Enabled: true, Charts: []ImageLintResult{}}
+		if r.outputFormat == "table" {
+			if r.lintChanged != nil {
+				fmt.Fprintf(r.w, "No Helm charts changed since %s (skipping image linting)\n\n", r.lintChanged.Ref)
+			} else {
+				fmt.Fprintf(r.w, "No Helm charts configured (skipping image linting)\n\n")
+			}
+		}
 	} else {
-		output.EmbeddedClusterResults = &EmbeddedClusterLintResults{Enabled: false, Specs: []EmbeddedClusterLintResult{}}
+		output.ImageResults = &ImageLintResults{Enabled: false, Charts: []ImageLintResult{}}
 		if r.outputFormat == "table" {
-			fmt.Fprintf(r.w, "Embedded Cluster linting is disabled in .replicated config\n\n")
+			fmt.Fprintf(r.w, "Image linting is disabled in .replicated config\n\n")
 		}
 	}
 
+	if r.args.lintVerbose && r.outputFormat == "table" && r.lintCache.cacheHits() > 0 {
+		fmt.Fprintf(r.w, "Reused cached results for %d unchanged resource(s) (use --no-cache to lint everything)\n\n", r.lintCache.cacheHits())
+	}
+
+	return r.finishLint(output)
+}
+
+// finishLint reports how the baseline was applied, prints the output in the requested
+// format and returns errLintFailed if any resource failed or --fail-on is reached
+func (r *runners) finishLint(output *JSONLintOutput) error {
+	// Report how the baseline was applied, including entries that no longer match
+	output.Baseline = r.lintBaseline.results()
+	if r.outputFormat == "table" {
+		r.displayBaselineResults(output.Baseline)
+	}
+
 	// Calculate overall summary
 	output.Summary = r.calculateOverallSummary(output)
 
 	// Output to stdout
-	if r.outputFormat == "json" {
-		if err := print.LintResults(r.outputFormat, r.w, output); err != nil {
-			return errors.Wrap(err, "failed to print JSON output to stdout")
+	if r.outputFormat != "table" {
+		if err := r.printLintOutput(output); err != nil {
+			return err
 		}
 	} else {
 		// Table format was already displayed by individual display functions
@@ -395,139 +777,281 @@ func (r *runners) runLint(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Writing a baseline accepts every current finding, so it never fails the run
+	if r.args.lintWriteBaseline != "" {
+		count, err := r.lintBaseline.writeBaseline(r.args.lintWriteBaseline)
+		if err != nil {
+			return errors.Wrap(err, "failed to write lint baseline")
+		}
+		if r.outputFormat == "table" {
+			fmt.Fprintf(r.w, "Wrote %d finding(s) to baseline %s\n", count, r.args.lintWriteBaseline)
+			r.w.Flush()
+		}
+		return nil
+	}
+
 	// Return error if any linting failed
-	if !output.Summary.OverallSuccess {
-		return errors.New("linting failed")
+	if lintShouldFail(output.Summary, r.args.lintReleaseFailOn) {
+		return errLintFailed
 	}
 
 	return nil
 }
 
-func (r *runners) lintHelmCharts(cmd *cobra.Command, chartPaths []string, helmVersion string) (*HelmLintResults, error) {
-	results := &HelmLintResults{
-		Enabled: true,
-		Charts:  make([]ChartLintResult, 0, len(chartPaths)),
+// lintShouldFail reports whether a local lint run fails for the --fail-on severity.
+// Errors fail the run unless --fail-on is none; warn and info also fail on warnings
+// and informational findings. An unset value behaves like the flag's default, error.
+func lintShouldFail(summary LintSummary, failOn string) bool {
+	switch failOn {
+	case "none":
+		return false
+	case "warn":
+		return !summary.OverallSuccess || summary.TotalWarnings > 0
+	case "info":
+		return !summary.OverallSuccess || summary.TotalWarnings > 0 || summary.TotalInfo > 0
+	default:
+		return !summary.OverallSuccess
 	}
+}
 
-	// Lint all charts and collect results
-	for _, chartPath := range chartPaths {
-		lint2Result, err := lint2.LintChart(cmd.Context(), chartPath, helmVersion)
-		if err != nil {
-			return nil, errors.Wrapf(err, "failed to lint chart: %s", chartPath)
-		}
+// lintHelmCharts starts a lint task per chart on the pool and returns a function that
+// waits for them, applies the baseline and displays the results in chart order. Each
+// chart is linted with every combination of its HelmChart values and values files.
+func (r *runners) lintHelmCharts(
+	ctx context.Context,
+	pool *lintPool,
+	chartPaths []string,
+	charts []lint2.ChartWithMetadata,
+	helmChartManifests map[string]*lint2.HelmChartManifest,
+	configValues map[string]string,
+	helmVersion string,
+) func() (*HelmLintResults, error) {
+	chartsByPath := make(map[string]lint2.ChartWithMetadata, len(charts))
+	for _, chart := range charts {
+		chartsByPath[chart.Path] = chart
+	}
 
-		// Convert to structured format
-		chartResult := ChartLintResult{
-			Path:     chartPath,
-			Success:  lint2Result.Success,
-			Messages: convertLint2Messages(lint2Result.Messages),
-			Summary:  calculateResourceSummary(lint2Result.Messages),
-		}
-		results.Charts = append(results.Charts, chartResult)
+	var wg sync.WaitGroup
+	lint2Results := make([]*lint2.LintResult, len(chartPaths))
+	lintErrs := make([]error, len(chartPaths))
+	for i, chartPath := range chartPaths {
+		pool.Go(&wg, func() {
+			var combinations []lint2.HelmValuesCombination
+			if chart, ok := chartsByPath[chartPath]; ok {
+				var err error
+				combinations, err = lint2.HelmValuesMatrix(chart, helmChartManifests, configValues)
+				if err != nil {
+					// Report values that cannot be rendered against the chart instead of stopping the run
+					lint2Results[i] = r.linterErrorResult("helm", chartPath, "helm-values", err)
+					return
+				}
+			}
+
+			lint2Results[i], lintErrs[i] = r.lintCache.lint("helm", helmVersion,
+				func(key *lint2.LintCacheKey) error {
+					return addChartCacheInputs(key, chartPath, combinations)
+				},
+				func() (*lint2.LintResult, error) {
+					return lint2.LintChartWithValues(ctx, chartPath, helmVersion, combinations, r.lintOptions("helm")...)
+				},
+			)
+		})
 	}
 
-	// Display results in table format (only if table output)
-	if r.outputFormat == "table" {
-		// Convert to []LintableResult for generic display
-		lintableResults := make([]LintableResult, len(results.Charts))
-		for i, chart := range results.Charts {
-			lintableResults[i] = chart
+	return func() (*HelmLintResults, error) {
+		wg.Wait()
+
+		results := &HelmLintResults{
+			Enabled: true,
+			Charts:  make([]ChartLintResult, 0, len(chartPaths)),
+		}
+
+		// Collect results in chart order
+		for i, chartPath := range chartPaths {
+			if lintErrs[i] != nil {
+				return nil, errors.Wrapf(lintErrs[i], "failed to lint chart: %s", chartPath)
+			}
+			lint2Result := lint2Results[i]
+
+			// Helm reports message paths relative to the chart directory
+			messages, success := r.lintBaseline.filter("helm", chartPath, lint2Result.Success, lint2Result.Messages, true)
+
+			// Convert to structured format
+			chartResult := ChartLintResult{
+				Path:     chartPath,
+				Success:  success,
+				Messages: convertLint2Messages(messages),
+				Summary:  calculateResourceSummary(messages, lint2Result.Suppressed),
+			}
+			results.Charts = append(results.Charts, chartResult)
 		}
-		if err := r.displayLintResults("HELM CHARTS", "chart", "charts", lintableResults); err != nil {
-			return nil, errors.Wrap(err, "failed to display helm results")
+
+		// Display results in table format (only if table output)
+		if r.outputFormat == "table" {
+			if err := r.displayLintResults("HELM CHARTS", "chart", "charts", lintableResults(results.Charts)); err != nil {
+				return nil, errors.Wrap(err, "failed to display helm results")
+			}
 		}
+
+		return results, nil
 	}
+}
 
-	return results, nil
+// addChartCacheInputs adds a chart and the values it is linted with to a cache key
+func addChartCacheInputs(key *lint2.LintCacheKey, chartPath string, combinations []lint2.HelmValuesCombination) error {
+	if err := key.Path(chartPath); err != nil {
+		return err
+	}
+	if err := key.JSON(combinations); err != nil {
+		return err
+	}
+	for _, combination := range combinations {
+		for _, valuesFile := range combination.ValuesFiles {
+			if err := key.Path(valuesFile); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
-func (r *runners) lintPreflightSpecs(cmd *cobra.Command, preflights []lint2.PreflightWithValues, helmChartManifests map[string]*lint2.HelmChartManifest, preflightVersion string) (*PreflightLintResults, error) {
-	results := &PreflightLintResults{
-		Enabled: true,
-		Specs:   make([]PreflightLintResult, 0, len(preflights)),
+// lintPreflightSpecs starts a lint task per preflight spec on the pool and returns a
+// function that waits for them, applies the baseline and displays the results in spec order.
+func (r *runners) lintPreflightSpecs(ctx context.Context, pool *lintPool, preflights []lint2.PreflightWithValues, helmChartManifests map[string]*lint2.HelmChartManifest, preflightVersion string) func() (*PreflightLintResults, error) {
+	var wg sync.WaitGroup
+	lint2Results := make([]*lint2.LintResult, len(preflights))
+	lintErrs := make([]error, len(preflights))
+	for i, pf := range preflights {
+		pool.Go(&wg, func() {
+			lint2Results[i], lintErrs[i] = r.lintCache.lint("preflight", preflightVersion,
+				func(key *lint2.LintCacheKey) error {
+					key.String(pf.ChartName)
+					key.String(pf.ChartVersion)
+					if err := key.Path(pf.SpecPath); err != nil {
+						return err
+					}
+					if pf.ValuesPath != "" {
+						if err := key.Path(pf.ValuesPath); err != nil {
+							return err
+						}
+					}
+					// HelmChart builder values are rendered into the preflight spec
+					return key.JSON(helmChartManifests)
+				},
+				func() (*lint2.LintResult, error) {
+					return lint2.LintPreflight(
+						ctx,
+						pf.SpecPath,
+						pf.ValuesPath,
+						pf.ChartName,
+						pf.ChartVersion,
+						helmChartManifests,
+						preflightVersion,
+						r.lintOptions("preflight")...,
+					)
+				},
+			)
+		})
 	}
 
-	// Lint all preflight specs and collect results
-	for _, pf := range preflights {
-		lint2Result, err := lint2.LintPreflight(
-			cmd.Context(),
-			pf.SpecPath,
-			pf.ValuesPath,
-			pf.ChartName,
-			pf.ChartVersion,
-			helmChartManifests,
-			preflightVersion,
-		)
-		if err != nil {
-			return nil, errors.Wrapf(err, "failed to lint preflight spec: %s", pf.SpecPath)
-		}
+	return func() (*PreflightLintResults, error) {
+		wg.Wait()
 
-		// Convert to structured format
-		preflightResult := PreflightLintResult{
-			Path:     pf.SpecPath,
-			Success:  lint2Result.Success,
-			Messages: convertLint2Messages(lint2Result.Messages),
-			Summary:  calculateResourceSummary(lint2Result.Messages),
+		results := &PreflightLintResults{
+			Enabled: true,
+			Specs:   make([]PreflightLintResult, 0, len(preflights)),
 		}
-		results.Specs = append(results.Specs, preflightResult)
-	}
 
-	// Display results in table format (only if table output)
-	if r.outputFormat == "table" {
-		// Convert to []LintableResult for generic display
-		lintableResults := make([]LintableResult, len(results.Specs))
-		for i, spec := range results.Specs {
-			lintableResults[i] = spec
+		// Collect results in spec order
+		for i, pf := range preflights {
+			if lintErrs[i] != nil {
+				return nil, errors.Wrapf(lintErrs[i], "failed to lint preflight spec: %s", pf.SpecPath)
+			}
+			lint2Result := lint2Results[i]
+
+			messages, success := r.lintBaseline.filter("preflight", pf.SpecPath, lint2Result.Success, lint2Result.Messages, false)
+
+			// Convert to structured format
+			preflightResult := PreflightLintResult{
+				Path:     pf.SpecPath,
+				Success:  success,
+				Messages: convertLint2Messages(messages),
+				Summary:  calculateResourceSummary(messages, lint2Result.Suppressed),
+			}
+			results.Specs = append(results.Specs, preflightResult)
 		}
-		if err := r.displayLintResults("PREFLIGHT CHECKS", "preflight spec", "preflight specs", lintableResults); err != nil {
-			return nil, errors.Wrap(err, "failed to display preflight results")
+
+		// Display results in table format (only if table output)
+		if r.outputFormat == "table" {
+			if err := r.displayLintResults("PREFLIGHT CHECKS", "preflight spec", "preflight specs", lintableResults(results.Specs)); err != nil {
+				return nil, errors.Wrap(err, "failed to display preflight results")
+			}
 		}
-	}
 
-	return results, nil
+		return results, nil
+	}
 }
 
-func (r *runners) lintSupportBundleSpecs(cmd *cobra.Command, sbPaths []string, sbVersion string) (*SupportBundleLintResults, error) {
-	results := &SupportBundleLintResults{
-		Enabled: true,
-		Specs:   make([]SupportBundleLintResult, 0, len(sbPaths)),
+// lintSupportBundleSpecs starts a lint task per support bundle spec on the pool and returns
+// a function that waits for them, applies the baseline and displays the results in spec order.
+func (r *runners) lintSupportBundleSpecs(ctx context.Context, pool *lintPool, sbPaths []string, sbVersion string) func() (*SupportBundleLintResults, error) {
+	var wg sync.WaitGroup
+	lint2Results := make([]*lint2.LintResult, len(sbPaths))
+	lintErrs := make([]error, len(sbPaths))
+	for i, specPath := range sbPaths {
+		pool.Go(&wg, func() {
+			lint2Results[i], lintErrs[i] = r.lintCache.lint("support-bundle", sbVersion,
+				func(key *lint2.LintCacheKey) error {
+					return key.Path(specPath)
+				},
+				func() (*lint2.LintResult, error) {
+					return lint2.LintSupportBundle(ctx, specPath, sbVersion, r.lintOptions("support-bundle")...)
+				},
+			)
+		})
 	}
 
-	// If no support bundles found, that's not an error - they're optional
-	if len(sbPaths) == 0 {
-		return results, nil
-	}
+	return func() (*SupportBundleLintResults, error) {
+		wg.Wait()
 
-	// Lint all support bundle specs and collect results
-	for _, specPath := range sbPaths {
-		lint2Result, err := lint2.LintSupportBundle(cmd.Context(), specPath, sbVersion)
-		if err != nil {
-			return nil, errors.Wrapf(err, "failed to lint support bundle spec: %s", specPath)
+		results := &SupportBundleLintResults{
+			Enabled: true,
+			Specs:   make([]SupportBundleLintResult, 0, len(sbPaths)),
 		}
 
-		// Convert to structured format
-		sbResult := SupportBundleLintResult{
-			Path:     specPath,
-			Success:  lint2Result.Success,
-			Messages: convertLint2Messages(lint2Result.Messages),
-			Summary:  calculateResourceSummary(lint2Result.Messages),
+		// If no support bundles found, that's not an error - they're optional
+		if len(sbPaths) == 0 {
+			return results, nil
 		}
-		results.Specs = append(results.Specs, sbResult)
-	}
 
-	// Display results in table format (only if table output)
-	if r.outputFormat == "table" {
-		// Convert to []LintableResult for generic display
-		lintableResults := make([]LintableResult, len(results.Specs))
-		for i, spec := range results.Specs {
-			lintableResults[i] = spec
+		// Collect results in spec order
+		for i, specPath := range sbPaths {
+			if lintErrs[i] != nil {
+				return nil, errors.Wrapf(lintErrs[i], "failed to lint support bundle spec: %s", specPath)
+			}
+			lint2Result := lint2Results[i]
+
+			messages, success := r.lintBaseline.filter("support-bundle", specPath, lint2Result.Success, lint2Result.Messages, false)
+
+			// Convert to structured format
+			sbResult := SupportBundleLintResult{
+				Path:     specPath,
+				Success:  success,
+				Messages: convertLint2Messages(messages),
+				Summary:  calculateResourceSummary(messages, lint2Result.Suppressed),
+			}
+			results.Specs = append(results.Specs, sbResult)
 		}
-		if err := r.displayLintResults("SUPPORT BUNDLES", "support bundle spec", "support bundle specs", lintableResults); err != nil {
-			return nil, errors.Wrap(err, "failed to display support bundle results")
+
+		// Display results in table format (only if table output)
+		if r.outputFormat == "table" {
+			if err := r.displayLintResults("SUPPORT BUNDLES", "support bundle spec", "support bundle specs", lintableResults(results.Specs)); err != nil {
+				return nil, errors.Wrap(err, "failed to display support bundle results")
+			}
 		}
-	}
 
-	return results, nil
+		return results, nil
+	}
 }
 
 // Removed unused generic display helpers in favor of specific display functions
@@ -723,6 +1247,25 @@ func (r *runners) displayImages(results *ImageExtractResults) {
 	r.w.Flush()
 }
 
+// printLintOutput prints lint results in a machine-readable output format (json, junit or sarif)
+func (r *runners) printLintOutput(output *JSONLintOutput) error {
+	var printable interface{} = output
+	switch r.outputFormat {
+	case "sarif":
+		root, err := sarifSourceRootDir()
+		if err != nil {
+			return errors.Wrap(err, "failed to determine the SARIF source root")
+		}
+		printable = newSARIFLog(output, root)
+	case "junit":
+		printable = newJUnitReport(output)
+	}
+	if err := print.LintResults(r.outputFormat, r.w, printable); err != nil {
+		return errors.Wrapf(err, "failed to print %s output to stdout", strings.ToUpper(r.outputFormat))
+	}
+	return nil
+}
+
 // accumulateSummary adds results from a set of lintable resources to the summary.
 // Leverages the LintableResult interface to provide generic accumulation across all resource types.
 func accumulateSummary(summary *LintSummary, results []LintableResult) {
@@ -737,6 +1280,7 @@ func accumulateSummary(summary *LintSummary, results []LintableResult) {
 		summary.TotalErrors += s.ErrorCount
 		summary.TotalWarnings += s.WarningCount
 		summary.TotalInfo += s.InfoCount
+		summary.TotalSuppressed += s.SuppressedCount
 	}
 }
 
@@ -744,40 +1288,8 @@ func accumulateSummary(summary *LintSummary, results []LintableResult) {
 func (r *runners) calculateOverallSummary(output *JSONLintOutput) LintSummary {
 	summary := LintSummary{}
 
-	// Accumulate from Helm results
-	if output.HelmResults != nil {
-		results := make([]LintableResult, len(output.HelmResults.Charts))
-		for i, chart := range output.HelmResults.Charts {
-			results[i] = chart
-		}
-		accumulateSummary(&summary, results)
-	}
-
-	// Accumulate from Preflight results
-	if output.PreflightResults != nil {
-		results := make([]LintableResult, len(output.PreflightResults.Specs))
-		for i, spec := range output.PreflightResults.Specs {
-			results[i] = spec
-		}
-		accumulateSummary(&summary, results)
-	}
-
-	// Accumulate from Support Bundle results
-	if output.SupportBundleResults != nil {
-		results := make([]LintableResult, len(output.SupportBundleResults.Specs))
-		for i, spec := range output.SupportBundleResults.Specs {
-			results[i] = spec
-		}
-		accumulateSummary(&summary, results)
-	}
-
-	// Accumulate from Embedded Cluster results
-	if output.EmbeddedClusterResults != nil {
-		results := make([]LintableResult, len(output.EmbeddedClusterResults.Specs))
-		for i, spec := range output.EmbeddedClusterResults.Specs {
-			results[i] = spec
-		}
-		accumulateSummary(&summary, results)
+	for _, section := range output.lintSections() {
+		accumulateSummary(&summary, section.results)
 	}
 
 	summary.OverallSuccess = summary.FailedResources == 0
@@ -810,17 +1322,26 @@ func (r *runners) displayLintResults(
 			fmt.Fprintf(r.w, "No issues found\n")
 		} else {
 			for _, msg := range result.GetMessages() {
+				severity := msg.Severity
+				if msg.PromotedFrom != "" {
+					severity += " (strict)"
+				}
+				message := msg.displayMessage()
 				if msg.Path != "" {
-					fmt.Fprintf(r.w, "[%s] %s: %s\n", msg.Severity, msg.Path, msg.Message)
+					fmt.Fprintf(r.w, "[%s] %s: %s\n", severity, msg.Path, message)
 				} else {
-					fmt.Fprintf(r.w, "[%s] %s\n", msg.Severity, msg.Message)
+					fmt.Fprintf(r.w, "[%s] %s\n", severity, message)
 				}
 			}
 		}
 
 		summary := result.GetSummary()
-		fmt.Fprintf(r.w, "\nSummary for %s: %d error(s), %d warning(s), %d info\n",
+		fmt.Fprintf(r.w, "\nSummary for %s: %d error(s), %d warning(s), %d info",
 			result.GetPath(), summary.ErrorCount, summary.WarningCount, summary.InfoCount)
+		if summary.SuppressedCount > 0 {
+			fmt.Fprintf(r.w, ", %d suppressed", summary.SuppressedCount)
+		}
+		fmt.Fprintln(r.w)
 
 		if result.GetSuccess() {
 			fmt.Fprintf(r.w, "Status: Passed\n\n")
@@ -834,6 +1355,7 @@ func (r *runners) displayLintResults(
 		totalErrors := 0
 		totalWarnings := 0
 		totalInfo := 0
+		totalSuppressed := 0
 		failedResources := 0
 
 		for _, result := range results {
@@ -841,6 +1363,7 @@ func (r *runners) displayLintResults(
 			totalErrors += summary.ErrorCount
 			totalWarnings += summary.WarningCount
 			totalInfo += summary.InfoCount
+			totalSuppressed += summary.SuppressedCount
 			if !result.GetSuccess() {
 				failedResources++
 			}
@@ -853,6 +1376,9 @@ func (r *runners) displayLintResults(
 		fmt.Fprintf(r.w, "Total errors: %d\n", totalErrors)
 		fmt.Fprintf(r.w, "Total warnings: %d\n", totalWarnings)
 		fmt.Fprintf(r.w, "Total info: %d\n", totalInfo)
+		if totalSuppressed > 0 {
+			fmt.Fprintf(r.w, "Total suppressed: %d\n", totalSuppressed)
+		}
 
 		if failedResources > 0 {
 			fmt.Fprintf(r.w, "\nOverall Status: Failed\n")
@@ -864,57 +1390,560 @@ func (r *runners) displayLintResults(
 	return nil
 }
 
-// lintEmbeddedClusterManifests runs the EC CLI lint tool against the provided paths.
-func (r *runners) lintEmbeddedClusterManifests(cmd *cobra.Command, paths []string, ecVersion string, ecBinaryPath string, disableChecks []string) (*EmbeddedClusterLintResults, error) {
-	results := &EmbeddedClusterLintResults{
-		Enabled: true,
-		Specs:   []EmbeddedClusterLintResult{},
+// resolveECBinaryPath returns the EC binary to lint with: the configured binary path,
+// overridden by REPLICATED_EMBEDDED_CLUSTER_BINARY_PATH, or else the binary for the EC
+// version the manifests declare, downloaded by the resolver.
+func resolveECBinaryPath(ctx context.Context, resolver *tools.Resolver, ecConfig tools.ECLinterConfig, ecPaths []string) (string, error) {
+	ecBinaryPath := ecConfig.BinaryPath
+	if val := os.Getenv("REPLICATED_EMBEDDED_CLUSTER_BINARY_PATH"); val != "" {
+		ecBinaryPath = val
+	}
+	if ecBinaryPath != "" {
+		return ecBinaryPath, nil
 	}
 
-	if ecBinaryPath == "" {
-		resolver := tools.NewResolver()
-		var err error
-		ecBinaryPath, err = resolver.Resolve(cmd.Context(), tools.ToolEmbeddedCluster, ecVersion)
-		if err != nil {
-			return nil, errors.Wrap(err, "resolving embedded-cluster binary")
+	// Version discovery is only needed when no binary path is provided, since
+	// the version is used solely to download the binary via the resolver.
+	ecVersion, err := lint2.DiscoverECVersion(ecPaths)
+	if err != nil {
+		return "", errors.Wrap(err, "discovering embedded-cluster version")
+	}
+	ecBinaryPath, err = resolver.Resolve(ctx, tools.ToolEmbeddedCluster, ecVersion)
+	if err != nil {
+		return "", errors.Wrap(err, "resolving embedded-cluster binary")
+	}
+	return ecBinaryPath, nil
+}
+
+// lintEmbeddedClusterManifests starts the EC CLI lint tool against the provided paths on the
+// pool and returns a function that waits for it, applies the baseline and displays the result.
+func (r *runners) lintEmbeddedClusterManifests(ctx context.Context, pool *lintPool, paths []string, ecBinaryPath string, disableChecks []string) func() (*EmbeddedClusterLintResults, error) {
+	var wg sync.WaitGroup
+	var lint2Result *lint2.LintResult
+	var lintErr error
+	pool.Go(&wg, func() {
+		// The EC binary has no version to key on, so its path, size and modification time stand in
+		lint2Result, lintErr = r.lintCache.lint("embedded-cluster", "",
+			func(key *lint2.LintCacheKey) error {
+				info, err := os.Stat(ecBinaryPath)
+				if err != nil {
+					return err
+				}
+				key.String(fmt.Sprintf("%s:%d:%d", ecBinaryPath, info.Size(), info.ModTime().UnixNano()))
+				if err := key.JSON(disableChecks); err != nil {
+					return err
+				}
+				for _, path := range paths {
+					if err := key.Path(path); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			func() (*lint2.LintResult, error) {
+				return lint2.LintEmbeddedCluster(ctx, paths, ecBinaryPath, disableChecks, r.lintOptions("embedded-cluster")...)
+			},
+		)
+	})
+
+	return func() (*EmbeddedClusterLintResults, error) {
+		wg.Wait()
+		if lintErr != nil {
+			return nil, errors.Wrap(lintErr, "failed to run ec lint")
+		}
+
+		results := &EmbeddedClusterLintResults{
+			Enabled: true,
+			Specs:   []EmbeddedClusterLintResult{},
+		}
+
+		// We treat the entire run as one result (EC lint scans across files itself).
+		// Individual messages carry their own file path from the EC lint output,
+		// so we use a fixed label here: findings without a path are fingerprinted
+		// with it, and it must not change as manifests are added or removed.
+		pathLabel := "embedded-cluster"
+		r.lintBaseline.markLinted("embedded-cluster", paths...)
+		messages, success := r.lintBaseline.filter("embedded-cluster", pathLabel, lint2Result.Success, lint2Result.Messages, false)
+		ecResult := EmbeddedClusterLintResult{
+			Path:     pathLabel,
+			Success:  success,
+			Messages: convertLint2Messages(messages),
+			Summary:  calculateResourceSummary(messages, lint2Result.Suppressed),
+		}
+		results.Specs = append(results.Specs, ecResult)
+
+		if r.outputFormat == "table" {
+			if err := r.displayLintResults("EMBEDDED CLUSTER", "embedded cluster path", "paths", []LintableResult{ecResult}); err != nil {
+				return nil, errors.Wrap(err, "failed to display embedded cluster results")
+			}
+		}
+
+		return results, nil
+	}
+}
+
+// lintKotsManifests starts the built-in KOTS linter against the provided manifest files on
+// the pool and returns a function that waits for it, applies the baseline and displays the results.
+// Each file containing KOTS kinds is cached on its own, keyed by every manifest file and
+// chart, since KOTS resolves config references and charts across files.
+func (r *runners) lintKotsManifests(pool *lintPool, manifestPaths []string, charts []lint2.ChartWithMetadata) func() (*KotsLintResults, error) {
+	var wg sync.WaitGroup
+	var kotsPaths []string
+	var lint2Results []*lint2.LintResult
+	var lintErr error
+	pool.Go(&wg, func() {
+		kotsPaths, lintErr = lint2.DiscoverKotsManifests(manifestPaths)
+		if lintErr != nil {
+			return
+		}
+
+		// The manifests are linted together, once, and only if a file misses the cache
+		lintAll := sync.OnceValues(func() (map[string]*lint2.LintResult, error) {
+			fileResults, err := lint2.LintKots(manifestPaths, charts, r.lintOptions("kots")...)
+			if err != nil {
+				return nil, err
+			}
+			byPath := make(map[string]*lint2.LintResult, len(fileResults))
+			for _, fileResult := range fileResults {
+				byPath[fileResult.Path] = &lint2.LintResult{
+					Success:    fileResult.Success,
+					Messages:   fileResult.Messages,
+					Suppressed: fileResult.Suppressed,
+				}
+			}
+			return byPath, nil
+		})
+
+		lint2Results = make([]*lint2.LintResult, len(kotsPaths))
+		for i, kotsPath := range kotsPaths {
+			lint2Results[i], lintErr = r.lintCache.lint("kots", "",
+				func(key *lint2.LintCacheKey) error {
+					key.String(kotsPath)
+					if err := key.JSON(charts); err != nil {
+						return err
+					}
+					for _, path := range manifestPaths {
+						if err := key.Path(path); err != nil {
+							return err
+						}
+					}
+					return nil
+				},
+				func() (*lint2.LintResult, error) {
+					byPath, err := lintAll()
+					if err != nil {
+						return nil, err
+					}
+					if result, ok := byPath[kotsPath]; ok {
+						return result, nil
+					}
+					return &lint2.LintResult{Success: true}, nil
+				},
+			)
+			if lintErr != nil {
+				return
+			}
+		}
+	})
+
+	return func() (*KotsLintResults, error) {
+		wg.Wait()
+		if lintErr != nil {
+			return nil, errors.Wrap(lintErr, "failed to run kots lint")
+		}
+
+		results := &KotsLintResults{
+			Enabled:   true,
+			Manifests: []KotsLintResult{},
+		}
+
+		for i, kotsPath := range kotsPaths {
+			// With --changed-since, every manifest is linted but only changed ones are reported
+			if r.lintChanged != nil && !r.lintChanged.Contains(kotsPath) {
+				continue
+			}
+			lint2Result := lint2Results[i]
+			messages, success := r.lintBaseline.filter("kots", kotsPath, lint2Result.Success, lint2Result.Messages, false)
+			results.Manifests = append(results.Manifests, KotsLintResult{
+				Path:     kotsPath,
+				Success:  success,
+				Messages: convertLint2Messages(messages),
+				Summary:  calculateResourceSummary(messages, lint2Result.Suppressed),
+			})
+		}
+
+		if r.outputFormat == "table" {
+			if err := r.displayLintResults("KOTS MANIFESTS", "kots manifest", "kots manifests", lintableResults(results.Manifests)); err != nil {
+				return nil, errors.Wrap(err, "failed to display kots results")
+			}
+		}
+
+		return results, nil
+	}
+}
+
+// lintImages starts a task per chart on the pool that renders the chart and checks the
+// images it references against the image linter's rules, and returns a function that
+// waits for them, applies the baseline and displays the results in chart order.
+// Charts are rendered with the same values combinations helm lint uses.
+func (r *runners) lintImages(
+	ctx context.Context,
+	pool *lintPool,
+	chartPaths []string,
+	charts []lint2.ChartWithMetadata,
+	helmChartManifests map[string]*lint2.HelmChartManifest,
+	configValues map[string]string,
+	imageConfig tools.ImageLinterConfig,
+) func() (*ImageLintResults, error) {
+	chartsByPath := make(map[string]lint2.ChartWithMetadata, len(charts))
+	for _, chart := range charts {
+		chartsByPath[chart.Path] = chart
+	}
+	policy := lint2.NewImagePolicy(imageConfig)
+
+	var wg sync.WaitGroup
+	lint2Results := make([]*lint2.LintResult, len(chartPaths))
+	lintErrs := make([]error, len(chartPaths))
+	for i, chartPath := range chartPaths {
+		pool.Go(&wg, func() {
+			var combinations []lint2.HelmValuesCombination
+			if chart, ok := chartsByPath[chartPath]; ok {
+				var err error
+				combinations, err = lint2.HelmValuesMatrix(chart, helmChartManifests, configValues)
+				if err != nil {
+					lint2Results[i] = r.linterErrorResult("image", chartPath, "helm-values", err)
+					return
+				}
+			}
+
+			lint2Results[i], lintErrs[i] = r.lintCache.lint("image", "",
+				func(key *lint2.LintCacheKey) error {
+					// The rules and their settings are not part of the common linter config
+					if err := key.JSON(imageConfig); err != nil {
+						return err
+					}
+					return addChartCacheInputs(key, chartPath, combinations)
+				},
+				func() (*lint2.LintResult, error) {
+					return lint2.LintChartImages(ctx, chartPath, combinations, policy, r.lintOptions("image")...)
+				},
+			)
+		})
+	}
+
+	return func() (*ImageLintResults, error) {
+		wg.Wait()
+
+		results := &ImageLintResults{
+			Enabled: true,
+			Charts:  make([]ImageLintResult, 0, len(chartPaths)),
+		}
+
+		for i, chartPath := range chartPaths {
+			if lintErrs[i] != nil {
+				return nil, errors.Wrapf(lintErrs[i], "failed to lint images in chart: %s", chartPath)
+			}
+			lint2Result := lint2Results[i]
+
+			// Messages point at chart templates, relative to the chart directory
+			messages, success := r.lintBaseline.filter("image", chartPath, lint2Result.Success, lint2Result.Messages, true)
+			results.Charts = append(results.Charts, ImageLintResult{
+				Path:     chartPath,
+				Success:  success,
+				Messages: convertLint2Messages(messages),
+				Summary:  calculateResourceSummary(messages, lint2Result.Suppressed),
+			})
+		}
+
+		if r.outputFormat == "table" {
+			if err := r.displayLintResults("IMAGES", "chart", "charts", lintableResults(results.Charts)); err != nil {
+				return nil, errors.Wrap(err, "failed to display image results")
+			}
 		}
+
+		return results, nil
 	}
+}
 
-	lint2Result, err := lint2.LintEmbeddedCluster(cmd.Context(), paths, ecBinaryPath, disableChecks)
+// lintReleaseGraph runs the release graph linter against every chart, manifest and
+// preflight in the config, applies the baseline and displays the results. The graph
+// spans every resource, so it is neither cached nor limited by --changed-since.
+func (r *runners) lintReleaseGraph(config *tools.Config) (*ReleaseGraphLintResults, error) {
+	lint2Results, err := lint2.LintReleaseGraph(config, r.lintOptions("release-graph")...)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to run ec lint")
-	}
-
-	// We treat the entire run as one result (EC lint scans across files itself).
-	// Individual messages carry their own file path from the EC lint output,
-	// so we use a short summary label here rather than joining all paths.
-	var pathLabel string
-	switch len(paths) {
-	case 0:
-		pathLabel = "embedded-cluster"
-	case 1:
-		pathLabel = paths[0]
-	default:
-		pathLabel = fmt.Sprintf("%d manifest files", len(paths))
+		return nil, errors.Wrap(err, "failed to run release graph lint")
+	}
+
+	results := &ReleaseGraphLintResults{
+		Enabled:   true,
+		Resources: []ReleaseGraphLintResult{},
 	}
-	ecResult := EmbeddedClusterLintResult{
-		Path:     pathLabel,
-		Success:  lint2Result.Success,
-		Messages: convertLint2Messages(lint2Result.Messages),
-		Summary:  calculateResourceSummary(lint2Result.Messages),
+
+	for _, resource := range lint2Results {
+		relative := resource.Kind == lint2.ReleaseGraphChart
+		messages, success := r.lintBaseline.filter("release-graph", resource.Path, resource.Success, resource.Messages, relative)
+		results.Resources = append(results.Resources, ReleaseGraphLintResult{
+			Path:     resource.Path,
+			Kind:     resource.Kind,
+			Success:  success,
+			Messages: convertLint2Messages(messages),
+			Summary:  calculateResourceSummary(messages, resource.Suppressed),
+		})
 	}
-	results.Specs = append(results.Specs, ecResult)
 
 	if r.outputFormat == "table" {
-		lintableResults := []LintableResult{ecResult}
-		if err := r.displayLintResults("EMBEDDED CLUSTER", "embedded cluster path", "paths", lintableResults); err != nil {
-			return nil, errors.Wrap(err, "failed to display embedded cluster results")
+		if err := r.displayLintResults("RELEASE GRAPH", "resource", "resources", lintableResults(results.Resources)); err != nil {
+			return nil, errors.Wrap(err, "failed to display release graph results")
 		}
 	}
 
 	return results, nil
 }
 
+// lintKubeSchema starts a task per chart on the pool that renders the chart for each
+// configured Kubernetes version and validates the objects against that version's
+// schema, and returns a function that waits for them, applies the baseline and
+// displays the results in chart order. Charts are rendered with the same values
+// combinations helm lint uses.
+func (r *runners) lintKubeSchema(
+	pool *lintPool,
+	chartPaths []string,
+	charts []lint2.ChartWithMetadata,
+	helmChartManifests map[string]*lint2.HelmChartManifest,
+	configValues map[string]string,
+	versions []string,
+	schemaData [][]byte,
+) func() (*KubeSchemaLintResults, error) {
+	chartsByPath := make(map[string]lint2.ChartWithMetadata, len(charts))
+	for _, chart := range charts {
+		chartsByPath[chart.Path] = chart
+	}
+
+	// Schemas are only parsed once a chart actually needs linting, so unchanged
+	// charts served from the cache cost nothing
+	loadSchemas := sync.OnceValues(func() ([]*lint2.KubeSchema, error) {
+		schemas := make([]*lint2.KubeSchema, len(versions))
+		for i, version := range versions {
+			schema, err := lint2.ParseKubeSchema(version, schemaData[i])
+			if err != nil {
+				return nil, err
+			}
+			schemas[i] = schema
+		}
+		return schemas, nil
+	})
+
+	var wg sync.WaitGroup
+	lint2Results := make([]*lint2.LintResult, len(chartPaths))
+	lintErrs := make([]error, len(chartPaths))
+	for i, chartPath := range chartPaths {
+		pool.Go(&wg, func() {
+			var combinations []lint2.HelmValuesCombination
+			if chart, ok := chartsByPath[chartPath]; ok {
+				var err error
+				combinations, err = lint2.HelmValuesMatrix(chart, helmChartManifests, configValues)
+				if err != nil {
+					lint2Results[i] = r.linterErrorResult("kube-schema", chartPath, "helm-values", err)
+					return
+				}
+			}
+
+			lint2Results[i], lintErrs[i] = r.lintCache.lint("kube-schema", strings.Join(versions, ","),
+				func(key *lint2.LintCacheKey) error {
+					return addChartCacheInputs(key, chartPath, combinations)
+				},
+				func() (*lint2.LintResult, error) {
+					schemas, err := loadSchemas()
+					if err != nil {
+						return nil, err
+					}
+					return lint2.LintKubeSchema(chartPath, combinations, schemas, r.lintOptions("kube-schema")...)
+				},
+			)
+		})
+	}
+
+	return func() (*KubeSchemaLintResults, error) {
+		wg.Wait()
+
+		results := &KubeSchemaLintResults{
+			Enabled:  true,
+			Versions: versions,
+			Charts:   make([]KubeSchemaLintResult, 0, len(chartPaths)),
+		}
+
+		for i, chartPath := range chartPaths {
+			if lintErrs[i] != nil {
+				return nil, errors.Wrapf(lintErrs[i], "failed to validate chart: %s", chartPath)
+			}
+			lint2Result := lint2Results[i]
+
+			// Messages point at chart templates, relative to the chart directory
+			messages, success := r.lintBaseline.filter("kube-schema", chartPath, lint2Result.Success, lint2Result.Messages, true)
+			results.Charts = append(results.Charts, KubeSchemaLintResult{
+				Path:     chartPath,
+				Success:  success,
+				Messages: convertLint2Messages(messages),
+				Summary:  calculateResourceSummary(messages, lint2Result.Suppressed),
+			})
+		}
+
+		if r.outputFormat == "table" {
+			title := fmt.Sprintf("KUBERNETES SCHEMAS (%s)", strings.Join(versions, ", "))
+			if err := r.displayLintResults(title, "chart", "charts", lintableResults(results.Charts)); err != nil {
+				return nil, errors.Wrap(err, "failed to display kubernetes schema results")
+			}
+		}
+
+		return results, nil
+	}
+}
+
+// policyTask is a resource policies are evaluated against
+type policyTask struct {
+	path   string
+	source string // lint2.PolicySourceChart, PolicySourceManifest or PolicySourcePreflight
+	inputs func(key *lint2.LintCacheKey) error
+	lint   func() (*lint2.LintResult, error)
+}
+
+// lintPolicies starts a task per chart, manifest file and preflight spec on the pool
+// that evaluates the policies against it, and returns a function that waits for
+// them, applies the baseline and displays the results in that order. Charts are
+// rendered with the same values combinations helm lint uses.
+func (r *runners) lintPolicies(
+	pool *lintPool,
+	policies *lint2.Policies,
+	policiesDir string,
+	chartPaths []string,
+	manifestPaths []string,
+	preflights []lint2.PreflightWithValues,
+	charts []lint2.ChartWithMetadata,
+	helmChartManifests map[string]*lint2.HelmChartManifest,
+	configValues map[string]string,
+) func() (*PolicyLintResults, error) {
+	chartsByPath := make(map[string]lint2.ChartWithMetadata, len(charts))
+	for _, chart := range charts {
+		chartsByPath[chart.Path] = chart
+	}
+
+	var tasks []policyTask
+	for _, chartPath := range chartPaths {
+		var combinations []lint2.HelmValuesCombination
+		var combinationsErr error
+		if chart, ok := chartsByPath[chartPath]; ok {
+			combinations, combinationsErr = lint2.HelmValuesMatrix(chart, helmChartManifests, configValues)
+		}
+		tasks = append(tasks, policyTask{
+			path:   chartPath,
+			source: lint2.PolicySourceChart,
+			inputs: func(key *lint2.LintCacheKey) error {
+				if combinationsErr != nil {
+					return combinationsErr
+				}
+				return addChartCacheInputs(key, chartPath, combinations)
+			},
+			lint: func() (*lint2.LintResult, error) {
+				if combinationsErr != nil {
+					return r.linterErrorResult("policy", chartPath, "helm-values", combinationsErr), nil
+				}
+				return lint2.LintChartPolicies(chartPath, combinations, policies, r.lintOptions("policy")...)
+			},
+		})
+	}
+	for _, manifestPath := range manifestPaths {
+		tasks = append(tasks, policyTask{
+			path:   manifestPath,
+			source: lint2.PolicySourceManifest,
+			inputs: func(key *lint2.LintCacheKey) error {
+				if err := key.Path(manifestPath); err != nil {
+					return err
+				}
+				return key.JSON(configValues)
+			},
+			lint: func() (*lint2.LintResult, error) {
+				return lint2.LintManifestPolicies(manifestPath, configValues, policies, r.lintOptions("policy")...)
+			},
+		})
+	}
+	for _, pf := range preflights {
+		tasks = append(tasks, policyTask{
+			path:   pf.SpecPath,
+			source: lint2.PolicySourcePreflight,
+			inputs: func(key *lint2.LintCacheKey) error {
+				key.String(pf.ChartName)
+				key.String(pf.ChartVersion)
+				if err := key.Path(pf.SpecPath); err != nil {
+					return err
+				}
+				if pf.ValuesPath != "" {
+					if err := key.Path(pf.ValuesPath); err != nil {
+						return err
+					}
+				}
+				return key.JSON(helmChartManifests)
+			},
+			lint: func() (*lint2.LintResult, error) {
+				return lint2.LintPreflightPolicies(pf, helmChartManifests, policies, r.lintOptions("policy")...)
+			},
+		})
+	}
+
+	var wg sync.WaitGroup
+	lint2Results := make([]*lint2.LintResult, len(tasks))
+	lintErrs := make([]error, len(tasks))
+	for i, task := range tasks {
+		pool.Go(&wg, func() {
+			lint2Results[i], lintErrs[i] = r.lintCache.lint("policy", "",
+				func(key *lint2.LintCacheKey) error {
+					key.String(task.source)
+					// Every resource is linted again when any policy changes
+					if err := key.Path(policiesDir); err != nil {
+						return err
+					}
+					return task.inputs(key)
+				},
+				task.lint,
+			)
+		})
+	}
+
+	return func() (*PolicyLintResults, error) {
+		wg.Wait()
+
+		results := &PolicyLintResults{
+			Enabled:   true,
+			Resources: make([]PolicyLintResult, 0, len(tasks)),
+		}
+
+		for i, task := range tasks {
+			if lintErrs[i] != nil {
+				return nil, errors.Wrapf(lintErrs[i], "failed to evaluate policies for %s", task.path)
+			}
+			lint2Result := lint2Results[i]
+
+			// Chart messages point at templates, relative to the chart directory
+			relative := task.source == lint2.PolicySourceChart
+			messages, success := r.lintBaseline.filter("policy", task.path, lint2Result.Success, lint2Result.Messages, relative)
+			results.Resources = append(results.Resources, PolicyLintResult{
+				Path:     task.path,
+				Source:   task.source,
+				Success:  success,
+				Messages: convertLint2Messages(messages),
+				Summary:  calculateResourceSummary(messages, lint2Result.Suppressed),
+			})
+		}
+
+		if r.outputFormat == "table" {
+			if err := r.displayLintResults("POLICIES", "resource", "resources", lintableResults(results.Resources)); err != nil {
+				return nil, errors.Wrap(err, "failed to display policy results")
+			}
+		}
+
+		return results, nil
+	}
+}
+
 // findConfigFilePath finds the .replicated config file path
 func findConfigFilePath(startPath string) string {
 	currentDir := startPath
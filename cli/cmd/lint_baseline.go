@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/replicated/pkg/lint2"
+)
+
+// BaselineResults describes how the configured lint baseline was applied
+type BaselineResults struct {
+	File       string                `json:"file"`
+	Suppressed int                   `json:"suppressed"`
+	Stale      []lint2.BaselineEntry `json:"stale"`
+}
+
+// lintFinding is a single lint message as seen by the baseline filter, with its
+// file path already resolved
+type lintFinding struct {
+	linter  string
+	path    string
+	rule    string
+	message string
+}
+
+// lintBaselineFilter suppresses findings recorded in a lint baseline file. It also
+// records every finding it sees, suppressed or not, and every resource each linter
+// ran on, so --write-baseline can replace the entries for the linted resources
+// while keeping the entries for resources this run did not lint.
+type lintBaselineFilter struct {
+	baselinePath string                 // configured baseline file ("" if none)
+	matcher      *lint2.BaselineMatcher // nil if no baseline is loaded
+	findings     []lintFinding
+	linted       map[string][]string // linter -> resource paths it ran on
+	suppressed   int
+}
+
+// newLintBaselineFilter loads the baseline at baselinePath. An empty path means no
+// findings are suppressed. A missing baseline file is only allowed when a new
+// baseline is about to be written.
+func newLintBaselineFilter(baselinePath string, writingBaseline bool) (*lintBaselineFilter, error) {
+	f := &lintBaselineFilter{baselinePath: baselinePath}
+	if baselinePath == "" {
+		return f, nil
+	}
+
+	baseline, err := lint2.LoadBaseline(baselinePath)
+	if err != nil {
+		if writingBaseline && errors.Is(err, fs.ErrNotExist) {
+			return f, nil
+		}
+		return nil, errors.Wrap(err, "failed to load lint baseline")
+	}
+	f.matcher = lint2.NewBaselineMatcher(baseline)
+
+	return f, nil
+}
+
+// filter removes baselined messages from a linted resource's results and returns the
+// remaining messages and the resource's success. A resource that only failed because
+// of baselined errors is considered successful. A nil filter returns its input unchanged.
+func (f *lintBaselineFilter) filter(linter, resultPath string, success bool, messages []lint2.LintMessage, messagePathsRelative bool) ([]lint2.LintMessage, bool) {
+	if f == nil {
+		return messages, success
+	}
+	f.markLinted(linter, resultPath)
+
+	hadErrors := false
+	remainingErrors := false
+	kept := make([]lint2.LintMessage, 0, len(messages))
+
+	for _, msg := range messages {
+		path := resolveLintMessagePath(resultPath, msg.Path, messagePathsRelative)
+		f.findings = append(f.findings, lintFinding{linter: linter, path: path, rule: msg.Rule, message: msg.Message})
+
+		if msg.Severity == "ERROR" {
+			hadErrors = true
+		}
+
+		if f.matcher != nil {
+			entry := lint2.NewBaselineEntry(linter, lint2.BaselineRelativePath(f.baselinePath, path), msg.Rule, msg.Message)
+			if f.matcher.Match(entry) {
+				f.suppressed++
+				continue
+			}
+		}
+
+		if msg.Severity == "ERROR" {
+			remainingErrors = true
+		}
+		kept = append(kept, msg)
+	}
+
+	if !success && hadErrors && !remainingErrors {
+		success = true
+	}
+
+	return kept, success
+}
+
+// markLinted records that linter ran on paths. filter marks the resource it is
+// given; linters that scan several files as one result mark each file.
+func (f *lintBaselineFilter) markLinted(linter string, paths ...string) {
+	if f == nil {
+		return
+	}
+	if f.linted == nil {
+		f.linted = make(map[string][]string)
+	}
+	f.linted[linter] = append(f.linted[linter], paths...)
+}
+
+// covers reports whether entry, read from baselineFile, belongs to a resource
+// linted in this run: its linter ran on the entry's path or a directory containing it
+func (f *lintBaselineFilter) covers(baselineFile string, entry lint2.BaselineEntry) bool {
+	for _, path := range f.linted[entry.Linter] {
+		resource := lint2.BaselineRelativePath(baselineFile, path)
+		if entry.Path == resource || strings.HasPrefix(entry.Path, resource+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// results returns how the baseline was applied, or nil if no baseline was loaded.
+// Must be called after all linters have run so stale entries are accurate. Only
+// entries for resources linted in this run can be stale; the others were not
+// checked (e.g. with --changed-since or a disabled linter).
+func (f *lintBaselineFilter) results() *BaselineResults {
+	if f == nil || f.matcher == nil {
+		return nil
+	}
+
+	stale := []lint2.BaselineEntry{}
+	for _, entry := range f.matcher.Stale() {
+		if f.covers(f.baselinePath, entry) {
+			stale = append(stale, entry)
+		}
+	}
+
+	return &BaselineResults{
+		File:       f.baselinePath,
+		Suppressed: f.suppressed,
+		Stale:      stale,
+	}
+}
+
+// writeBaseline writes every finding seen during the run to path. Entries already
+// in the file at path are kept for resources this run did not lint, so a partial
+// run (--changed-since, disabled linters, a failed release graph) does not drop
+// them. It returns the number of entries written.
+func (f *lintBaselineFilter) writeBaseline(path string) (int, error) {
+	entries := make([]lint2.BaselineEntry, 0, len(f.findings))
+	for _, finding := range f.findings {
+		entries = append(entries, lint2.NewBaselineEntry(finding.linter, lint2.BaselineRelativePath(path, finding.path), finding.rule, finding.message))
+	}
+
+	existing, err := lint2.LoadBaseline(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return 0, err
+	}
+	if existing != nil {
+		current := lint2.NewBaselineMatcher(&lint2.Baseline{Findings: entries})
+		for _, entry := range existing.Findings {
+			// Skip entries this run re-checked, and entries it already wrote again
+			if f.covers(path, entry) || current.Match(entry) {
+				continue
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	if err := lint2.WriteBaseline(path, entries); err != nil {
+		return 0, err
+	}
+
+	return len(entries), nil
+}
+
+// displayBaselineResults prints the baseline summary in table format
+func (r *runners) displayBaselineResults(results *BaselineResults) {
+	if results == nil {
+		return
+	}
+
+	fmt.Fprintf(r.w, "Baseline: %d known finding(s) suppressed by %s\n", results.Suppressed, results.File)
+	if len(results.Stale) > 0 {
+		fmt.Fprintf(r.w, "Warning: %d baseline entry(s) no longer match any finding and can be removed:\n", len(results.Stale))
+		for _, entry := range results.Stale {
+			if entry.Path != "" {
+				fmt.Fprintf(r.w, "  - [%s] %s: %s\n", entry.Linter, entry.Path, entry.Message)
+			} else {
+				fmt.Fprintf(r.w, "  - [%s] %s\n", entry.Linter, entry.Message)
+			}
+		}
+		fmt.Fprintf(r.w, "Run with --write-baseline %s to refresh the baseline.\n", results.File)
+	}
+	fmt.Fprintln(r.w)
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/replicatedhq/replicated/pkg/lint2"
+)
+
+func TestLintBaselineFilter(t *testing.T) {
+	dir := t.TempDir()
+	baselinePath := filepath.Join(dir, ".replicated-lint-baseline.json")
+	chartPath := filepath.Join(dir, "charts", "app")
+
+	// Record the current findings as the baseline
+	recorder, err := newLintBaselineFilter("", true)
+	if err != nil {
+		t.Fatalf("newLintBaselineFilter() error = %v", err)
+	}
+	recorder.filter("helm", chartPath, false, []lint2.LintMessage{
+		{Severity: "ERROR", Path: "templates/deployment.yaml", Message: "line 4: known error"},
+		{Severity: "WARNING", Path: "values.yaml", Message: "known warning"},
+		{Severity: "INFO", Path: "Chart.yaml", Message: "since fixed"},
+	}, true)
+	count, err := recorder.writeBaseline(baselinePath)
+	if err != nil {
+		t.Fatalf("writeBaseline() error = %v", err)
+	}
+	if count != 3 {
+		t.Errorf("expected 3 findings written, got %d", count)
+	}
+
+	// A later run with the baseline only reports new findings
+	f, err := newLintBaselineFilter(baselinePath, false)
+	if err != nil {
+		t.Fatalf("newLintBaselineFilter() error = %v", err)
+	}
+	messages, success := f.filter("helm", chartPath, false, []lint2.LintMessage{
+		{Severity: "ERROR", Path: "templates/deployment.yaml", Message: "line 9: known error"},
+		{Severity: "WARNING", Path: "values.yaml", Message: "known warning"},
+		{Severity: "WARNING", Path: "values.yaml", Message: "new warning"},
+	}, true)
+
+	if !success {
+		t.Error("expected resource to pass once its only error is baselined")
+	}
+	if len(messages) != 1 || messages[0].Message != "new warning" {
+		t.Errorf("expected only the new finding to be reported, got %+v", messages)
+	}
+
+	results := f.results()
+	if results.Suppressed != 2 {
+		t.Errorf("expected 2 suppressed findings, got %d", results.Suppressed)
+	}
+	if len(results.Stale) != 1 || results.Stale[0].Message != "since fixed" || results.Stale[0].Path != "charts/app/Chart.yaml" {
+		t.Errorf("expected the fixed finding to be reported as stale, got %+v", results.Stale)
+	}
+}
+
+func TestLintBaselineFilter_NewErrorStillFails(t *testing.T) {
+	f, err := newLintBaselineFilter("", false)
+	if err != nil {
+		t.Fatalf("newLintBaselineFilter() error = %v", err)
+	}
+
+	messages, success := f.filter("preflight", "preflight.yaml", false, []lint2.LintMessage{
+		{Severity: "ERROR", Message: "new error"},
+	}, false)
+	if success || len(messages) != 1 {
+		t.Errorf("expected unbaselined error to fail, got success=%v messages=%+v", success, messages)
+	}
+	if f.results() != nil {
+		t.Error("expected no baseline results when no baseline is configured")
+	}
+}
+
+func TestNewLintBaselineFilter_MissingFile(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.json")
+
+	if _, err := newLintBaselineFilter(missing, false); err == nil {
+		t.Error("expected error for a missing baseline file")
+	}
+	if _, err := newLintBaselineFilter(missing, true); err != nil {
+		t.Errorf("expected a missing baseline file to be allowed when writing a baseline, got %v", err)
+	}
+}
+
+func TestLintBaselineFilter_Nil(t *testing.T) {
+	var f *lintBaselineFilter
+	input := []lint2.LintMessage{{Severity: "ERROR", Message: "error"}}
+
+	messages, success := f.filter("helm", "chart", false, input, true)
+	if success || len(messages) != 1 {
+		t.Errorf("expected nil filter to return input unchanged, got success=%v messages=%+v", success, messages)
+	}
+	if f.results() != nil {
+		t.Error("expected nil results from nil filter")
+	}
+}
+
+func TestLintBaselineFilter_PartialRun(t *testing.T) {
+	dir := t.TempDir()
+	baselinePath := filepath.Join(dir, ".replicated-lint-baseline.json")
+	appPath := filepath.Join(dir, "charts", "app")
+	dbPath := filepath.Join(dir, "charts", "db")
+
+	recorder, err := newLintBaselineFilter("", true)
+	if err != nil {
+		t.Fatalf("newLintBaselineFilter() error = %v", err)
+	}
+	recorder.filter("helm", appPath, true, []lint2.LintMessage{
+		{Severity: "WARNING", Path: "values.yaml", Message: "app warning"},
+	}, true)
+	recorder.filter("helm", dbPath, true, []lint2.LintMessage{
+		{Severity: "WARNING", Path: "values.yaml", Message: "db warning"},
+	}, true)
+	recorder.filter("preflight", filepath.Join(dir, "preflight.yaml"), true, []lint2.LintMessage{
+		{Severity: "WARNING", Message: "preflight warning"},
+	}, false)
+	if _, err := recorder.writeBaseline(baselinePath); err != nil {
+		t.Fatalf("writeBaseline() error = %v", err)
+	}
+
+	// A later run only lints the app chart (e.g. --changed-since) and has fixed its warning
+	f, err := newLintBaselineFilter(baselinePath, true)
+	if err != nil {
+		t.Fatalf("newLintBaselineFilter() error = %v", err)
+	}
+	f.filter("helm", appPath, true, []lint2.LintMessage{
+		{Severity: "WARNING", Path: "templates/service.yaml", Message: "new app warning"},
+	}, true)
+
+	results := f.results()
+	if len(results.Stale) != 1 || results.Stale[0].Message != "app warning" {
+		t.Errorf("expected only the linted chart's fixed finding to be stale, got %+v", results.Stale)
+	}
+
+	count, err := f.writeBaseline(baselinePath)
+	if err != nil {
+		t.Fatalf("writeBaseline() error = %v", err)
+	}
+	if count != 3 {
+		t.Errorf("expected 3 findings written, got %d", count)
+	}
+
+	baseline, err := lint2.LoadBaseline(baselinePath)
+	if err != nil {
+		t.Fatalf("LoadBaseline() error = %v", err)
+	}
+	var messages []string
+	for _, entry := range baseline.Findings {
+		messages = append(messages, entry.Message)
+	}
+	want := []string{"new app warning", "db warning", "preflight warning"}
+	if len(messages) != len(want) {
+		t.Fatalf("expected baseline %v, got %v", want, messages)
+	}
+	for i := range want {
+		if messages[i] != want[i] {
+			t.Errorf("expected baseline %v, got %v", want, messages)
+			break
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/replicatedhq/replicated/pkg/lint2"
+	"github.com/replicatedhq/replicated/pkg/tools"
+	"github.com/replicatedhq/replicated/pkg/version"
+)
+
+// lintResultCache skips linting resources whose inputs have not changed since an
+// earlier run. It is safe for concurrent use by lint tasks.
+type lintResultCache struct {
+	cache   *lint2.LintCache // nil when results are only kept in memory
+	memory  sync.Map         // key -> *lint2.LintResult, for results produced in this process
+	linters map[string]tools.LinterConfig
+	hits    atomic.Int32
+}
+
+// newLintResultCache opens the lint cache. It returns nil (caching disabled) when the
+// cache directory cannot be determined, since caching is only an optimization.
+func newLintResultCache(config *tools.Config) *lintResultCache {
+	dir, err := lint2.LintCacheDir()
+	if err != nil {
+		return nil
+	}
+
+	c := &lintResultCache{cache: lint2.NewLintCache(dir)}
+	c.useConfig(config)
+	return c
+}
+
+// newMemoryLintResultCache returns a cache that only lives for this process. --watch
+// uses it with --no-cache so unchanged resources are not linted again on every change.
+func newMemoryLintResultCache(config *tools.Config) *lintResultCache {
+	c := &lintResultCache{}
+	c.useConfig(config)
+	return c
+}
+
+// useConfig sets the linter config included in cache keys. It must not be called
+// while lint tasks are running.
+func (c *lintResultCache) useConfig(config *tools.Config) {
+	c.linters = nil
+	if config != nil && config.ReplLint != nil {
+		c.linters = linterConfigsByName(config.ReplLint.Linters)
+	}
+}
+
+// resetHits resets the hit count before another lint pass
+func (c *lintResultCache) resetHits() {
+	if c != nil {
+		c.hits.Store(0)
+	}
+}
+
+// lint returns the cached result for a resource when its inputs are unchanged, and
+// otherwise runs lint and caches the result. addInputs adds the resource's files and
+// values to the key; the linter's config and the CLI version are always included.
+// Resources whose key cannot be computed are linted without caching. A nil cache
+// always runs lint.
+func (c *lintResultCache) lint(linter, toolVersion string, addInputs func(*lint2.LintCacheKey) error, lint func() (*lint2.LintResult, error)) (*lint2.LintResult, error) {
+	if c == nil {
+		return lint()
+	}
+
+	key := lint2.NewLintCacheKey(linter, toolVersion)
+	key.String(version.Version())
+	if err := key.JSON(c.linters[linter]); err != nil {
+		return lint()
+	}
+	if err := addInputs(key); err != nil {
+		return lint()
+	}
+	sum := key.Sum()
+
+	if result, ok := c.memory.Load(sum); ok {
+		c.hits.Add(1)
+		return result.(*lint2.LintResult), nil
+	}
+	if c.cache != nil {
+		if result, ok := c.cache.Get(sum); ok {
+			c.memory.Store(sum, result)
+			c.hits.Add(1)
+			return result, nil
+		}
+	}
+
+	result, err := lint()
+	if err != nil {
+		return nil, err
+	}
+	c.memory.Store(sum, result)
+	if c.cache != nil {
+		// A failed write only means the resource is linted again next time
+		_ = c.cache.Put(sum, result)
+	}
+
+	return result, nil
+}
+
+// cacheHits returns how many resources were served from the cache
+func (c *lintResultCache) cacheHits() int {
+	if c == nil {
+		return 0
+	}
+	return int(c.hits.Load())
+}
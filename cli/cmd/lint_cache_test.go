@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/replicatedhq/replicated/pkg/lint2"
+	"github.com/replicatedhq/replicated/pkg/tools"
+)
+
+func TestLintResultCache(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", t.TempDir())
+
+	c := newLintResultCache(&tools.Config{ReplLint: &tools.ReplLintConfig{}})
+	if c == nil {
+		t.Fatal("expected cache to open")
+	}
+
+	runs := 0
+	lint := func() (*lint2.LintResult, error) {
+		runs++
+		return &lint2.LintResult{Success: true, Messages: []lint2.LintMessage{{Severity: "INFO", Message: "icon is recommended"}}}, nil
+	}
+	inputs := func(key *lint2.LintCacheKey) error {
+		key.String("chart")
+		return nil
+	}
+
+	for i := 0; i < 2; i++ {
+		result, err := c.lint("helm", "3.14.4", inputs, lint)
+		if err != nil {
+			t.Fatalf("lint() error = %v", err)
+		}
+		if len(result.Messages) != 1 || result.Messages[0].Message != "icon is recommended" {
+			t.Errorf("unexpected result %+v", result)
+		}
+	}
+	if runs != 1 {
+		t.Errorf("expected the second run to be served from the cache, linted %d times", runs)
+	}
+	if c.cacheHits() != 1 {
+		t.Errorf("expected 1 cache hit, got %d", c.cacheHits())
+	}
+
+	// A different tool version is linted again
+	if _, err := c.lint("helm", "3.15.0", inputs, lint); err != nil {
+		t.Fatal(err)
+	}
+	if runs != 2 {
+		t.Errorf("expected a new tool version to miss the cache, linted %d times", runs)
+	}
+}
+
+func TestLintResultCache_Nil(t *testing.T) {
+	var c *lintResultCache
+	runs := 0
+	for i := 0; i < 2; i++ {
+		_, _ = c.lint("helm", "3.14.4", func(*lint2.LintCacheKey) error { return nil }, func() (*lint2.LintResult, error) {
+			runs++
+			return &lint2.LintResult{Success: true}, nil
+		})
+	}
+	if runs != 2 {
+		t.Errorf("expected nil cache to always lint, linted %d times", runs)
+	}
+}
+
+func TestLintResultCache_Memory(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", t.TempDir())
+
+	c := newMemoryLintResultCache(nil)
+	runs := 0
+	lint := func() (*lint2.LintResult, error) {
+		runs++
+		return &lint2.LintResult{Success: true}, nil
+	}
+	inputs := func(key *lint2.LintCacheKey) error {
+		key.String("chart")
+		return nil
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.lint("helm", "3.14.4", inputs, lint); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if runs != 1 {
+		t.Errorf("expected the second run to be served from memory, linted %d times", runs)
+	}
+
+	// Nothing is written to disk, so a new cache lints again
+	if _, err := newLintResultCache(nil).lint("helm", "3.14.4", inputs, lint); err != nil {
+		t.Fatal(err)
+	}
+	if runs != 2 {
+		t.Errorf("expected the on-disk cache to miss, linted %d times", runs)
+	}
+
+	// Changing the linter config changes the key
+	c.useConfig(&tools.Config{ReplLint: &tools.ReplLintConfig{Linters: tools.LintersConfig{Helm: tools.LinterConfig{Ignore: []tools.IgnoreRule{{Rule: "icon"}}}}}})
+	c.resetHits()
+	if _, err := c.lint("helm", "3.14.4", inputs, lint); err != nil {
+		t.Fatal(err)
+	}
+	if runs != 3 || c.cacheHits() != 0 {
+		t.Errorf("expected a config change to miss the cache, linted %d times with %d hits", runs, c.cacheHits())
+	}
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"github.com/replicatedhq/replicated/pkg/lint2"
+)
+
+// filterChangedPaths returns the paths (files or chart directories) that are or
+// contain a changed file
+func filterChangedPaths(paths []string, changed *lint2.ChangedFiles) []string {
+	var result []string
+	for _, path := range paths {
+		if changed.Contains(path) {
+			result = append(result, path)
+		}
+	}
+	return result
+}
+
+// filterChangedCharts returns the chart paths whose chart directory or values files
+// changed, or whose HelmChart manifest changed, since its values are linted with the chart
+func filterChangedCharts(
+	chartPaths []string,
+	charts []lint2.ChartWithMetadata,
+	helmChartManifests map[string]*lint2.HelmChartManifest,
+	changed *lint2.ChangedFiles,
+) []string {
+	chartsByPath := make(map[string]lint2.ChartWithMetadata, len(charts))
+	for _, chart := range charts {
+		chartsByPath[chart.Path] = chart
+	}
+
+	var result []string
+	for _, chartPath := range chartPaths {
+		if changed.Contains(chartPath) {
+			result = append(result, chartPath)
+			continue
+		}
+		chart, ok := chartsByPath[chartPath]
+		if !ok {
+			continue
+		}
+		if changed.ContainsAny(chart.ValuesFiles) {
+			result = append(result, chartPath)
+			continue
+		}
+		if manifest := lint2.FindHelmChartManifest(chart.Name, chart.Version, helmChartManifests); manifest != nil && changed.Contains(manifest.FilePath) {
+			result = append(result, chartPath)
+		}
+	}
+	return result
+}
+
+// filterChangedPreflights returns the preflights whose spec or values file changed, or
+// whose referenced chart or HelmChart manifest changed, since both are rendered into
+// the spec before it is linted
+func filterChangedPreflights(
+	preflights []lint2.PreflightWithValues,
+	charts []lint2.ChartWithMetadata,
+	helmChartManifests map[string]*lint2.HelmChartManifest,
+	changed *lint2.ChangedFiles,
+) []lint2.PreflightWithValues {
+	var result []lint2.PreflightWithValues
+	for _, pf := range preflights {
+		if preflightChanged(pf, charts, helmChartManifests, changed) {
+			result = append(result, pf)
+		}
+	}
+	return result
+}
+
+func preflightChanged(
+	pf lint2.PreflightWithValues,
+	charts []lint2.ChartWithMetadata,
+	helmChartManifests map[string]*lint2.HelmChartManifest,
+	changed *lint2.ChangedFiles,
+) bool {
+	if changed.Contains(pf.SpecPath) {
+		return true
+	}
+	if pf.ValuesPath != "" && changed.Contains(pf.ValuesPath) {
+		return true
+	}
+	if pf.ChartName == "" {
+		return false
+	}
+
+	for _, chart := range charts {
+		if chart.Name == pf.ChartName && chart.Version == pf.ChartVersion && changed.Contains(chart.Path) {
+			return true
+		}
+	}
+	if manifest := lint2.FindHelmChartManifest(pf.ChartName, pf.ChartVersion, helmChartManifests); manifest != nil && changed.Contains(manifest.FilePath) {
+		return true
+	}
+	return false
+}
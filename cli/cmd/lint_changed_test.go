@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/replicatedhq/replicated/pkg/lint2"
+)
+
+func TestFilterChangedPreflights(t *testing.T) {
+	charts := []lint2.ChartWithMetadata{
+		{Path: "/repo/charts/app", Name: "app", Version: "1.0.0"},
+		{Path: "/repo/charts/db", Name: "db", Version: "2.0.0"},
+	}
+	manifests := map[string]*lint2.HelmChartManifest{
+		"db:2.0.0": {Name: "db", ChartVersion: "2.0.0", FilePath: "/repo/manifests/db-helmchart.yaml"},
+	}
+	preflights := []lint2.PreflightWithValues{
+		{SpecPath: "/repo/preflights/app.yaml", ChartName: "app", ChartVersion: "1.0.0"},
+		{SpecPath: "/repo/preflights/db.yaml", ChartName: "db", ChartVersion: "2.0.0"},
+		{SpecPath: "/repo/preflights/standalone.yaml"},
+		{SpecPath: "/repo/preflights/edited.yaml"},
+	}
+
+	changed := lint2.NewChangedFiles("main", []string{
+		"/repo/charts/app/templates/deployment.yaml", // referenced chart changed
+		"/repo/manifests/db-helmchart.yaml",          // HelmChart builder values changed
+		"/repo/preflights/edited.yaml",               // spec itself changed
+	})
+
+	got := filterChangedPreflights(preflights, charts, manifests, changed)
+	want := []string{"/repo/preflights/app.yaml", "/repo/preflights/db.yaml", "/repo/preflights/edited.yaml"}
+	if len(got) != len(want) {
+		t.Fatalf("filterChangedPreflights() = %+v, want specs %v", got, want)
+	}
+	for i, pf := range got {
+		if pf.SpecPath != want[i] {
+			t.Errorf("got[%d] = %s, want %s", i, pf.SpecPath, want[i])
+		}
+	}
+}
+
+func TestFilterChangedPaths(t *testing.T) {
+	changed := lint2.NewChangedFiles("main", []string{"/repo/charts/app/values.yaml"})
+
+	got := filterChangedPaths([]string{"/repo/charts/app", "/repo/charts/db"}, changed)
+	if len(got) != 1 || got[0] != "/repo/charts/app" {
+		t.Errorf("filterChangedPaths() = %v, want [/repo/charts/app]", got)
+	}
+}
+
+func TestFilterChangedCharts(t *testing.T) {
+	charts := []lint2.ChartWithMetadata{
+		{Path: "/repo/charts/app", Name: "app", Version: "1.0.0"},
+		{Path: "/repo/charts/db", Name: "db", Version: "2.0.0"},
+		{Path: "/repo/charts/web", Name: "web", Version: "3.0.0", ValuesFiles: []string{"/repo/ci/web-ha.yaml"}},
+		{Path: "/repo/charts/worker", Name: "worker", Version: "4.0.0"},
+	}
+	manifests := map[string]*lint2.HelmChartManifest{
+		"db:2.0.0": {Name: "db", ChartVersion: "2.0.0", FilePath: "/repo/manifests/db-helmchart.yaml"},
+	}
+	chartPaths := []string{"/repo/charts/app", "/repo/charts/db", "/repo/charts/web", "/repo/charts/worker"}
+
+	changed := lint2.NewChangedFiles("main", []string{
+		"/repo/charts/app/values.yaml",      // chart itself changed
+		"/repo/manifests/db-helmchart.yaml", // HelmChart values changed
+		"/repo/ci/web-ha.yaml",              // values file changed
+	})
+
+	got := filterChangedCharts(chartPaths, charts, manifests, changed)
+	want := []string{"/repo/charts/app", "/repo/charts/db", "/repo/charts/web"}
+	if len(got) != len(want) {
+		t.Fatalf("filterChangedCharts() = %v, want %v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("got[%d] = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/replicated/pkg/lint2"
+	"github.com/replicatedhq/replicated/pkg/tools"
+)
+
+// LintFix is a change made to a file by --fix
+type LintFix struct {
+	Path    string `json:"path"`
+	Line    int    `json:"line"`
+	Fixer   string `json:"fixer"`
+	Message string `json:"message"`
+}
+
+// validateLintFixFlags checks that --dry-run and --oci-layout are only used with --fix
+func (r *runners) validateLintFixFlags() error {
+	if !r.args.lintFix {
+		if r.args.lintFixDryRun {
+			return errors.New("--dry-run can only be used with --fix")
+		}
+		if r.args.lintFixOCILayout != "" {
+			return errors.New("--oci-layout can only be used with --fix")
+		}
+		return nil
+	}
+	if r.args.lintWatch {
+		return errors.New("--watch cannot be used with --fix")
+	}
+	return nil
+}
+
+// fixLintFindings applies the fixers to the release described by config before it is
+// linted. With --dry-run the fixes are printed as a unified diff and no file is
+// written. The applied fixes are returned for the lint output.
+func (r *runners) fixLintFindings(config *tools.Config) ([]LintFix, error) {
+	inputs := lint2.FixInputs{}
+
+	if len(config.Preflights) > 0 {
+		preflights, err := lint2.GetPreflightWithValuesFromConfig(config)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to discover preflight specs to fix")
+		}
+		inputs.Preflights = preflights
+	}
+	if len(config.Charts) > 0 {
+		charts, err := lint2.GetChartsWithMetadataFromConfig(config)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to discover charts to fix")
+		}
+		inputs.Charts = charts
+	}
+	manifests, err := lint2.ExpandManifestGlobs(config.Manifests)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to expand manifest globs to fix")
+	}
+	inputs.Manifests = manifests
+
+	if r.args.lintFixOCILayout != "" {
+		inputs.Images, err = lint2.LoadOCILayout(r.args.lintFixOCILayout)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load OCI layout")
+		}
+	}
+
+	fixes, err := lint2.FixRelease(inputs)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fix lint findings")
+	}
+
+	if r.args.lintFixDryRun {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get current directory")
+		}
+		for _, fix := range fixes {
+			diff, err := lint2.UnifiedDiff(fix, wd)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to diff %s", fix.Path)
+			}
+			fmt.Fprint(r.w, diff)
+		}
+		if len(fixes) == 0 {
+			fmt.Fprintln(r.w, "No fixes to apply")
+		}
+		return nil, r.w.Flush()
+	}
+
+	var applied []LintFix
+	for _, fix := range fixes {
+		info, err := os.Stat(fix.Path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to stat %s", fix.Path)
+		}
+		if err := os.WriteFile(fix.Path, fix.Fixed, info.Mode().Perm()); err != nil {
+			return nil, errors.Wrapf(err, "failed to write %s", fix.Path)
+		}
+		for _, edit := range fix.Edits {
+			applied = append(applied, LintFix{Path: fix.Path, Line: edit.Line, Fixer: edit.Fixer, Message: edit.Message})
+		}
+	}
+
+	if r.outputFormat == "table" && len(applied) > 0 {
+		fmt.Fprintf(r.w, "Applied %d fix(es):\n", len(applied))
+		for _, fix := range applied {
+			fmt.Fprintf(r.w, "  %s:%d: %s (%s)\n", fix.Path, fix.Line, fix.Message, fix.Fixer)
+		}
+		fmt.Fprintln(r.w)
+		if err := r.w.Flush(); err != nil {
+			return nil, err
+		}
+	}
+
+	return applied, nil
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/tabwriter"
+
+	"github.com/replicatedhq/replicated/pkg/tools"
+)
+
+func TestValidateLintFixFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    runnerArgs
+		wantErr string
+	}{
+		{name: "no fix"},
+		{name: "fix", args: runnerArgs{lintFix: true, lintFixDryRun: true, lintFixOCILayout: "./layout"}},
+		{name: "dry run without fix", args: runnerArgs{lintFixDryRun: true}, wantErr: "--dry-run can only be used with --fix"},
+		{name: "layout without fix", args: runnerArgs{lintFixOCILayout: "./layout"}, wantErr: "--oci-layout can only be used with --fix"},
+		{name: "fix with watch", args: runnerArgs{lintFix: true, lintWatch: true}, wantErr: "--watch cannot be used with --fix"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &runners{args: tt.args}
+			err := r.validateLintFixFlags()
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFixLintFindings(t *testing.T) {
+	dir := t.TempDir()
+	manifest := filepath.Join(dir, "manifests", "web.yaml")
+	original := "apiVersion: kots.io/v1beta2\nkind: HelmChart\nspec:\n  chart:\n    name: web\n"
+	for path, content := range map[string]string{
+		filepath.Join(dir, "charts", "web", "Chart.yaml"): "apiVersion: v2\nname: web\nversion: 1.2.0\n",
+		manifest: original,
+	} {
+		writeTestFile(t, path, content)
+	}
+	config := &tools.Config{
+		Charts:    []tools.ChartConfig{{Path: filepath.Join(dir, "charts", "web")}},
+		Manifests: []string{filepath.Join(dir, "manifests", "*.yaml")},
+	}
+
+	// --dry-run prints a diff and leaves the file alone
+	buf := new(bytes.Buffer)
+	r := &runners{
+		w:            tabwriter.NewWriter(buf, 0, 8, 4, ' ', 0),
+		outputFormat: "table",
+		args:         runnerArgs{lintFix: true, lintFixDryRun: true},
+	}
+	fixes, err := r.fixLintFindings(config)
+	if err != nil {
+		t.Fatalf("fixLintFindings() error = %v", err)
+	}
+	if len(fixes) != 0 {
+		t.Errorf("expected no fixes to be applied with --dry-run, got %+v", fixes)
+	}
+	if !strings.Contains(buf.String(), "+    chartVersion: 1.2.0\n") {
+		t.Errorf("expected a diff adding the chart version, got:\n%s", buf.String())
+	}
+	if data, _ := os.ReadFile(manifest); string(data) != original {
+		t.Errorf("expected --dry-run to leave the file unchanged, got:\n%s", data)
+	}
+
+	// Without --dry-run the file is written and the fix is reported
+	buf.Reset()
+	r.args.lintFixDryRun = false
+	fixes, err = r.fixLintFindings(config)
+	if err != nil {
+		t.Fatalf("fixLintFindings() error = %v", err)
+	}
+	if len(fixes) != 1 || fixes[0].Fixer != "helmchart-chart-version" || fixes[0].Line != 5 {
+		t.Errorf("unexpected fixes %+v", fixes)
+	}
+	if data, _ := os.ReadFile(manifest); string(data) != original+"    chartVersion: 1.2.0\n" {
+		t.Errorf("unexpected fixed file:\n%s", data)
+	}
+	if !strings.Contains(buf.String(), "Applied 1 fix(es):") {
+		t.Errorf("expected the applied fixes to be listed, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// JUnitTestSuites is the root element of the JUnit XML report produced by --output junit.
+// Each linter is a test suite and each linted resource is a test case.
+type JUnitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Suites   []JUnitTestSuite `xml:"testsuite"`
+}
+
+// JUnitTestSuite contains the test cases for a single linter
+type JUnitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Timestamp string          `xml:"timestamp,attr,omitempty"`
+	TestCases []JUnitTestCase `xml:"testcase"`
+}
+
+// JUnitTestCase is a single linted resource. Every ERROR message is reported as a
+// failure; WARNING and INFO messages are reported as system-out.
+type JUnitTestCase struct {
+	Name      string         `xml:"name,attr"`
+	ClassName string         `xml:"classname,attr"`
+	Failures  []JUnitFailure `xml:"failure,omitempty"`
+	SystemOut string         `xml:"system-out,omitempty"`
+}
+
+// JUnitFailure is a single ERROR message
+type JUnitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// newJUnitReport converts lint output into a JUnit report. Totals come from the
+// overall lint summary so they match the table and JSON output.
+func newJUnitReport(output *JSONLintOutput) *JUnitTestSuites {
+	summary := output.Summary
+	report := &JUnitTestSuites{
+		Name:     "replicated-lint",
+		Tests:    summary.TotalResources,
+		Failures: summary.FailedResources,
+		Suites:   []JUnitTestSuite{},
+	}
+
+	addSuite := func(name string, results []LintableResult) {
+		suite := JUnitTestSuite{
+			Name:      name,
+			Tests:     len(results),
+			Timestamp: output.Metadata.Timestamp,
+			TestCases: []JUnitTestCase{},
+		}
+		for _, result := range results {
+			testCase := newJUnitTestCase(name, result)
+			if !result.GetSuccess() {
+				suite.Failures++
+			}
+			suite.TestCases = append(suite.TestCases, testCase)
+		}
+		report.Suites = append(report.Suites, suite)
+	}
+
+	for _, section := range output.lintSections() {
+		if section.enabled {
+			addSuite(section.linter, section.results)
+		}
+	}
+
+	return report
+}
+
+func newJUnitTestCase(linter string, result LintableResult) JUnitTestCase {
+	testCase := JUnitTestCase{
+		Name:      result.GetPath(),
+		ClassName: linter,
+	}
+
+	var systemOut strings.Builder
+	for _, msg := range result.GetMessages() {
+		text := msg.displayMessage()
+		if msg.Path != "" {
+			text = fmt.Sprintf("%s: %s", msg.Path, text)
+		}
+
+		if msg.Severity == "ERROR" {
+			testCase.Failures = append(testCase.Failures, JUnitFailure{
+				Message: msg.displayMessage(),
+				Type:    msg.Severity,
+				Text:    text,
+			})
+			continue
+		}
+		fmt.Fprintf(&systemOut, "[%s] %s\n", msg.Severity, text)
+	}
+	testCase.SystemOut = systemOut.String()
+
+	// Resources can fail without an ERROR message (e.g. a tool that exits non-zero);
+	// record a failure so the test case still shows as failed
+	if !result.GetSuccess() && len(testCase.Failures) == 0 {
+		testCase.Failures = append(testCase.Failures, JUnitFailure{
+			Message: "linting failed",
+			Type:    "ERROR",
+		})
+	}
+
+	return testCase
+}
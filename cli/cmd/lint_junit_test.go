@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"text/tabwriter"
+)
+
+func TestNewJUnitReport(t *testing.T) {
+	output := &JSONLintOutput{
+		HelmResults: &HelmLintResults{
+			Enabled: true,
+			Charts: []ChartLintResult{
+				{
+					Path:    "charts/app",
+					Success: false,
+					Messages: []LintMessage{
+						{Severity: "ERROR", Path: "templates/deployment.yaml", Message: "bad template"},
+						{Severity: "ERROR", Message: "missing values"},
+						{Severity: "INFO", Path: "Chart.yaml", Message: "icon is recommended"},
+					},
+					Summary: ResourceSummary{ErrorCount: 2, InfoCount: 1},
+				},
+				{Path: "charts/api", Success: true, Messages: []LintMessage{}},
+			},
+		},
+		PreflightResults: &PreflightLintResults{
+			Enabled: true,
+			Specs: []PreflightLintResult{{
+				Path:     "preflight.yaml",
+				Success:  true,
+				Messages: []LintMessage{{Severity: "WARNING", Message: "line 3: deprecated field"}},
+				Summary:  ResourceSummary{WarningCount: 1},
+			}},
+		},
+		SupportBundleResults: &SupportBundleLintResults{Enabled: false},
+	}
+	r := &runners{}
+	output.Summary = r.calculateOverallSummary(output)
+
+	report := newJUnitReport(output)
+
+	if report.Tests != 3 || report.Failures != 1 {
+		t.Errorf("expected 3 tests and 1 failure from the summary, got %d tests, %d failures", report.Tests, report.Failures)
+	}
+	if len(report.Suites) != 2 {
+		t.Fatalf("expected 2 suites (disabled linters skipped), got %d", len(report.Suites))
+	}
+
+	helm := report.Suites[0]
+	if helm.Name != "helm" || helm.Tests != 2 || helm.Failures != 1 {
+		t.Errorf("unexpected helm suite: name=%s tests=%d failures=%d", helm.Name, helm.Tests, helm.Failures)
+	}
+	failed := helm.TestCases[0]
+	if failed.Name != "charts/app" || failed.ClassName != "helm" {
+		t.Errorf("unexpected test case identity: %+v", failed)
+	}
+	if len(failed.Failures) != 2 {
+		t.Fatalf("expected one failure per ERROR message, got %d", len(failed.Failures))
+	}
+	if failed.Failures[0].Text != "templates/deployment.yaml: bad template" {
+		t.Errorf("unexpected failure text: %q", failed.Failures[0].Text)
+	}
+	if failed.SystemOut != "[INFO] Chart.yaml: icon is recommended\n" {
+		t.Errorf("expected INFO message in system-out, got %q", failed.SystemOut)
+	}
+	if len(helm.TestCases[1].Failures) != 0 {
+		t.Errorf("expected passing chart to have no failures, got %+v", helm.TestCases[1].Failures)
+	}
+
+	preflight := report.Suites[1].TestCases[0]
+	if len(preflight.Failures) != 0 || !strings.Contains(preflight.SystemOut, "[WARNING] line 3: deprecated field") {
+		t.Errorf("expected warning in system-out only, got %+v", preflight)
+	}
+}
+
+func TestNewJUnitReport_FailureWithoutErrorMessage(t *testing.T) {
+	output := &JSONLintOutput{
+		SupportBundleResults: &SupportBundleLintResults{
+			Enabled: true,
+			Specs:   []SupportBundleLintResult{{Path: "sb.yaml", Success: false}},
+		},
+	}
+
+	report := newJUnitReport(output)
+	testCase := report.Suites[0].TestCases[0]
+	if len(testCase.Failures) != 1 || testCase.Failures[0].Message != "linting failed" {
+		t.Errorf("expected a generic failure for a failed resource, got %+v", testCase.Failures)
+	}
+}
+
+func TestPrintLintOutput_JUnit(t *testing.T) {
+	var buf bytes.Buffer
+	r := &runners{
+		outputFormat: "junit",
+		w:            tabwriter.NewWriter(&buf, 0, 8, 4, ' ', 0),
+	}
+
+	output := &JSONLintOutput{
+		HelmResults: &HelmLintResults{
+			Enabled: true,
+			Charts: []ChartLintResult{{
+				Path:     "chart",
+				Success:  false,
+				Messages: []LintMessage{{Severity: "ERROR", Message: "a < b & c"}},
+			}},
+		},
+	}
+	output.Summary = r.calculateOverallSummary(output)
+
+	if err := r.printLintOutput(output); err != nil {
+		t.Fatalf("printLintOutput() error = %v", err)
+	}
+
+	if !strings.HasPrefix(buf.String(), "<?xml") {
+		t.Errorf("expected XML header, got %q", buf.String())
+	}
+
+	var decoded JUnitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("output is not valid XML: %v\n%s", err, buf.String())
+	}
+	if decoded.Tests != 1 || decoded.Failures != 1 {
+		t.Errorf("unexpected totals: tests=%d failures=%d", decoded.Tests, decoded.Failures)
+	}
+	if decoded.Suites[0].TestCases[0].Failures[0].Text != "a < b & c" {
+		t.Errorf("expected failure text to round-trip, got %q", decoded.Suites[0].TestCases[0].Failures[0].Text)
+	}
+}
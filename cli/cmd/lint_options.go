@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/replicated/pkg/lint2"
+	"github.com/replicatedhq/replicated/pkg/tools"
+)
+
+// newLinterOptions builds the lint2 options for each linter from its config (ignore
+// rules and strict mode), keyed by the linter's name in the config. Linters run
+// their tools with resolver.
+func newLinterOptions(config *tools.Config, resolver *tools.Resolver) (map[string][]lint2.LintOption, error) {
+	if config == nil || config.ReplLint == nil {
+		return nil, nil
+	}
+
+	configByLinter := linterConfigsByName(config.ReplLint.Linters)
+
+	options := make(map[string][]lint2.LintOption, len(configByLinter))
+	for linter, linterConfig := range configByLinter {
+		opts := []lint2.LintOption{lint2.WithResolver(resolver)}
+
+		if len(linterConfig.Ignore) > 0 {
+			suppressor, err := lint2.NewSuppressor(linterConfig.Ignore)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid ignore rules for %s linter", linter)
+			}
+			opts = append(opts, lint2.WithSuppressor(suppressor))
+		}
+		if linterConfig.IsStrict() {
+			opts = append(opts, lint2.WithStrict(true))
+		}
+
+		options[linter] = opts
+	}
+
+	return options, nil
+}
+
+// linterConfigsByName returns each linter's config keyed by the linter's name in the config
+func linterConfigsByName(linters tools.LintersConfig) map[string]tools.LinterConfig {
+	return map[string]tools.LinterConfig{
+		"helm":             linters.Helm,
+		"preflight":        linters.Preflight,
+		"support-bundle":   linters.SupportBundle,
+		"embedded-cluster": linters.EmbeddedCluster.LinterConfig,
+		"kots":             linters.Kots,
+		"kube-schema":      linters.KubeSchema.LinterConfig,
+		"policy":           linters.Policy,
+		"release-graph":    linters.ReleaseGraph,
+		"image":            linters.Image.LinterConfig,
+	}
+}
+
+// lintOptions returns the lint2 options for the named linter. Linters without
+// ignore rules still honor inline ignore comments.
+func (r *runners) lintOptions(linter string) []lint2.LintOption {
+	return r.linterOptions[linter]
+}
+
+// linterErrorResult reports an error that kept the named linter from linting the
+// resource at path as a failed result, with the linter's options applied
+func (r *runners) linterErrorResult(linter, path, rule string, err error) *lint2.LintResult {
+	return lint2.ErrorResult(path, rule, err, r.lintOptions(linter)...)
+}
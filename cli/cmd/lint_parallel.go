@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"context"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/replicatedhq/replicated/pkg/tools"
+)
+
+// lintPool bounds how many lint tasks run at once across all linters. A task is a
+// single lint2 call, usually one linted resource.
+type lintPool struct {
+	sem chan struct{}
+}
+
+// newLintPool returns a pool running up to parallel tasks at once. Values below 1
+// default to GOMAXPROCS.
+func newLintPool(parallel int) *lintPool {
+	if parallel < 1 {
+		parallel = runtime.GOMAXPROCS(0)
+	}
+	return &lintPool{sem: make(chan struct{}, parallel)}
+}
+
+// Go runs task once a slot is free. wg tracks the task so a linter can wait for its
+// own tasks without waiting for the rest of the pool.
+func (p *lintPool) Go(wg *sync.WaitGroup, task func()) {
+	wg.Go(func() {
+		p.sem <- struct{}{}
+		defer func() { <-p.sem }()
+		task()
+	})
+}
+
+// prefetchLintTools makes sure each tool is in the local cache before linters start.
+// Every lint task resolves its tool, and downloading here first keeps concurrent
+// tasks from racing to download the same binary. Errors are left for the lint
+// tasks to report with their usual context.
+func prefetchLintTools(ctx context.Context, resolver *tools.Resolver, versions map[string]string) {
+	names := make([]string, 0, len(versions))
+	for name := range versions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		_, _ = resolver.Resolve(ctx, name, versions[name])
+	}
+}
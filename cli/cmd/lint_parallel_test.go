@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLintPool_BoundsConcurrency(t *testing.T) {
+	pool := newLintPool(2)
+
+	var running, maxRunning atomic.Int32
+	var wg sync.WaitGroup
+	results := make([]int, 10)
+	for i := range results {
+		pool.Go(&wg, func() {
+			n := running.Add(1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			results[i] = i
+			running.Add(-1)
+		})
+	}
+	wg.Wait()
+
+	if got := maxRunning.Load(); got > 2 {
+		t.Errorf("expected at most 2 concurrent tasks, got %d", got)
+	}
+	for i, got := range results {
+		if got != i {
+			t.Errorf("results[%d] = %d, want %d", i, got, i)
+		}
+	}
+}
+
+func TestLintPool_WaitsOnlyForOwnTasks(t *testing.T) {
+	pool := newLintPool(2)
+
+	release := make(chan struct{})
+	var slow, fast sync.WaitGroup
+	pool.Go(&slow, func() { <-release })
+	pool.Go(&fast, func() {})
+
+	done := make(chan struct{})
+	go func() {
+		fast.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("waiting for one linter's tasks blocked on another linter's task")
+	}
+	close(release)
+	slow.Wait()
+}
+
+func TestNewLintPool_Default(t *testing.T) {
+	if got := cap(newLintPool(0).sem); got != runtime.GOMAXPROCS(0) {
+		t.Errorf("expected default parallelism of GOMAXPROCS (%d), got %d", runtime.GOMAXPROCS(0), got)
+	}
+}
@@ -0,0 +1,309 @@
+package cmd
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/replicatedhq/replicated/pkg/lint2"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+
+	// sarifSourceRoot is the uriBaseId that artifact URIs are relative to
+	sarifSourceRoot = "%SRCROOT%"
+)
+
+// SARIFLog is the top-level SARIF 2.1.0 document produced by --output sarif.
+// Only the subset of the specification needed to report lint results is modelled.
+type SARIFLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SARIFRun `json:"runs"`
+}
+
+// SARIFRun contains the results of a single linter
+type SARIFRun struct {
+	Tool               SARIFTool                        `json:"tool"`
+	OriginalURIBaseIDs map[string]SARIFArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	Results            []SARIFResult                    `json:"results"`
+}
+
+// SARIFTool describes the linter that produced a run
+type SARIFTool struct {
+	Driver SARIFDriver `json:"driver"`
+}
+
+// SARIFDriver identifies the linter and the rules it reported
+type SARIFDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []SARIFRule `json:"rules,omitempty"`
+}
+
+// SARIFRule is a single rule reported by a linter
+type SARIFRule struct {
+	ID string `json:"id"`
+}
+
+// SARIFResult is a single lint finding
+type SARIFResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   SARIFMessage    `json:"message"`
+	Locations []SARIFLocation `json:"locations,omitempty"`
+}
+
+// SARIFMessage holds the text of a result
+type SARIFMessage struct {
+	Text string `json:"text"`
+}
+
+// SARIFLocation points a result at a file and, when known, a line
+type SARIFLocation struct {
+	PhysicalLocation SARIFPhysicalLocation `json:"physicalLocation"`
+}
+
+// SARIFPhysicalLocation is the file and region a result applies to
+type SARIFPhysicalLocation struct {
+	ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+	Region           *SARIFRegion          `json:"region,omitempty"`
+}
+
+// SARIFArtifactLocation is the URI of a file. Files in the source root are relative
+// to it and name it in URIBaseID; others are absolute file URIs.
+type SARIFArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+// SARIFRegion is the line a result applies to
+type SARIFRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// sarifRunBuilder accumulates the results and rules for a single run
+type sarifRunBuilder struct {
+	run       SARIFRun
+	root      string // absolute source root artifact URIs are relative to
+	seenRules map[string]bool
+}
+
+func newSARIFRunBuilder(name, version, informationURI, root string) *sarifRunBuilder {
+	return &sarifRunBuilder{
+		run: SARIFRun{
+			Tool: SARIFTool{Driver: SARIFDriver{
+				Name:           name,
+				Version:        version,
+				InformationURI: informationURI,
+			}},
+			OriginalURIBaseIDs: map[string]SARIFArtifactLocation{
+				sarifSourceRoot: {URI: strings.TrimSuffix(sarifFileURI(root), "/") + "/"},
+			},
+			Results: []SARIFResult{},
+		},
+		root:      root,
+		seenRules: make(map[string]bool),
+	}
+}
+
+func (b *sarifRunBuilder) add(ruleID, severity, message, path string, line int) {
+	// Messages without a rule are reported under the linter's name so that every
+	// result has a ruleId, which code-scanning tools require
+	if ruleID == "" {
+		ruleID = b.run.Tool.Driver.Name
+	}
+	if !b.seenRules[ruleID] {
+		b.seenRules[ruleID] = true
+		b.run.Tool.Driver.Rules = append(b.run.Tool.Driver.Rules, SARIFRule{ID: ruleID})
+	}
+
+	result := SARIFResult{
+		RuleID:  ruleID,
+		Level:   sarifLevel(severity),
+		Message: SARIFMessage{Text: message},
+	}
+	if path != "" {
+		location := SARIFLocation{PhysicalLocation: SARIFPhysicalLocation{
+			ArtifactLocation: b.artifactLocation(path),
+		}}
+		if line > 0 {
+			location.PhysicalLocation.Region = &SARIFRegion{StartLine: line}
+		}
+		result.Locations = []SARIFLocation{location}
+	}
+	b.run.Results = append(b.run.Results, result)
+}
+
+// addLintResults adds every message of the given results to the run.
+// messagePathsRelative reports whether a result's message paths are relative to the
+// result path (as with helm, which reports paths inside the chart directory).
+func (b *sarifRunBuilder) addLintResults(results []LintableResult, messagePathsRelative func(LintableResult) bool) {
+	for _, result := range results {
+		relative := messagePathsRelative != nil && messagePathsRelative(result)
+		for _, msg := range result.GetMessages() {
+			path := resolveLintMessagePath(result.GetPath(), msg.Path, relative)
+			b.add(msg.Rule, msg.Severity, msg.displayMessage(), path, msg.Line)
+		}
+	}
+}
+
+// sarifDriver describes the tool behind a linter's run
+type sarifDriver struct {
+	version        func(LintMetadata) string
+	informationURI string
+	// messagePathsRelative reports whether a result's message paths are relative to
+	// the result path; nil means they never are
+	messagePathsRelative func(LintableResult) bool
+}
+
+// alwaysRelative is used by linters whose messages point at chart templates
+func alwaysRelative(LintableResult) bool { return true }
+
+func cliVersion(m LintMetadata) string { return m.CLIVersion }
+
+// sarifDrivers maps each linter to the tool that produced its run
+var sarifDrivers = map[string]sarifDriver{
+	"helm": {
+		version:              func(m LintMetadata) string { return m.HelmVersion },
+		informationURI:       "https://helm.sh/docs/helm/helm_lint/",
+		messagePathsRelative: alwaysRelative,
+	},
+	"preflight": {
+		version:        func(m LintMetadata) string { return m.PreflightVersion },
+		informationURI: "https://troubleshoot.sh/docs/preflight/introduction/",
+	},
+	"support-bundle": {
+		version:        func(m LintMetadata) string { return m.SupportBundleVersion },
+		informationURI: "https://troubleshoot.sh/docs/support-bundle/introduction/",
+	},
+	"embedded-cluster": {
+		informationURI: "https://docs.replicated.com/embedded-cluster",
+	},
+	"kots": {
+		version:        cliVersion,
+		informationURI: "https://docs.replicated.com/reference/custom-resource-about",
+	},
+	"kube-schema": {
+		version:              cliVersion,
+		informationURI:       "https://kubernetes.io/docs/reference/using-api/deprecation-guide/",
+		messagePathsRelative: alwaysRelative,
+	},
+	"policy": {
+		version: cliVersion,
+		// Chart messages point at templates, relative to the chart
+		messagePathsRelative: func(result LintableResult) bool {
+			resource, ok := result.(PolicyLintResult)
+			return ok && resource.Source == lint2.PolicySourceChart
+		},
+	},
+	"release-graph": {
+		version: cliVersion,
+		// Chart messages point at Chart.yaml, relative to the chart
+		messagePathsRelative: func(result LintableResult) bool {
+			resource, ok := result.(ReleaseGraphLintResult)
+			return ok && resource.Kind == lint2.ReleaseGraphChart
+		},
+	},
+	"image": {
+		version:              cliVersion,
+		messagePathsRelative: alwaysRelative,
+	},
+}
+
+// newSARIFLog converts lint output into a SARIF log with one run per enabled linter.
+// Artifact URIs are relative to root, which each run declares as %SRCROOT%.
+func newSARIFLog(output *JSONLintOutput, root string) *SARIFLog {
+	log := &SARIFLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []SARIFRun{},
+	}
+
+	for _, section := range output.lintSections() {
+		if !section.enabled {
+			continue
+		}
+		driver := sarifDrivers[section.linter]
+		var version string
+		if driver.version != nil {
+			version = driver.version(output.Metadata)
+		}
+		b := newSARIFRunBuilder(section.linter, version, driver.informationURI, root)
+		b.addLintResults(section.results, driver.messagePathsRelative)
+		log.Runs = append(log.Runs, b.run)
+	}
+
+	if output.Images != nil && len(output.Images.Warnings) > 0 {
+		b := newSARIFRunBuilder("image-extract", output.Metadata.CLIVersion, "", root)
+		for _, warning := range output.Images.Warnings {
+			var path string
+			if warning.Source != nil {
+				path = warning.Source.File
+			}
+			message := warning.Message
+			if warning.Image != "" {
+				message = warning.Image + ": " + message
+			}
+			b.add(string(warning.Type), "WARNING", message, path, 0)
+		}
+		log.Runs = append(log.Runs, b.run)
+	}
+
+	return log
+}
+
+// sarifLevel maps a lint severity to a SARIF result level
+func sarifLevel(severity string) string {
+	switch strings.ToUpper(severity) {
+	case "ERROR":
+		return "error"
+	case "WARNING":
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// sarifSourceRootDir returns the directory SARIF URIs are relative to: the root of
+// the git repository containing the working directory, so code-scanning tools can
+// map results to repository files, or else the working directory itself
+func sarifSourceRootDir() (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	if root, err := lint2.FindGitRoot(wd); err == nil {
+		return root, nil
+	}
+	return wd, nil
+}
+
+// artifactLocation converts a file path, relative to the working directory or
+// absolute, into a slash-separated URI relative to the run's source root. Paths
+// outside the source root become absolute file URIs.
+func (b *sarifRunBuilder) artifactLocation(path string) SARIFArtifactLocation {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return SARIFArtifactLocation{URI: filepath.ToSlash(filepath.Clean(path))}
+	}
+	rel, err := filepath.Rel(b.root, absPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return SARIFArtifactLocation{URI: sarifFileURI(absPath)}
+	}
+	return SARIFArtifactLocation{URI: filepath.ToSlash(rel), URIBaseID: sarifSourceRoot}
+}
+
+// sarifFileURI returns the file URI of an absolute path
+func sarifFileURI(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		// Windows paths such as C:/repo
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"text/tabwriter"
+
+	"github.com/replicatedhq/replicated/pkg/imageextract"
+)
+
+func TestNewSARIFLog(t *testing.T) {
+	output := &JSONLintOutput{
+		Metadata: LintMetadata{
+			HelmVersion:      "3.14.4",
+			PreflightVersion: "0.123.9",
+			CLIVersion:       "v0.90.0",
+		},
+		HelmResults: &HelmLintResults{
+			Enabled: true,
+			Charts: []ChartLintResult{{
+				Path:    "charts/my-chart",
+				Success: false,
+				Messages: []LintMessage{
+					{Severity: "ERROR", Path: "templates/deployment.yaml", Message: "yaml: line 12: bad", Line: 12},
+					{Severity: "INFO", Path: "Chart.yaml", Message: "icon is recommended"},
+				},
+			}},
+		},
+		PreflightResults: &PreflightLintResults{
+			Enabled: true,
+			Specs: []PreflightLintResult{{
+				Path:     "preflights/check.yaml",
+				Success:  true,
+				Messages: []LintMessage{{Severity: "WARNING", Message: "line 3: something", Line: 3, Rule: "some-rule"}},
+			}},
+		},
+		SupportBundleResults: &SupportBundleLintResults{Enabled: false},
+		Images: &ImageExtractResults{
+			Warnings: []imageextract.Warning{{
+				Image:   "nginx:latest",
+				Type:    imageextract.WarningLatestTag,
+				Message: "uses latest tag",
+				Source:  &imageextract.Source{File: "templates/deployment.yaml"},
+			}},
+		},
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := newSARIFLog(output, wd)
+
+	if log.Version != "2.1.0" {
+		t.Errorf("expected SARIF version 2.1.0, got %q", log.Version)
+	}
+	// Disabled linters must not produce a run
+	if len(log.Runs) != 3 {
+		t.Fatalf("expected 3 runs (helm, preflight, image-extract), got %d", len(log.Runs))
+	}
+
+	helm := log.Runs[0]
+	if helm.Tool.Driver.Name != "helm" || helm.Tool.Driver.Version != "3.14.4" {
+		t.Errorf("unexpected helm driver: %+v", helm.Tool.Driver)
+	}
+	if len(helm.Results) != 2 {
+		t.Fatalf("expected 2 helm results, got %d", len(helm.Results))
+	}
+	first := helm.Results[0]
+	if first.Level != "error" || first.RuleID != "helm" {
+		t.Errorf("unexpected helm result: %+v", first)
+	}
+	location := first.Locations[0].PhysicalLocation
+	if location.ArtifactLocation.URI != "charts/my-chart/templates/deployment.yaml" || location.ArtifactLocation.URIBaseID != "%SRCROOT%" {
+		t.Errorf("expected helm paths to be joined with the chart path, got %+v", location.ArtifactLocation)
+	}
+	if base := helm.OriginalURIBaseIDs["%SRCROOT%"].URI; base != "file://"+filepath.ToSlash(wd)+"/" {
+		t.Errorf("expected %%SRCROOT%% to be the source root, got %q", base)
+	}
+	if location.Region == nil || location.Region.StartLine != 12 {
+		t.Errorf("expected region start line 12, got %+v", location.Region)
+	}
+	if helm.Results[1].Level != "note" || helm.Results[1].Locations[0].PhysicalLocation.Region != nil {
+		t.Errorf("expected INFO without line to be a note without region, got %+v", helm.Results[1])
+	}
+
+	preflight := log.Runs[1]
+	result := preflight.Results[0]
+	if result.RuleID != "some-rule" || result.Level != "warning" {
+		t.Errorf("unexpected preflight result: %+v", result)
+	}
+	if result.Locations[0].PhysicalLocation.ArtifactLocation.URI != "preflights/check.yaml" {
+		t.Errorf("expected message without path to use the spec path, got %+v", result.Locations)
+	}
+	if len(preflight.Tool.Driver.Rules) != 1 || preflight.Tool.Driver.Rules[0].ID != "some-rule" {
+		t.Errorf("expected rules to list some-rule, got %+v", preflight.Tool.Driver.Rules)
+	}
+
+	images := log.Runs[2]
+	if images.Results[0].RuleID != "latest-tag" || images.Results[0].Message.Text != "nginx:latest: uses latest tag" {
+		t.Errorf("unexpected image result: %+v", images.Results[0])
+	}
+}
+
+func TestNewSARIFLog_SourceRoot(t *testing.T) {
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(root, ".git", "HEAD"), "ref: refs/heads/main\n")
+	sub := filepath.Join(root, "apps", "web")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(sub)
+
+	gotRoot, err := sarifSourceRootDir()
+	if err != nil {
+		t.Fatalf("sarifSourceRootDir() error = %v", err)
+	}
+	if gotRoot != root {
+		t.Fatalf("sarifSourceRootDir() = %q, want the git root %q", gotRoot, root)
+	}
+
+	outside := filepath.Join(filepath.Dir(root), "shared", "preflight.yaml")
+	output := &JSONLintOutput{
+		PreflightResults: &PreflightLintResults{
+			Enabled: true,
+			Specs: []PreflightLintResult{
+				{Path: "preflights/check.yaml", Messages: []LintMessage{{Severity: "WARNING", Message: "in repo"}}},
+				{Path: outside, Messages: []LintMessage{{Severity: "WARNING", Message: "outside repo"}}},
+			},
+		},
+	}
+	results := newSARIFLog(output, gotRoot).Runs[0].Results
+
+	// Paths relative to the working directory are reported relative to the repository root
+	if got := results[0].Locations[0].PhysicalLocation.ArtifactLocation; got.URI != "apps/web/preflights/check.yaml" || got.URIBaseID != "%SRCROOT%" {
+		t.Errorf("expected a URI relative to the git root, got %+v", got)
+	}
+	if got := results[1].Locations[0].PhysicalLocation.ArtifactLocation; got.URI != "file://"+filepath.ToSlash(outside) || got.URIBaseID != "" {
+		t.Errorf("expected an absolute file URI outside the git root, got %+v", got)
+	}
+}
+
+func TestPrintLintOutput_SARIF(t *testing.T) {
+	var buf bytes.Buffer
+	r := &runners{
+		outputFormat: "sarif",
+		w:            tabwriter.NewWriter(&buf, 0, 8, 4, ' ', 0),
+	}
+
+	output := &JSONLintOutput{
+		HelmResults: &HelmLintResults{Enabled: true, Charts: []ChartLintResult{{Path: "chart", Success: true}}},
+	}
+	if err := r.printLintOutput(output); err != nil {
+		t.Fatalf("printLintOutput() error = %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if decoded["$schema"] == nil || decoded["version"] != "2.1.0" {
+		t.Errorf("expected SARIF envelope, got %v", decoded)
+	}
+	runs, ok := decoded["runs"].([]interface{})
+	if !ok || len(runs) != 1 {
+		t.Fatalf("expected one run, got %v", decoded["runs"])
+	}
+	results := runs[0].(map[string]interface{})["results"].([]interface{})
+	if len(results) != 0 {
+		t.Errorf("expected empty results for a clean chart, got %v", results)
+	}
+}
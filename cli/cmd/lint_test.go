@@ -75,6 +75,17 @@ func copyFixtureToTemp(t *testing.T, fixturePath string) string {
 	return destPath
 }
 
+// writeTestFile writes content to path, creating its parent directories
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestLint_VerboseFlag(t *testing.T) {
 	fixturePath := getTestDataPath(t, "testdata/lint/simple-chart")
 	testDir := copyFixtureToTemp(t, fixturePath)
@@ -1876,3 +1887,30 @@ spec:
 
 	t.Log("SUCCESS: Autodiscovery correctly triggered with no config in directory tree")
 }
+
+func TestLintShouldFail(t *testing.T) {
+	passedWithWarnings := LintSummary{OverallSuccess: true, TotalWarnings: 1}
+	passedWithInfo := LintSummary{OverallSuccess: true, TotalInfo: 1}
+	failed := LintSummary{OverallSuccess: false, TotalErrors: 1}
+
+	tests := []struct {
+		failOn  string
+		summary LintSummary
+		want    bool
+	}{
+		{"", failed, true},
+		{"", passedWithWarnings, false},
+		{"error", failed, true},
+		{"error", passedWithWarnings, false},
+		{"warn", passedWithWarnings, true},
+		{"warn", passedWithInfo, false},
+		{"info", passedWithInfo, true},
+		{"info", LintSummary{OverallSuccess: true}, false},
+		{"none", failed, false},
+	}
+	for _, tt := range tests {
+		if got := lintShouldFail(tt.summary, tt.failOn); got != tt.want {
+			t.Errorf("lintShouldFail(%+v, %q) = %v, want %v", tt.summary, tt.failOn, got, tt.want)
+		}
+	}
+}
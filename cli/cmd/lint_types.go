@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"fmt"
+	"path/filepath"
 	"time"
 
 	"github.com/replicatedhq/replicated/pkg/imageextract"
@@ -14,6 +16,13 @@ type JSONLintOutput struct {
 	PreflightResults       *PreflightLintResults       `json:"preflight_results,omitempty"`
 	SupportBundleResults   *SupportBundleLintResults   `json:"support_bundle_results,omitempty"`
 	EmbeddedClusterResults *EmbeddedClusterLintResults `json:"embedded_cluster_results,omitempty"`
+	KotsResults            *KotsLintResults            `json:"kots_results,omitempty"`
+	KubeSchemaResults      *KubeSchemaLintResults      `json:"kube_schema_results,omitempty"`
+	PolicyResults          *PolicyLintResults          `json:"policy_results,omitempty"`
+	ReleaseGraphResults    *ReleaseGraphLintResults    `json:"release_graph_results,omitempty"`
+	ImageResults           *ImageLintResults           `json:"image_results,omitempty"`
+	Baseline               *BaselineResults            `json:"baseline,omitempty"`
+	Fixes                  []LintFix                   `json:"fixes,omitempty"` // Only with --fix
 	Summary                LintSummary                 `json:"summary"`
 	Images                 *ImageExtractResults        `json:"images,omitempty"` // Only if --verbose
 }
@@ -90,11 +99,126 @@ func (e EmbeddedClusterLintResult) GetSuccess() bool            { return e.Succe
 func (e EmbeddedClusterLintResult) GetMessages() []LintMessage  { return e.Messages }
 func (e EmbeddedClusterLintResult) GetSummary() ResourceSummary { return e.Summary }
 
+// KotsLintResults contains all KOTS manifest lint results
+type KotsLintResults struct {
+	Enabled   bool             `json:"enabled"`
+	Manifests []KotsLintResult `json:"manifests"`
+}
+
+// KotsLintResult represents lint results for a single manifest file containing KOTS kinds
+type KotsLintResult struct {
+	Path     string          `json:"path"`
+	Success  bool            `json:"success"`
+	Messages []LintMessage   `json:"messages"`
+	Summary  ResourceSummary `json:"summary"`
+}
+
+// Implement LintableResult interface for KotsLintResult
+func (k KotsLintResult) GetPath() string             { return k.Path }
+func (k KotsLintResult) GetSuccess() bool            { return k.Success }
+func (k KotsLintResult) GetMessages() []LintMessage  { return k.Messages }
+func (k KotsLintResult) GetSummary() ResourceSummary { return k.Summary }
+
+// KubeSchemaLintResults contains the Kubernetes schema lint results for each chart
+type KubeSchemaLintResults struct {
+	Enabled  bool                   `json:"enabled"`
+	Versions []string               `json:"versions,omitempty"` // Kubernetes minor versions validated against
+	Charts   []KubeSchemaLintResult `json:"charts"`
+}
+
+// KubeSchemaLintResult represents the Kubernetes schema lint results for a single Helm chart
+type KubeSchemaLintResult struct {
+	Path     string          `json:"path"`
+	Success  bool            `json:"success"`
+	Messages []LintMessage   `json:"messages"`
+	Summary  ResourceSummary `json:"summary"`
+}
+
+// Implement LintableResult interface for KubeSchemaLintResult
+func (k KubeSchemaLintResult) GetPath() string             { return k.Path }
+func (k KubeSchemaLintResult) GetSuccess() bool            { return k.Success }
+func (k KubeSchemaLintResult) GetMessages() []LintMessage  { return k.Messages }
+func (k KubeSchemaLintResult) GetSummary() ResourceSummary { return k.Summary }
+
+// PolicyLintResults contains the policy lint results for each chart, manifest and preflight spec
+type PolicyLintResults struct {
+	Enabled   bool               `json:"enabled"`
+	Resources []PolicyLintResult `json:"resources"`
+}
+
+// PolicyLintResult represents the policy lint results for a single resource
+type PolicyLintResult struct {
+	Path     string          `json:"path"`
+	Source   string          `json:"source"` // chart, manifest or preflight
+	Success  bool            `json:"success"`
+	Messages []LintMessage   `json:"messages"`
+	Summary  ResourceSummary `json:"summary"`
+}
+
+// Implement LintableResult interface for PolicyLintResult
+func (p PolicyLintResult) GetPath() string             { return p.Path }
+func (p PolicyLintResult) GetSuccess() bool            { return p.Success }
+func (p PolicyLintResult) GetMessages() []LintMessage  { return p.Messages }
+func (p PolicyLintResult) GetSummary() ResourceSummary { return p.Summary }
+
+// ReleaseGraphLintResults contains the release graph lint results for each chart, manifest and preflight spec
+type ReleaseGraphLintResults struct {
+	Enabled   bool                     `json:"enabled"`
+	Resources []ReleaseGraphLintResult `json:"resources"`
+}
+
+// ReleaseGraphLintResult represents the release graph lint results for a single resource
+type ReleaseGraphLintResult struct {
+	Path     string          `json:"path"`
+	Kind     string          `json:"kind"` // chart, helmchart, preflight or support-bundle
+	Success  bool            `json:"success"`
+	Messages []LintMessage   `json:"messages"`
+	Summary  ResourceSummary `json:"summary"`
+}
+
+// Implement LintableResult interface for ReleaseGraphLintResult
+func (g ReleaseGraphLintResult) GetPath() string             { return g.Path }
+func (g ReleaseGraphLintResult) GetSuccess() bool            { return g.Success }
+func (g ReleaseGraphLintResult) GetMessages() []LintMessage  { return g.Messages }
+func (g ReleaseGraphLintResult) GetSummary() ResourceSummary { return g.Summary }
+
+// ImageLintResults contains the image lint results for each chart
+type ImageLintResults struct {
+	Enabled bool              `json:"enabled"`
+	Charts  []ImageLintResult `json:"charts"`
+}
+
+// ImageLintResult represents the image lint results for a single chart
+type ImageLintResult struct {
+	Path     string          `json:"path"`
+	Success  bool            `json:"success"`
+	Messages []LintMessage   `json:"messages"`
+	Summary  ResourceSummary `json:"summary"`
+}
+
+// Implement LintableResult interface for ImageLintResult
+func (i ImageLintResult) GetPath() string             { return i.Path }
+func (i ImageLintResult) GetSuccess() bool            { return i.Success }
+func (i ImageLintResult) GetMessages() []LintMessage  { return i.Messages }
+func (i ImageLintResult) GetSummary() ResourceSummary { return i.Summary }
+
 // LintMessage represents a single lint issue (wraps lint2.LintMessage with JSON tags)
 type LintMessage struct {
-	Severity string `json:"severity"` // ERROR, WARNING, INFO
-	Path     string `json:"path,omitempty"`
-	Message  string `json:"message"`
+	Severity     string `json:"severity"` // ERROR, WARNING, INFO
+	Path         string `json:"path,omitempty"`
+	Message      string `json:"message"`
+	Line         int    `json:"line,omitempty"`
+	Rule         string `json:"rule,omitempty"`
+	PromotedFrom string `json:"promoted_from,omitempty"` // original severity when strict mode raised it to ERROR
+	Values       string `json:"values,omitempty"`        // values combinations that produced the message, when not all did
+}
+
+// displayMessage returns the message with the values combinations that produced it, if any
+func (m LintMessage) displayMessage() string {
+	if m.Values == "" {
+		return m.Message
+	}
+	return fmt.Sprintf("%s (values: %s)", m.Message, m.Values)
 }
 
 // ResourceSummary contains counts by severity for a resource
@@ -102,6 +226,9 @@ type ResourceSummary struct {
 	ErrorCount   int `json:"error_count"`
 	WarningCount int `json:"warning_count"`
 	InfoCount    int `json:"info_count"`
+	// SuppressedCount is the number of findings dropped by ignore rules or inline
+	// ignore comments. Baselined findings are reported separately.
+	SuppressedCount int `json:"suppressed_count"`
 }
 
 // LintSummary contains overall statistics across all linted resources
@@ -112,6 +239,7 @@ type LintSummary struct {
 	TotalErrors     int  `json:"total_errors"`
 	TotalWarnings   int  `json:"total_warnings"`
 	TotalInfo       int  `json:"total_info"`
+	TotalSuppressed int  `json:"total_suppressed"`
 	OverallSuccess  bool `json:"overall_success"`
 }
 
@@ -182,24 +310,82 @@ func (s SupportBundleLintResult) GetSuccess() bool            { return s.Success
 func (s SupportBundleLintResult) GetMessages() []LintMessage  { return s.Messages }
 func (s SupportBundleLintResult) GetSummary() ResourceSummary { return s.Summary }
 
+// lintSection holds the results of one linter
+type lintSection struct {
+	linter  string
+	enabled bool
+	results []LintableResult
+}
+
+// lintSections returns the results of every linter that ran, in report order
+func (o *JSONLintOutput) lintSections() []lintSection {
+	var sections []lintSection
+	add := func(linter string, enabled bool, results []LintableResult) {
+		sections = append(sections, lintSection{linter: linter, enabled: enabled, results: results})
+	}
+
+	if o.HelmResults != nil {
+		add("helm", o.HelmResults.Enabled, lintableResults(o.HelmResults.Charts))
+	}
+	if o.PreflightResults != nil {
+		add("preflight", o.PreflightResults.Enabled, lintableResults(o.PreflightResults.Specs))
+	}
+	if o.SupportBundleResults != nil {
+		add("support-bundle", o.SupportBundleResults.Enabled, lintableResults(o.SupportBundleResults.Specs))
+	}
+	if o.EmbeddedClusterResults != nil {
+		add("embedded-cluster", o.EmbeddedClusterResults.Enabled, lintableResults(o.EmbeddedClusterResults.Specs))
+	}
+	if o.KotsResults != nil {
+		add("kots", o.KotsResults.Enabled, lintableResults(o.KotsResults.Manifests))
+	}
+	if o.KubeSchemaResults != nil {
+		add("kube-schema", o.KubeSchemaResults.Enabled, lintableResults(o.KubeSchemaResults.Charts))
+	}
+	if o.PolicyResults != nil {
+		add("policy", o.PolicyResults.Enabled, lintableResults(o.PolicyResults.Resources))
+	}
+	if o.ReleaseGraphResults != nil {
+		add("release-graph", o.ReleaseGraphResults.Enabled, lintableResults(o.ReleaseGraphResults.Resources))
+	}
+	if o.ImageResults != nil {
+		add("image", o.ImageResults.Enabled, lintableResults(o.ImageResults.Charts))
+	}
+	return sections
+}
+
 // Helper functions to convert between types
 
+// lintableResults converts a slice of a linter's results to []LintableResult
+func lintableResults[T LintableResult](results []T) []LintableResult {
+	converted := make([]LintableResult, len(results))
+	for i, result := range results {
+		converted[i] = result
+	}
+	return converted
+}
+
 // convertLint2Messages converts lint2.LintMessage slice to LintMessage slice
 func convertLint2Messages(messages []lint2.LintMessage) []LintMessage {
 	result := make([]LintMessage, len(messages))
 	for i, msg := range messages {
 		result[i] = LintMessage{
-			Severity: msg.Severity,
-			Path:     msg.Path,
-			Message:  msg.Message,
+			Severity:     msg.Severity,
+			Path:         msg.Path,
+			Message:      msg.Message,
+			Line:         msg.Line,
+			Rule:         msg.Rule,
+			PromotedFrom: msg.PromotedFrom,
+			Values:       msg.Values,
 		}
 	}
 	return result
 }
 
-// calculateResourceSummary calculates summary from lint messages
-func calculateResourceSummary(messages []lint2.LintMessage) ResourceSummary {
-	summary := ResourceSummary{}
+// calculateResourceSummary calculates summary from lint messages and the number of
+// findings suppressed before they were reported
+func calculateResourceSummary(messages []lint2.LintMessage, suppressed int) ResourceSummary {
+	summary := ResourceSummary{SuppressedCount: suppressed}
 	for _, msg := range messages {
 		switch msg.Severity {
 		case "ERROR":
@@ -213,6 +399,21 @@ func calculateResourceSummary(messages []lint2.LintMessage) ResourceSummary {
 	return summary
 }
 
+// resolveLintMessagePath returns the file a lint message applies to. Messages without
+// a path apply to the linted resource itself. When messagePathsRelative is true, message
+// paths are relative to the resource path (as with helm, which reports paths inside
+// the chart directory).
+func resolveLintMessagePath(resultPath, messagePath string, messagePathsRelative bool) string {
+	switch {
+	case messagePath == "":
+		return resultPath
+	case messagePathsRelative && !filepath.IsAbs(messagePath):
+		return filepath.Join(resultPath, messagePath)
+	default:
+		return messagePath
+	}
+}
+
 // newLintMetadata creates metadata for the lint output
 func newLintMetadata(configFile, helmVersion, preflightVersion, supportBundleVersion, cliVersion string) LintMetadata {
 	return LintMetadata{
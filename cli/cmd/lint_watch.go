@@ -0,0 +1,430 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/replicated/pkg/lint2"
+	"github.com/spf13/cobra"
+)
+
+// lintWatchDebounce is how long the watcher waits for more events after a change, so
+// an editor saving several files (or writing one in steps) triggers a single re-lint
+const lintWatchDebounce = 300 * time.Millisecond
+
+// lintConfigFileNames are the file names FindAndParseConfig loads config from
+var lintConfigFileNames = map[string]bool{
+	".replicated":      true,
+	".replicated.yaml": true,
+}
+
+// lintWatcher watches the files local lint reads and reports batches of changes.
+// The current directory is watched recursively, skipping gitignored directories.
+// Resources configured outside it are watched too, as are the directories
+// .replicated files and the files they include are loaded from.
+type lintWatcher struct {
+	watcher   *fsnotify.Watcher
+	gitignore *lint2.GitignoreChecker
+	// watched maps each watched directory to whether events in it are checked
+	// against .gitignore. Directories of explicitly configured resources are not.
+	watched map[string]bool
+	// resources are the files and directories linted in the last pass
+	resources map[string]bool
+	// configFiles are the config files, including the files they include, read in
+	// the last pass
+	configFiles map[string]bool
+}
+
+// newLintWatcher starts watching the current directory and the directories config
+// files are loaded from
+func newLintWatcher() (*lintWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to start file watcher")
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		watcher.Close()
+		return nil, errors.Wrap(err, "failed to get current directory")
+	}
+
+	// A nil checker (not a git repository) ignores nothing
+	gitignore, _ := lint2.NewGitignoreChecker(cwd)
+
+	w := &lintWatcher{
+		watcher:   watcher,
+		gitignore: gitignore,
+		watched:   make(map[string]bool),
+	}
+
+	// Config is merged from every .replicated file between here and the filesystem
+	// root. Only config file events matter in these directories; failing to watch
+	// one (e.g. no permission) just means edits there are not picked up.
+	for dir := cwd; ; dir = filepath.Dir(dir) {
+		_ = watcher.Add(dir)
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+
+	if err := w.watchTree(cwd, true); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// Close stops watching
+func (w *lintWatcher) Close() error {
+	return w.watcher.Close()
+}
+
+// watchTree watches root and every directory below it. With respectGitignore,
+// gitignored directories are skipped and events in them are dropped.
+func (w *lintWatcher) watchTree(root string, respectGitignore bool) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Directories can disappear or be unreadable while walking
+			if path == root {
+				return errors.Wrapf(err, "failed to watch %s", root)
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if path != root && respectGitignore && w.gitignore.ShouldIgnore(path) {
+			return filepath.SkipDir
+		}
+		return w.watchDir(path, respectGitignore)
+	})
+}
+
+func (w *lintWatcher) watchDir(dir string, respectGitignore bool) error {
+	if current, ok := w.watched[dir]; ok {
+		// A directory is only checked against .gitignore if nothing configured lives in it
+		w.watched[dir] = current && respectGitignore
+		return nil
+	}
+	if err := w.watcher.Add(dir); err != nil {
+		return errors.Wrapf(err, "failed to watch %s", dir)
+	}
+	w.watched[dir] = respectGitignore
+	return nil
+}
+
+// watchResources watches the files and directories of the resources being linted,
+// so resources configured outside the current directory (or in gitignored
+// directories) are watched as well. Chart directories are watched recursively;
+// for files, the directory containing them is watched so replacing a file (as many
+// editors do on save) is still seen.
+func (w *lintWatcher) watchResources(paths []string) {
+	if w == nil {
+		return
+	}
+	w.resources = make(map[string]bool, len(paths))
+	for _, path := range paths {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			continue
+		}
+		w.resources[absPath] = true
+		info, err := os.Stat(absPath)
+		if err != nil {
+			continue
+		}
+		if info.IsDir() {
+			_ = w.watchTree(absPath, false)
+		} else {
+			_ = w.watchDir(filepath.Dir(absPath), false)
+		}
+	}
+}
+
+// watchConfigFiles watches the config files lint reads, so that edits to files a
+// .replicated config includes, which may have any name, are seen as config changes
+func (w *lintWatcher) watchConfigFiles(paths []string) {
+	if w == nil {
+		return
+	}
+	w.configFiles = make(map[string]bool, len(paths))
+	for _, path := range paths {
+		w.configFiles[path] = true
+		// Like the parent directories of the current one, only config file events
+		// matter in these directories
+		_ = w.watcher.Add(filepath.Dir(path))
+	}
+}
+
+// isConfigFile reports whether path is a config file
+func (w *lintWatcher) isConfigFile(path string) bool {
+	return lintConfigFileNames[filepath.Base(path)] || w.configFiles[path]
+}
+
+// lintWatchPaths returns the files and directories read while linting extracted
+// resources and the Embedded Cluster and KOTS manifests
+func lintWatchPaths(extracted *ExtractedPaths, ecPaths, kotsPaths []string) []string {
+	var paths []string
+	paths = append(paths, extracted.ChartPaths...)
+	for _, chart := range extracted.ChartsWithMetadata {
+		paths = append(paths, chart.ValuesFiles...)
+	}
+	for _, preflight := range extracted.Preflights {
+		paths = append(paths, preflight.SpecPath)
+		if preflight.ValuesPath != "" {
+			paths = append(paths, preflight.ValuesPath)
+		}
+	}
+	paths = append(paths, extracted.SupportBundles...)
+	for _, manifest := range extracted.HelmChartManifests {
+		paths = append(paths, manifest.FilePath)
+	}
+	paths = append(paths, ecPaths...)
+	paths = append(paths, kotsPaths...)
+	return paths
+}
+
+// releaseGraphWatchPaths returns the resources checked by the release graph linter,
+// which are all that is watched when it stops the run before the other linters
+func releaseGraphWatchPaths(results *ReleaseGraphLintResults) []string {
+	paths := make([]string, 0, len(results.Resources))
+	for _, resource := range results.Resources {
+		paths = append(paths, resource.Path)
+	}
+	return paths
+}
+
+// relevant reports whether an event on path should trigger a re-lint: a change to
+// a config file, to a linted resource, or to a YAML file that may be a new one
+func (w *lintWatcher) relevant(path string) bool {
+	if w.isConfigFile(path) {
+		return true
+	}
+
+	respectGitignore, ok := w.watched[filepath.Dir(path)]
+	if !ok {
+		// Config directories outside the watched tree only matter for config files
+		return false
+	}
+	for _, part := range strings.Split(path, string(filepath.Separator)) {
+		if part == ".git" {
+			return false
+		}
+	}
+	if w.isResource(path) {
+		return true
+	}
+	if respectGitignore && w.gitignore.ShouldIgnore(path) {
+		return false
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// isNewDir reports whether event is a directory created inside the watched tree
+func (w *lintWatcher) isNewDir(event fsnotify.Event) bool {
+	if !event.Has(fsnotify.Create) {
+		return false
+	}
+	respectGitignore, ok := w.watched[filepath.Dir(event.Name)]
+	if !ok || filepath.Base(event.Name) == ".git" {
+		return false
+	}
+	if info, err := os.Stat(event.Name); err != nil || !info.IsDir() {
+		return false
+	}
+	return !respectGitignore || !w.gitignore.ShouldIgnore(event.Name)
+}
+
+// isResource reports whether path is, or is inside, a resource linted in the last pass
+func (w *lintWatcher) isResource(path string) bool {
+	for dir := path; ; dir = filepath.Dir(dir) {
+		if w.resources[dir] {
+			return true
+		}
+		if filepath.Dir(dir) == dir {
+			return false
+		}
+	}
+}
+
+// lintWatchChange is a batch of file changes
+type lintWatchChange struct {
+	paths         []string
+	configChanged bool
+}
+
+// wait blocks until relevant files change and returns them once no further events
+// arrive for the debounce interval. New directories are watched as they appear.
+func (w *lintWatcher) wait(ctx context.Context, debounce time.Duration) (*lintWatchChange, error) {
+	changed := make(map[string]bool)
+	configChanged := false
+	var timer <-chan time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+
+		case _, ok := <-w.watcher.Errors:
+			if !ok {
+				return nil, errors.New("file watcher stopped")
+			}
+			// Events may have been lost (e.g. the event queue overflowed), so
+			// re-discover everything rather than trusting the changed paths
+			configChanged = true
+			timer = time.After(debounce)
+
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return nil, errors.New("file watcher stopped")
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+
+			if w.isNewDir(event) {
+				// Watch directories created inside the watched tree, like a new chart.
+				// Files may have been written into it before it was watched, so it
+				// counts as a change itself.
+				_ = w.watchTree(event.Name, w.watched[filepath.Dir(event.Name)])
+			} else if !w.relevant(event.Name) {
+				continue
+			}
+
+			changed[event.Name] = true
+			if w.isConfigFile(event.Name) {
+				configChanged = true
+			}
+			timer = time.After(debounce)
+
+		case <-timer:
+			paths := make([]string, 0, len(changed))
+			for path := range changed {
+				paths = append(paths, path)
+			}
+			sort.Strings(paths)
+			return &lintWatchChange{paths: paths, configChanged: configChanged}, nil
+		}
+	}
+}
+
+// watchLint lints once, then re-lints whenever a watched file changes until
+// interrupted. One result cache is used for the whole session (kept in memory only
+// with --no-cache), so only resources whose inputs changed are linted again. Every
+// pass re-reads the .replicated config and re-discovers resources, so config edits
+// take effect immediately.
+func (r *runners) watchLint(cmd *cobra.Command) error {
+	if r.args.lintWriteBaseline != "" {
+		return errors.New("--watch cannot be used with --write-baseline")
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	cmd.SetContext(ctx)
+
+	watcher, err := newLintWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	r.lintWatcher = watcher
+	defer func() { r.lintWatcher = nil }()
+
+	if r.args.lintNoCache {
+		r.lintCache = newMemoryLintResultCache(nil)
+	} else {
+		r.lintCache = newLintResultCache(nil)
+		if r.lintCache == nil {
+			r.lintCache = newMemoryLintResultCache(nil)
+		}
+	}
+	defer func() { r.lintCache = nil }()
+
+	clearScreen := r.outputFormat == "table" && r.stdoutIsTTY
+	for {
+		r.reportWatchPass(r.lintOnce(cmd))
+
+		if r.outputFormat == "table" {
+			fmt.Fprintf(r.w, "\nWatching for changes (press Ctrl+C to stop)...\n")
+			r.w.Flush()
+		}
+
+		change, err := watcher.wait(ctx, lintWatchDebounce)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		if clearScreen {
+			// Clear the screen and move the cursor home so the results table is redrawn
+			fmt.Fprint(r.w, "\033[H\033[2J")
+		}
+		if r.outputFormat == "table" {
+			fmt.Fprintf(r.w, "%s\n\n", describeLintWatchChange(change))
+			r.w.Flush()
+		}
+	}
+}
+
+// reportWatchPass prints an error from a lint pass in watch mode. Lint failures were
+// already reported in the results, so they are not repeated.
+func (r *runners) reportWatchPass(err error) {
+	if err == nil || errors.Is(err, errLintFailed) {
+		return
+	}
+	if r.outputFormat == "table" {
+		fmt.Fprintf(r.w, "Error: %v\n", err)
+	} else {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	}
+	r.w.Flush()
+}
+
+// describeLintWatchChange summarizes a batch of changes for display
+func describeLintWatchChange(change *lintWatchChange) string {
+	if change.configChanged {
+		return "Config changed, re-discovering resources..."
+	}
+	if len(change.paths) == 0 {
+		return "Re-linting..."
+	}
+
+	names := make([]string, 0, len(change.paths))
+	for _, path := range change.paths {
+		name := path
+		if cwd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(cwd, path); err == nil && !strings.HasPrefix(rel, "..") {
+				name = rel
+			}
+		}
+		names = append(names, name)
+	}
+
+	const maxNames = 3
+	if len(names) > maxNames {
+		return fmt.Sprintf("Changed: %s and %d more, re-linting...", strings.Join(names[:maxNames], ", "), len(names)-maxNames)
+	}
+	return fmt.Sprintf("Changed: %s, re-linting...", strings.Join(names, ", "))
+}
@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newTestLintWatcher starts a watcher in a temp git repository that ignores dist/
+func newTestLintWatcher(t *testing.T) (*lintWatcher, string) {
+	t.Helper()
+
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sub := range []string{".git", "chart", "dist"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTestFile(t, filepath.Join(dir, ".gitignore"), "dist/\n")
+	t.Chdir(dir)
+
+	w, err := newLintWatcher()
+	if err != nil {
+		t.Fatalf("newLintWatcher() error = %v", err)
+	}
+	t.Cleanup(func() { w.Close() })
+	return w, dir
+}
+
+func waitForTestChange(t *testing.T, w *lintWatcher) *lintWatchChange {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	change, err := w.wait(ctx, 100*time.Millisecond)
+	if err != nil {
+		t.Fatalf("wait() error = %v", err)
+	}
+	return change
+}
+
+func TestLintWatcher_BatchesChangesAndSkipsGitignored(t *testing.T) {
+	w, dir := newTestLintWatcher(t)
+
+	writeTestFile(t, filepath.Join(dir, "dist", "rendered.yaml"), "kind: Deployment\n")
+	writeTestFile(t, filepath.Join(dir, "lint-output.txt"), "Status: Passed\n")
+	writeTestFile(t, filepath.Join(dir, "chart", "values.yaml"), "replicas: 1\n")
+	writeTestFile(t, filepath.Join(dir, "chart", "values.yaml"), "replicas: 2\n")
+
+	change := waitForTestChange(t, w)
+	want := []string{filepath.Join(dir, "chart", "values.yaml")}
+	if !reflect.DeepEqual(change.paths, want) {
+		t.Errorf("paths = %v, want %v", change.paths, want)
+	}
+	if change.configChanged {
+		t.Error("expected configChanged = false")
+	}
+}
+
+func TestLintWatcher_ConfigChange(t *testing.T) {
+	w, dir := newTestLintWatcher(t)
+
+	writeTestFile(t, filepath.Join(dir, ".replicated"), "appSlug: test\n")
+
+	if change := waitForTestChange(t, w); !change.configChanged {
+		t.Errorf("expected a .replicated edit to be a config change, got %+v", change)
+	}
+}
+
+func TestLintWatcher_IncludedConfigChange(t *testing.T) {
+	w, dir := newTestLintWatcher(t)
+
+	shared, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	included := filepath.Join(shared, "lint.yaml")
+	writeTestFile(t, included, "repl-lint:\n  version: 1\n")
+	w.watchConfigFiles([]string{included, filepath.Join(dir, ".replicated")})
+
+	writeTestFile(t, included, "repl-lint:\n  version: 1\n  strict: true\n")
+
+	change := waitForTestChange(t, w)
+	if !change.configChanged || !reflect.DeepEqual(change.paths, []string{included}) {
+		t.Errorf("expected an included file edit to be a config change, got %+v", change)
+	}
+}
+
+func TestLintWatcher_WatchesNewDirectories(t *testing.T) {
+	w, dir := newTestLintWatcher(t)
+
+	if err := os.Mkdir(filepath.Join(dir, "new-chart"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	waitForTestChange(t, w)
+
+	writeTestFile(t, filepath.Join(dir, "new-chart", "Chart.yaml"), "name: new-chart\n")
+	change := waitForTestChange(t, w)
+	want := []string{filepath.Join(dir, "new-chart", "Chart.yaml")}
+	if !reflect.DeepEqual(change.paths, want) {
+		t.Errorf("paths = %v, want %v", change.paths, want)
+	}
+}
+
+func TestLintWatcher_WatchResources(t *testing.T) {
+	w, dir := newTestLintWatcher(t)
+
+	// Configured resources are watched even outside the current directory or when gitignored
+	outside, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := filepath.Join(outside, "preflight.yaml")
+	writeTestFile(t, spec, "kind: Preflight\n")
+	ignored := filepath.Join(dir, "dist", "support-bundle.yaml")
+	writeTestFile(t, ignored, "kind: SupportBundle\n")
+	w.watchResources([]string{spec, ignored})
+
+	writeTestFile(t, spec, "kind: Preflight\nspec: {}\n")
+	change := waitForTestChange(t, w)
+	if !reflect.DeepEqual(change.paths, []string{spec}) {
+		t.Errorf("paths = %v, want %v", change.paths, []string{spec})
+	}
+
+	writeTestFile(t, ignored, "kind: SupportBundle\nspec: {}\n")
+	change = waitForTestChange(t, w)
+	if !reflect.DeepEqual(change.paths, []string{ignored}) {
+		t.Errorf("paths = %v, want %v", change.paths, []string{ignored})
+	}
+}
+
+func TestLintWatcher_ResourceFiles(t *testing.T) {
+	w, dir := newTestLintWatcher(t)
+
+	// Any file in a linted chart counts, not only YAML
+	w.watchResources([]string{filepath.Join(dir, "chart")})
+	helpers := filepath.Join(dir, "chart", "_helpers.tpl")
+	writeTestFile(t, helpers, "{{- define \"name\" -}}{{- end -}}\n")
+
+	change := waitForTestChange(t, w)
+	if !reflect.DeepEqual(change.paths, []string{helpers}) {
+		t.Errorf("paths = %v, want %v", change.paths, []string{helpers})
+	}
+}
+
+func TestLintWatcher_WaitCanceled(t *testing.T) {
+	w, _ := newTestLintWatcher(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := w.wait(ctx, 100*time.Millisecond); err == nil {
+		t.Error("expected an error after the context is canceled")
+	}
+}
+
+func TestDescribeLintWatchChange(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		change *lintWatchChange
+		want   string
+	}{
+		{
+			name:   "config",
+			change: &lintWatchChange{paths: []string{filepath.Join(cwd, ".replicated")}, configChanged: true},
+			want:   "Config changed, re-discovering resources...",
+		},
+		{
+			name:   "files",
+			change: &lintWatchChange{paths: []string{filepath.Join(cwd, "chart", "values.yaml")}},
+			want:   "Changed: " + filepath.Join("chart", "values.yaml") + ", re-linting...",
+		},
+		{
+			name: "many files",
+			change: &lintWatchChange{paths: []string{
+				filepath.Join(cwd, "a.yaml"), filepath.Join(cwd, "b.yaml"),
+				filepath.Join(cwd, "c.yaml"), filepath.Join(cwd, "d.yaml"),
+			}},
+			want: "Changed: a.yaml, b.yaml, c.yaml and 1 more, re-linting...",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeLintWatchChange(tt.change); got != tt.want {
+				t.Errorf("describeLintWatchChange() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
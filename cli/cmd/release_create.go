@@ -13,8 +13,6 @@ import (
 	"time"
 
 	"github.com/bmatcuk/doublestar/v4"
-	"github.com/go-git/go-git/v5"
-	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/google/uuid"
 	"github.com/manifoldco/promptui"
 	"github.com/pkg/errors"
@@ -71,6 +69,7 @@ replicated release create --version 1.0.0 --promote Unstable --required`,
 	cmd.Flags().StringVar(&r.args.lintReleaseFailOn, "fail-on", "error", "The minimum severity to cause the command to exit with a non-zero exit code. Supported values are [info, warn, error, none].")
 	// Replicated release create lint flag
 	cmd.Flags().BoolVar(&r.args.createReleaseLint, "lint", false, "Lint a manifests directory prior to creation of the KOTS Release.")
+	cmd.Flags().StringVar(&r.args.createReleaseLintOutput, "lint-output", "", "The output format for --lint results. Supported formats are [table, json, junit, sarif]; junit and sarif require local lint. Defaults to --output.")
 	cmd.Flags().BoolVar(&r.args.createReleasePromoteRequired, "required", false, "When used with --promote <channel>, marks this release as required during upgrades.")
 	cmd.Flags().BoolVar(&r.args.createReleasePromoteEnsureChannel, "ensure-channel", false, "When used with --promote <channel>, will create the channel if it doesn't exist")
 	cmd.Flags().BoolVar(&r.args.createReleasePromoteWaitForAirgap, "wait-for-airgap", false, "When used with --promote <channel>, wait for airgap bundle builds to complete (KOTS apps only)")
@@ -138,39 +137,7 @@ replicated release create --version 1.0.0 --promote Unstable --required`,
 }
 
 func (r *runners) gitSHABranch() (sha string, branch string, dirty bool, err error) {
-	path := "."
-	rev := "HEAD"
-	repository, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{DetectDotGit: true})
-	if err != nil {
-		return "", "", false, fmt.Errorf("git open '%q' failed: %w", path, err)
-	}
-	h, err := repository.ResolveRevision(plumbing.Revision(rev))
-	if err != nil {
-		return "", "", false, fmt.Errorf("git resolve revision '%q' failed: %w", rev, err)
-	}
-	head, err := repository.Head()
-	if err != nil {
-		return "", "", false, fmt.Errorf("git resolve HEAD failed: %w", err)
-	}
-
-	worktree, err := repository.Worktree()
-	if err != nil {
-		return "", "", false, fmt.Errorf("git get worktree failed: %w", err)
-	}
-	status, err := worktree.Status()
-	if err != nil {
-		return "", "", false, fmt.Errorf("git get status failed: %w", err)
-	}
-
-	branchName := head.Name().Short()
-
-	// for GH Actions, prefer env branch
-	envBranch := os.Getenv("GITHUB_BRANCH_NAME")
-	if envBranch != "" {
-		branchName = envBranch
-	}
-
-	return h.String()[0:7], branchName, !status.IsClean(), nil
+	return tools.GitSHABranch(".")
 }
 
 func (r *runners) setKOTSDefaultReleaseParams() error {
@@ -253,10 +220,10 @@ func (r *runners) releaseCreate(cmd *cobra.Command, args []string) (err error) {
 	var stagingDir string
 
 	if useConfigFlow {
-		// Try to find and parse .replicated config
-		parser := tools.NewConfigParser()
+		// Try to find and parse .replicated config. The release is built from its
+		// values, so ${git.*} variables must be readable.
 		var configErr error
-		config, configErr = parser.FindAndParseConfig(".")
+		config, configErr = findAndParseEnvironmentConfig(".", tools.WithGitRequired(true))
 		if configErr != nil {
 			return errors.Wrap(configErr, "failed to find or parse .replicated config file")
 		}
@@ -339,8 +306,15 @@ Prepared to create release with defaults:
 		// Request lint release yaml directory to check
 		r.args.lintReleaseYamlDir = r.args.createReleaseYamlDir
 		r.args.lintReleaseChart = r.args.createReleaseChart
+		// Lint results can be reported in a different format than the release itself,
+		// e.g. a JUnit report for CI alongside the usual table output
+		outputFormat := r.outputFormat
+		if r.args.createReleaseLintOutput != "" {
+			r.outputFormat = r.args.createReleaseLintOutput
+		}
 		// Call release_lint.go releaseLint function
 		err = r.releaseLint(cmd, args)
+		r.outputFormat = outputFormat
 		if err != nil {
 			return errors.Wrap(err, "lint yaml")
 		}
@@ -7,6 +7,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"runtime"
 
 	"github.com/pkg/errors"
 	"github.com/replicatedhq/replicated/cli/print"
@@ -25,7 +26,7 @@ var (
 	}
 )
 
-func (r *runners) InitReleaseLint(parent *cobra.Command) {
+func (r *runners) InitReleaseLint(parent *cobra.Command) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:          "lint",
 		Short:        "Lint a directory of KOTS manifests or local resources",
@@ -41,10 +42,20 @@ func (r *runners) InitReleaseLint(parent *cobra.Command) {
 
 	// New flags (for local lint - when flag=1)
 	cmd.Flags().BoolVarP(&r.args.lintVerbose, "verbose", "v", false, "Show detailed output including extracted container images (local lint only)")
+	cmd.Flags().IntVar(&r.args.lintParallel, "parallel", runtime.GOMAXPROCS(0), "Maximum number of lint tasks to run at once (local lint only)")
+	cmd.Flags().StringVar(&r.args.lintChangedSince, "changed-since", "", "Only lint charts, preflights and manifests changed since this git ref, e.g. origin/main (local lint only)")
+	cmd.Flags().BoolVar(&r.args.lintWatch, "watch", false, "Watch charts, preflights, manifests and the .replicated config, and re-lint when they change (local lint only)")
+	cmd.Flags().BoolVar(&r.args.lintNoCache, "no-cache", false, "Lint every resource even if its inputs are unchanged since the last run (local lint only)")
+	cmd.Flags().StringVar(&r.args.lintWriteBaseline, "write-baseline", "", "Write all current findings to this baseline file so later runs only report new findings (local lint only)")
+	cmd.Flags().BoolVar(&r.args.lintFix, "fix", false, "Fix mechanical findings in place before linting (local lint only)")
+	cmd.Flags().BoolVar(&r.args.lintFixDryRun, "dry-run", false, "With --fix, print the fixes as a unified diff without changing any file (local lint only)")
+	cmd.Flags().StringVar(&r.args.lintFixOCILayout, "oci-layout", "", "With --fix, pin image tags to the digests of the images in this local OCI layout directory (local lint only)")
 
 	cmd.Flags().MarkHidden("chart")
 
 	cmd.RunE = r.releaseLint
+
+	return cmd
 }
 
 // releaseLint uses the replicatedhq/kots-lint service. This currently uses
@@ -83,8 +94,19 @@ func (r *runners) releaseLint(cmd *cobra.Command, args []string) error {
 	return r.releaseLintV1(cmd, args)
 }
 
+// validReleaseLintV1OutputFormats are the output formats the remote release linter supports
+var validReleaseLintV1OutputFormats = map[string]struct{}{
+	"table": {},
+	"json":  {},
+}
+
 // releaseLintV1 is the original release lint implementation (used when flag=0)
 func (r *runners) releaseLintV1(_ *cobra.Command, _ []string) error {
+	// JUnit and SARIF reports are built from local lint results only
+	if _, ok := validReleaseLintV1OutputFormats[r.outputFormat]; !ok {
+		return errors.Errorf("output format %q is only supported by local lint; the remote release linter (used with --yaml-dir or --chart) supports [table, json]", r.outputFormat)
+	}
+
 	if !r.hasApp() {
 		return errors.New("no app specified")
 	}
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func (r *runners) InitReleaseLintCache(parent *cobra.Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the local lint result cache",
+		Long: `Local linting caches the result for each chart and spec, keyed by a hash of its files, values, linter tool version and lint config. Unchanged resources are not linted again on later runs.
+
+Use --no-cache on release lint to bypass the cache for a single run.`,
+		Example: `# Remove all cached lint results
+replicated release lint cache clear`,
+		// Override parent's pre-run. The lint cache is local and doesn't need API access.
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
+	}
+	parent.AddCommand(cmd)
+
+	return cmd
+}
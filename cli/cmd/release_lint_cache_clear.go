@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/replicated/pkg/lint2"
+	"github.com/spf13/cobra"
+)
+
+func (r *runners) InitReleaseLintCacheClear(parent *cobra.Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "clear",
+		Short: "Remove all cached lint results",
+		Long: `Remove all cached lint results so the next local lint run lints every resource.
+
+Downloaded linter tools are not removed.`,
+		Example: `# Remove all cached lint results
+replicated release lint cache clear`,
+		Args:         cobra.NoArgs,
+		RunE:         r.clearLintCache,
+		SilenceUsage: true,
+	}
+	parent.AddCommand(cmd)
+
+	return cmd
+}
+
+func (r *runners) clearLintCache(cmd *cobra.Command, args []string) error {
+	dir, err := lint2.LintCacheDir()
+	if err != nil {
+		return errors.Wrap(err, "get lint cache directory")
+	}
+
+	if err := lint2.NewLintCache(dir).Clear(); err != nil {
+		return errors.Wrap(err, "clear lint cache")
+	}
+
+	fmt.Fprintf(r.w, "Cleared lint cache at %s\n", dir)
+	return r.w.Flush()
+}
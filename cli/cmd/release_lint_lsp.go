@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/replicated/pkg/lint2"
+	"github.com/replicatedhq/replicated/pkg/lsp"
+	"github.com/replicatedhq/replicated/pkg/tools"
+	"github.com/replicatedhq/replicated/pkg/version"
+	"github.com/spf13/cobra"
+)
+
+func (r *runners) InitReleaseLintLSP(parent *cobra.Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lsp",
+		Short: "Run a language server that lints documents as they are saved",
+		Long: `Run a Language Server Protocol server over stdin and stdout for editors.
+
+When a document in the workspace is opened or saved, the server finds the .replicated
+config that applies to it, runs the preflight, support bundle, Embedded Cluster and KOTS
+linters that cover the document, and publishes their findings as diagnostics. Linter
+settings, ignore rules and the lint baseline in the config are honored. Without a
+.replicated config, resources are auto-discovered in the workspace like release lint does.`,
+		Example: `# Configure your editor to start the language server for YAML files with
+replicated release lint lsp`,
+		Args:         cobra.NoArgs,
+		RunE:         r.runLintLSP,
+		SilenceUsage: true,
+		// Override parent's pre-run. Linting is local and doesn't need API access.
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
+	}
+	parent.AddCommand(cmd)
+
+	return cmd
+}
+
+func (r *runners) runLintLSP(cmd *cobra.Command, args []string) error {
+	// The protocol is spoken over stdout, so anything else written there, such as
+	// tool download progress, is sent to stderr instead
+	out := os.Stdout
+	os.Stdout = os.Stderr
+	defer func() { os.Stdout = out }()
+
+	server := lsp.NewServer(lintDocument, "replicated", version.Version())
+	return server.Run(cmd.Context(), cmd.InOrStdin(), out)
+}
+
+// lintDocument runs the linters that cover the document at path and returns their
+// findings for that document, keyed by linter. Only documents that are part of the
+// release described by the .replicated config are linted.
+func lintDocument(ctx context.Context, root, path string) (map[string][]lint2.LintMessage, error) {
+	config, err := findAndParseEnvironmentConfig(filepath.Dir(path))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load .replicated config")
+	}
+
+	// Without configured resources, discover them in the workspace
+	if len(config.Charts) == 0 && len(config.Preflights) == 0 && len(config.Manifests) == 0 {
+		if root == "" {
+			root = filepath.Dir(path)
+		}
+		pattern := filepath.Join(root, "**")
+		preflightPaths, err := lint2.DiscoverPreflightPaths(pattern)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to discover preflight specs")
+		}
+		if len(preflightPaths) > 0 {
+			config.Preflights = []tools.PreflightConfig{{Path: pattern}}
+		}
+		config.Manifests = []string{pattern}
+	}
+
+	resolver := newLintResolver(config)
+	linterOptions, err := newLinterOptions(config, resolver)
+	if err != nil {
+		return nil, err
+	}
+	baseline, err := newLintBaselineFilter(config.ReplLint.Baseline, false)
+	if err != nil {
+		return nil, err
+	}
+
+	preflightVersion := resolveToolVersion(ctx, config, resolver, tools.ToolPreflight, tools.DefaultPreflightVersion)
+	supportBundleVersion := resolveToolVersion(ctx, config, resolver, tools.ToolSupportBundle, tools.DefaultSupportBundleVersion)
+
+	extracted, err := extractAllPathsAndMetadata(ctx, config, false, "", preflightVersion, supportBundleVersion)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to extract paths and metadata")
+	}
+
+	linters := config.ReplLint.Linters
+	results := make(map[string][]lint2.LintMessage)
+
+	// add records the findings of a linted resource that belong to the document
+	add := func(linter, resultPath string, result *lint2.LintResult) {
+		messages, _ := baseline.filter(linter, resultPath, result.Success, result.Messages, false)
+		for _, msg := range messages {
+			if samePath(resolveLintMessagePath(resultPath, msg.Path, false), path) {
+				results[linter] = append(results[linter], msg)
+			}
+		}
+	}
+
+	if linters.Preflight.IsEnabled() {
+		for _, pf := range extracted.Preflights {
+			if !samePath(pf.SpecPath, path) {
+				continue
+			}
+			result, err := lint2.LintPreflight(ctx, pf.SpecPath, pf.ValuesPath, pf.ChartName, pf.ChartVersion,
+				extracted.HelmChartManifests, preflightVersion, linterOptions["preflight"]...)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to lint preflight spec: %s", pf.SpecPath)
+			}
+			add("preflight", pf.SpecPath, result)
+		}
+	}
+
+	if linters.SupportBundle.IsEnabled() {
+		for _, specPath := range extracted.SupportBundles {
+			if !samePath(specPath, path) {
+				continue
+			}
+			result, err := lint2.LintSupportBundle(ctx, specPath, supportBundleVersion, linterOptions["support-bundle"]...)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to lint support bundle spec: %s", specPath)
+			}
+			add("support-bundle", specPath, result)
+		}
+	}
+
+	if !linters.EmbeddedCluster.IsEnabled() && !linters.Kots.IsEnabled() {
+		return results, nil
+	}
+
+	// EC and KOTS lint every manifest together, since they check references across files
+	manifestPaths, err := lint2.ExpandManifestGlobs(config.Manifests)
+	if err != nil {
+		return nil, errors.Wrap(err, "expanding manifest globs for ec and kots lint")
+	}
+	if !slices.ContainsFunc(manifestPaths, func(manifestPath string) bool { return samePath(manifestPath, path) }) {
+		return results, nil
+	}
+
+	if linters.EmbeddedCluster.IsEnabled() {
+		ecBinaryPath, err := resolveECBinaryPath(ctx, resolver, linters.EmbeddedCluster, manifestPaths)
+		if err != nil {
+			return nil, err
+		}
+		result, err := lint2.LintEmbeddedCluster(ctx, manifestPaths, ecBinaryPath, linters.EmbeddedCluster.GetDisableChecks(), linterOptions["embedded-cluster"]...)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to run ec lint")
+		}
+		// EC reports the file of each finding; findings without one are not shown
+		add("embedded-cluster", "", result)
+	}
+
+	if linters.Kots.IsEnabled() {
+		fileResults, err := lint2.LintKots(manifestPaths, extracted.ChartsWithMetadata, linterOptions["kots"]...)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to run kots lint")
+		}
+		for _, fileResult := range fileResults {
+			if samePath(fileResult.Path, path) {
+				add("kots", fileResult.Path, &lint2.LintResult{Success: fileResult.Success, Messages: fileResult.Messages})
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// samePath reports whether two paths name the same file, resolving relative paths
+// against the current directory
+func samePath(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+	return absA == absB
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLintDocument(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		".replicated": `manifests:
+  - ./manifests/*.yaml
+repl-lint:
+  tools:
+    preflight: "0.123.9"
+    support-bundle: "0.123.9"
+  linters:
+    kots:
+      disabled: false
+      ignore:
+        - rule: application-ports
+`,
+		"manifests/app.yaml": `apiVersion: kots.io/v1beta1
+kind: Application
+metadata:
+  name: app
+spec:
+  statusInformers: deployment/web
+  ports: web
+`,
+		"manifests/config.yaml": `apiVersion: kots.io/v1beta1
+kind: Config
+metadata:
+  name: config
+spec: {}
+`,
+		"other/app.yaml": `apiVersion: kots.io/v1beta1
+kind: Application
+metadata:
+  name: other
+spec: {}
+`,
+	}
+	for name, content := range files {
+		writeTestFile(t, filepath.Join(dir, name), content)
+	}
+
+	results, err := lintDocument(context.Background(), dir, filepath.Join(dir, "manifests", "app.yaml"))
+	if err != nil {
+		t.Fatalf("lintDocument() error = %v", err)
+	}
+
+	// The Config's findings belong to another document and the ports finding is ignored
+	type finding struct {
+		Severity, Rule string
+		Line, Column   int
+	}
+	var got []finding
+	for linter, messages := range results {
+		if linter != "kots" {
+			t.Errorf("unexpected findings from %s: %+v", linter, messages)
+		}
+		for _, msg := range messages {
+			got = append(got, finding{msg.Severity, msg.Rule, msg.Line, msg.Column})
+		}
+	}
+	want := []finding{
+		{"ERROR", "application-status-informers", 6, 20},
+		{"WARNING", "application-title", 6, 3},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findings = %+v, want %+v", got, want)
+	}
+
+	// Documents that are not part of the release are not linted
+	results, err = lintDocument(context.Background(), dir, filepath.Join(dir, "other", "app.yaml"))
+	if err != nil {
+		t.Fatalf("lintDocument() error = %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("expected no findings outside the configured manifests, got %+v", results)
+	}
+}
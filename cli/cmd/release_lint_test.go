@@ -154,3 +154,12 @@ func TestTarYAMLDir_ProducesValidTar(t *testing.T) {
 	sort.Strings(expected)
 	assert.Equal(t, expected, names)
 }
+
+func TestReleaseLintV1_RejectsLocalOnlyOutputFormats(t *testing.T) {
+	for _, format := range []string{"junit", "sarif"} {
+		r := &runners{outputFormat: format, args: runnerArgs{lintReleaseYamlDir: t.TempDir()}}
+		err := r.releaseLintV1(nil, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "only supported by local lint")
+	}
+}
@@ -33,10 +33,15 @@ var (
 	appSlugOrID     string
 	apiToken        string
 	profileNameFlag string
+	envNameFlag     string
 	platformOrigin  = "https://api.replicated.com/vendor"
 	kurlDotSHOrigin = "https://kurl.sh"
 	cache           *replicatedcache.Cache
 	debugFlag       bool
+
+	// credentialsProfile is the profile the API token was loaded from, once
+	// preRunSetupAPIs has loaded it
+	credentialsProfile string
 )
 
 func init() {
@@ -68,6 +73,7 @@ func GetRootCmd() *cobra.Command {
 	rootCmd.PersistentFlags().StringVar(&appSlugOrID, "app", "", "The app slug or app id to use in all calls")
 	rootCmd.PersistentFlags().StringVar(&apiToken, "token", "", "The API token to use to access your app in the Vendor API")
 	rootCmd.PersistentFlags().StringVar(&profileNameFlag, "profile", "", "The authentication profile to use for this command")
+	rootCmd.PersistentFlags().StringVar(&envNameFlag, "env", "", "The environment from the .replicated config to use (defaults to the one matching the authentication profile)")
 	rootCmd.PersistentFlags().BoolVar(&debugFlag, "debug", false, "Enable debug output")
 
 	return rootCmd
@@ -101,17 +107,52 @@ Use "{{.CommandPath}} [command] --help" for more information about a command.{{e
 `
 
 // resolveAppFromConfig looks for a .replicated config file in the current directory
-// and walks up the tree to find an app slug or app id.
-func resolveAppFromConfig() string {
-	parser := tools.NewConfigParser()
-	config, err := parser.FindAndParseConfig(".")
+// and walks up the tree to find an app slug or app id. It only fails when the
+// environment selected with --env is not defined; a config that can't be read is
+// ignored, as the commands that need it report the problem themselves.
+func resolveAppFromConfig() (string, error) {
+	config, err := findAndParseEnvironmentConfig(".")
 	if err != nil {
-		return ""
+		var envErr *tools.EnvironmentNotDefinedError
+		if envNameFlag != "" && errors.As(err, &envErr) {
+			return "", err
+		}
+		if debugFlag {
+			fmt.Fprintf(os.Stderr, "[DEBUG] Ignoring .replicated config for app resolution: %v\n", err)
+		}
+		return "", nil
 	}
 	if config.AppSlug != "" {
-		return config.AppSlug
+		return config.AppSlug, nil
+	}
+	return config.AppId, nil
+}
+
+// findAndParseEnvironmentConfig finds and parses the .replicated config for startPath
+// and applies the environment selected with --env, or by the active profile.
+func findAndParseEnvironmentConfig(startPath string, opts ...tools.ConfigParserOption) (*tools.Config, error) {
+	parser := tools.NewConfigParser(opts...)
+	return parser.FindAndParseEnvironmentConfig(startPath, envNameFlag, activeProfileName())
+}
+
+// activeProfileName returns the authentication profile credentials are read from:
+// the --profile flag, else the default profile. There is none when an API token is
+// given with --token or REPLICATED_API_TOKEN.
+func activeProfileName() string {
+	if credentialsProfile != "" {
+		return credentialsProfile
+	}
+	if apiToken != "" || os.Getenv("REPLICATED_API_TOKEN") != "" {
+		return ""
+	}
+	if profileNameFlag != "" {
+		return profileNameFlag
+	}
+	defaultProfileName, err := credentials.GetDefaultProfile()
+	if err != nil {
+		return ""
 	}
-	return config.AppId
+	return defaultProfileName
 }
 
 // resolveAppSlugOrID returns the app slug or ID to use, following the precedence:
@@ -119,24 +160,29 @@ func resolveAppFromConfig() string {
 // 2. REPLICATED_APP environment variable
 // 3. .replicated file in cwd (or parent directories)
 // 4. Cached default app
-func resolveAppSlugOrID(flagValue string) string {
+// It fails when --env names an environment the .replicated file does not define.
+func resolveAppSlugOrID(flagValue string) (string, error) {
 	if flagValue != "" {
-		return flagValue
+		return flagValue, nil
 	}
 
 	if v := os.Getenv("REPLICATED_APP"); v != "" {
-		return v
+		return v, nil
 	}
 
-	if v := resolveAppFromConfig(); v != "" {
-		return v
+	v, err := resolveAppFromConfig()
+	if err != nil {
+		return "", err
+	}
+	if v != "" {
+		return v, nil
 	}
 
 	if cache.DefaultApp != "" {
-		return cache.DefaultApp
+		return cache.DefaultApp, nil
 	}
 
-	return ""
+	return "", nil
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -221,7 +267,10 @@ func Execute(rootCmd *cobra.Command, stdin io.Reader, stdout io.Writer, stderr i
 	runCmds.IniReleaseList(releaseCmd)
 	runCmds.InitReleaseUpdate(releaseCmd)
 	runCmds.InitReleasePromote(releaseCmd)
-	runCmds.InitReleaseLint(releaseCmd)
+	releaseLintCmd := runCmds.InitReleaseLint(releaseCmd)
+	releaseLintCacheCmd := runCmds.InitReleaseLintCache(releaseLintCmd)
+	runCmds.InitReleaseLintCacheClear(releaseLintCacheCmd)
+	runCmds.InitReleaseLintLSP(releaseLintCmd)
 	runCmds.InitReleaseTest(releaseCmd)
 	runCmds.InitReleaseCompatibility(releaseCmd)
 	runCmds.InitReleaseImageLS(releaseCmd)
@@ -364,6 +413,14 @@ func Execute(rootCmd *cobra.Command, stdin io.Reader, stdout io.Writer, stderr i
 	runCmds.InitProfileSetDefaultCommand(profileCmd)
 	runCmds.InitProfileUseCommand(profileCmd)
 
+	toolsCmd := runCmds.InitToolsCommand(runCmds.rootCmd)
+	runCmds.InitToolsBundle(toolsCmd)
+	runCmds.InitToolsImport(toolsCmd)
+	runCmds.InitToolsInstall(toolsCmd)
+	runCmds.InitToolsLs(toolsCmd)
+	runCmds.InitToolsPrune(toolsCmd)
+	runCmds.InitToolsWhich(toolsCmd)
+
 	apiCmd := runCmds.InitAPICommand(runCmds.rootCmd)
 	runCmds.InitAPIGet(apiCmd)
 	runCmds.InitAPIPost(apiCmd)
@@ -402,6 +459,9 @@ func Execute(rootCmd *cobra.Command, stdin io.Reader, stdout io.Writer, stderr i
 			}
 
 			apiToken = creds.APIToken
+			if creds.IsProfile {
+				credentialsProfile = profileName
+			}
 
 			if debugFlag {
 				maskedToken := apiToken
@@ -507,7 +567,11 @@ func Execute(rootCmd *cobra.Command, stdin io.Reader, stdout io.Writer, stderr i
 				appSlugOrID = cache.DefaultApp
 			}
 		} else {
-			appSlugOrID = resolveAppSlugOrID(appSlugOrID)
+			resolved, err := resolveAppSlugOrID(appSlugOrID)
+			if err != nil {
+				return err
+			}
+			appSlugOrID = resolved
 		}
 
 		// attempt to load the app from cache
@@ -599,6 +663,9 @@ func Execute(rootCmd *cobra.Command, stdin io.Reader, stdout io.Writer, stderr i
 	// Add config command with init subcommand
 	configCmd := runCmds.InitConfigCommand(runCmds.rootCmd)
 	runCmds.InitInitCommand(configCmd)
+	runCmds.InitConfigValidate(configCmd)
+	runCmds.InitConfigSchema(configCmd)
+	runCmds.InitConfigShow(configCmd)
 	configCmd.PersistentPreRunE = preRunSetupAPIs
 
 	runCmds.rootCmd.AddCommand(runCmds.Version())
@@ -63,7 +63,10 @@ func TestResolveAppSlugOrID(t *testing.T) {
 			os.Chdir(tmpDir)
 			defer os.Chdir(origWd)
 
-			result := resolveAppSlugOrID(tt.flagValue)
+			result, err := resolveAppSlugOrID(tt.flagValue)
+			if err != nil {
+				t.Fatalf("resolveAppSlugOrID() error = %v", err)
+			}
 			if result != tt.expectedResult {
 				t.Errorf("resolveAppSlugOrID() = %q, want %q", result, tt.expectedResult)
 			}
@@ -91,7 +94,10 @@ func TestResolveAppSlugOrID_ReplicatedFilePrecedence(t *testing.T) {
 		os.Chdir(tmpDir)
 		defer os.Chdir(origWd)
 
-		result := resolveAppSlugOrID("")
+		result, err := resolveAppSlugOrID("")
+		if err != nil {
+			t.Fatalf("resolveAppSlugOrID() error = %v", err)
+		}
 		if result != "file-app" {
 			t.Errorf("resolveAppSlugOrID() = %q, want %q", result, "file-app")
 		}
@@ -112,7 +118,10 @@ func TestResolveAppSlugOrID_ReplicatedFilePrecedence(t *testing.T) {
 		os.Chdir(tmpDir)
 		defer os.Chdir(origWd)
 
-		result := resolveAppSlugOrID("")
+		result, err := resolveAppSlugOrID("")
+		if err != nil {
+			t.Fatalf("resolveAppSlugOrID() error = %v", err)
+		}
 		if result != "env-app" {
 			t.Errorf("resolveAppSlugOrID() = %q, want %q", result, "env-app")
 		}
@@ -131,7 +140,10 @@ func TestResolveAppFromConfig(t *testing.T) {
 		os.Chdir(tmpDir)
 		defer os.Chdir(origWd)
 
-		result := resolveAppFromConfig()
+		result, err := resolveAppFromConfig()
+		if err != nil {
+			t.Fatalf("resolveAppFromConfig() error = %v", err)
+		}
 		if result != "my-app" {
 			t.Errorf("resolveAppFromConfig() = %q, want %q", result, "my-app")
 		}
@@ -148,7 +160,10 @@ func TestResolveAppFromConfig(t *testing.T) {
 		os.Chdir(tmpDir)
 		defer os.Chdir(origWd)
 
-		result := resolveAppFromConfig()
+		result, err := resolveAppFromConfig()
+		if err != nil {
+			t.Fatalf("resolveAppFromConfig() error = %v", err)
+		}
 		if result != "yaml-app" {
 			t.Errorf("resolveAppFromConfig() = %q, want %q", result, "yaml-app")
 		}
@@ -171,7 +186,10 @@ func TestResolveAppFromConfig(t *testing.T) {
 		os.Chdir(childDir)
 		defer os.Chdir(origWd)
 
-		result := resolveAppFromConfig()
+		result, err := resolveAppFromConfig()
+		if err != nil {
+			t.Fatalf("resolveAppFromConfig() error = %v", err)
+		}
 		if result != "parent-app" {
 			t.Errorf("resolveAppFromConfig() = %q, want %q", result, "parent-app")
 		}
@@ -184,7 +202,10 @@ func TestResolveAppFromConfig(t *testing.T) {
 		os.Chdir(tmpDir)
 		defer os.Chdir(origWd)
 
-		result := resolveAppFromConfig()
+		result, err := resolveAppFromConfig()
+		if err != nil {
+			t.Fatalf("resolveAppFromConfig() error = %v", err)
+		}
 		if result != "" {
 			t.Errorf("resolveAppFromConfig() = %q, want empty string", result)
 		}
@@ -201,7 +222,10 @@ func TestResolveAppFromConfig(t *testing.T) {
 		os.Chdir(tmpDir)
 		defer os.Chdir(origWd)
 
-		result := resolveAppFromConfig()
+		result, err := resolveAppFromConfig()
+		if err != nil {
+			t.Fatalf("resolveAppFromConfig() error = %v", err)
+		}
 		if result != "slug-app" {
 			t.Errorf("resolveAppFromConfig() = %q, want %q", result, "slug-app")
 		}
@@ -218,9 +242,64 @@ func TestResolveAppFromConfig(t *testing.T) {
 		os.Chdir(tmpDir)
 		defer os.Chdir(origWd)
 
-		result := resolveAppFromConfig()
+		result, err := resolveAppFromConfig()
+		if err != nil {
+			t.Fatalf("resolveAppFromConfig() error = %v", err)
+		}
 		if result != "id-app" {
 			t.Errorf("resolveAppFromConfig() = %q, want %q", result, "id-app")
 		}
 	})
+	t.Run("fails when --env names an undefined environment", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		configPath := filepath.Join(tmpDir, ".replicated")
+		config := "appSlug: my-app\nenvironments:\n  staging:\n    appSlug: staging-app\n"
+		if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		origWd, _ := os.Getwd()
+		os.Chdir(tmpDir)
+		defer os.Chdir(origWd)
+
+		originalEnv := envNameFlag
+		defer func() { envNameFlag = originalEnv }()
+		envNameFlag = "prod"
+
+		if result, err := resolveAppFromConfig(); err == nil {
+			t.Errorf("resolveAppFromConfig() = %q, want an error for an undefined environment", result)
+		}
+		if result, err := resolveAppSlugOrID(""); err == nil {
+			t.Errorf("resolveAppSlugOrID() = %q, want an error for an undefined environment", result)
+		}
+
+		envNameFlag = "staging"
+		result, err := resolveAppFromConfig()
+		if err != nil {
+			t.Fatalf("resolveAppFromConfig() error = %v", err)
+		}
+		if result != "staging-app" {
+			t.Errorf("resolveAppFromConfig() = %q, want %q", result, "staging-app")
+		}
+	})
+
+	t.Run("ignores a config that can't be read", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		configPath := filepath.Join(tmpDir, ".replicated")
+		if err := os.WriteFile(configPath, []byte("appSlug: [unclosed\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		origWd, _ := os.Getwd()
+		os.Chdir(tmpDir)
+		defer os.Chdir(origWd)
+
+		result, err := resolveAppFromConfig()
+		if err != nil {
+			t.Fatalf("resolveAppFromConfig() error = %v", err)
+		}
+		if result != "" {
+			t.Errorf("resolveAppFromConfig() = %q, want empty string", result)
+		}
+	})
 }
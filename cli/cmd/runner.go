@@ -8,6 +8,7 @@ import (
 
 	"github.com/replicatedhq/replicated/client"
 	"github.com/replicatedhq/replicated/pkg/kotsclient"
+	"github.com/replicatedhq/replicated/pkg/lint2"
 	"github.com/replicatedhq/replicated/pkg/platformclient"
 	"github.com/spf13/cobra"
 	"helm.sh/helm/v3/pkg/cli/values"
@@ -29,6 +30,17 @@ type runners struct {
 
 	rootCmd *cobra.Command
 	args    runnerArgs
+
+	// lintBaseline suppresses known findings during local lint (nil when no baseline is in use)
+	lintBaseline *lintBaselineFilter
+	// linterOptions holds each linter's ignore rules and strict setting, keyed by linter name
+	linterOptions map[string][]lint2.LintOption
+	// lintCache reuses results for unchanged resources during local lint (nil with --no-cache)
+	lintCache *lintResultCache
+	// lintChanged limits local lint to resources changed since --changed-since (nil otherwise)
+	lintChanged *lint2.ChangedFiles
+	// lintWatcher watches linted resources for changes with --watch (nil otherwise)
+	lintWatcher *lintWatcher
 }
 
 func (r *runners) hasApp() bool {
@@ -77,10 +89,19 @@ type runnerArgs struct {
 	createReleasePromoteEnsureChannel bool
 	// Add Create Release Lint
 	createReleaseLint                  bool
+	createReleaseLintOutput            string
 	lintReleaseYamlDir                 string
 	lintReleaseChart                   string
 	lintReleaseFailOn                  string
 	lintVerbose                        bool
+	lintWriteBaseline                  string
+	lintParallel                       int
+	lintNoCache                        bool
+	lintChangedSince                   string
+	lintWatch                          bool
+	lintFix                            bool
+	lintFixDryRun                      bool
+	lintFixOCILayout                   string
 	releaseOptional                    bool
 	releaseRequired                    bool
 	releaseNotes                       string
@@ -318,4 +339,17 @@ type runnerArgs struct {
 	profileEditAPIOrigin      string
 	profileEditRegistryOrigin string
 	profileEditNamespace      string
+
+	// Tools bundle
+	toolsBundleOutput       string
+	toolsBundleOS           string
+	toolsBundleArch         string
+	toolsBundleTools        []string
+	toolsBundleKubeVersions []string
+	toolsPruneKeepLatest    int
+	toolsPruneDryRun        bool
+	toolsPruneForce         bool
+
+	// Config show
+	configShowResolved bool
 }
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func (r *runners) InitToolsCommand(parent *cobra.Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tools",
+		Short: "Manage the linter tools used by local lint",
+		Long: `Manage the helm, preflight, support-bundle and embedded-cluster binaries and the Kubernetes schemas that local lint downloads to ~/.replicated/tools.
+
+Use "tools install" to download tools ahead of time, "tools ls" and "tools which" to inspect the cache, and "tools prune" to remove old versions. Installs and prunes lock the cache directory, so concurrent jobs can share it.
+
+In environments without internet access, create a tools bundle on a connected machine with "tools bundle", then install it with "tools import" or point REPLICATED_TOOLS_BUNDLE at it.`,
+		Example: `# Install the tools .replicated uses
+replicated tools install
+
+# Remove all but the newest version of each tool
+replicated tools prune --keep-latest 1
+
+# Bundle the tool versions from .replicated for linux/amd64
+replicated tools bundle --output tools.tgz --os linux --arch amd64
+
+# Install the tools from a bundle
+replicated tools import tools.tgz`,
+	}
+	parent.AddCommand(cmd)
+
+	return cmd
+}
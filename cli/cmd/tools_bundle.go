@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/replicated/pkg/lint2"
+	"github.com/replicatedhq/replicated/pkg/tools"
+	"github.com/spf13/cobra"
+)
+
+func (r *runners) InitToolsBundle(parent *cobra.Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Package linter tools for use without internet access",
+		Long: `Download the linter tools local lint uses for a platform, verify their checksums and package them with a manifest of their SHA-256 checksums into a single archive.
+
+By default the bundle holds helm, preflight and support-bundle at the versions in the repl-lint.tools section of .replicated ("latest" is resolved to the current stable version). When the embedded-cluster linter is enabled, the embedded-cluster version declared in the manifests is added, and when the kube-schema linter is enabled, the schemas for its Kubernetes versions are added. Use --tool and --kube-version to choose the contents instead.
+
+Install the bundle on the offline machine with "replicated tools import" or by setting REPLICATED_TOOLS_BUNDLE to its path.`,
+		Example: `# Bundle the tools from .replicated for this machine
+replicated tools bundle --output tools.tgz
+
+# Bundle for a linux/arm64 build farm
+replicated tools bundle --output tools.tgz --os linux --arch arm64
+
+# Bundle specific tool versions and Kubernetes schemas
+replicated tools bundle --output tools.tgz --tool helm@3.14.4 --tool preflight@0.123.9 --kube-version 1.31`,
+		Args:         cobra.NoArgs,
+		RunE:         r.toolsBundle,
+		SilenceUsage: true,
+	}
+	parent.AddCommand(cmd)
+
+	cmd.Flags().StringVarP(&r.args.toolsBundleOutput, "output", "o", "", "Path to write the bundle to")
+	cmd.Flags().StringVar(&r.args.toolsBundleOS, "os", runtime.GOOS, "Operating system to bundle the tools for")
+	cmd.Flags().StringVar(&r.args.toolsBundleArch, "arch", runtime.GOARCH, "Architecture to bundle the tools for")
+	cmd.Flags().StringArrayVar(&r.args.toolsBundleTools, "tool", nil, "Tool to bundle as name or name@version, e.g. helm@3.14.4 (can be repeated; replaces the tools from .replicated)")
+	cmd.Flags().StringArrayVar(&r.args.toolsBundleKubeVersions, "kube-version", nil, "Kubernetes minor version whose schema to bundle, e.g. 1.31 (can be repeated; replaces the versions from .replicated)")
+	cmd.MarkFlagRequired("output")
+
+	return cmd
+}
+
+func (r *runners) toolsBundle(cmd *cobra.Command, args []string) error {
+	opts := tools.BundleOptions{
+		Platform: tools.Platform{OS: r.args.toolsBundleOS, Arch: r.args.toolsBundleArch},
+	}
+
+	config, err := tools.NewConfigParser().FindAndParseConfig(".")
+	if err != nil {
+		return errors.Wrap(err, "failed to load .replicated config")
+	}
+	opts.Tools, err = bundleToolVersions(config, r.args.toolsBundleTools)
+	if err != nil {
+		return err
+	}
+	opts.KubeSchemaVersions = r.args.toolsBundleKubeVersions
+	if len(opts.KubeSchemaVersions) == 0 && config.ReplLint.Linters.KubeSchema.IsEnabled() {
+		opts.KubeSchemaVersions = config.ReplLint.Linters.KubeSchema.Versions
+	}
+
+	// Write next to the output and rename, so a failed bundle never replaces a good one
+	tmp, err := os.CreateTemp(filepath.Dir(r.args.toolsBundleOutput), ".tools-bundle-*")
+	if err != nil {
+		return errors.Wrap(err, "failed to create bundle")
+	}
+	defer os.Remove(tmp.Name())
+
+	manifest, err := tools.NewDownloader(tools.WithDownloadMirrors(config.ReplLint.ToolsMirror)).CreateBundle(cmd.Context(), tmp, opts)
+	if err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "failed to create bundle")
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "failed to write bundle")
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return errors.Wrap(err, "failed to write bundle")
+	}
+	if err := os.Rename(tmp.Name(), r.args.toolsBundleOutput); err != nil {
+		return errors.Wrap(err, "failed to write bundle")
+	}
+
+	fmt.Fprintf(r.w, "Wrote %s for %s:\n", r.args.toolsBundleOutput, manifest.Platform)
+	printBundleManifest(r, manifest)
+	return r.w.Flush()
+}
+
+// bundleToolVersions returns the tools to bundle and their versions: the --tool flags
+// when given, else the tools local lint would use with config
+func bundleToolVersions(config *tools.Config, toolFlags []string) (map[string]string, error) {
+	versions := make(map[string]string)
+
+	if len(toolFlags) > 0 {
+		for _, flag := range toolFlags {
+			name, version, _ := strings.Cut(flag, "@")
+			switch name {
+			case tools.ToolHelm, tools.ToolPreflight, tools.ToolSupportBundle:
+			case tools.ToolEmbeddedCluster:
+				if version == "" || version == "latest" {
+					return nil, errors.Errorf("--tool %s requires an explicit version", name)
+				}
+			default:
+				return nil, errors.Errorf("unknown tool %q in --tool, must be one of %s, %s, %s or %s", name, tools.ToolHelm, tools.ToolPreflight, tools.ToolSupportBundle, tools.ToolEmbeddedCluster)
+			}
+			if version == "" {
+				version = "latest"
+			}
+			versions[name] = version
+		}
+		return versions, nil
+	}
+
+	configured := tools.GetToolVersions(config)
+	for _, name := range []string{tools.ToolHelm, tools.ToolPreflight, tools.ToolSupportBundle} {
+		versions[name] = "latest"
+		if v := configured[name]; v != "" {
+			versions[name] = v
+		}
+	}
+
+	ecConfig := config.ReplLint.Linters.EmbeddedCluster
+	if ecConfig.IsEnabled() && ecConfig.BinaryPath == "" {
+		manifestPaths, err := lint2.ExpandManifestGlobs(config.Manifests)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to expand manifest globs")
+		}
+		ecVersion, err := lint2.DiscoverECVersion(manifestPaths)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to discover the embedded-cluster version; pass it with --tool embedded-cluster@<version>")
+		}
+		versions[tools.ToolEmbeddedCluster] = ecVersion
+	}
+
+	return versions, nil
+}
+
+func printBundleManifest(r *runners, manifest *tools.BundleManifest) {
+	for _, entry := range manifest.Tools {
+		if entry.Name == tools.KubeSchemaDir {
+			fmt.Fprintf(r.w, "  Kubernetes %s schema\tsha256:%s\n", entry.Version, entry.SHA256)
+			continue
+		}
+		fmt.Fprintf(r.w, "  %s %s\tsha256:%s\n", entry.Name, entry.Version, entry.SHA256)
+	}
+}
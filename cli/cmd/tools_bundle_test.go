@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/replicatedhq/replicated/pkg/tools"
+)
+
+func TestBundleToolVersions(t *testing.T) {
+	dir := t.TempDir()
+	manifest := filepath.Join(dir, "embedded-cluster.yaml")
+	writeTestFile(t, manifest, "apiVersion: embeddedcluster.replicated.com/v1beta1\nkind: Config\nspec:\n  version: 2.1.3+k8s-1.30\n")
+
+	parser := tools.NewConfigParser()
+	config := parser.DefaultConfig()
+	config.ReplLint.Tools = map[string]string{tools.ToolHelm: "3.14.4"}
+
+	got, err := bundleToolVersions(config, nil)
+	if err != nil {
+		t.Fatalf("bundleToolVersions() error = %v", err)
+	}
+	want := map[string]string{tools.ToolHelm: "3.14.4", tools.ToolPreflight: "latest", tools.ToolSupportBundle: "latest"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("bundleToolVersions() = %v, want %v", got, want)
+	}
+
+	// The embedded-cluster version comes from the manifests when its linter is enabled
+	enabled := false
+	config.ReplLint.Linters.EmbeddedCluster.Disabled = &enabled
+	config.Manifests = []string{manifest}
+	got, err = bundleToolVersions(config, nil)
+	if err != nil {
+		t.Fatalf("bundleToolVersions() error = %v", err)
+	}
+	if got[tools.ToolEmbeddedCluster] != "2.1.3" {
+		t.Errorf("expected the embedded-cluster version from the manifest, got %v", got)
+	}
+
+	// --tool replaces the tools from the config
+	got, err = bundleToolVersions(config, []string{"helm@3.15.0", "preflight"})
+	if err != nil {
+		t.Fatalf("bundleToolVersions() error = %v", err)
+	}
+	want = map[string]string{tools.ToolHelm: "3.15.0", tools.ToolPreflight: "latest"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("bundleToolVersions() = %v, want %v", got, want)
+	}
+
+	for _, flag := range []string{"kubectl", "embedded-cluster"} {
+		if _, err := bundleToolVersions(config, []string{flag}); err == nil || !strings.Contains(err.Error(), "--tool") {
+			t.Errorf("expected --tool %s to be rejected, got %v", flag, err)
+		}
+	}
+}
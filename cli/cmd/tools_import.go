@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/replicated/pkg/tools"
+	"github.com/spf13/cobra"
+)
+
+func (r *runners) InitToolsImport(parent *cobra.Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import BUNDLE",
+		Short: "Install linter tools from a tools bundle",
+		Long: `Install the tools and Kubernetes schemas in a bundle created by "replicated tools bundle" into ~/.replicated/tools, verifying each against the bundle's checksums. The bundle must be for this machine's OS and architecture.
+
+After importing, local lint uses the bundled versions wherever a tool version is "latest" when REPLICATED_TOOLS_OFFLINE is true or replicated.app cannot be reached. "replicated tools prune --force" forgets the import. To use a bundle without importing it, set REPLICATED_TOOLS_BUNDLE to its path.`,
+		Example: `# Install the tools from a bundle
+replicated tools import tools.tgz`,
+		Args:         cobra.ExactArgs(1),
+		RunE:         r.toolsImport,
+		SilenceUsage: true,
+	}
+	parent.AddCommand(cmd)
+
+	return cmd
+}
+
+func (r *runners) toolsImport(cmd *cobra.Command, args []string) error {
+	manifest, err := tools.ImportBundle(args[0])
+	if err != nil {
+		return errors.Wrap(err, "failed to import tools bundle")
+	}
+
+	fmt.Fprintf(r.w, "Imported %s for %s:\n", args[0], manifest.Platform)
+	printBundleManifest(r, manifest)
+	return r.w.Flush()
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/replicated/pkg/tools"
+	"github.com/spf13/cobra"
+)
+
+func (r *runners) InitToolsInstall(parent *cobra.Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "install [TOOL[@VERSION]...]",
+		Short: "Download linter tools to the local cache",
+		Long: `Download tools to ~/.replicated/tools ahead of time, for example to warm a cache shared by CI jobs.
+
+Each argument is a tool name (helm, preflight, support-bundle, embedded-cluster or kube-schema) with an optional version; without a version, the latest stable version is installed. embedded-cluster and kube-schema need a version. With no arguments, the tools local lint uses with the .replicated config are installed.
+
+Tools are verified against their checksums. Mirrors configured in repl-lint.tools-mirror and REPLICATED_TOOLS_BUNDLE are honored.`,
+		Example: `# Install the tools .replicated uses
+replicated tools install
+
+# Install specific versions
+replicated tools install helm@3.14.0 preflight@0.123.9
+
+# Install the Kubernetes 1.31 schema
+replicated tools install kube-schema@1.31`,
+		RunE:         r.toolsInstall,
+		SilenceUsage: true,
+	}
+	parent.AddCommand(cmd)
+
+	return cmd
+}
+
+func (r *runners) toolsInstall(cmd *cobra.Command, args []string) error {
+	config, err := tools.NewConfigParser().FindAndParseConfig(".")
+	if err != nil {
+		return errors.Wrap(err, "failed to load .replicated config")
+	}
+
+	type toolVersion struct{ name, version string }
+	var toInstall []toolVersion
+	if len(args) > 0 {
+		for _, arg := range args {
+			name, version, err := parseToolArg(arg)
+			if err != nil {
+				return err
+			}
+			toInstall = append(toInstall, toolVersion{name, version})
+		}
+	} else {
+		versions, err := bundleToolVersions(config, nil)
+		if err != nil {
+			return err
+		}
+		for name, version := range versions {
+			toInstall = append(toInstall, toolVersion{name, version})
+		}
+		sort.Slice(toInstall, func(i, j int) bool { return toInstall[i].name < toInstall[j].name })
+		if config.ReplLint.Linters.KubeSchema.IsEnabled() {
+			for _, version := range config.ReplLint.Linters.KubeSchema.Versions {
+				toInstall = append(toInstall, toolVersion{tools.KubeSchemaDir, version})
+			}
+		}
+	}
+
+	resolver := tools.NewResolver(tools.WithMirrors(config.ReplLint.ToolsMirror))
+	for _, tool := range toInstall {
+		var path string
+		if tool.name == tools.KubeSchemaDir {
+			path, err = resolver.InstallKubeSchema(cmd.Context(), tool.version)
+		} else {
+			if tool.version == "latest" {
+				tool.version, err = resolver.ResolveLatestVersion(cmd.Context(), tool.name)
+				if err != nil {
+					return err
+				}
+			}
+			path, err = resolver.Install(cmd.Context(), tool.name, tool.version)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to install %s", toolDisplayName(tool.name, tool.version, ""))
+		}
+		fmt.Fprintf(r.w, "Installed %s\t%s\n", toolDisplayName(tool.name, tool.version, ""), path)
+	}
+
+	return r.w.Flush()
+}
+
+// parseToolArg parses a TOOL[@VERSION] argument. The version defaults to latest,
+// except for embedded-cluster and kube-schema, which have no latest version.
+func parseToolArg(arg string) (string, string, error) {
+	name, version, _ := strings.Cut(arg, "@")
+	switch name {
+	case tools.ToolHelm, tools.ToolPreflight, tools.ToolSupportBundle:
+		if version == "" {
+			version = "latest"
+		}
+	case tools.ToolEmbeddedCluster, tools.KubeSchemaDir:
+		if version == "" || version == "latest" {
+			return "", "", errors.Errorf("%s requires an explicit version, e.g. %s@<version>", name, name)
+		}
+	default:
+		return "", "", errors.Errorf("unknown tool %q: must be one of %s", name, strings.Join(tools.MirrorTools, ", "))
+	}
+	return name, version, nil
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func TestParseToolArg(t *testing.T) {
+	tests := []struct {
+		arg         string
+		wantName    string
+		wantVersion string
+		wantErr     string
+	}{
+		{arg: "helm", wantName: "helm", wantVersion: "latest"},
+		{arg: "helm@3.14.0", wantName: "helm", wantVersion: "3.14.0"},
+		{arg: "preflight@latest", wantName: "preflight", wantVersion: "latest"},
+		{arg: "kube-schema@1.31", wantName: "kube-schema", wantVersion: "1.31"},
+		{arg: "embedded-cluster@2.1.3", wantName: "embedded-cluster", wantVersion: "2.1.3"},
+		{arg: "embedded-cluster", wantErr: "embedded-cluster requires an explicit version, e.g. embedded-cluster@<version>"},
+		{arg: "kube-schema@latest", wantErr: "kube-schema requires an explicit version, e.g. kube-schema@<version>"},
+		{arg: "kubectl@1.31", wantErr: `unknown tool "kubectl": must be one of helm, preflight, support-bundle, embedded-cluster, kube-schema`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			name, version, err := parseToolArg(tt.arg)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("parseToolArg() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseToolArg() error = %v", err)
+			}
+			if name != tt.wantName || version != tt.wantVersion {
+				t.Errorf("parseToolArg() = %q, %q, want %q, %q", name, version, tt.wantName, tt.wantVersion)
+			}
+		})
+	}
+}
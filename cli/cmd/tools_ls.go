@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/replicated/cli/print"
+	"github.com/replicatedhq/replicated/pkg/tools"
+	"github.com/spf13/cobra"
+)
+
+func (r *runners) InitToolsLs(parent *cobra.Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "List the linter tools in the local cache",
+		Long: `List the tool binaries and Kubernetes schemas downloaded to ~/.replicated/tools, newest version first.
+
+Cached lint results are not listed; use "replicated release lint cache clear" to remove them.`,
+		Example: `# List cached tools
+replicated tools ls
+
+# List cached tools as JSON
+replicated tools ls --output json`,
+		Args:         cobra.NoArgs,
+		RunE:         r.toolsLs,
+		SilenceUsage: true,
+	}
+	parent.AddCommand(cmd)
+
+	return cmd
+}
+
+func (r *runners) toolsLs(cmd *cobra.Command, args []string) error {
+	cached, err := tools.ListCachedTools()
+	if err != nil {
+		return errors.Wrap(err, "failed to list cached tools")
+	}
+
+	return print.CachedTools(r.outputFormat, r.w, cached)
+}
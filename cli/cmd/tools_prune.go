@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/replicated/pkg/tools"
+	"github.com/spf13/cobra"
+)
+
+func (r *runners) InitToolsPrune(parent *cobra.Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove old versions of linter tools from the local cache",
+		Long: `Remove all but the newest versions of each tool binary and of the Kubernetes schemas from ~/.replicated/tools. Versions are compared as semantic versions, separately for each platform.
+
+Versions pinned in repl-lint.tools of the .replicated config and those installed by the last "replicated tools import" are kept unless --force is set. With --force, the import is also forgotten, so "latest" no longer resolves to its versions offline.
+
+Removed tools are downloaded again the next time local lint needs them.`,
+		Example: `# Keep only the newest version of each tool
+replicated tools prune --keep-latest 1
+
+# Show what would be removed
+replicated tools prune --keep-latest 2 --dry-run
+
+# Also remove pinned and imported versions
+replicated tools prune --keep-latest 1 --force`,
+		Args:         cobra.NoArgs,
+		RunE:         r.toolsPrune,
+		SilenceUsage: true,
+	}
+	parent.AddCommand(cmd)
+
+	cmd.Flags().IntVar(&r.args.toolsPruneKeepLatest, "keep-latest", 1, "Number of versions of each tool to keep")
+	cmd.Flags().BoolVar(&r.args.toolsPruneDryRun, "dry-run", false, "Show what would be removed without removing anything")
+	cmd.Flags().BoolVar(&r.args.toolsPruneForce, "force", false, "Also remove versions pinned in the config or installed by a tools bundle import")
+
+	return cmd
+}
+
+func (r *runners) toolsPrune(cmd *cobra.Command, args []string) error {
+	if r.args.toolsPruneKeepLatest < 0 {
+		return errors.New("--keep-latest must not be negative")
+	}
+
+	config, err := tools.NewConfigParser().FindAndParseConfig(".")
+	if err != nil {
+		return errors.Wrap(err, "failed to load .replicated config")
+	}
+
+	removed, skipped, err := tools.PruneCachedTools(tools.PruneOptions{
+		KeepLatest: r.args.toolsPruneKeepLatest,
+		Pinned:     config.ReplLint.Tools,
+		Force:      r.args.toolsPruneForce,
+		DryRun:     r.args.toolsPruneDryRun,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to prune tools cache")
+	}
+
+	for _, tool := range skipped {
+		fmt.Fprintf(r.w, "Kept %s (pinned or imported; use --force to remove)\n", toolDisplayName(tool.Name, tool.Version, tool.Platform))
+	}
+
+	if len(removed) == 0 {
+		fmt.Fprintln(r.w, "Nothing to prune")
+		return r.w.Flush()
+	}
+
+	verb := "Removed"
+	if r.args.toolsPruneDryRun {
+		verb = "Would remove"
+	}
+	var size int64
+	for _, tool := range removed {
+		fmt.Fprintf(r.w, "%s %s\n", verb, toolDisplayName(tool.Name, tool.Version, tool.Platform))
+		size += tool.Size
+	}
+	fmt.Fprintf(r.w, "%s %d file(s), %d bytes\n", verb, len(removed), size)
+	return r.w.Flush()
+}
+
+// toolDisplayName describes a cached tool, e.g. "helm 3.14.4 (linux-amd64)"
+func toolDisplayName(name, version, platform string) string {
+	if name == tools.KubeSchemaDir {
+		return fmt.Sprintf("Kubernetes %s schema", version)
+	}
+	if platform == "" {
+		return fmt.Sprintf("%s %s", name, version)
+	}
+	return fmt.Sprintf("%s %s (%s)", name, version, platform)
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/replicated/pkg/tools"
+	"github.com/spf13/cobra"
+)
+
+func (r *runners) InitToolsWhich(parent *cobra.Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "which TOOL[@VERSION]",
+		Short: "Print the path of a cached linter tool",
+		Long: `Print the path of the cached binary local lint runs for a tool, or of a cached Kubernetes schema.
+
+Without a version, the tool version from the repl-lint.tools section of .replicated is used, and "latest" is resolved the same way local lint resolves it. Nothing is downloaded: the command fails if the tool is not cached.`,
+		Example: `# Print the path of the preflight binary local lint uses
+replicated tools which preflight
+
+# Print the path of a specific helm version
+replicated tools which helm@3.14.4`,
+		Args:         cobra.ExactArgs(1),
+		RunE:         r.toolsWhich,
+		SilenceUsage: true,
+	}
+	parent.AddCommand(cmd)
+
+	return cmd
+}
+
+func (r *runners) toolsWhich(cmd *cobra.Command, args []string) error {
+	name, version, err := parseToolArg(args[0])
+	if err != nil {
+		return err
+	}
+
+	if version == "latest" {
+		config, err := tools.NewConfigParser().FindAndParseConfig(".")
+		if err != nil {
+			return errors.Wrap(err, "failed to load .replicated config")
+		}
+		if v := tools.GetToolVersions(config)[name]; v != "" {
+			version = v
+		}
+		if version == "latest" {
+			version, err = tools.NewResolver().ResolveLatestVersion(cmd.Context(), name)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	var path string
+	if name == tools.KubeSchemaDir {
+		path, err = tools.GetKubeSchemaPath(version)
+	} else {
+		path, err = tools.GetToolPath(name, version)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to get cache path")
+	}
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		return errors.Errorf("%s is not installed; install it with: replicated tools install %s@%s", toolDisplayName(name, version, ""), name, version)
+	}
+
+	fmt.Fprintln(r.w, path)
+	return r.w.Flush()
+}
@@ -0,0 +1,111 @@
+package print
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/replicatedhq/replicated/pkg/tools"
+)
+
+// configValidation is the JSON output of config validate
+type configValidation struct {
+	Files  []string            `json:"files"`
+	Valid  bool                `json:"valid"`
+	Issues []tools.ConfigIssue `json:"issues"`
+}
+
+func ConfigValidation(outputFormat string, w *tabwriter.Writer, files []string, issues []tools.ConfigIssue) error {
+	if outputFormat == "json" {
+		if issues == nil {
+			issues = []tools.ConfigIssue{}
+		}
+		cAsByte, _ := json.MarshalIndent(configValidation{Files: files, Valid: len(issues) == 0, Issues: issues}, "", "  ")
+		if _, err := fmt.Fprintln(w, string(cAsByte)); err != nil {
+			return err
+		}
+		return w.Flush()
+	}
+
+	for _, issue := range issues {
+		if _, err := fmt.Fprintln(w, issue.String()); err != nil {
+			return err
+		}
+	}
+	if len(issues) == 0 {
+		for _, file := range files {
+			if _, err := fmt.Fprintf(w, "%s is valid\n", file); err != nil {
+				return err
+			}
+		}
+	}
+
+	return w.Flush()
+}
+
+// configFile is the JSON output of config show
+type configFile struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+func ConfigFiles(outputFormat string, w *tabwriter.Writer, paths []string, contents [][]byte) error {
+	if outputFormat == "json" {
+		files := make([]configFile, 0, len(paths))
+		for i, path := range paths {
+			files = append(files, configFile{Path: path, Content: string(contents[i])})
+		}
+		cAsByte, _ := json.MarshalIndent(files, "", "  ")
+		if _, err := fmt.Fprintln(w, string(cAsByte)); err != nil {
+			return err
+		}
+		return w.Flush()
+	}
+
+	for i, path := range paths {
+		if i > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "# %s\n%s", path, strings.TrimRight(string(contents[i]), "\n")+"\n"); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// resolvedConfig is the JSON output of config show --resolved
+type resolvedConfig struct {
+	Files   []string          `json:"files"`
+	Config  map[string]any    `json:"config"`
+	Sources map[string]string `json:"sources"`
+}
+
+func ResolvedConfig(outputFormat string, w *tabwriter.Writer, config *tools.Config, provenance *tools.ConfigProvenance) error {
+	if outputFormat == "json" {
+		value, err := tools.ConfigValue(config)
+		if err != nil {
+			return err
+		}
+		files := provenance.Files
+		if files == nil {
+			files = []string{}
+		}
+		cAsByte, _ := json.MarshalIndent(resolvedConfig{Files: files, Config: value, Sources: provenance.Sources}, "", "  ")
+		if _, err := fmt.Fprintln(w, string(cAsByte)); err != nil {
+			return err
+		}
+		return w.Flush()
+	}
+
+	data, err := tools.ConfigYAML(config, provenance)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	return w.Flush()
+}
@@ -2,6 +2,7 @@ package print
 
 import (
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"text/tabwriter"
 
@@ -19,10 +20,14 @@ func LintResults(format string, w *tabwriter.Writer, output interface{}) error {
 		// Table format is handled by the display functions in lint.go
 		// This function is only called for non-table formats
 		return errors.New("table format should be handled by display functions")
-	case "json":
+	case "json", "sarif":
+		// SARIF logs are JSON documents; the caller passes the SARIF log as output
 		return printLintResultsJSON(w, output)
+	case "junit":
+		// The caller passes the JUnit report as output
+		return printLintResultsXML(w, output)
 	default:
-		return errors.Errorf("invalid format: %s. Supported formats: json, table", format)
+		return errors.Errorf("invalid format: %s. Supported formats: json, junit, sarif, table", format)
 	}
 }
 
@@ -46,3 +51,21 @@ func printLintResultsJSON(w *tabwriter.Writer, output interface{}) error {
 
 	return nil
 }
+
+// printLintResultsXML outputs lint results as an indented XML document
+func printLintResultsXML(w *tabwriter.Writer, output interface{}) error {
+	xmlBytes, err := xml.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal lint results to XML")
+	}
+
+	if _, err := fmt.Fprintf(w, "%s%s\n", xml.Header, xmlBytes); err != nil {
+		return errors.Wrap(err, "failed to write XML output")
+	}
+
+	if err := w.Flush(); err != nil {
+		return errors.Wrap(err, "failed to flush output")
+	}
+
+	return nil
+}
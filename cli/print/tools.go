@@ -0,0 +1,58 @@
+package print
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/replicatedhq/replicated/pkg/tools"
+)
+
+var cachedToolsTmplSrc = `TOOL	VERSION	PLATFORM	SIZE	MODIFIED	PATH
+{{ range . -}}
+{{ .Name }}	{{ .Version }}	{{ if .Platform }}{{ .Platform }}{{ else }}-{{ end }}	{{ size .Size }}	{{ localeTime .Modified }}	{{ .Path }}
+{{ end }}`
+
+var cachedToolsTmpl = template.Must(template.New("cachedTools").Funcs(funcs).Funcs(template.FuncMap{
+	"size": formatSize,
+}).Parse(cachedToolsTmplSrc))
+
+func CachedTools(outputFormat string, w *tabwriter.Writer, cached []tools.CachedTool) error {
+	if outputFormat == "json" {
+		if cached == nil {
+			cached = []tools.CachedTool{}
+		}
+		cAsByte, _ := json.MarshalIndent(cached, "", "  ")
+		if _, err := fmt.Fprintln(w, string(cAsByte)); err != nil {
+			return err
+		}
+		return w.Flush()
+	}
+
+	if len(cached) == 0 {
+		if _, err := fmt.Fprintln(w, "No tools cached"); err != nil {
+			return err
+		}
+		return w.Flush()
+	}
+	if err := cachedToolsTmpl.Execute(w, cached); err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
+
+// formatSize formats a number of bytes with a binary unit, e.g. 52.3 MiB
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,182 @@
+package lint2
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// BaselineVersion is the current version of the lint baseline file format
+const BaselineVersion = 1
+
+// Baseline is a set of known lint findings that are suppressed in later runs.
+// It is stored as JSON, typically in .replicated-lint-baseline.json.
+type Baseline struct {
+	Version  int             `json:"version"`
+	Findings []BaselineEntry `json:"findings"`
+}
+
+// BaselineEntry identifies a single known finding. Paths are relative to the
+// directory containing the baseline file so the file can be committed and used
+// from any checkout.
+type BaselineEntry struct {
+	Fingerprint string `json:"fingerprint"`
+	Linter      string `json:"linter"`
+	Path        string `json:"path,omitempty"`
+	Rule        string `json:"rule,omitempty"`
+	Message     string `json:"message"`
+}
+
+var (
+	// lineNumberPattern matches line numbers embedded in lint messages
+	lineNumberPattern = regexp.MustCompile(`\blines? \d+(:\d+)?\b`)
+	whitespacePattern = regexp.MustCompile(`\s+`)
+)
+
+// NormalizeLintMessage strips the parts of a lint message that change without
+// the finding itself changing (line numbers, whitespace), so fingerprints stay
+// stable when unrelated lines are added above a finding.
+func NormalizeLintMessage(message string) string {
+	message = lineNumberPattern.ReplaceAllString(message, "line N")
+	message = whitespacePattern.ReplaceAllString(message, " ")
+	return strings.TrimSpace(message)
+}
+
+// NewBaselineEntry creates a baseline entry for a finding. path must already be
+// relative to the baseline file's directory (see BaselineRelativePath).
+func NewBaselineEntry(linter, path, rule, message string) BaselineEntry {
+	entry := BaselineEntry{
+		Linter:  linter,
+		Path:    path,
+		Rule:    rule,
+		Message: NormalizeLintMessage(message),
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join([]string{entry.Linter, entry.Path, entry.Rule, entry.Message}, "\x00")))
+	entry.Fingerprint = hex.EncodeToString(sum[:8])
+
+	return entry
+}
+
+// BaselineRelativePath returns path relative to the directory containing
+// baselineFile, using forward slashes. Paths outside that directory are
+// returned as absolute slash-separated paths.
+func BaselineRelativePath(baselineFile, path string) string {
+	if path == "" {
+		return ""
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	absDir, err := filepath.Abs(filepath.Dir(baselineFile))
+	if err != nil {
+		return filepath.ToSlash(absPath)
+	}
+
+	rel, err := filepath.Rel(absDir, absPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(absPath)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// LoadBaseline reads a baseline file
+func LoadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline file %s: %w", path, err)
+	}
+
+	var baseline Baseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline file %s: %w", path, err)
+	}
+	if baseline.Version > BaselineVersion {
+		return nil, fmt.Errorf("baseline file %s has unsupported version %d (max %d)", path, baseline.Version, BaselineVersion)
+	}
+
+	return &baseline, nil
+}
+
+// WriteBaseline writes the given findings to a baseline file. Findings are sorted
+// so the file diffs cleanly between runs.
+func WriteBaseline(path string, findings []BaselineEntry) error {
+	sorted := make([]BaselineEntry, len(findings))
+	copy(sorted, findings)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.Linter != b.Linter {
+			return a.Linter < b.Linter
+		}
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		return a.Message < b.Message
+	})
+
+	data, err := json.MarshalIndent(Baseline{Version: BaselineVersion, Findings: sorted}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal baseline: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write baseline file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// BaselineMatcher matches findings from a lint run against a baseline. Each
+// baseline entry suppresses at most one finding, so a new occurrence of a known
+// message is still reported.
+type BaselineMatcher struct {
+	remaining map[string][]BaselineEntry
+}
+
+// NewBaselineMatcher creates a matcher for the findings in baseline
+func NewBaselineMatcher(baseline *Baseline) *BaselineMatcher {
+	m := &BaselineMatcher{remaining: make(map[string][]BaselineEntry)}
+	if baseline == nil {
+		return m
+	}
+	for _, entry := range baseline.Findings {
+		m.remaining[entry.Fingerprint] = append(m.remaining[entry.Fingerprint], entry)
+	}
+	return m
+}
+
+// Match reports whether entry is in the baseline, consuming the matching baseline entry
+func (m *BaselineMatcher) Match(entry BaselineEntry) bool {
+	entries := m.remaining[entry.Fingerprint]
+	if len(entries) == 0 {
+		return false
+	}
+	m.remaining[entry.Fingerprint] = entries[1:]
+	return true
+}
+
+// Stale returns the baseline entries that did not match any finding, sorted by path
+func (m *BaselineMatcher) Stale() []BaselineEntry {
+	var stale []BaselineEntry
+	for _, entries := range m.remaining {
+		stale = append(stale, entries...)
+	}
+	sort.Slice(stale, func(i, j int) bool {
+		if stale[i].Path != stale[j].Path {
+			return stale[i].Path < stale[j].Path
+		}
+		return stale[i].Fingerprint < stale[j].Fingerprint
+	})
+	return stale
+}
@@ -0,0 +1,109 @@
+package lint2
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestNormalizeLintMessage(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{"line 12: missing field", "line N: missing field"},
+		{"yaml: line 3:7: did not find expected key", "yaml: line N: did not find expected key"},
+		{"  icon   is\trecommended ", "icon is recommended"},
+		{"no line numbers here", "no line numbers here"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeLintMessage(tt.message); got != tt.want {
+			t.Errorf("NormalizeLintMessage(%q) = %q, want %q", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestNewBaselineEntry_FingerprintStableAcrossLineChanges(t *testing.T) {
+	a := NewBaselineEntry("helm", "chart/values.yaml", "", "line 10: bad value")
+	b := NewBaselineEntry("helm", "chart/values.yaml", "", "line 42: bad value")
+	if a.Fingerprint != b.Fingerprint {
+		t.Errorf("expected fingerprints to ignore line numbers, got %s and %s", a.Fingerprint, b.Fingerprint)
+	}
+
+	c := NewBaselineEntry("preflight", "chart/values.yaml", "", "line 10: bad value")
+	if a.Fingerprint == c.Fingerprint {
+		t.Error("expected different linters to produce different fingerprints")
+	}
+}
+
+func TestBaselineRelativePath(t *testing.T) {
+	dir := t.TempDir()
+	baselineFile := filepath.Join(dir, ".replicated-lint-baseline.json")
+
+	if got := BaselineRelativePath(baselineFile, filepath.Join(dir, "charts", "app", "Chart.yaml")); got != "charts/app/Chart.yaml" {
+		t.Errorf("expected path relative to baseline dir, got %q", got)
+	}
+
+	outside := filepath.Join(filepath.Dir(dir), "other", "file.yaml")
+	if got := BaselineRelativePath(baselineFile, outside); got != filepath.ToSlash(outside) {
+		t.Errorf("expected absolute path for files outside the baseline dir, got %q", got)
+	}
+}
+
+func TestBaseline_WriteAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	entries := []BaselineEntry{
+		NewBaselineEntry("preflight", "b.yaml", "", "warning b"),
+		NewBaselineEntry("helm", "a.yaml", "", "warning a"),
+	}
+
+	if err := WriteBaseline(path, entries); err != nil {
+		t.Fatalf("WriteBaseline() error = %v", err)
+	}
+
+	baseline, err := LoadBaseline(path)
+	if err != nil {
+		t.Fatalf("LoadBaseline() error = %v", err)
+	}
+	if baseline.Version != BaselineVersion {
+		t.Errorf("expected version %d, got %d", BaselineVersion, baseline.Version)
+	}
+	if len(baseline.Findings) != 2 || baseline.Findings[0].Path != "a.yaml" {
+		t.Errorf("expected findings sorted by path, got %+v", baseline.Findings)
+	}
+}
+
+func TestLoadBaseline_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := LoadBaseline(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("expected error for missing baseline file")
+	}
+
+	newer := writeTestFile(t, dir, "newer.json", `{"version": 99, "findings": []}`)
+	if _, err := LoadBaseline(newer); err == nil {
+		t.Error("expected error for unsupported baseline version")
+	}
+}
+
+func TestBaselineMatcher(t *testing.T) {
+	known := NewBaselineEntry("helm", "a.yaml", "", "warning a")
+	stale := NewBaselineEntry("helm", "gone.yaml", "", "fixed warning")
+	matcher := NewBaselineMatcher(&Baseline{Version: 1, Findings: []BaselineEntry{known, stale}})
+
+	if !matcher.Match(known) {
+		t.Error("expected baselined finding to match")
+	}
+	// Each baseline entry suppresses only one occurrence
+	if matcher.Match(known) {
+		t.Error("expected a second occurrence of a baselined finding to be reported")
+	}
+	if matcher.Match(NewBaselineEntry("helm", "a.yaml", "", "new warning")) {
+		t.Error("expected new finding not to match")
+	}
+
+	remaining := matcher.Stale()
+	if len(remaining) != 1 || remaining[0].Fingerprint != stale.Fingerprint {
+		t.Errorf("expected only the unmatched entry to be stale, got %+v", remaining)
+	}
+}
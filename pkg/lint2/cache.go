@@ -0,0 +1,209 @@
+package lint2
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/replicatedhq/replicated/pkg/tools"
+)
+
+// lintCacheVersion is bumped whenever the cached entry format or the way results
+// are produced changes, so stale entries are never reused
+const lintCacheVersion = 2
+
+// LintCacheDir returns the directory lint results are cached in.
+// Example: ~/.replicated/tools/lint-cache
+func LintCacheDir() (string, error) {
+	cacheDir, err := tools.GetCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "lint-cache"), nil
+}
+
+// LintCache stores lint results keyed by a hash of everything that affects them,
+// so unchanged resources are not linted again. It is safe for concurrent use.
+type LintCache struct {
+	dir string
+}
+
+// NewLintCache returns a cache that stores entries in dir
+func NewLintCache(dir string) *LintCache {
+	return &LintCache{dir: dir}
+}
+
+type lintCacheEntry struct {
+	Version int         `json:"version"`
+	Result  *LintResult `json:"result"`
+}
+
+func (c *LintCache) entryPath(key string) string {
+	// Shard by the first two characters to keep directories small
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+// Get returns the cached result for key. Missing, unreadable and outdated entries
+// are all treated as a miss.
+func (c *LintCache) Get(key string) (*LintResult, bool) {
+	data, err := os.ReadFile(c.entryPath(key))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry lintCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Version != lintCacheVersion || entry.Result == nil {
+		return nil, false
+	}
+	return entry.Result, true
+}
+
+// Put stores the result for key. The entry is written to a temp file and renamed
+// so concurrent readers never see a partial entry.
+func (c *LintCache) Put(key string, result *LintResult) error {
+	data, err := json.Marshal(lintCacheEntry{Version: lintCacheVersion, Result: result})
+	if err != nil {
+		return fmt.Errorf("encoding cache entry: %w", err)
+	}
+
+	path := c.entryPath(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating cache entry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+	return nil
+}
+
+// Clear removes every cached result
+func (c *LintCache) Clear() error {
+	if err := os.RemoveAll(c.dir); err != nil {
+		return fmt.Errorf("removing lint cache: %w", err)
+	}
+	return nil
+}
+
+// LintCacheKey builds a cache key from a resource's inputs: the linter and its tool
+// version, any values or config that affect the result, and the content of the
+// resource's files.
+type LintCacheKey struct {
+	h hash.Hash
+}
+
+// NewLintCacheKey starts a key for the given linter and tool version
+func NewLintCacheKey(linter, toolVersion string) *LintCacheKey {
+	k := &LintCacheKey{h: sha256.New()}
+	k.String(fmt.Sprintf("v%d", lintCacheVersion))
+	k.String(linter)
+	k.String(toolVersion)
+	return k
+}
+
+// String adds a string to the key
+func (k *LintCacheKey) String(s string) {
+	// Length-prefix each value so ("ab", "c") and ("a", "bc") hash differently
+	fmt.Fprintf(k.h, "%d:%s\n", len(s), s)
+}
+
+// JSON adds the JSON encoding of v to the key. Map keys are encoded in sorted
+// order, so equal values always produce the same key.
+func (k *LintCacheKey) JSON(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encoding cache key input: %w", err)
+	}
+	k.String(string(data))
+	return nil
+}
+
+// Path adds the content of a file, or of every file below a directory, to the key.
+// A path that does not exist is added as missing rather than failing, since the
+// linter reports missing files itself.
+func (k *LintCacheKey) Path(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			k.String("missing:" + path)
+			return nil
+		}
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	if !info.IsDir() {
+		k.String("file:" + path)
+		return k.addFileContent(path)
+	}
+
+	var files []string
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if d.Type().IsRegular() {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("walking %s: %w", path, err)
+	}
+	sort.Strings(files)
+
+	k.String("dir:" + path)
+	for _, file := range files {
+		rel, err := filepath.Rel(path, file)
+		if err != nil {
+			return err
+		}
+		k.String(filepath.ToSlash(rel))
+		if err := k.addFileContent(file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (k *LintCacheKey) addFileContent(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	defer f.Close()
+
+	fileHash := sha256.New()
+	if _, err := io.Copy(fileHash, f); err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	k.String(hex.EncodeToString(fileHash.Sum(nil)))
+	return nil
+}
+
+// Sum returns the key
+func (k *LintCacheKey) Sum() string {
+	return hex.EncodeToString(k.h.Sum(nil))
+}
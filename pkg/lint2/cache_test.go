@@ -0,0 +1,92 @@
+package lint2
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLintCache_PutGet(t *testing.T) {
+	cache := NewLintCache(t.TempDir())
+	key := NewLintCacheKey("helm", "3.14.4").Sum()
+
+	if _, ok := cache.Get(key); ok {
+		t.Fatal("expected miss for an empty cache")
+	}
+
+	want := &LintResult{
+		Success:    false,
+		Messages:   []LintMessage{{Severity: "ERROR", Path: "values.yaml", Message: "bad value", Line: 3, Rule: "values"}},
+		Suppressed: 2,
+	}
+	if err := cache.Put(key, want); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	got, ok := cache.Get(key)
+	if !ok {
+		t.Fatal("expected hit after Put")
+	}
+	if got.Success != want.Success || got.Suppressed != want.Suppressed || len(got.Messages) != 1 || got.Messages[0] != want.Messages[0] {
+		t.Errorf("Get() = %+v, want %+v", got, want)
+	}
+
+	if err := cache.Clear(); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if _, ok := cache.Get(key); ok {
+		t.Error("expected miss after Clear")
+	}
+}
+
+func TestLintCache_IgnoresCorruptEntries(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewLintCache(dir)
+	key := NewLintCacheKey("helm", "3.14.4").Sum()
+
+	path := cache.entryPath(key)
+	writeTestFile(t, filepath.Dir(path), filepath.Base(path), "not json")
+	if _, ok := cache.Get(key); ok {
+		t.Error("expected corrupt entry to be a miss")
+	}
+}
+
+func TestLintCacheKey(t *testing.T) {
+	chartDir := t.TempDir()
+	writeTestFile(t, chartDir, "Chart.yaml", "name: app\n")
+	writeTestFile(t, chartDir, "templates/deployment.yaml", "kind: Deployment\n")
+
+	keyFor := func(linter, toolVersion string, values any) string {
+		t.Helper()
+		key := NewLintCacheKey(linter, toolVersion)
+		if err := key.Path(chartDir); err != nil {
+			t.Fatalf("Path() error = %v", err)
+		}
+		if err := key.JSON(values); err != nil {
+			t.Fatalf("JSON() error = %v", err)
+		}
+		return key.Sum()
+	}
+
+	base := keyFor("helm", "3.14.4", map[string]string{"a": "1", "b": "2"})
+	if got := keyFor("helm", "3.14.4", map[string]string{"b": "2", "a": "1"}); got != base {
+		t.Error("expected the same inputs to produce the same key")
+	}
+	if got := keyFor("helm", "3.15.0", map[string]string{"a": "1", "b": "2"}); got == base {
+		t.Error("expected a different tool version to change the key")
+	}
+	if got := keyFor("helm", "3.14.4", map[string]string{"a": "1"}); got == base {
+		t.Error("expected different values to change the key")
+	}
+
+	writeTestFile(t, chartDir, "templates/deployment.yaml", "kind: StatefulSet\n")
+	if got := keyFor("helm", "3.14.4", map[string]string{"a": "1", "b": "2"}); got == base {
+		t.Error("expected a changed file to change the key")
+	}
+}
+
+func TestLintCacheKey_MissingPath(t *testing.T) {
+	key := NewLintCacheKey("preflight", "0.100.0")
+	if err := key.Path(filepath.Join(t.TempDir(), "missing.yaml")); err != nil {
+		t.Errorf("expected missing path to be added to the key, got error %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+package lint2
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// ChangedFiles is the set of files changed in a git repository relative to a ref.
+// Paths are absolute.
+type ChangedFiles struct {
+	Ref   string
+	files map[string]bool
+}
+
+// NewChangedFiles returns a set of changed files from explicit paths
+func NewChangedFiles(ref string, paths []string) *ChangedFiles {
+	changed := &ChangedFiles{Ref: ref, files: make(map[string]bool, len(paths))}
+	for _, path := range paths {
+		if absPath, err := filepath.Abs(path); err == nil {
+			changed.files[absPath] = true
+		}
+	}
+	return changed
+}
+
+// ChangedSince returns the files changed on the current branch since it diverged
+// from ref (like `git diff ref...HEAD`), plus uncommitted changes in the worktree:
+// staged, unstaged, untracked and deleted files. dir is any directory inside the
+// repository.
+func ChangedSince(dir, ref string) (*ChangedFiles, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("resolving %s: %w", dir, err)
+	}
+	repoRoot, err := FindGitRoot(absDir)
+	if err != nil {
+		return nil, err
+	}
+
+	repository, err := git.PlainOpen(repoRoot)
+	if err != nil {
+		return nil, fmt.Errorf("git open %q failed: %w", repoRoot, err)
+	}
+
+	refHash, err := repository.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return nil, fmt.Errorf("git resolve revision %q failed: %w", ref, err)
+	}
+	refCommit, err := repository.CommitObject(*refHash)
+	if err != nil {
+		return nil, fmt.Errorf("git get commit %q failed: %w", ref, err)
+	}
+	head, err := repository.Head()
+	if err != nil {
+		return nil, fmt.Errorf("git resolve HEAD failed: %w", err)
+	}
+	headCommit, err := repository.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("git get HEAD commit failed: %w", err)
+	}
+
+	// Compare against the merge base so changes made on ref since the branch
+	// diverged are not counted as changes on this branch
+	baseCommit := refCommit
+	if bases, err := refCommit.MergeBase(headCommit); err == nil && len(bases) > 0 {
+		baseCommit = bases[0]
+	}
+
+	baseTree, err := baseCommit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("git get tree for %q failed: %w", ref, err)
+	}
+	headTree, err := headCommit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("git get tree for HEAD failed: %w", err)
+	}
+	changes, err := object.DiffTree(baseTree, headTree)
+	if err != nil {
+		return nil, fmt.Errorf("git diff %q failed: %w", ref, err)
+	}
+
+	changed := &ChangedFiles{Ref: ref, files: make(map[string]bool)}
+	add := func(name string) {
+		if name != "" {
+			changed.files[filepath.Join(repoRoot, filepath.FromSlash(name))] = true
+		}
+	}
+	for _, change := range changes {
+		// Renames and deletions touch both the old and the new path
+		add(change.From.Name)
+		add(change.To.Name)
+	}
+
+	worktree, err := repository.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("git get worktree failed: %w", err)
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, fmt.Errorf("git get status failed: %w", err)
+	}
+	for name, fileStatus := range status {
+		if fileStatus.Staging != git.Unmodified || fileStatus.Worktree != git.Unmodified {
+			add(name)
+		}
+	}
+
+	return changed, nil
+}
+
+// Len returns the number of changed files
+func (c *ChangedFiles) Len() int {
+	return len(c.files)
+}
+
+// Contains reports whether path is a changed file or, for a directory, contains one
+func (c *ChangedFiles) Contains(path string) bool {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	if c.files[absPath] {
+		return true
+	}
+
+	prefix := absPath + string(filepath.Separator)
+	for file := range c.files {
+		if strings.HasPrefix(file, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ContainsAny reports whether any of paths is or contains a changed file
+func (c *ChangedFiles) ContainsAny(paths []string) bool {
+	for _, path := range paths {
+		if c.Contains(path) {
+			return true
+		}
+	}
+	return false
+}
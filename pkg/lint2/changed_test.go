@@ -0,0 +1,115 @@
+package lint2
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func commitAll(t *testing.T, worktree *git.Worktree, message string) {
+	t.Helper()
+	if err := worktree.AddGlob("."); err != nil {
+		t.Fatalf("git add: %v", err)
+	}
+	_, err := worktree.Commit(message, &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("git commit: %v", err)
+	}
+}
+
+func TestChangedSince(t *testing.T) {
+	dir := t.TempDir()
+	repository, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repository.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeTestFile(t, dir, "charts/app/Chart.yaml", "name: app\n")
+	writeTestFile(t, dir, "preflight.yaml", "kind: Preflight\n")
+	writeTestFile(t, dir, "other.yaml", "a: 1\n")
+	commitAll(t, worktree, "initial")
+	head, err := repository.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mainBranch := head.Name()
+
+	// Branch changes a chart template
+	if err := worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature"), Create: true}); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, dir, "charts/app/templates/deployment.yaml", "kind: Deployment\n")
+	commitAll(t, worktree, "add template")
+
+	// Main moves on after the branch diverged
+	if err := worktree.Checkout(&git.CheckoutOptions{Branch: mainBranch}); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, dir, "other.yaml", "a: 2\n")
+	commitAll(t, worktree, "change other")
+
+	// Back on the branch with an uncommitted new file
+	if err := worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature")}); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, dir, "support-bundle.yaml", "kind: SupportBundle\n")
+
+	changed, err := ChangedSince(filepath.Join(dir, "charts"), mainBranch.Short())
+	if err != nil {
+		t.Fatalf("ChangedSince() error = %v", err)
+	}
+
+	for path, want := range map[string]bool{
+		filepath.Join(dir, "charts", "app"):                                 true,
+		filepath.Join(dir, "charts", "app", "templates", "deployment.yaml"): true,
+		filepath.Join(dir, "support-bundle.yaml"):                           true,
+		filepath.Join(dir, "preflight.yaml"):                                false,
+		filepath.Join(dir, "other.yaml"):                                    false, // changed on main after the merge base
+	} {
+		if got := changed.Contains(path); got != want {
+			t.Errorf("Contains(%s) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestChangedSince_UnknownRef(t *testing.T) {
+	dir := t.TempDir()
+	repository, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repository.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, dir, "a.yaml", "a: 1\n")
+	commitAll(t, worktree, "initial")
+
+	if _, err := ChangedSince(dir, "does-not-exist"); err == nil {
+		t.Error("expected error for unknown ref")
+	}
+}
+
+func TestChangedFiles_Contains(t *testing.T) {
+	changed := NewChangedFiles("main", []string{"/repo/charts/app/values.yaml"})
+
+	if !changed.Contains("/repo/charts/app") {
+		t.Error("expected directory containing a changed file to match")
+	}
+	if changed.Contains("/repo/charts/app2") {
+		t.Error("expected sibling directory with a shared prefix not to match")
+	}
+	if !changed.ContainsAny([]string{"/repo/other.yaml", "/repo/charts/app/values.yaml"}) {
+		t.Error("expected ContainsAny to match a changed file")
+	}
+}
@@ -46,32 +46,41 @@ func expandPaths[T any](
 
 // ChartWithMetadata pairs a chart path with its metadata from Chart.yaml
 type ChartWithMetadata struct {
-	Path    string // Absolute path to the chart directory
-	Name    string // Chart name from Chart.yaml
-	Version string // Chart version from Chart.yaml
+	Path        string   // Absolute path to the chart directory
+	Name        string   // Chart name from Chart.yaml
+	Version     string   // Chart version from Chart.yaml
+	ValuesFiles []string // Values files to lint the chart with, from .replicated
 }
 
 // GetChartsWithMetadataFromConfig extracts chart paths and their metadata from config
 // This function combines GetChartPathsFromConfig with metadata extraction, reducing
 // boilerplate for callers that need both path and metadata information (like image extraction).
 func GetChartsWithMetadataFromConfig(config *tools.Config) ([]ChartWithMetadata, error) {
-	chartPaths, err := GetChartPathsFromConfig(config)
-	if err != nil {
-		return nil, err
+	if len(config.Charts) == 0 {
+		return nil, fmt.Errorf("no charts found in .replicated config")
 	}
 
 	var results []ChartWithMetadata
-	for _, chartPath := range chartPaths {
-		metadata, err := GetChartMetadata(chartPath)
+	for _, chartConfig := range config.Charts {
+		// Expand each entry separately so charts keep the values files listed with them
+		chartPaths, err := expandPaths([]tools.ChartConfig{chartConfig}, func(c tools.ChartConfig) string { return c.Path }, DiscoverChartPaths, "charts")
 		if err != nil {
-			return nil, fmt.Errorf("failed to read chart metadata for %s: %w", chartPath, err)
+			return nil, err
 		}
 
-		results = append(results, ChartWithMetadata{
-			Path:    chartPath,
-			Name:    metadata.Name,
-			Version: metadata.Version,
-		})
+		for _, chartPath := range chartPaths {
+			metadata, err := GetChartMetadata(chartPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read chart metadata for %s: %w", chartPath, err)
+			}
+
+			results = append(results, ChartWithMetadata{
+				Path:        chartPath,
+				Name:        metadata.Name,
+				Version:     metadata.Version,
+				ValuesFiles: chartConfig.Values,
+			})
+		}
 	}
 
 	return results, nil
@@ -146,21 +155,32 @@ func BuildChartLookup(config *tools.Config) (map[string]*ChartWithMetadata, erro
 		return nil, fmt.Errorf("failed to get charts from config: %w", err)
 	}
 
-	// Build lookup map
+	chartLookup, duplicates := indexCharts(charts)
+	if len(duplicates) > 0 {
+		return nil, duplicates[0]
+	}
+
+	return chartLookup, nil
+}
+
+// indexCharts keys charts by "name:version". The first chart for a key wins; every
+// later one is returned as a duplicate.
+func indexCharts(charts []ChartWithMetadata) (map[string]*ChartWithMetadata, []*DuplicateChartError) {
 	chartLookup := make(map[string]*ChartWithMetadata)
+	var duplicates []*DuplicateChartError
 	for i := range charts {
 		key := fmt.Sprintf("%s:%s", charts[i].Name, charts[i].Version)
 		if existing, exists := chartLookup[key]; exists {
-			return nil, &DuplicateChartError{
+			duplicates = append(duplicates, &DuplicateChartError{
 				ChartKey:   key,
 				FirstPath:  existing.Path,
 				SecondPath: charts[i].Path,
-			}
+			})
+			continue
 		}
 		chartLookup[key] = &charts[i]
 	}
-
-	return chartLookup, nil
+	return chartLookup, duplicates
 }
 
 // findValuesFile finds values.yaml or values.yml in a chart directory.
@@ -10,6 +10,20 @@ import (
 
 // Test helpers
 
+// writeTestFile writes content to name under dir, creating parent directories, and
+// returns the file's path
+func writeTestFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
 // createTestChart creates a minimal Chart.yaml file in the specified directory
 func createTestChart(t *testing.T, dir, name string) string {
 	t.Helper()
@@ -6,6 +6,9 @@
 //   - Preflight specs (via preflight lint from troubleshoot.sh)
 //   - Support Bundle specs (via support-bundle lint from troubleshoot.sh)
 //
+// KOTS custom resources (Application, Config, HelmChart, LintConfig, Identity and velero
+// Backup) are linted in-process by LintKots; no tool binary is required.
+//
 // # Features
 //
 // Common functionality across all linters:
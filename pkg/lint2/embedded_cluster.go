@@ -151,7 +151,7 @@ func parseECVersionFromFile(path string) (string, error) {
 // LintEmbeddedCluster runs `ec lint --format json <paths...>` and returns structured results.
 // The binary must already be available at ecBinaryPath. disableChecks is passed as --disable
 // to the EC CLI; if empty the caller should supply the defaults.
-func LintEmbeddedCluster(ctx context.Context, paths []string, ecBinaryPath string, disableChecks []string) (*LintResult, error) {
+func LintEmbeddedCluster(ctx context.Context, paths []string, ecBinaryPath string, disableChecks []string, opts ...LintOption) (*LintResult, error) {
 	if len(paths) == 0 {
 		return &LintResult{Success: true, Messages: []LintMessage{}}, nil
 	}
@@ -186,8 +186,12 @@ func LintEmbeddedCluster(ctx context.Context, paths []string, ecBinaryPath strin
 		}
 	}
 
-	return &LintResult{
+	result := &LintResult{
 		Success:  success,
 		Messages: messages,
-	}, nil
+	}
+	// EC messages carry their own file paths; there is no single base path
+	applyLintOptions(newLintOptions(opts), "", result, false)
+
+	return result, nil
 }
@@ -0,0 +1,434 @@
+package lint2
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/distribution/reference"
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v3"
+)
+
+// Fixers that can be applied by FixRelease
+const (
+	// FixerPreflightAPIVersion adds the missing apiVersion to v1beta3 preflight specs,
+	// which are linked to a chart or read chart values
+	FixerPreflightAPIVersion = "preflight-api-version"
+	// FixerHelmChartVersion adds a missing spec.chart.chartVersion to a HelmChart whose
+	// chart is configured, from the chart's Chart.yaml
+	FixerHelmChartVersion = "helmchart-chart-version"
+	// FixerImageDigest pins tagged image references to the digest of the image in a
+	// local OCI layout
+	FixerImageDigest = "image-digest"
+)
+
+const preflightAPIVersionV1Beta3 = "troubleshoot.sh/v1beta3"
+
+// FixInputs describes the release files to fix
+type FixInputs struct {
+	Preflights []PreflightWithValues
+	Manifests  []string            // manifest files (not globs)
+	Charts     []ChartWithMetadata // configured charts; their values.yaml is fixed too
+	Images     *OCILayout          // pins image references when set
+}
+
+// FixEdit is a single change made to a file by a fixer
+type FixEdit struct {
+	Fixer   string
+	Line    int // 1-based line of the original file the change applies to
+	Message string
+}
+
+// FileFix holds the original and fixed content of a file and the changes made to it
+type FileFix struct {
+	Path     string
+	Original []byte
+	Fixed    []byte
+	Edits    []FixEdit
+}
+
+// textEdit inserts text at a byte offset of the original file
+type textEdit struct {
+	offset int
+	insert string
+	edit   FixEdit
+}
+
+// FixRelease computes the fixes for the release files. Files are only read: the
+// caller decides whether to write FileFix.Fixed back. Edits are made to the original
+// text at positions found by parsing it with yaml.v3, so comments and formatting are
+// kept. Files that are not valid YAML and changes that would be ambiguous are skipped.
+// Only files with at least one edit are returned, ordered by path.
+func FixRelease(inputs FixInputs) ([]FileFix, error) {
+	type fileFixers struct {
+		preflightV1Beta3 bool // a v1beta3 preflight spec
+		manifest         bool
+		values           bool // a chart values file
+	}
+	files := make(map[string]*fileFixers)
+	get := func(path string) *fileFixers {
+		if files[path] == nil {
+			files[path] = &fileFixers{}
+		}
+		return files[path]
+	}
+
+	for _, pf := range inputs.Preflights {
+		get(pf.SpecPath).preflightV1Beta3 = pf.ChartName != ""
+	}
+	for _, path := range inputs.Manifests {
+		get(path).manifest = true
+	}
+	if inputs.Images != nil {
+		for _, chart := range inputs.Charts {
+			valuesPath := filepath.Join(chart.Path, "values.yaml")
+			if _, err := os.Stat(valuesPath); err == nil {
+				get(valuesPath).values = true
+			}
+		}
+	}
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var results []FileFix
+	for _, path := range paths {
+		fixers := files[path]
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		docs, err := parseFixDocuments(data)
+		if err != nil {
+			// Invalid (e.g. templated) YAML is left for the linters to report
+			continue
+		}
+
+		var edits []textEdit
+		for _, doc := range docs {
+			edits = append(edits, fixPreflightAPIVersion(data, doc, fixers.preflightV1Beta3)...)
+			if fixers.manifest {
+				edits = append(edits, fixHelmChartVersion(data, doc, inputs.Charts)...)
+			}
+			if (fixers.manifest || fixers.values) && inputs.Images != nil {
+				edits = append(edits, fixImageDigests(data, doc, inputs.Images)...)
+			}
+		}
+		if len(edits) == 0 {
+			continue
+		}
+
+		results = append(results, applyTextEdits(path, data, edits))
+	}
+
+	return results, nil
+}
+
+// parseFixDocuments parses every document in a YAML file
+func parseFixDocuments(data []byte) ([]*yaml.Node, error) {
+	var docs []*yaml.Node
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yaml.Node
+		if err := decoder.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				return docs, nil
+			}
+			return nil, err
+		}
+		if len(doc.Content) > 0 {
+			docs = append(docs, doc.Content[0])
+		}
+	}
+}
+
+// fixPreflightAPIVersion adds apiVersion to a Preflight document that lacks one.
+// linked reports whether the spec is known to be v1beta3 from the config; otherwise
+// the spec has to read chart values to be treated as v1beta3.
+func fixPreflightAPIVersion(data []byte, root *yaml.Node, linked bool) []textEdit {
+	if root.Kind != yaml.MappingNode || root.Style&yaml.FlowStyle != 0 || len(root.Content) == 0 {
+		return nil
+	}
+	if scalarValue(mappingValue(root, "kind")) != "Preflight" || mappingValue(root, "apiVersion") != nil {
+		return nil
+	}
+	if !linked && !nodeContains(root, ".Values") {
+		return nil
+	}
+
+	edit, ok := insertLineBefore(data, root.Content[0], "apiVersion: "+preflightAPIVersionV1Beta3)
+	if !ok {
+		return nil
+	}
+	edit.edit = FixEdit{
+		Fixer:   FixerPreflightAPIVersion,
+		Line:    root.Content[0].Line,
+		Message: fmt.Sprintf("added apiVersion: %s", preflightAPIVersionV1Beta3),
+	}
+	return []textEdit{edit}
+}
+
+// fixHelmChartVersion adds spec.chart.chartVersion to a HelmChart that names a
+// configured chart but no version. Charts configured with several versions are skipped.
+func fixHelmChartVersion(data []byte, root *yaml.Node, charts []ChartWithMetadata) []textEdit {
+	if root.Kind != yaml.MappingNode || scalarValue(mappingValue(root, "kind")) != "HelmChart" {
+		return nil
+	}
+	apiVersion := scalarValue(mappingValue(root, "apiVersion"))
+	if apiVersion != kotsAPIVersionV1Beta1 && apiVersion != kotsAPIVersionV1Beta2 {
+		return nil
+	}
+	chart := mappingValue(mappingValue(root, "spec"), "chart")
+	if chart == nil || chart.Kind != yaml.MappingNode || chart.Style&yaml.FlowStyle != 0 {
+		return nil
+	}
+	name := mappingValue(chart, "name")
+	if scalarValue(name) == "" || mappingValue(chart, "chartVersion") != nil {
+		return nil
+	}
+
+	version := ""
+	for _, c := range charts {
+		if c.Name != name.Value {
+			continue
+		}
+		if version != "" && version != c.Version {
+			return nil
+		}
+		version = c.Version
+	}
+	if version == "" {
+		return nil
+	}
+
+	// The new key goes after the name, at the indentation of the name key
+	var nameKey *yaml.Node
+	for i := 0; i+1 < len(chart.Content); i += 2 {
+		if chart.Content[i+1] == name {
+			nameKey = chart.Content[i]
+		}
+	}
+	if name.Line != nameKey.Line || name.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		return nil
+	}
+	edit, ok := insertLineAfter(data, nameKey, "chartVersion: "+yamlScalar(version))
+	if !ok {
+		return nil
+	}
+	edit.edit = FixEdit{
+		Fixer:   FixerHelmChartVersion,
+		Line:    name.Line,
+		Message: fmt.Sprintf("added chartVersion: %s from the %s chart", version, name.Value),
+	}
+	return []textEdit{edit}
+}
+
+// fixImageDigests pins every tagged image reference under an image key that the OCI
+// layout holds, keeping the tag for readability (name:tag@digest)
+func fixImageDigests(data []byte, root *yaml.Node, layout *OCILayout) []textEdit {
+	var edits []textEdit
+	var walk func(node *yaml.Node)
+	walk = func(node *yaml.Node) {
+		if node.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key, value := node.Content[i], node.Content[i+1]
+				if key.Value == "image" && value.Kind == yaml.ScalarNode && value.Tag == "!!str" {
+					if edit, ok := pinImage(data, value, layout); ok {
+						edits = append(edits, edit)
+					}
+				}
+			}
+		}
+		for _, child := range node.Content {
+			walk(child)
+		}
+	}
+	walk(root)
+	return edits
+}
+
+// pinImage returns the edit that appends the digest to an image reference scalar
+func pinImage(data []byte, node *yaml.Node, layout *OCILayout) (textEdit, bool) {
+	if isTemplated(node.Value) {
+		return textEdit{}, false
+	}
+	named, err := reference.ParseNormalizedNamed(node.Value)
+	if err != nil {
+		return textEdit{}, false
+	}
+	if _, ok := named.(reference.Digested); ok {
+		return textEdit{}, false
+	}
+	if _, ok := named.(reference.Tagged); !ok {
+		return textEdit{}, false
+	}
+	digest, ok := layout.Digest(node.Value)
+	if !ok {
+		return textEdit{}, false
+	}
+
+	// Only single-line plain or quoted scalars whose text matches their value are edited
+	var quote string
+	switch node.Style {
+	case 0:
+	case yaml.DoubleQuotedStyle:
+		quote = `"`
+	case yaml.SingleQuotedStyle:
+		quote = "'"
+	default:
+		return textEdit{}, false
+	}
+	start, ok := nodeOffset(data, node)
+	if !ok {
+		return textEdit{}, false
+	}
+	raw := quote + node.Value + quote
+	if !bytes.HasPrefix(data[start:], []byte(raw)) {
+		return textEdit{}, false
+	}
+
+	return textEdit{
+		offset: start + len(quote) + len(node.Value),
+		insert: "@" + digest,
+		edit: FixEdit{
+			Fixer:   FixerImageDigest,
+			Line:    node.Line,
+			Message: fmt.Sprintf("pinned image %s to %s", node.Value, digest),
+		},
+	}, true
+}
+
+// applyTextEdits applies edits to the original file content
+func applyTextEdits(path string, data []byte, edits []textEdit) FileFix {
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].offset < edits[j].offset })
+
+	var fixed bytes.Buffer
+	last := 0
+	result := FileFix{Path: path, Original: data}
+	for _, e := range edits {
+		fixed.Write(data[last:e.offset])
+		fixed.WriteString(e.insert)
+		last = e.offset
+		result.Edits = append(result.Edits, e.edit)
+	}
+	fixed.Write(data[last:])
+	result.Fixed = fixed.Bytes()
+
+	return result
+}
+
+// insertLineBefore returns an edit that inserts a line before the line of node, at the
+// node's indentation. The node must be the first thing on its line.
+func insertLineBefore(data []byte, node *yaml.Node, line string) (textEdit, bool) {
+	start, ok := nodeOffset(data, node)
+	if !ok {
+		return textEdit{}, false
+	}
+	lineStart := bytes.LastIndexByte(data[:start], '\n') + 1
+	indent := data[lineStart:start]
+	if len(bytes.TrimLeft(indent, " ")) > 0 {
+		return textEdit{}, false
+	}
+	return textEdit{offset: lineStart, insert: string(indent) + line + "\n"}, true
+}
+
+// insertLineAfter returns an edit that inserts a line after the line of node, at the
+// node's indentation. The node must be the first thing on its line.
+func insertLineAfter(data []byte, node *yaml.Node, line string) (textEdit, bool) {
+	start, ok := nodeOffset(data, node)
+	if !ok {
+		return textEdit{}, false
+	}
+	lineStart := bytes.LastIndexByte(data[:start], '\n') + 1
+	indent := data[lineStart:start]
+	if len(bytes.TrimLeft(indent, " ")) > 0 {
+		return textEdit{}, false
+	}
+	lineEnd := bytes.IndexByte(data[start:], '\n')
+	if lineEnd < 0 {
+		return textEdit{offset: len(data), insert: "\n" + string(indent) + line}, true
+	}
+	return textEdit{offset: start + lineEnd + 1, insert: string(indent) + line + "\n"}, true
+}
+
+// nodeOffset returns the byte offset of a node from its 1-based line and column
+func nodeOffset(data []byte, node *yaml.Node) (int, bool) {
+	offset := 0
+	for line := 1; line < node.Line; line++ {
+		next := bytes.IndexByte(data[offset:], '\n')
+		if next < 0 {
+			return 0, false
+		}
+		offset += next + 1
+	}
+	// Columns count characters, not bytes
+	for column := 1; column < node.Column; column++ {
+		if offset >= len(data) || data[offset] == '\n' {
+			return 0, false
+		}
+		_, size := utf8.DecodeRune(data[offset:])
+		offset += size
+	}
+	return offset, true
+}
+
+// nodeContains reports whether any scalar under node contains s
+func nodeContains(node *yaml.Node, s string) bool {
+	if node.Kind == yaml.ScalarNode && strings.Contains(node.Value, s) {
+		return true
+	}
+	for _, child := range node.Content {
+		if nodeContains(child, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// yamlScalar renders a string as a YAML scalar, quoting it when it would otherwise be
+// read as another type (e.g. 1.0)
+func yamlScalar(value string) string {
+	out, err := yaml.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%q", value)
+	}
+	return strings.TrimSuffix(string(out), "\n")
+}
+
+// UnifiedDiff returns a unified diff of a fix, with paths shown relative to dir when
+// they are inside it
+func UnifiedDiff(fix FileFix, dir string) (string, error) {
+	name := fix.Path
+	if rel, err := filepath.Rel(dir, fix.Path); err == nil && !strings.HasPrefix(rel, "..") {
+		name = rel
+	}
+	name = filepath.ToSlash(name)
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        diffLines(fix.Original),
+		B:        diffLines(fix.Fixed),
+		FromFile: "a/" + name,
+		ToFile:   "b/" + name,
+		Context:  3,
+	})
+}
+
+// diffLines splits a file into lines that each end with a newline
+func diffLines(data []byte) []string {
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+	lines[len(lines)-1] += "\n"
+	return lines
+}
@@ -0,0 +1,229 @@
+package lint2
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testImageDigest = "sha256:6b3ea6a5a5bd1d1c2f0d1b3a0d4f6f1b7c2e9d5a8f3c4b1e2d7a6c5b4f3e2d1c"
+
+func writeOCILayout(t *testing.T, dir string) *OCILayout {
+	t.Helper()
+	writeTestFile(t, dir, "oci-layout", `{"imageLayoutVersion": "1.0.0"}`)
+	writeTestFile(t, dir, "index.json", `{
+  "schemaVersion": 2,
+  "manifests": [
+    {
+      "mediaType": "application/vnd.oci.image.index.v1+json",
+      "digest": "`+testImageDigest+`",
+      "size": 1000,
+      "annotations": {"io.containerd.image.name": "docker.io/library/nginx:1.27"}
+    },
+    {
+      "mediaType": "application/vnd.oci.image.manifest.v1+json",
+      "digest": "sha256:1111111111111111111111111111111111111111111111111111111111111111",
+      "size": 500,
+      "annotations": {"org.opencontainers.image.ref.name": "registry.example.com/app/api:2.0.0"}
+    },
+    {
+      "mediaType": "application/vnd.oci.image.manifest.v1+json",
+      "digest": "sha256:2222222222222222222222222222222222222222222222222222222222222222",
+      "size": 500,
+      "annotations": {"org.opencontainers.image.ref.name": "3.0.0"}
+    }
+  ]
+}`)
+	layout, err := LoadOCILayout(dir)
+	if err != nil {
+		t.Fatalf("LoadOCILayout() error = %v", err)
+	}
+	return layout
+}
+
+func TestFixRelease(t *testing.T) {
+	dir := t.TempDir()
+	preflight := writeTestFile(t, dir, "preflights/preflight.yaml", `# Preflight checks for web
+kind: Preflight
+metadata:
+  name: web
+spec:
+  analyzers: []
+`)
+	unlinked := writeTestFile(t, dir, "preflights/legacy.yaml", `kind: Preflight
+metadata:
+  name: legacy
+`)
+	helmChart := writeTestFile(t, dir, "manifests/web.yaml", `apiVersion: kots.io/v1beta2
+kind: HelmChart
+metadata:
+  name: web
+spec:
+  chart:
+    name: web # the web chart
+    releaseName: web
+  values:
+    image: nginx:1.27
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: sidecar
+spec:
+  containers:
+    - name: api
+      image: "registry.example.com/app/api:2.0.0"
+    - name: pinned
+      image: 'nginx:1.27@`+testImageDigest+`'
+    - name: unknown
+      image: busybox:1.36
+    - name: tag-only
+      image: example/other:3.0.0
+`)
+	chartDir := filepath.Join(dir, "charts", "web")
+	writeTestFile(t, dir, "charts/web/Chart.yaml", "apiVersion: v2\nname: web\nversion: 1.0\n")
+	writeTestFile(t, dir, "charts/web/values.yaml", "image: nginx:1.27\nworker:\n  image: '{{ .Values.image }}'\n")
+
+	fixes, err := FixRelease(FixInputs{
+		Preflights: []PreflightWithValues{
+			{SpecPath: preflight, ChartName: "web", ChartVersion: "1.0"},
+			{SpecPath: unlinked},
+		},
+		Manifests: []string{helmChart},
+		Charts:    []ChartWithMetadata{{Path: chartDir, Name: "web", Version: "1.0"}},
+		Images:    writeOCILayout(t, filepath.Join(dir, "layout")),
+	})
+	if err != nil {
+		t.Fatalf("FixRelease() error = %v", err)
+	}
+
+	got := map[string]string{}
+	var edits []FixEdit
+	for _, fix := range fixes {
+		rel, _ := filepath.Rel(dir, fix.Path)
+		got[rel] = string(fix.Fixed)
+		edits = append(edits, fix.Edits...)
+	}
+
+	want := map[string]string{
+		"charts/web/values.yaml": "image: nginx:1.27@" + testImageDigest + "\nworker:\n  image: '{{ .Values.image }}'\n",
+		"manifests/web.yaml": `apiVersion: kots.io/v1beta2
+kind: HelmChart
+metadata:
+  name: web
+spec:
+  chart:
+    name: web # the web chart
+    chartVersion: "1.0"
+    releaseName: web
+  values:
+    image: nginx:1.27@` + testImageDigest + `
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: sidecar
+spec:
+  containers:
+    - name: api
+      image: "registry.example.com/app/api:2.0.0@sha256:1111111111111111111111111111111111111111111111111111111111111111"
+    - name: pinned
+      image: 'nginx:1.27@` + testImageDigest + `'
+    - name: unknown
+      image: busybox:1.36
+    - name: tag-only
+      image: example/other:3.0.0
+`,
+		"preflights/preflight.yaml": `# Preflight checks for web
+apiVersion: troubleshoot.sh/v1beta3
+kind: Preflight
+metadata:
+  name: web
+spec:
+  analyzers: []
+`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fixed files =\n%v\nwant\n%v", got, want)
+	}
+
+	var fixers []string
+	for _, edit := range edits {
+		fixers = append(fixers, edit.Fixer)
+	}
+	wantFixers := []string{FixerImageDigest, FixerHelmChartVersion, FixerImageDigest, FixerImageDigest, FixerPreflightAPIVersion}
+	if !reflect.DeepEqual(fixers, wantFixers) {
+		t.Errorf("fixers = %v, want %v", fixers, wantFixers)
+	}
+	if edits[1].Line != 7 || edits[4].Line != 2 {
+		t.Errorf("unexpected edit lines: %+v", edits)
+	}
+}
+
+func TestFixRelease_NoImagesWithoutLayout(t *testing.T) {
+	dir := t.TempDir()
+	manifest := writeTestFile(t, dir, "manifests/pod.yaml", "apiVersion: v1\nkind: Pod\nspec:\n  image: nginx:1.27\n")
+
+	fixes, err := FixRelease(FixInputs{Manifests: []string{manifest}})
+	if err != nil {
+		t.Fatalf("FixRelease() error = %v", err)
+	}
+	if len(fixes) != 0 {
+		t.Errorf("expected no fixes, got %+v", fixes)
+	}
+}
+
+func TestFixRelease_AmbiguousChartVersion(t *testing.T) {
+	dir := t.TempDir()
+	manifest := writeTestFile(t, dir, "manifests/web.yaml", "apiVersion: kots.io/v1beta2\nkind: HelmChart\nspec:\n  chart:\n    name: web")
+
+	charts := []ChartWithMetadata{{Name: "web", Version: "1.0.0"}}
+	fixes, err := FixRelease(FixInputs{Manifests: []string{manifest}, Charts: charts})
+	if err != nil {
+		t.Fatalf("FixRelease() error = %v", err)
+	}
+	// The name is on the last line, which has no newline
+	if len(fixes) != 1 || !strings.HasSuffix(string(fixes[0].Fixed), "    name: web\n    chartVersion: 1.0.0") {
+		t.Errorf("unexpected fixes %+v", fixes)
+	}
+
+	charts = append(charts, ChartWithMetadata{Name: "web", Version: "2.0.0"})
+	fixes, err = FixRelease(FixInputs{Manifests: []string{manifest}, Charts: charts})
+	if err != nil {
+		t.Fatalf("FixRelease() error = %v", err)
+	}
+	if len(fixes) != 0 {
+		t.Errorf("expected charts with several versions to be skipped, got %+v", fixes)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	fix := FileFix{
+		Path:     filepath.Join("/work", "manifests", "web.yaml"),
+		Original: []byte("kind: HelmChart\nspec:\n  chart:\n    name: web\n"),
+		Fixed:    []byte("kind: HelmChart\nspec:\n  chart:\n    name: web\n    chartVersion: 1.0.0\n"),
+	}
+
+	diff, err := UnifiedDiff(fix, "/work")
+	if err != nil {
+		t.Fatalf("UnifiedDiff() error = %v", err)
+	}
+	want := `--- a/manifests/web.yaml
++++ b/manifests/web.yaml
+@@ -2,3 +2,4 @@
+ spec:
+   chart:
+     name: web
++    chartVersion: 1.0.0
+`
+	if diff != want {
+		t.Errorf("UnifiedDiff() =\n%s\nwant\n%s", diff, want)
+	}
+}
+
+func TestLoadOCILayout_NotALayout(t *testing.T) {
+	if _, err := LoadOCILayout(t.TempDir()); err == nil || !strings.Contains(err.Error(), "not an OCI image layout") {
+		t.Errorf("expected a missing oci-layout file to be reported, got %v", err)
+	}
+}
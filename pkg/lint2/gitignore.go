@@ -39,7 +39,7 @@ func NewGitignoreChecker(baseDir string) (*GitignoreChecker, error) {
 	}
 
 	// Find repository root (look for .git directory)
-	repoRoot, err := findGitRoot(absBaseDir)
+	repoRoot, err := FindGitRoot(absBaseDir)
 	if err != nil {
 		// Not a git repository - return nil (no gitignore checking)
 		return nil, nil
@@ -89,9 +89,9 @@ func NewGitignoreChecker(baseDir string) (*GitignoreChecker, error) {
 	}, nil
 }
 
-// findGitRoot walks up the directory tree to find the .git directory.
+// FindGitRoot walks up the directory tree to find the .git directory.
 // Returns the repository root directory or an error if not found.
-func findGitRoot(startDir string) (string, error) {
+func FindGitRoot(startDir string) (string, error) {
 	currentDir := startDir
 
 	for {
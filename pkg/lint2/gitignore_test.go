@@ -270,7 +270,7 @@ func TestFindGitRoot_ValidRepository(t *testing.T) {
 	subDir := filepath.Join(tmpDir, "sub", "dir")
 	require.NoError(t, os.MkdirAll(subDir, 0755))
 
-	root, err := findGitRoot(subDir)
+	root, err := FindGitRoot(subDir)
 	require.NoError(t, err)
 	assert.Equal(t, tmpDir, root)
 }
@@ -278,7 +278,7 @@ func TestFindGitRoot_ValidRepository(t *testing.T) {
 func TestFindGitRoot_NotRepository(t *testing.T) {
 	tmpDir := t.TempDir()
 
-	_, err := findGitRoot(tmpDir)
+	_, err := FindGitRoot(tmpDir)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "not a git repository")
 }
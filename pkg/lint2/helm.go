@@ -7,6 +7,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/replicatedhq/replicated/pkg/tools"
@@ -14,9 +15,11 @@ import (
 )
 
 // LintChart executes helm lint on the given chart path and returns structured results
-func LintChart(ctx context.Context, chartPath string, helmVersion string) (*LintResult, error) {
+func LintChart(ctx context.Context, chartPath string, helmVersion string, opts ...LintOption) (*LintResult, error) {
+	options := newLintOptions(opts)
+
 	// Use resolver to get helm binary
-	resolver := tools.NewResolver()
+	resolver := options.resolver()
 	helmPath, err := resolver.Resolve(ctx, tools.ToolHelm, helmVersion)
 	if err != nil {
 		return nil, fmt.Errorf("resolving helm: %w", err)
@@ -40,8 +43,8 @@ func LintChart(ctx context.Context, chartPath string, helmVersion string) (*Lint
 	// but we still want to parse and display the output
 	outputStr := string(output)
 
-	// Parse the output
-	messages := parseHelmOutput(outputStr)
+	// Parse the output, dropping suppressed findings
+	messages, suppressed := parseHelmOutput(outputStr, chartPath, options.Suppressor)
 
 	// Determine success based on exit code
 	// We trust helm's exit code: 0 = success, non-zero = failure
@@ -49,19 +52,23 @@ func LintChart(ctx context.Context, chartPath string, helmVersion string) (*Lint
 
 	// However, if helm failed but we got parseable output, we should
 	// still return the parsed messages
-	if err != nil && len(messages) == 0 {
+	if err != nil && len(messages)+len(suppressed) == 0 {
 		// If helm failed and we have no parsed messages, return the error
 		return nil, fmt.Errorf("helm lint failed: %w\n%s", err, outputStr)
 	}
 
-	return &LintResult{
-		Success:  success,
-		Messages: messages,
-	}, nil
+	result := newParsedLintResult(success, messages, suppressed)
+	if options.Strict {
+		applyStrict(result)
+	}
+
+	return result, nil
 }
 
-// parseHelmOutput parses helm lint output into structured messages
-func parseHelmOutput(output string) []LintMessage {
+// parseHelmOutput parses helm lint output into structured messages and splits off
+// the findings the suppressor or inline ignore comments suppress. Helm reports paths
+// relative to chartPath.
+func parseHelmOutput(output string, chartPath string, suppressor *Suppressor) ([]LintMessage, []LintMessage) {
 	var messages []LintMessage
 
 	// Pattern to match: [SEVERITY] path: message
@@ -80,10 +87,12 @@ func parseHelmOutput(output string) []LintMessage {
 
 		// Try pattern with path first
 		if matches := pattern.FindStringSubmatch(line); matches != nil {
+			message := strings.TrimSpace(matches[3])
 			messages = append(messages, LintMessage{
 				Severity: matches[1],
 				Path:     strings.TrimSpace(matches[2]),
-				Message:  strings.TrimSpace(matches[3]),
+				Message:  message,
+				Line:     parseHelmMessageLine(message),
 			})
 			continue
 		}
@@ -100,7 +109,24 @@ func parseHelmOutput(output string) []LintMessage {
 		// Ignore lines that don't match (headers, summaries, etc.)
 	}
 
-	return messages
+	return suppressor.split(chartPath, messages, true)
+}
+
+// helmLinePattern matches the line number helm includes in YAML parse errors
+// Example: unable to parse YAML: error converting YAML to JSON: yaml: line 12: did not find expected key
+var helmLinePattern = regexp.MustCompile(`\bline (\d+)\b`)
+
+// parseHelmMessageLine returns the line number mentioned in a helm lint message, or 0 if none
+func parseHelmMessageLine(message string) int {
+	matches := helmLinePattern.FindStringSubmatch(message)
+	if matches == nil {
+		return 0
+	}
+	line, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return 0
+	}
+	return line
 }
 
 // ChartMetadata represents basic metadata from a Helm chart's Chart.yaml
@@ -74,3 +74,35 @@ func TestLintChart_Integration(t *testing.T) {
 		}
 	})
 }
+
+// TestLintChartWithValues_Integration lints a chart with several values combinations
+// and checks that findings are attributed to the combination that produced them
+func TestLintChartWithValues_Integration(t *testing.T) {
+	ctx := context.Background()
+
+	combinations := []HelmValuesCombination{
+		{Name: "HelmChart values", Values: map[string]interface{}{"image": map[string]interface{}{"repository": "nginx"}}},
+		{Name: "chart defaults"},
+	}
+	result, err := LintChartWithValues(ctx, "testdata/charts/required-values", tools.DefaultHelmVersion, combinations)
+	if err != nil {
+		t.Fatalf("LintChartWithValues() error = %v, want nil", err)
+	}
+
+	if result.Success {
+		t.Errorf("Expected success=false when one combination fails, got true")
+	}
+
+	found := false
+	for _, msg := range result.Messages {
+		if msg.Severity == "ERROR" && contains(msg.Message, "image.repository is required") {
+			found = true
+			if msg.Values != "chart defaults" {
+				t.Errorf("Expected the error to be attributed to %q, got %q", "chart defaults", msg.Values)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("Expected an ERROR for the missing image.repository, got %+v", result.Messages)
+	}
+}
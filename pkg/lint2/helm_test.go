@@ -91,3 +91,31 @@ name: my-chart
 		}
 	})
 }
+
+func TestParseHelmOutput(t *testing.T) {
+	output := `==> Linting ./my-chart
+[INFO] Chart.yaml: icon is recommended
+[ERROR] templates/deployment.yaml: unable to parse YAML: error converting YAML to JSON: yaml: line 12: did not find expected key
+[WARNING] chart directory is missing values.yaml
+
+1 chart(s) linted, 1 chart(s) failed
+`
+
+	messages, _ := parseHelmOutput(output, "", nil)
+	if len(messages) != 3 {
+		t.Fatalf("expected 3 messages, got %d: %+v", len(messages), messages)
+	}
+
+	if messages[0].Severity != "INFO" || messages[0].Path != "Chart.yaml" || messages[0].Line != 0 {
+		t.Errorf("unexpected first message: %+v", messages[0])
+	}
+	if messages[1].Severity != "ERROR" || messages[1].Path != "templates/deployment.yaml" {
+		t.Errorf("unexpected second message: %+v", messages[1])
+	}
+	if messages[1].Line != 12 {
+		t.Errorf("expected line 12 from YAML parse error, got %d", messages[1].Line)
+	}
+	if messages[2].Severity != "WARNING" || messages[2].Path != "" {
+		t.Errorf("unexpected third message: %+v", messages[2])
+	}
+}
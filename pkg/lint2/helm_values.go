@@ -0,0 +1,575 @@
+package lint2
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/replicatedhq/replicated/pkg/tools"
+	"gopkg.in/yaml.v3"
+)
+
+// helmChartOptionalValues is a single spec.optionalValues entry of a HelmChart manifest
+type helmChartOptionalValues struct {
+	when           string
+	recursiveMerge bool
+	values         *yaml.Node
+}
+
+// HelmValuesCombination is one set of values a chart is linted with
+type HelmValuesCombination struct {
+	Name        string                 // Where the values come from, e.g. "HelmChart values + ci/values-ha.yaml"
+	Values      map[string]interface{} // HelmChart values rendered with sample config values (nil for chart defaults)
+	ValuesFiles []string               // Values files from .replicated, applied after Values
+}
+
+// HelmValuesMatrix returns every combination of values a chart should be linted with.
+//
+// When the chart's HelmChart manifest sets spec.values or spec.optionalValues, its
+// values are rendered with the sample config values (see DiscoverConfigSampleValues)
+// the way KOTS would at install time. optionalValues whose `when` is true for the
+// sample values are applied; each one whose `when` is false adds a combination with
+// that entry applied as well, so values that are only used for some configs are
+// linted too. Each values file listed for the chart in .replicated is then linted on
+// top of every one of those combinations.
+//
+// Returns nil when the chart has neither HelmChart values nor values files, in which
+// case it is linted with its default values only.
+func HelmValuesMatrix(chart ChartWithMetadata, manifests map[string]*HelmChartManifest, configValues map[string]string) ([]HelmValuesCombination, error) {
+	var base []HelmValuesCombination
+	if manifest := FindHelmChartManifest(chart.Name, chart.Version, manifests); manifest != nil && manifest.hasValues() {
+		combinations, err := manifest.valuesCombinations(configValues)
+		if err != nil {
+			return nil, fmt.Errorf("rendering values from %s: %w", manifest.FilePath, err)
+		}
+		base = combinations
+	}
+
+	if len(chart.ValuesFiles) == 0 {
+		return base, nil
+	}
+
+	if len(base) == 0 {
+		base = []HelmValuesCombination{{Name: "chart defaults"}}
+	}
+	var matrix []HelmValuesCombination
+	for _, combination := range base {
+		for _, valuesFile := range chart.ValuesFiles {
+			matrix = append(matrix, HelmValuesCombination{
+				Name:        combination.Name + " + " + displayPath(valuesFile),
+				Values:      combination.Values,
+				ValuesFiles: []string{valuesFile},
+			})
+		}
+	}
+	return matrix, nil
+}
+
+func (m *HelmChartManifest) hasValues() bool {
+	return m.values != nil || len(m.optionalValues) > 0
+}
+
+// valuesCombinations renders the manifest's values and optionalValues
+func (m *HelmChartManifest) valuesCombinations(configValues map[string]string) ([]HelmValuesCombination, error) {
+	values, err := renderValuesMap(m.values, configValues)
+	if err != nil {
+		return nil, fmt.Errorf("spec.values: %w", err)
+	}
+
+	type optional struct {
+		values         map[string]interface{}
+		recursiveMerge bool
+		active         bool
+		templated      bool
+	}
+	optionals := make([]optional, len(m.optionalValues))
+	for i, entry := range m.optionalValues {
+		when, err := renderKotsTemplate(entry.when, configValues)
+		if err != nil {
+			return nil, fmt.Errorf("spec.optionalValues[%d].when: %w", i, err)
+		}
+		// KOTS skips entries whose when does not parse as a bool
+		active, _ := strconv.ParseBool(strings.TrimSpace(when))
+
+		optionalValues, err := renderValuesMap(entry.values, configValues)
+		if err != nil {
+			return nil, fmt.Errorf("spec.optionalValues[%d].values: %w", i, err)
+		}
+		optionals[i] = optional{
+			values:         optionalValues,
+			recursiveMerge: entry.recursiveMerge,
+			active:         active,
+			templated:      hasKotsTemplate(entry.when),
+		}
+	}
+
+	// apply merges the optionalValues that are active, plus extra, in manifest order
+	apply := func(extra int) map[string]interface{} {
+		merged := copyValues(values)
+		for i, entry := range optionals {
+			if !entry.active && i != extra {
+				continue
+			}
+			if entry.recursiveMerge {
+				merged = mergeValues(merged, entry.values)
+			} else {
+				// Without recursiveMerge, top-level keys replace those in values
+				for key, value := range entry.values {
+					merged[key] = value
+				}
+			}
+		}
+		return merged
+	}
+
+	combinations := []HelmValuesCombination{{Name: "HelmChart values", Values: apply(-1)}}
+	for i, entry := range optionals {
+		// Entries that are always off can never be applied, so they are not linted
+		if entry.active || !entry.templated {
+			continue
+		}
+		combinations = append(combinations, HelmValuesCombination{
+			Name:   fmt.Sprintf("HelmChart values + optionalValues[%d]", i),
+			Values: apply(i),
+		})
+	}
+	return combinations, nil
+}
+
+// LintChartWithValues runs helm lint on a chart once per values combination and
+// merges the results. Each message records the combinations that produced it in
+// LintMessage.Values, unless every combination produced it. The chart fails if any
+// combination fails. With no combinations it is the same as LintChart.
+func LintChartWithValues(ctx context.Context, chartPath string, helmVersion string, combinations []HelmValuesCombination, opts ...LintOption) (*LintResult, error) {
+	if len(combinations) == 0 {
+		return LintChart(ctx, chartPath, helmVersion, opts...)
+	}
+
+	options := newLintOptions(opts)
+	resolver := options.resolver()
+	helmPath, err := resolver.Resolve(ctx, tools.ToolHelm, helmVersion)
+	if err != nil {
+		return nil, fmt.Errorf("resolving helm: %w", err)
+	}
+
+	if _, err := os.Stat(chartPath); err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("chart path does not exist: %s", chartPath)
+		}
+		return nil, fmt.Errorf("failed to access chart path: %w", err)
+	}
+
+	result := &LintResult{Success: true}
+	messages := newMessageSet()
+	suppressed := newMessageSet()
+
+	for _, combination := range combinations {
+		combinationResult, combinationSuppressed, err := lintChartCombination(ctx, helmPath, chartPath, combination, options.Suppressor)
+		if err != nil {
+			return nil, fmt.Errorf("values %s: %w", combination.Name, err)
+		}
+		if !combinationResult.Success {
+			result.Success = false
+		}
+		messages.add(combination.Name, combinationResult.Messages)
+		suppressed.add(combination.Name, combinationSuppressed)
+	}
+
+	result.Messages = messages.list(len(combinations), func(msg *LintMessage, producedBy []string) {
+		msg.Values = strings.Join(producedBy, "; ")
+	})
+	// A finding suppressed in several combinations is counted once
+	result.Suppressed = len(suppressed.order)
+
+	if options.Strict {
+		applyStrict(result)
+	}
+
+	return result, nil
+}
+
+// messageSet collects the messages of several lint runs over the same resource,
+// dropping duplicates and recording which runs produced each message
+type messageSet struct {
+	order      []string
+	messages   map[string]LintMessage
+	producedBy map[string][]string
+}
+
+func newMessageSet() *messageSet {
+	return &messageSet{
+		messages:   make(map[string]LintMessage),
+		producedBy: make(map[string][]string),
+	}
+}
+
+// add records the messages produced by the named run
+func (s *messageSet) add(run string, messages []LintMessage) {
+	for _, msg := range messages {
+		key := fmt.Sprintf("%s\x00%s\x00%d\x00%s\x00%s", msg.Severity, msg.Path, msg.Line, msg.Message, msg.Values)
+		if _, seen := s.messages[key]; !seen {
+			s.messages[key] = msg
+			s.order = append(s.order, key)
+		}
+		s.producedBy[key] = append(s.producedBy[key], run)
+	}
+}
+
+// list returns the messages in the order they were first produced. attribute is
+// called for each message that fewer than all runs produced, with the runs that did.
+func (s *messageSet) list(runs int, attribute func(msg *LintMessage, producedBy []string)) []LintMessage {
+	var messages []LintMessage
+	for _, key := range s.order {
+		msg := s.messages[key]
+		if len(s.producedBy[key]) < runs {
+			attribute(&msg, s.producedBy[key])
+		}
+		messages = append(messages, msg)
+	}
+	return messages
+}
+
+// lintChartCombination runs helm lint with a single combination of values. It returns
+// the result without the suppressed findings, and the suppressed findings.
+func lintChartCombination(ctx context.Context, helmPath, chartPath string, combination HelmValuesCombination, suppressor *Suppressor) (*LintResult, []LintMessage, error) {
+	args := []string{"lint", chartPath}
+
+	if combination.Values != nil {
+		valuesFile, err := os.CreateTemp("", "replicated-helmchart-values-*.yaml")
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create temp file for HelmChart values: %w", err)
+		}
+		defer os.Remove(valuesFile.Name())
+
+		data, err := yaml.Marshal(combination.Values)
+		if err != nil {
+			valuesFile.Close()
+			return nil, nil, fmt.Errorf("failed to marshal HelmChart values: %w", err)
+		}
+		if _, err := valuesFile.Write(data); err != nil {
+			valuesFile.Close()
+			return nil, nil, fmt.Errorf("failed to write HelmChart values: %w", err)
+		}
+		if err := valuesFile.Close(); err != nil {
+			return nil, nil, fmt.Errorf("failed to write HelmChart values: %w", err)
+		}
+		args = append(args, "--values", valuesFile.Name())
+	}
+	for _, valuesFile := range combination.ValuesFiles {
+		args = append(args, "--values", valuesFile)
+	}
+
+	cmd := exec.CommandContext(ctx, helmPath, args...)
+	output, err := cmd.CombinedOutput()
+	outputStr := string(output)
+
+	messages, suppressed := parseHelmOutput(outputStr, chartPath, suppressor)
+	if err != nil && len(messages)+len(suppressed) == 0 {
+		return nil, nil, fmt.Errorf("helm lint failed: %w\n%s", err, outputStr)
+	}
+
+	return newParsedLintResult(err == nil, messages, suppressed), suppressed, nil
+}
+
+// DiscoverConfigSampleValues returns a sample value for every item in the KOTS Config
+// manifests matched by manifestGlobs: the item's value if set, otherwise its default,
+// otherwise empty. Templated values and defaults are left empty, since they depend on
+// the install.
+func DiscoverConfigSampleValues(manifestGlobs []string) (map[string]string, error) {
+	values := make(map[string]string)
+	seenFiles := make(map[string]bool)
+
+	for _, pattern := range manifestGlobs {
+		matches, err := GlobFiles(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("failed to expand manifest pattern %s: %w", pattern, err)
+		}
+
+		for _, path := range matches {
+			if isHiddenPath(path) || seenFiles[path] {
+				continue
+			}
+			seenFiles[path] = true
+
+			isConfig, err := hasAPIVersionKind(path, kotsAPIVersionV1Beta1, "Config")
+			if err != nil || !isConfig {
+				continue
+			}
+
+			// Skip Config manifests that fail to parse; the KOTS linter reports them
+			_ = parseConfigSampleValues(path, values)
+		}
+	}
+
+	return values, nil
+}
+
+// parseConfigSampleValues adds the sample value of each item in the Config documents
+// of a file to values
+func parseConfigSampleValues(path string, values map[string]string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var config struct {
+			APIVersion string `yaml:"apiVersion"`
+			Kind       string `yaml:"kind"`
+			Spec       struct {
+				Groups []struct {
+					Items []struct {
+						Name    string `yaml:"name"`
+						Value   string `yaml:"value"`
+						Default string `yaml:"default"`
+					} `yaml:"items"`
+				} `yaml:"groups"`
+			} `yaml:"spec"`
+		}
+
+		if err := decoder.Decode(&config); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("failed to parse YAML: %w", err)
+		}
+		if config.Kind != "Config" || config.APIVersion != kotsAPIVersionV1Beta1 {
+			continue
+		}
+
+		for _, group := range config.Spec.Groups {
+			for _, item := range group.Items {
+				if item.Name == "" {
+					continue
+				}
+				value := item.Value
+				if value == "" {
+					value = item.Default
+				}
+				if hasKotsTemplate(value) {
+					value = ""
+				}
+				values[item.Name] = value
+			}
+		}
+	}
+}
+
+// kotsTemplateDelims are the delimiter pairs KOTS renders templates with. Plain {{ }}
+// is left alone, since it is usually meant for helm.
+var kotsTemplateDelims = [][2]string{{"repl{{", "}}"}, {"{{repl", "}}"}}
+
+// hasKotsTemplate reports whether s contains a KOTS template expression
+func hasKotsTemplate(s string) bool {
+	return strings.Contains(s, "repl{{") || strings.Contains(s, "{{repl")
+}
+
+// renderKotsTemplate renders the KOTS template expressions in s with sample config
+// values. Functions that depend on the install (license fields, registry settings,
+// and so on) render as empty values.
+func renderKotsTemplate(s string, configValues map[string]string) (string, error) {
+	if !hasKotsTemplate(s) {
+		return s, nil
+	}
+
+	for _, delims := range kotsTemplateDelims {
+		tmpl, err := template.New("kots").Delims(delims[0], delims[1]).Funcs(kotsSampleFuncs(configValues)).Parse(s)
+		if err != nil {
+			return "", err
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, nil); err != nil {
+			return "", err
+		}
+		s = buf.String()
+	}
+	return s, nil
+}
+
+// kotsSampleFuncs returns sprig plus the KOTS template functions, backed by sample
+// config values
+func kotsSampleFuncs(configValues map[string]string) template.FuncMap {
+	funcs := sprig.TxtFuncMap()
+	for _, name := range kotsTemplateFuncs {
+		funcs[name] = func(...interface{}) string { return "" }
+	}
+
+	configOption := func(name string) string { return configValues[name] }
+	funcs["ConfigOption"] = configOption
+	funcs["ConfigOptionData"] = configOption
+	funcs["ConfigOptionEquals"] = func(name, value string) bool { return configValues[name] == value }
+	funcs["ConfigOptionNotEquals"] = func(name, value string) bool { return configValues[name] != value }
+	funcs["LocalImageName"] = func(image string) string { return image }
+	funcs["ReplicatedImageName"] = func(image string, args ...interface{}) string { return image }
+	funcs["Lookup"] = func(...interface{}) map[string]interface{} { return map[string]interface{}{} }
+	funcs["Namespace"] = func() string { return "default" }
+	funcs["HasLocalRegistry"] = func() bool { return false }
+	funcs["IsAirgap"] = func() bool { return false }
+	funcs["IsKurl"] = func() bool { return false }
+	funcs["IsUpgrade"] = func() bool { return false }
+	funcs["ParseBool"] = func(s string) bool {
+		b, _ := strconv.ParseBool(s)
+		return b
+	}
+	funcs["ParseInt"] = func(s string, args ...int) int64 {
+		i, _ := strconv.ParseInt(s, 10, 64)
+		return i
+	}
+	funcs["ParseUint"] = func(s string, args ...int) uint64 {
+		u, _ := strconv.ParseUint(s, 10, 64)
+		return u
+	}
+	funcs["ParseFloat"] = func(s string) float64 {
+		f, _ := strconv.ParseFloat(s, 64)
+		return f
+	}
+	funcs["ToLower"] = strings.ToLower
+	funcs["ToUpper"] = strings.ToUpper
+	funcs["TrimSpace"] = strings.TrimSpace
+	funcs["Trim"] = func(s string, args ...string) string {
+		if len(args) == 0 {
+			return strings.TrimSpace(s)
+		}
+		return strings.Trim(s, args[0])
+	}
+	funcs["Split"] = strings.Split
+	return funcs
+}
+
+// renderValuesMap renders a values mapping node, returning nil for a missing node
+func renderValuesMap(node *yaml.Node, configValues map[string]string) (map[string]interface{}, error) {
+	if node == nil {
+		return nil, nil
+	}
+	rendered, err := renderValuesNode(node, configValues)
+	if err != nil {
+		return nil, err
+	}
+	values, ok := rendered.(map[string]interface{})
+	if !ok && rendered != nil {
+		return nil, fmt.Errorf("expected a mapping, got %T", rendered)
+	}
+	return values, nil
+}
+
+// renderValuesNode converts a YAML node to plain values, rendering KOTS templates in
+// scalars. Like KOTS, which renders the manifest before parsing it, the output of an
+// unquoted template is parsed as YAML, so repl{{ ConfigOption "replicas" }} can
+// produce a number; quoted templates always produce strings.
+func renderValuesNode(node *yaml.Node, configValues map[string]string) (interface{}, error) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil, nil
+		}
+		return renderValuesNode(node.Content[0], configValues)
+
+	case yaml.AliasNode:
+		return renderValuesNode(node.Alias, configValues)
+
+	case yaml.MappingNode:
+		values := make(map[string]interface{}, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, err := renderKotsTemplate(node.Content[i].Value, configValues)
+			if err != nil {
+				return nil, err
+			}
+			value, err := renderValuesNode(node.Content[i+1], configValues)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			values[key] = value
+		}
+		return values, nil
+
+	case yaml.SequenceNode:
+		values := make([]interface{}, 0, len(node.Content))
+		for i, item := range node.Content {
+			value, err := renderValuesNode(item, configValues)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			values = append(values, value)
+		}
+		return values, nil
+
+	case yaml.ScalarNode:
+		if !hasKotsTemplate(node.Value) {
+			var value interface{}
+			if err := node.Decode(&value); err != nil {
+				return nil, err
+			}
+			return value, nil
+		}
+
+		rendered, err := renderKotsTemplate(node.Value, configValues)
+		if err != nil {
+			return nil, err
+		}
+		if node.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+			return rendered, nil
+		}
+		var value interface{}
+		if err := yaml.Unmarshal([]byte(rendered), &value); err != nil {
+			return rendered, nil
+		}
+		return value, nil
+	}
+
+	return nil, nil
+}
+
+// copyValues returns a deep copy of values, so merging never changes the original
+func copyValues(values map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		if nested, ok := value.(map[string]interface{}); ok {
+			value = copyValues(nested)
+		}
+		copied[key] = value
+	}
+	return copied
+}
+
+// mergeValues merges overrides into values recursively, with overrides winning
+func mergeValues(values, overrides map[string]interface{}) map[string]interface{} {
+	for key, override := range overrides {
+		overrideMap, overrideIsMap := override.(map[string]interface{})
+		existingMap, existingIsMap := values[key].(map[string]interface{})
+		if overrideIsMap && existingIsMap {
+			values[key] = mergeValues(existingMap, overrideMap)
+			continue
+		}
+		if overrideIsMap {
+			override = copyValues(overrideMap)
+		}
+		values[key] = override
+	}
+	return values
+}
+
+// displayPath returns path relative to the current directory when it is inside it
+func displayPath(path string) string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(cwd, absPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return rel
+}
@@ -0,0 +1,211 @@
+package lint2
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeHelmChartManifest(t *testing.T, dir, content string) map[string]*HelmChartManifest {
+	t.Helper()
+	path := writeTestFile(t, dir, "helmchart.yaml", content)
+	manifests, err := DiscoverHelmChartManifests([]string{path})
+	if err != nil {
+		t.Fatalf("DiscoverHelmChartManifests() error = %v", err)
+	}
+	return manifests
+}
+
+func TestHelmValuesMatrix(t *testing.T) {
+	manifests := writeHelmChartManifest(t, t.TempDir(), `apiVersion: kots.io/v1beta2
+kind: HelmChart
+metadata:
+  name: app
+spec:
+  chart:
+    name: app
+    chartVersion: 1.0.0
+  values:
+    replicas: repl{{ ConfigOption "replicas" }}
+    hostname: 'repl{{ ConfigOption "port" }}'
+    image:
+      repository: nginx
+      tag: repl{{ ConfigOption "tag" | default "latest" }}
+    helmTemplate: "{{ .Release.Name }}"
+  optionalValues:
+    - when: 'repl{{ ConfigOptionEquals "db_type" "embedded" }}'
+      recursiveMerge: true
+      values:
+        image:
+          pullPolicy: Always
+    - when: 'repl{{ ConfigOptionEquals "db_type" "external" }}'
+      values:
+        image:
+          repository: postgres
+    - when: "false"
+      values:
+        unused: true
+`)
+
+	configValues := map[string]string{"replicas": "3", "port": "8080", "db_type": "embedded"}
+	chart := ChartWithMetadata{Path: "/charts/app", Name: "app", Version: "1.0.0"}
+
+	matrix, err := HelmValuesMatrix(chart, manifests, configValues)
+	if err != nil {
+		t.Fatalf("HelmValuesMatrix() error = %v", err)
+	}
+
+	want := []HelmValuesCombination{
+		{
+			Name: "HelmChart values",
+			Values: map[string]interface{}{
+				"replicas":     3,
+				"hostname":     "8080",
+				"image":        map[string]interface{}{"repository": "nginx", "tag": "latest", "pullPolicy": "Always"},
+				"helmTemplate": "{{ .Release.Name }}",
+			},
+		},
+		{
+			// optionalValues[1] replaces the top-level image key since recursiveMerge is false
+			Name: "HelmChart values + optionalValues[1]",
+			Values: map[string]interface{}{
+				"replicas":     3,
+				"hostname":     "8080",
+				"image":        map[string]interface{}{"repository": "postgres"},
+				"helmTemplate": "{{ .Release.Name }}",
+			},
+		},
+	}
+	if !reflect.DeepEqual(matrix, want) {
+		t.Errorf("HelmValuesMatrix() =\n%#v\nwant\n%#v", matrix, want)
+	}
+}
+
+func TestHelmValuesMatrix_ValuesFiles(t *testing.T) {
+	dir := t.TempDir()
+	manifests := writeHelmChartManifest(t, dir, `apiVersion: kots.io/v1beta2
+kind: HelmChart
+spec:
+  chart:
+    name: app
+    chartVersion: 1.0.0
+  values:
+    replicas: 2
+`)
+
+	t.Chdir(dir)
+	chart := ChartWithMetadata{
+		Path: filepath.Join(dir, "chart"), Name: "app", Version: "1.0.0",
+		ValuesFiles: []string{filepath.Join(dir, "ci", "ha.yaml"), filepath.Join(dir, "ci", "airgap.yaml")},
+	}
+
+	matrix, err := HelmValuesMatrix(chart, manifests, nil)
+	if err != nil {
+		t.Fatalf("HelmValuesMatrix() error = %v", err)
+	}
+
+	var names []string
+	for _, combination := range matrix {
+		names = append(names, combination.Name)
+		if combination.Values["replicas"] != 2 {
+			t.Errorf("%s: expected HelmChart values to be applied, got %v", combination.Name, combination.Values)
+		}
+	}
+	wantNames := []string{
+		"HelmChart values + " + filepath.Join("ci", "ha.yaml"),
+		"HelmChart values + " + filepath.Join("ci", "airgap.yaml"),
+	}
+	if !reflect.DeepEqual(names, wantNames) {
+		t.Errorf("names = %v, want %v", names, wantNames)
+	}
+
+	// Without a HelmChart manifest, values files are applied to the chart defaults
+	chart.Name = "other"
+	matrix, err = HelmValuesMatrix(chart, manifests, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matrix) != 2 || matrix[0].Values != nil || matrix[0].Name != "chart defaults + "+filepath.Join("ci", "ha.yaml") {
+		t.Errorf("unexpected matrix without HelmChart values: %+v", matrix)
+	}
+
+	// Charts with neither are linted with their defaults only
+	chart.ValuesFiles = nil
+	if matrix, err := HelmValuesMatrix(chart, manifests, nil); err != nil || matrix != nil {
+		t.Errorf("HelmValuesMatrix() = %+v, %v; want nil, nil", matrix, err)
+	}
+}
+
+func TestHelmValuesMatrix_RenderError(t *testing.T) {
+	manifests := writeHelmChartManifest(t, t.TempDir(), `apiVersion: kots.io/v1beta2
+kind: HelmChart
+spec:
+  chart:
+    name: app
+    chartVersion: 1.0.0
+  values:
+    replicas: repl{{ ConfigOption "replicas" }
+`)
+
+	chart := ChartWithMetadata{Name: "app", Version: "1.0.0"}
+	if _, err := HelmValuesMatrix(chart, manifests, nil); err == nil {
+		t.Error("expected an error for an unterminated template")
+	}
+}
+
+func TestRenderKotsTemplate(t *testing.T) {
+	configValues := map[string]string{"enabled": "1", "name": "App"}
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: `repl{{ ConfigOption "name" }}`, want: "App"},
+		{in: `{{repl ConfigOption "name" | ToLower }}`, want: "app"},
+		{in: `repl{{ ConfigOptionEquals "enabled" "1" }}`, want: "true"},
+		{in: `repl{{ ParseBool (ConfigOption "enabled") }}`, want: "true"},
+		{in: `repl{{ LicenseFieldValue "customerName" }}`, want: ""},
+		{in: `{{ .Values.name }} repl{{ Namespace }}`, want: "{{ .Values.name }} default"},
+	}
+	for _, tt := range tests {
+		got, err := renderKotsTemplate(tt.in, configValues)
+		if err != nil {
+			t.Errorf("renderKotsTemplate(%q) error = %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("renderKotsTemplate(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDiscoverConfigSampleValues(t *testing.T) {
+	dir := t.TempDir()
+	content := `apiVersion: kots.io/v1beta1
+kind: Config
+spec:
+  groups:
+    - name: settings
+      items:
+        - name: replicas
+          type: text
+          default: "2"
+        - name: hostname
+          type: text
+          value: example.com
+          default: localhost
+        - name: password
+          type: password
+          value: repl{{ RandomString 16 }}
+`
+	writeTestFile(t, dir, "config.yaml", content)
+
+	values, err := DiscoverConfigSampleValues([]string{filepath.Join(dir, "*.yaml")})
+	if err != nil {
+		t.Fatalf("DiscoverConfigSampleValues() error = %v", err)
+	}
+	want := map[string]string{"replicas": "2", "hostname": "example.com", "password": ""}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("DiscoverConfigSampleValues() = %v, want %v", values, want)
+	}
+}
@@ -17,6 +17,13 @@ type HelmChartManifest struct {
 	ChartVersion  string                 // spec.chart.chartVersion - must match Chart.yaml version
 	BuilderValues map[string]interface{} // spec.builder - values for air gap bundle rendering (can be nil/empty)
 	FilePath      string                 // Source file path for error reporting
+
+	values         *yaml.Node                // spec.values, unrendered (nil if not set)
+	optionalValues []helmChartOptionalValues // spec.optionalValues, unrendered
+
+	nameLine    int  // line of the chart name, for findings about the reference
+	versionLine int  // line of the chart version
+	extension   bool // declared as an Embedded Cluster extension, which may install charts that are not packaged
 }
 
 // FindHelmChartManifest looks up a HelmChart manifest by chart name and version.
@@ -62,12 +69,21 @@ func (e *DuplicateHelmChartError) Error() string {
 //   - Files that don't contain kind: HelmChart
 //   - Hidden directories (.git, .github, etc.)
 func DiscoverHelmChartManifests(manifestGlobs []string) (map[string]*HelmChartManifest, error) {
-	if len(manifestGlobs) == 0 {
-		// Return empty map - validation layer will handle this
-		return make(map[string]*HelmChartManifest), nil
+	manifests, err := discoverHelmChartManifests(manifestGlobs)
+	if err != nil {
+		return nil, err
+	}
+	helmCharts, duplicates := indexHelmChartManifests(manifests)
+	if len(duplicates) > 0 {
+		return nil, duplicates[0]
 	}
+	return helmCharts, nil
+}
 
-	helmCharts := make(map[string]*HelmChartManifest)
+// discoverHelmChartManifests returns the HelmChart custom resources in the manifests,
+// followed by the charts declared as Embedded Cluster extensions, in discovery order
+func discoverHelmChartManifests(manifestGlobs []string) ([]*HelmChartManifest, error) {
+	var manifests []*HelmChartManifest
 	seenFiles := make(map[string]bool) // Global deduplication across all patterns
 
 	for _, pattern := range manifestGlobs {
@@ -106,44 +122,35 @@ func DiscoverHelmChartManifests(manifestGlobs []string) (map[string]*HelmChartMa
 				// Skip malformed HelmCharts (missing required fields, etc.)
 				continue
 			}
-
-			// Check for duplicates
-			key := fmt.Sprintf("%s:%s", manifest.Name, manifest.ChartVersion)
-			if existing, found := helmCharts[key]; found {
-				return nil, &DuplicateHelmChartError{
-					ChartKey:   key,
-					FirstFile:  existing.FilePath,
-					SecondFile: manifest.FilePath,
-				}
-			}
-
-			helmCharts[key] = manifest
+			manifests = append(manifests, manifest)
 		}
 	}
 
-	// Discover EC Config helm charts and merge
-	ecHelmCharts, err := DiscoverECConfigHelmCharts(manifestGlobs)
+	ecHelmCharts, err := discoverECConfigHelmCharts(manifestGlobs)
 	if err != nil {
 		return nil, err
 	}
-	for key, manifest := range ecHelmCharts {
+	return append(manifests, ecHelmCharts...), nil
+}
+
+// indexHelmChartManifests keys manifests by "name:chartVersion". The first manifest
+// for a key wins; every later one is returned as a duplicate.
+func indexHelmChartManifests(manifests []*HelmChartManifest) (map[string]*HelmChartManifest, []*DuplicateHelmChartError) {
+	helmCharts := make(map[string]*HelmChartManifest)
+	var duplicates []*DuplicateHelmChartError
+	for _, manifest := range manifests {
+		key := fmt.Sprintf("%s:%s", manifest.Name, manifest.ChartVersion)
 		if existing, found := helmCharts[key]; found {
-			return nil, &DuplicateHelmChartError{
+			duplicates = append(duplicates, &DuplicateHelmChartError{
 				ChartKey:   key,
 				FirstFile:  existing.FilePath,
 				SecondFile: manifest.FilePath,
-			}
+			})
+			continue
 		}
 		helmCharts[key] = manifest
 	}
-
-	// Return empty map if no HelmCharts found - validation layer will check if charts need HelmCharts
-	// Discovery is lenient - validation happens later in the flow
-	if len(helmCharts) == 0 {
-		return make(map[string]*HelmChartManifest), nil
-	}
-
-	return helmCharts, nil
+	return helmCharts, duplicates
 }
 
 // isHelmChartManifest checks if a YAML file contains a HelmChart kind.
@@ -162,11 +169,21 @@ func isHelmChartManifest(path string) (bool, error) {
 //   - Files that don't contain an EC Config with extensions.helmCharts
 //   - Hidden directories (.git, .github, etc.)
 func DiscoverECConfigHelmCharts(manifestGlobs []string) (map[string]*HelmChartManifest, error) {
-	if len(manifestGlobs) == 0 {
-		return make(map[string]*HelmChartManifest), nil
+	manifests, err := discoverECConfigHelmCharts(manifestGlobs)
+	if err != nil {
+		return nil, err
 	}
+	helmCharts, duplicates := indexHelmChartManifests(manifests)
+	if len(duplicates) > 0 {
+		return nil, duplicates[0]
+	}
+	return helmCharts, nil
+}
 
-	helmCharts := make(map[string]*HelmChartManifest)
+// discoverECConfigHelmCharts returns the charts declared by the Embedded Cluster
+// Configs in the manifests, in discovery order
+func discoverECConfigHelmCharts(manifestGlobs []string) ([]*HelmChartManifest, error) {
+	var manifests []*HelmChartManifest
 	seenFiles := make(map[string]bool)
 
 	for _, pattern := range manifestGlobs {
@@ -191,30 +208,15 @@ func DiscoverECConfigHelmCharts(manifestGlobs []string) (map[string]*HelmChartMa
 			}
 
 			// Parse EC Config helm charts
-			manifests, err := parseECConfigHelmCharts(path)
+			fileManifests, err := parseECConfigHelmCharts(path)
 			if err != nil {
 				continue
 			}
-
-			for _, manifest := range manifests {
-				key := fmt.Sprintf("%s:%s", manifest.Name, manifest.ChartVersion)
-				if existing, found := helmCharts[key]; found {
-					return nil, &DuplicateHelmChartError{
-						ChartKey:   key,
-						FirstFile:  existing.FilePath,
-						SecondFile: manifest.FilePath,
-					}
-				}
-				helmCharts[key] = manifest
-			}
+			manifests = append(manifests, fileManifests...)
 		}
 	}
 
-	if len(helmCharts) == 0 {
-		return make(map[string]*HelmChartManifest), nil
-	}
-
-	return helmCharts, nil
+	return manifests, nil
 }
 
 // parseHelmChartManifest parses a HelmChart manifest and extracts the fields needed for preflight rendering.
@@ -241,10 +243,16 @@ func parseHelmChartManifest(path string) (*HelmChartManifest, error) {
 			Kind       string `yaml:"kind"`
 			Spec       struct {
 				Chart struct {
-					Name         string `yaml:"name"`
-					ChartVersion string `yaml:"chartVersion"`
+					Name         yaml.Node `yaml:"name"`
+					ChartVersion yaml.Node `yaml:"chartVersion"`
 				} `yaml:"chart"`
-				Builder map[string]interface{} `yaml:"builder"`
+				Builder        map[string]interface{} `yaml:"builder"`
+				Values         yaml.Node              `yaml:"values"`
+				OptionalValues []struct {
+					When           string    `yaml:"when"`
+					RecursiveMerge bool      `yaml:"recursiveMerge"`
+					Values         yaml.Node `yaml:"values"`
+				} `yaml:"optionalValues"`
 			} `yaml:"spec"`
 		}
 
@@ -258,10 +266,11 @@ func parseHelmChartManifest(path string) (*HelmChartManifest, error) {
 
 		if helmChart.Kind == "HelmChart" {
 			// Validate required fields
-			if helmChart.Spec.Chart.Name == "" {
+			chart := helmChart.Spec.Chart
+			if chart.Name.Value == "" {
 				return nil, fmt.Errorf("spec.chart.name is required but not found")
 			}
-			if helmChart.Spec.Chart.ChartVersion == "" {
+			if chart.ChartVersion.Value == "" {
 				return nil, fmt.Errorf("spec.chart.chartVersion is required but not found")
 			}
 
@@ -269,12 +278,28 @@ func parseHelmChartManifest(path string) (*HelmChartManifest, error) {
 			// The preflight linter will validate apiVersion when it processes the HelmChart.
 			// This allows future apiVersions to work without code changes.
 
-			return &HelmChartManifest{
-				Name:          helmChart.Spec.Chart.Name,
-				ChartVersion:  helmChart.Spec.Chart.ChartVersion,
+			manifest := &HelmChartManifest{
+				Name:          chart.Name.Value,
+				ChartVersion:  chart.ChartVersion.Value,
 				BuilderValues: helmChart.Spec.Builder, // Can be nil or empty - that's valid
 				FilePath:      path,
-			}, nil
+				nameLine:      chart.Name.Line,
+				versionLine:   chart.ChartVersion.Line,
+			}
+
+			// Values are kept unrendered; HelmValuesMatrix renders them with sample config values
+			if helmChart.Spec.Values.Kind != 0 {
+				manifest.values = &helmChart.Spec.Values
+			}
+			for _, optional := range helmChart.Spec.OptionalValues {
+				entry := helmChartOptionalValues{when: optional.When, recursiveMerge: optional.RecursiveMerge}
+				if optional.Values.Kind != 0 {
+					entry.values = &optional.Values
+				}
+				manifest.optionalValues = append(manifest.optionalValues, entry)
+			}
+
+			return manifest, nil
 		}
 	}
 }
@@ -299,8 +324,8 @@ func parseECConfigHelmCharts(path string) ([]*HelmChartManifest, error) {
 				Extensions struct {
 					HelmCharts []struct {
 						Chart struct {
-							Name         string `yaml:"name"`
-							ChartVersion string `yaml:"chartVersion"`
+							Name         yaml.Node `yaml:"name"`
+							ChartVersion yaml.Node `yaml:"chartVersion"`
 						} `yaml:"chart"`
 					} `yaml:"helmCharts"`
 				} `yaml:"extensions"`
@@ -320,13 +345,16 @@ func parseECConfigHelmCharts(path string) ([]*HelmChartManifest, error) {
 		}
 
 		for _, hc := range ecConfig.Spec.Extensions.HelmCharts {
-			if hc.Chart.Name == "" || hc.Chart.ChartVersion == "" {
+			if hc.Chart.Name.Value == "" || hc.Chart.ChartVersion.Value == "" {
 				continue
 			}
 			manifests = append(manifests, &HelmChartManifest{
-				Name:         hc.Chart.Name,
-				ChartVersion: hc.Chart.ChartVersion,
+				Name:         hc.Chart.Name.Value,
+				ChartVersion: hc.Chart.ChartVersion.Value,
 				FilePath:     path,
+				nameLine:     hc.Chart.Name.Line,
+				versionLine:  hc.Chart.ChartVersion.Line,
+				extension:    true,
 			})
 		}
 	}
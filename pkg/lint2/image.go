@@ -0,0 +1,211 @@
+package lint2
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/replicatedhq/replicated/pkg/imageextract"
+	"github.com/replicatedhq/replicated/pkg/tools"
+)
+
+// imageRuleRender is reported when a chart cannot be rendered to extract its images
+const imageRuleRender = "image-render"
+
+// Rules checked by the image linter in addition to the imageextract warnings
+const (
+	imageRuleRequireDigest     = "require-digest"
+	imageRuleAllowedRegistries = "allowed-registries"
+	imageRuleProxyRegistry     = "proxy-registry"
+)
+
+// defaultImageRuleLevels are the severities used for rules that are not set in the
+// config. allowed-registries and proxy-registry only apply once their setting is configured.
+var defaultImageRuleLevels = map[string]string{
+	string(imageextract.WarningLatestTag):     "warn",
+	string(imageextract.WarningNoTag):         "warn",
+	string(imageextract.WarningInsecure):      "warn",
+	string(imageextract.WarningUnqualified):   "warn",
+	string(imageextract.WarningInvalidSyntax): "warn",
+	imageRuleRequireDigest:                    "off",
+	imageRuleAllowedRegistries:                "error",
+	imageRuleProxyRegistry:                    "error",
+}
+
+// imageRuleSeverities maps config levels to message severities
+var imageRuleSeverities = map[string]string{
+	"error": "ERROR",
+	"warn":  "WARNING",
+	"info":  "INFO",
+}
+
+// imageWarningMessages describe each imageextract warning type
+var imageWarningMessages = map[imageextract.WarningType]string{
+	imageextract.WarningLatestTag:   "uses the latest tag, which is not recommended for production",
+	imageextract.WarningNoTag:       "has no tag",
+	imageextract.WarningInsecure:    "uses an insecure HTTP registry",
+	imageextract.WarningUnqualified: "does not name a registry",
+}
+
+// ImagePolicy holds the image linter rules and their severities
+type ImagePolicy struct {
+	severities        map[string]string // rule ID to message severity; rules that are off are absent
+	allowedRegistries []string
+	proxyHostname     string
+}
+
+// NewImagePolicy builds the image policy from the image linter config. The config is
+// validated when it is parsed.
+func NewImagePolicy(config tools.ImageLinterConfig) *ImagePolicy {
+	policy := &ImagePolicy{
+		severities:        make(map[string]string),
+		allowedRegistries: config.AllowedRegistries,
+		proxyHostname:     config.ProxyHostname,
+	}
+
+	for _, rule := range tools.ImageLintRules {
+		level, ok := config.Rules[rule]
+		if !ok {
+			level = defaultImageRuleLevels[rule]
+		}
+		if severity, ok := imageRuleSeverities[level]; ok {
+			policy.severities[rule] = severity
+		}
+	}
+	if len(config.AllowedRegistries) == 0 {
+		delete(policy.severities, imageRuleAllowedRegistries)
+	}
+	if config.ProxyHostname == "" {
+		delete(policy.severities, imageRuleProxyRegistry)
+	}
+
+	return policy
+}
+
+// LintChartImages renders a chart with each values combination (see HelmValuesMatrix)
+// and checks every image the rendered objects reference against the image policy.
+// Messages produced by only some combinations name those combinations.
+func LintChartImages(ctx context.Context, chartPath string, combinations []HelmValuesCombination, policy *ImagePolicy, opts ...LintOption) (*LintResult, error) {
+	if _, err := os.Stat(chartPath); err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("chart path does not exist: %s", chartPath)
+		}
+		return nil, fmt.Errorf("failed to access chart path: %w", err)
+	}
+
+	if len(combinations) == 0 {
+		combinations = []HelmValuesCombination{{Name: "chart defaults"}}
+	}
+
+	extractor := imageextract.NewExtractor()
+	byCombination := newMessageSet()
+	for _, combination := range combinations {
+		manifests, err := renderChartForKubeVersion(chartPath, combination, "")
+		if err != nil {
+			byCombination.add(combination.Name, []LintMessage{{
+				Severity: "ERROR",
+				Message:  fmt.Sprintf("failed to render chart: %v", err),
+				Rule:     imageRuleRender,
+			}})
+			continue
+		}
+
+		var messages []LintMessage
+		for _, manifest := range manifests {
+			extracted, err := extractor.ExtractFromManifests(ctx, []byte(manifest.Content), imageextract.Options{})
+			if err != nil {
+				return nil, err
+			}
+			name := ""
+			if objects, err := decodePolicyObjects([]byte(manifest.Content), manifest.Source, false); err == nil && len(objects) == 1 {
+				name = kubeObjectName(objects[0].object)
+			}
+			for _, msg := range policy.check(extracted) {
+				msg.Path = manifest.Source
+				if name != "" {
+					msg.Message = fmt.Sprintf("%s: %s", name, msg.Message)
+				}
+				messages = append(messages, msg)
+			}
+		}
+		byCombination.add(combination.Name, messages)
+	}
+
+	result := &LintResult{
+		Messages: byCombination.list(len(combinations), func(msg *LintMessage, producedBy []string) {
+			msg.Values = strings.Join(producedBy, "; ")
+		}),
+	}
+	result.Success = !hasErrorMessage(result.Messages)
+
+	// Messages point at the template that produced each object, relative to the chart
+	applyLintOptions(newLintOptions(opts), chartPath, result, true)
+
+	return result, nil
+}
+
+// check reports the policy violations of the images extracted from a manifest, in
+// the order the images appear and then in rule order
+func (p *ImagePolicy) check(extracted *imageextract.Result) []LintMessage {
+	warnings := make(map[string][]imageextract.Warning)
+	for _, warning := range extracted.Warnings {
+		warnings[warning.Image] = append(warnings[warning.Image], warning)
+	}
+
+	var messages []LintMessage
+	for _, img := range extracted.Images {
+		found := make(map[string]string)
+		for _, warning := range warnings[img.Raw] {
+			message, ok := imageWarningMessages[warning.Type]
+			if !ok {
+				message = warning.Message
+			}
+			found[string(warning.Type)] = message
+		}
+
+		if img.Repository == "" {
+			found[string(imageextract.WarningInvalidSyntax)] = "is not a valid image reference"
+		} else {
+			if img.Digest == "" {
+				found[imageRuleRequireDigest] = "is not pinned to a digest"
+			}
+			if len(p.allowedRegistries) > 0 && !imageFromRegistry(img, p.allowedRegistries) {
+				found[imageRuleAllowedRegistries] = fmt.Sprintf("is not from an allowed registry (%s)", strings.Join(p.allowedRegistries, ", "))
+			}
+			if p.proxyHostname != "" && img.Registry != p.proxyHostname {
+				found[imageRuleProxyRegistry] = fmt.Sprintf("is not pulled through the proxy registry %s", p.proxyHostname)
+			}
+		}
+
+		for _, rule := range tools.ImageLintRules {
+			message, ok := found[rule]
+			if !ok {
+				continue
+			}
+			severity, ok := p.severities[rule]
+			if !ok {
+				continue
+			}
+			messages = append(messages, LintMessage{
+				Severity: severity,
+				Message:  fmt.Sprintf("image %q %s", img.Raw, message),
+				Rule:     rule,
+			})
+		}
+	}
+	return messages
+}
+
+// imageFromRegistry reports whether an image comes from one of the registries. A
+// registry may include a path prefix, e.g. registry.example.com/team.
+func imageFromRegistry(img imageextract.ImageRef, registries []string) bool {
+	name := img.Registry + "/" + img.Repository
+	for _, registry := range registries {
+		registry = strings.TrimSuffix(registry, "/")
+		if img.Registry == registry || strings.HasPrefix(name, registry+"/") {
+			return true
+		}
+	}
+	return false
+}
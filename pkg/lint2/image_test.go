@@ -0,0 +1,116 @@
+package lint2
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/replicatedhq/replicated/pkg/imageextract"
+	"github.com/replicatedhq/replicated/pkg/tools"
+)
+
+func TestLintChartImages_Defaults(t *testing.T) {
+	policy := NewImagePolicy(tools.ImageLinterConfig{})
+
+	result, err := LintChartImages(context.Background(), filepath.Join("testdata", "charts", "images"), nil, policy)
+	if err != nil {
+		t.Fatalf("LintChartImages() error = %v", err)
+	}
+	if !result.Success {
+		t.Error("expected the imageextract warnings to not fail the chart by default")
+	}
+
+	var rules []string
+	for _, msg := range result.Messages {
+		if msg.Severity != "WARNING" || msg.Path != "templates/deployment.yaml" {
+			t.Errorf("unexpected message %+v", msg)
+		}
+		rules = append(rules, msg.Rule)
+	}
+	want := []string{"latest-tag", "no-tag", "unqualified-name"}
+	if !reflect.DeepEqual(rules, want) {
+		t.Errorf("rules = %v, want %v", rules, want)
+	}
+}
+
+func TestLintChartImages_Policy(t *testing.T) {
+	policy := NewImagePolicy(tools.ImageLinterConfig{
+		Rules: map[string]string{
+			"latest-tag":       "error",
+			"no-tag":           "off",
+			"unqualified-name": "off",
+			"require-digest":   "warn",
+		},
+		AllowedRegistries: []string{"proxy.example.com/proxy/app"},
+		ProxyHostname:     "proxy.example.com",
+	})
+	combinations := []HelmValuesCombination{
+		{Name: "chart defaults"},
+		{Name: "worker", Values: map[string]interface{}{"worker": map[string]interface{}{"enabled": true}}},
+	}
+
+	result, err := LintChartImages(context.Background(), filepath.Join("testdata", "charts", "images"), combinations, policy)
+	if err != nil {
+		t.Fatalf("LintChartImages() error = %v", err)
+	}
+	if result.Success {
+		t.Error("expected lint to fail")
+	}
+
+	type finding struct {
+		Severity, Path, Message, Rule, Values string
+	}
+	var got []finding
+	for _, msg := range result.Messages {
+		got = append(got, finding{msg.Severity, msg.Path, msg.Message, msg.Rule, msg.Values})
+	}
+	want := []finding{
+		{"ERROR", "templates/deployment.yaml", `Deployment "web": image "busybox" uses the latest tag, which is not recommended for production`, "latest-tag", ""},
+		{"WARNING", "templates/deployment.yaml", `Deployment "web": image "busybox" is not pinned to a digest`, "require-digest", ""},
+		{"ERROR", "templates/deployment.yaml", `Deployment "web": image "busybox" is not from an allowed registry (proxy.example.com/proxy/app)`, "allowed-registries", ""},
+		{"ERROR", "templates/deployment.yaml", `Deployment "web": image "busybox" is not pulled through the proxy registry proxy.example.com`, "proxy-registry", ""},
+		{"WARNING", "templates/worker.yaml", `CronJob "worker": image "docker.io/library/worker:1.0" is not pinned to a digest`, "require-digest", "worker"},
+		{"ERROR", "templates/worker.yaml", `CronJob "worker": image "docker.io/library/worker:1.0" is not from an allowed registry (proxy.example.com/proxy/app)`, "allowed-registries", "worker"},
+		{"ERROR", "templates/worker.yaml", `CronJob "worker": image "docker.io/library/worker:1.0" is not pulled through the proxy registry proxy.example.com`, "proxy-registry", "worker"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("messages =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestLintChartImages_RenderError(t *testing.T) {
+	combinations := []HelmValuesCombination{
+		{Name: "missing file", ValuesFiles: []string{filepath.Join(t.TempDir(), "missing.yaml")}},
+	}
+
+	result, err := LintChartImages(context.Background(), filepath.Join("testdata", "charts", "images"), combinations, NewImagePolicy(tools.ImageLinterConfig{}))
+	if err != nil {
+		t.Fatalf("LintChartImages() error = %v", err)
+	}
+	if result.Success || len(result.Messages) != 1 || result.Messages[0].Rule != "image-render" {
+		t.Errorf("expected a single image-render error, got %+v", result)
+	}
+}
+
+func TestImageFromRegistry(t *testing.T) {
+	tests := []struct {
+		registries []string
+		registry   string
+		repository string
+		want       bool
+	}{
+		{[]string{"registry.example.com"}, "registry.example.com", "team/app", true},
+		{[]string{"registry.example.com/team"}, "registry.example.com", "team/app", true},
+		{[]string{"registry.example.com/team/"}, "registry.example.com", "team/app", true},
+		{[]string{"registry.example.com/team"}, "registry.example.com", "teamwork/app", false},
+		{[]string{"registry.example.com"}, "docker.io", "library/nginx", false},
+		{[]string{"docker.io"}, "docker.io", "library/nginx", true},
+	}
+	for _, tt := range tests {
+		img := imageextract.ImageRef{Registry: tt.registry, Repository: tt.repository}
+		if got := imageFromRegistry(img, tt.registries); got != tt.want {
+			t.Errorf("imageFromRegistry(%s/%s, %v) = %v, want %v", tt.registry, tt.repository, tt.registries, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,604 @@
+package lint2
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// KOTS API versions recognized by the KOTS linter
+const (
+	kotsAPIVersionV1Beta1 = "kots.io/v1beta1"
+	kotsAPIVersionV1Beta2 = "kots.io/v1beta2"
+	veleroAPIVersionV1    = "velero.io/v1"
+)
+
+// kotsLintIssue is a single finding from the KOTS linter. It implements LintIssue
+// so findings can be converted with the same helpers used by the other linters.
+type kotsLintIssue struct {
+	Rule    string
+	Line    int
+	Column  int
+	Message string
+	Field   string
+}
+
+func (i kotsLintIssue) GetLine() int       { return i.Line }
+func (i kotsLintIssue) GetColumn() int     { return i.Column }
+func (i kotsLintIssue) GetMessage() string { return i.Message }
+func (i kotsLintIssue) GetField() string   { return i.Field }
+func (i kotsLintIssue) GetRule() string    { return i.Rule }
+
+// KotsFileResult contains the KOTS lint results for a single manifest file
+type KotsFileResult struct {
+	Path       string
+	Success    bool
+	Messages   []LintMessage
+	Suppressed int
+}
+
+// kotsDocument is a single KOTS kind parsed from a manifest file
+type kotsDocument struct {
+	APIVersion string
+	Kind       string
+	Root       *yaml.Node // mapping node of the document
+}
+
+// kotsFile holds the KOTS documents found in a single manifest file and the issues
+// collected while parsing and linting them.
+type kotsFile struct {
+	Path   string
+	Docs   []kotsDocument
+	Result FileLintResult[kotsLintIssue]
+}
+
+func (f *kotsFile) addError(rule string, node *yaml.Node, field, format string, args ...interface{}) {
+	f.Result.Errors = append(f.Result.Errors, newKotsIssue(rule, node, field, format, args...))
+}
+
+func (f *kotsFile) addWarning(rule string, node *yaml.Node, field, format string, args ...interface{}) {
+	f.Result.Warnings = append(f.Result.Warnings, newKotsIssue(rule, node, field, format, args...))
+}
+
+func newKotsIssue(rule string, node *yaml.Node, field, format string, args ...interface{}) kotsLintIssue {
+	issue := kotsLintIssue{
+		Rule:    rule,
+		Message: fmt.Sprintf(format, args...),
+		Field:   field,
+	}
+	if node != nil {
+		issue.Line = node.Line
+		issue.Column = node.Column
+	}
+	return issue
+}
+
+// IsKotsKind reports whether an apiVersion/kind pair is one of the kinds checked by the KOTS linter
+func IsKotsKind(apiVersion, kind string) bool {
+	switch kind {
+	case "Application", "Config", "LintConfig", "Identity":
+		return apiVersion == kotsAPIVersionV1Beta1
+	case "HelmChart":
+		return apiVersion == kotsAPIVersionV1Beta1 || apiVersion == kotsAPIVersionV1Beta2
+	case "Backup":
+		return apiVersion == veleroAPIVersionV1
+	}
+	return false
+}
+
+// LintKots lints the KOTS custom resources (Application, Config, HelmChart, LintConfig,
+// Backup and Identity) found in the given manifest files. Unlike the other linters this
+// runs entirely in-process; no tool binary is downloaded.
+//
+// Checks performed:
+//   - Structural validity of each kind (required fields, value types)
+//   - Config group and item references made by ConfigOption* template functions
+//   - Template function names used in repl{{ }} and {{repl }} expressions
+//   - HelmChart spec.chart.name/chartVersion matching a configured chart
+//
+// Files that contain no KOTS kinds are skipped and do not appear in the results.
+// charts may be empty, in which case HelmChart-to-chart matching is skipped.
+func LintKots(manifestPaths []string, charts []ChartWithMetadata, opts ...LintOption) ([]KotsFileResult, error) {
+	options := newLintOptions(opts)
+
+	var files []*kotsFile
+
+	for _, path := range manifestPaths {
+		file, err := parseKotsFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if file != nil {
+			files = append(files, file)
+		}
+	}
+
+	// Config items and groups are referenced across files, so collect them first
+	configItems, haveConfig := collectConfigItemNames(files)
+
+	for _, file := range files {
+		for _, doc := range file.Docs {
+			lintKotsDocument(file, doc, charts)
+			lintTemplateExpressions(file, doc.Root, configItems, haveConfig)
+		}
+	}
+
+	results := make([]KotsFileResult, 0, len(files))
+	for _, file := range files {
+		output := &LintOutput[kotsLintIssue]{Results: []FileLintResult[kotsLintIssue]{file.Result}}
+		result := &LintResult{
+			Success:  len(file.Result.Errors) == 0,
+			Messages: convertLintOutputToMessages(output),
+		}
+		applyLintOptions(options, file.Path, result, false)
+
+		results = append(results, KotsFileResult{
+			Path:       file.Path,
+			Success:    result.Success,
+			Messages:   result.Messages,
+			Suppressed: result.Suppressed,
+		})
+	}
+
+	return results, nil
+}
+
+// DiscoverKotsManifests returns the manifest files that LintKots reports results
+// for: those containing KOTS kinds, and those that look like KOTS manifests but fail
+// to parse. Paths are returned in the order given.
+func DiscoverKotsManifests(manifestPaths []string) ([]string, error) {
+	var kotsPaths []string
+	for _, path := range manifestPaths {
+		file, err := parseKotsFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if file != nil {
+			kotsPaths = append(kotsPaths, path)
+		}
+	}
+	return kotsPaths, nil
+}
+
+// parseKotsFile reads a manifest file and returns the KOTS documents it contains.
+// Returns nil if the file contains no KOTS kinds. A file that looks like a KOTS
+// manifest but fails to parse is returned with a parse error recorded against it.
+func parseKotsFile(path string) (*kotsFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest %s: %w", path, err)
+	}
+
+	file := &kotsFile{Path: path}
+	file.Result.FilePath = path
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yaml.Node
+		err := decoder.Decode(&doc)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			// Only report parse errors for files that look like KOTS manifests;
+			// other linters own the rest of the manifest directory.
+			if looksLikeKotsManifest(data) {
+				file.addError("yaml-parse", nil, "", "failed to parse YAML: %v", err)
+				return file, nil
+			}
+			return nil, nil
+		}
+
+		if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+			continue
+		}
+		root := doc.Content[0]
+
+		apiVersion := scalarValue(mappingValue(root, "apiVersion"))
+		kind := scalarValue(mappingValue(root, "kind"))
+		if !IsKotsKind(apiVersion, kind) {
+			continue
+		}
+
+		file.Docs = append(file.Docs, kotsDocument{
+			APIVersion: apiVersion,
+			Kind:       kind,
+			Root:       root,
+		})
+	}
+
+	if len(file.Docs) == 0 {
+		return nil, nil
+	}
+
+	return file, nil
+}
+
+// kotsKindPattern matches the apiVersion line of a KOTS or velero manifest
+var kotsKindPattern = regexp.MustCompile(`(?m)^apiVersion:\s+["']?(kots\.io|velero\.io)/`)
+
+// looksLikeKotsManifest is the fallback used when a file is not valid YAML
+func looksLikeKotsManifest(data []byte) bool {
+	return kotsKindPattern.Match(data)
+}
+
+// lintKotsDocument runs the structural checks for a single KOTS document
+func lintKotsDocument(file *kotsFile, doc kotsDocument, charts []ChartWithMetadata) {
+	metadata := mappingValue(doc.Root, "metadata")
+	if scalarValue(mappingValue(metadata, "name")) == "" {
+		file.addError("metadata-name", doc.Root, "metadata.name", "%s is missing metadata.name", doc.Kind)
+	}
+
+	spec := mappingValue(doc.Root, "spec")
+	if spec == nil {
+		file.addError("spec-required", doc.Root, "spec", "%s is missing spec", doc.Kind)
+		return
+	}
+	if spec.Kind != yaml.MappingNode {
+		file.addError("spec-required", spec, "spec", "%s spec must be a mapping", doc.Kind)
+		return
+	}
+
+	switch doc.Kind {
+	case "Application":
+		lintKotsApplication(file, spec)
+	case "Config":
+		lintKotsConfig(file, spec)
+	case "HelmChart":
+		lintKotsHelmChart(file, doc, spec, charts)
+	case "LintConfig":
+		lintKotsLintConfig(file, spec)
+	case "Identity":
+		lintKotsIdentity(file, spec)
+	case "Backup":
+		// velero Backup specs are validated by velero itself; only the
+		// envelope (metadata and spec mapping) is checked here
+	}
+}
+
+// statusInformerPattern matches [namespace/]kind/name
+var statusInformerPattern = regexp.MustCompile(`^([a-z0-9-]+/)?[A-Za-z]+/[a-z0-9.-]+$`)
+
+func lintKotsApplication(file *kotsFile, spec *yaml.Node) {
+	title := mappingValue(spec, "title")
+	if scalarValue(title) == "" {
+		file.addWarning("application-title", spec, "spec.title", "Application is missing spec.title")
+	}
+
+	if informers := mappingValue(spec, "statusInformers"); informers != nil {
+		if informers.Kind != yaml.SequenceNode {
+			file.addError("application-status-informers", informers, "spec.statusInformers", "statusInformers must be a list")
+		} else {
+			for i, informer := range informers.Content {
+				value := scalarValue(informer)
+				if isTemplated(value) {
+					continue
+				}
+				if !statusInformerPattern.MatchString(value) {
+					file.addError("application-status-informers", informer, fmt.Sprintf("spec.statusInformers[%d]", i),
+						"status informer %q must be in the format [namespace/]kind/name", value)
+				}
+			}
+		}
+	}
+
+	if ports := mappingValue(spec, "ports"); ports != nil {
+		if ports.Kind != yaml.SequenceNode {
+			file.addError("application-ports", ports, "spec.ports", "ports must be a list")
+		} else {
+			for i, port := range ports.Content {
+				field := fmt.Sprintf("spec.ports[%d]", i)
+				if scalarValue(mappingValue(port, "serviceName")) == "" {
+					file.addError("application-ports", port, field+".serviceName", "port is missing serviceName")
+				}
+				if servicePort := mappingValue(port, "servicePort"); servicePort == nil {
+					file.addError("application-ports", port, field+".servicePort", "port is missing servicePort")
+				} else if !isIntScalar(servicePort) && !isTemplated(servicePort.Value) {
+					file.addError("application-ports", servicePort, field+".servicePort", "servicePort must be an integer")
+				}
+			}
+		}
+	}
+}
+
+// validConfigItemTypes are the item types supported by the KOTS admin console
+var validConfigItemTypes = map[string]bool{
+	"bool":       true,
+	"dropdown":   true,
+	"file":       true,
+	"heading":    true,
+	"label":      true,
+	"password":   true,
+	"radio":      true,
+	"select_one": true,
+	"text":       true,
+	"textarea":   true,
+}
+
+func lintKotsConfig(file *kotsFile, spec *yaml.Node) {
+	groups := mappingValue(spec, "groups")
+	if groups == nil {
+		file.addError("config-groups", spec, "spec.groups", "Config is missing spec.groups")
+		return
+	}
+	if groups.Kind != yaml.SequenceNode {
+		file.addError("config-groups", groups, "spec.groups", "groups must be a list")
+		return
+	}
+
+	seenGroups := make(map[string]bool)
+	seenItems := make(map[string]bool)
+
+	for i, group := range groups.Content {
+		groupField := fmt.Sprintf("spec.groups[%d]", i)
+		if group.Kind != yaml.MappingNode {
+			file.addError("config-groups", group, groupField, "group must be a mapping")
+			continue
+		}
+
+		groupName := scalarValue(mappingValue(group, "name"))
+		if groupName == "" {
+			file.addError("config-group-name", group, groupField+".name", "group is missing name")
+		} else if seenGroups[groupName] {
+			file.addError("config-group-name", group, groupField+".name", "duplicate group name %q", groupName)
+		}
+		seenGroups[groupName] = true
+
+		items := mappingValue(group, "items")
+		if items == nil {
+			continue
+		}
+		if items.Kind != yaml.SequenceNode {
+			file.addError("config-items", items, groupField+".items", "items must be a list")
+			continue
+		}
+
+		for j, item := range items.Content {
+			itemField := fmt.Sprintf("%s.items[%d]", groupField, j)
+			lintKotsConfigItem(file, item, itemField, seenItems)
+		}
+	}
+}
+
+func lintKotsConfigItem(file *kotsFile, item *yaml.Node, field string, seenItems map[string]bool) {
+	if item.Kind != yaml.MappingNode {
+		file.addError("config-items", item, field, "item must be a mapping")
+		return
+	}
+
+	itemName := scalarValue(mappingValue(item, "name"))
+	if itemName == "" {
+		file.addError("config-item-name", item, field+".name", "item is missing name")
+	} else if seenItems[itemName] {
+		file.addError("config-item-name", item, field+".name", "duplicate item name %q", itemName)
+	}
+	seenItems[itemName] = true
+
+	itemTypeNode := mappingValue(item, "type")
+	itemType := scalarValue(itemTypeNode)
+	if itemType == "" {
+		file.addError("config-item-type", item, field+".type", "item %q is missing type", itemName)
+		return
+	}
+	if !validConfigItemTypes[itemType] {
+		file.addError("config-item-type", itemTypeNode, field+".type", "item %q has unsupported type %q", itemName, itemType)
+		return
+	}
+
+	if itemType != "select_one" && itemType != "radio" && itemType != "dropdown" {
+		return
+	}
+
+	// Choice items must list their options, and default/value must name one of them
+	options := mappingValue(item, "items")
+	if options == nil || options.Kind != yaml.SequenceNode || len(options.Content) == 0 {
+		file.addError("config-item-choices", item, field+".items", "%s item %q must define items", itemType, itemName)
+		return
+	}
+	optionNames := make(map[string]bool)
+	for _, option := range options.Content {
+		optionNames[scalarValue(mappingValue(option, "name"))] = true
+	}
+	for _, key := range []string{"default", "value"} {
+		node := mappingValue(item, key)
+		value := scalarValue(node)
+		if value == "" || isTemplated(value) {
+			continue
+		}
+		if !optionNames[value] {
+			file.addError("config-item-choices", node, field+"."+key, "%s %q of item %q does not match any of its items", key, value, itemName)
+		}
+	}
+}
+
+func lintKotsHelmChart(file *kotsFile, doc kotsDocument, spec *yaml.Node, charts []ChartWithMetadata) {
+	if doc.APIVersion == kotsAPIVersionV1Beta1 {
+		file.addWarning("helmchart-api-version", doc.Root, "apiVersion", "HelmChart %s is deprecated, use %s", kotsAPIVersionV1Beta1, kotsAPIVersionV1Beta2)
+	}
+
+	chart := mappingValue(spec, "chart")
+	chartName := scalarValue(mappingValue(chart, "name"))
+	chartVersion := scalarValue(mappingValue(chart, "chartVersion"))
+	if chart == nil {
+		file.addError("helmchart-chart", spec, "spec.chart", "HelmChart is missing spec.chart")
+	} else {
+		if chartName == "" {
+			file.addError("helmchart-chart", chart, "spec.chart.name", "HelmChart is missing spec.chart.name")
+		}
+		if chartVersion == "" {
+			file.addError("helmchart-chart", chart, "spec.chart.chartVersion", "HelmChart is missing spec.chart.chartVersion")
+		}
+	}
+
+	if values := mappingValue(spec, "values"); values != nil && values.Kind != yaml.MappingNode {
+		file.addError("helmchart-values", values, "spec.values", "values must be a mapping")
+	}
+
+	if optionalValues := mappingValue(spec, "optionalValues"); optionalValues != nil {
+		if optionalValues.Kind != yaml.SequenceNode {
+			file.addError("helmchart-optional-values", optionalValues, "spec.optionalValues", "optionalValues must be a list")
+		} else {
+			for i, optional := range optionalValues.Content {
+				field := fmt.Sprintf("spec.optionalValues[%d]", i)
+				if mappingValue(optional, "when") == nil {
+					file.addError("helmchart-optional-values", optional, field+".when", "optionalValues entry is missing when")
+				}
+				if values := mappingValue(optional, "values"); values == nil || values.Kind != yaml.MappingNode {
+					file.addError("helmchart-optional-values", optional, field+".values", "optionalValues entry must define values as a mapping")
+				}
+			}
+		}
+	}
+
+	if chartName == "" || chartVersion == "" || len(charts) == 0 {
+		return
+	}
+
+	var versions []string
+	for _, c := range charts {
+		if c.Name != chartName {
+			continue
+		}
+		if c.Version == chartVersion {
+			return
+		}
+		versions = append(versions, c.Version)
+	}
+
+	if len(versions) > 0 {
+		sort.Strings(versions)
+		file.addError("helmchart-chart-version-mismatch", chart, "spec.chart.chartVersion",
+			"chartVersion %q does not match configured chart %q (version %s)", chartVersion, chartName, strings.Join(versions, ", "))
+		return
+	}
+	file.addWarning("helmchart-chart-not-configured", chart, "spec.chart.name", "HelmChart references chart %q which is not configured", chartName)
+}
+
+// validLintConfigLevels are the levels a LintConfig rule may be set to
+var validLintConfigLevels = map[string]bool{
+	"error": true,
+	"warn":  true,
+	"info":  true,
+	"off":   true,
+}
+
+func lintKotsLintConfig(file *kotsFile, spec *yaml.Node) {
+	rules := mappingValue(spec, "rules")
+	if rules == nil {
+		return
+	}
+	if rules.Kind != yaml.SequenceNode {
+		file.addError("lintconfig-rules", rules, "spec.rules", "rules must be a list")
+		return
+	}
+
+	for i, rule := range rules.Content {
+		field := fmt.Sprintf("spec.rules[%d]", i)
+		if scalarValue(mappingValue(rule, "name")) == "" {
+			file.addError("lintconfig-rules", rule, field+".name", "rule is missing name")
+		}
+		levelNode := mappingValue(rule, "level")
+		level := scalarValue(levelNode)
+		if level == "" {
+			file.addError("lintconfig-rules", rule, field+".level", "rule is missing level")
+		} else if !validLintConfigLevels[level] {
+			file.addError("lintconfig-rules", levelNode, field+".level", "rule level %q must be one of error, warn, info, off", level)
+		}
+	}
+}
+
+func lintKotsIdentity(file *kotsFile, spec *yaml.Node) {
+	redirectURIs := mappingValue(spec, "oidcRedirectUris")
+	if redirectURIs == nil || redirectURIs.Kind != yaml.SequenceNode || len(redirectURIs.Content) == 0 {
+		file.addError("identity-redirect-uris", spec, "spec.oidcRedirectUris", "Identity must define at least one oidcRedirectUris entry")
+	}
+
+	if roles := mappingValue(spec, "roles"); roles != nil && roles.Kind == yaml.SequenceNode {
+		for i, role := range roles.Content {
+			if scalarValue(mappingValue(role, "id")) == "" {
+				file.addError("identity-roles", role, fmt.Sprintf("spec.roles[%d].id", i), "role is missing id")
+			}
+		}
+	}
+}
+
+// collectConfigItemNames returns every Config item name across all files, including
+// the child items of select_one, radio and dropdown items. Group names are not config
+// options, so they are left out. The boolean result reports whether any Config kind
+// was found.
+func collectConfigItemNames(files []*kotsFile) (map[string]bool, bool) {
+	names := make(map[string]bool)
+	haveConfig := false
+
+	for _, file := range files {
+		for _, doc := range file.Docs {
+			if doc.Kind != "Config" {
+				continue
+			}
+			haveConfig = true
+
+			groups := mappingValue(mappingValue(doc.Root, "spec"), "groups")
+			if groups == nil || groups.Kind != yaml.SequenceNode {
+				continue
+			}
+			for _, group := range groups.Content {
+				collectItemNames(mappingValue(group, "items"), names)
+			}
+		}
+	}
+
+	return names, haveConfig
+}
+
+// collectItemNames adds the names of the items in an items sequence, and of their
+// child items, to names
+func collectItemNames(items *yaml.Node, names map[string]bool) {
+	if items == nil || items.Kind != yaml.SequenceNode {
+		return
+	}
+	for _, item := range items.Content {
+		if name := scalarValue(mappingValue(item, "name")); name != "" {
+			names[name] = true
+		}
+		collectItemNames(mappingValue(item, "items"), names)
+	}
+}
+
+// mappingValue returns the value node for key in a mapping node, or nil if absent
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// scalarValue returns the value of a scalar node, or "" for nil and non-scalar nodes
+func scalarValue(node *yaml.Node) string {
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return node.Value
+}
+
+// isIntScalar reports whether node is a scalar holding an integer
+func isIntScalar(node *yaml.Node) bool {
+	return node != nil && node.Kind == yaml.ScalarNode && node.Tag == "!!int"
+}
+
+// isTemplated reports whether a value contains a template expression that is
+// only resolved at install time
+func isTemplated(value string) bool {
+	return strings.Contains(value, "{{")
+}
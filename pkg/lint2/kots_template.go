@@ -0,0 +1,188 @@
+package lint2
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"text/template/parse"
+
+	"github.com/Masterminds/sprig/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// kotsTemplateFuncs are the template functions provided by KOTS in addition to sprig,
+// grouped by context as in the KOTS template function reference
+var kotsTemplateFuncs = []string{
+	// Config context
+	"ConfigOption", "ConfigOptionData", "ConfigOptionEquals", "ConfigOptionFilename",
+	"ConfigOptionNotEquals", "LocalImageName", "LocalRegistryAddress", "LocalRegistryHost",
+	"LocalRegistryNamespace", "LocalRegistryImagePullSecret", "HasLocalRegistry",
+	"ImagePullSecretName", "ReplicatedImageName", "ReplicatedImageRegistry",
+	// License context
+	"LicenseFieldValue", "LicenseDockerCfg",
+	// Identity context
+	"IdentityServiceEnabled", "IdentityServiceClientID", "IdentityServiceClientSecret",
+	"IdentityServiceRoles", "IdentityServiceName", "IdentityServicePort",
+	// Kurl context
+	"KurlBool", "KurlInt", "KurlString", "KurlOption", "KurlAll",
+	// Static context
+	"Add", "Base64Decode", "Base64Encode", "Distribution", "Div", "HumanSize", "IsAirgap",
+	"IsKurl", "KotsVersion", "KubeSeed", "KubernetesVersion", "KubernetesMajorVersion",
+	"KubernetesMinorVersion", "Lookup", "Mult", "Namespace", "NodeCount", "Now", "NowFmt",
+	"ParseBool", "ParseFloat", "ParseInt", "ParseUint", "PrivateCACert", "RandomBytes",
+	"RandomString", "Split", "Sub", "TLSCACert", "TLSCert", "TLSCertFromCA", "TLSKey",
+	"TLSKeyFromCA", "ToLower", "ToUpper", "Trim", "TrimSpace", "UrlEncode", "UrlPathEscape",
+	"YamlEscape", "HTTPProxy", "HTTPSProxy", "NoProxy", "Sha256sum",
+	// Version context
+	"Cursor", "ChannelName", "ReleaseNotes", "Sequence", "VersionLabel", "IsUpgrade",
+}
+
+// goTemplateBuiltins are the functions predefined by text/template
+var goTemplateBuiltins = []string{
+	"and", "call", "html", "index", "slice", "js", "len", "not", "or", "print", "printf",
+	"println", "urlquery", "eq", "ne", "lt", "le", "gt", "ge",
+}
+
+// configOptionFuncs are the template functions whose first argument names a Config item
+var configOptionFuncs = map[string]bool{
+	"ConfigOption":          true,
+	"ConfigOptionData":      true,
+	"ConfigOptionEquals":    true,
+	"ConfigOptionFilename":  true,
+	"ConfigOptionNotEquals": true,
+}
+
+var knownTemplateFuncs = buildKnownTemplateFuncs()
+
+func buildKnownTemplateFuncs() map[string]bool {
+	known := make(map[string]bool)
+	for name := range sprig.TxtFuncMap() {
+		known[name] = true
+	}
+	for _, name := range goTemplateBuiltins {
+		known[name] = true
+	}
+	for _, name := range kotsTemplateFuncs {
+		known[name] = true
+	}
+	return known
+}
+
+// kotsTemplatePattern matches both KOTS template delimiters: repl{{ expr }} and {{repl expr }}
+var kotsTemplatePattern = regexp.MustCompile(`repl\{\{(.*?)\}\}|\{\{repl(.*?)\}\}`)
+
+// lintTemplateExpressions checks every KOTS template expression found in the
+// scalar values of a document. Unknown function names are reported as warnings.
+// When haveConfig is true, ConfigOption* references to items that don't exist in
+// any Config are reported as errors.
+func lintTemplateExpressions(file *kotsFile, node *yaml.Node, configItems map[string]bool, haveConfig bool) {
+	if node == nil {
+		return
+	}
+
+	if node.Kind == yaml.ScalarNode {
+		lintTemplateScalar(file, node, configItems, haveConfig)
+		return
+	}
+
+	for _, child := range node.Content {
+		lintTemplateExpressions(file, child, configItems, haveConfig)
+	}
+}
+
+func lintTemplateScalar(file *kotsFile, node *yaml.Node, configItems map[string]bool, haveConfig bool) {
+	if !strings.Contains(node.Value, "repl") {
+		return
+	}
+
+	for _, loc := range kotsTemplatePattern.FindAllStringSubmatchIndex(node.Value, -1) {
+		var body string
+		if loc[2] >= 0 {
+			body = node.Value[loc[2]:loc[3]]
+		} else {
+			body = node.Value[loc[4]:loc[5]]
+		}
+
+		// Point at the line of the expression within multi-line scalars
+		issueNode := *node
+		issueNode.Line += strings.Count(node.Value[:loc[0]], "\n")
+		if node.Style == yaml.LiteralStyle || node.Style == yaml.FoldedStyle {
+			issueNode.Line++
+		}
+
+		// Function names are checked against knownTemplateFuncs below so that every
+		// unknown name is reported, not just the first one the parser finds
+		tree := parse.New("kots")
+		tree.Mode = parse.SkipFuncCheck
+		trees := make(map[string]*parse.Tree)
+		if _, err := tree.Parse("{{"+body+"}}", "{{", "}}", trees); err != nil {
+			file.addWarning("template-syntax", &issueNode, "", "invalid template expression %q: %v", strings.TrimSpace(body), err)
+			continue
+		}
+
+		var unknown []string
+		for _, t := range trees {
+			walkTemplateNode(t.Root, func(ident string, args []parse.Node) {
+				if !knownTemplateFuncs[ident] {
+					unknown = append(unknown, ident)
+					return
+				}
+				if !haveConfig || !configOptionFuncs[ident] || len(args) == 0 {
+					return
+				}
+				if name, ok := args[0].(*parse.StringNode); ok && !configItems[name.Text] {
+					file.addError("config-option-undefined", &issueNode, "", "%s references config item %q which is not defined in any Config", ident, name.Text)
+				}
+			})
+		}
+
+		sort.Strings(unknown)
+		for _, name := range unknown {
+			file.addWarning("template-unknown-function", &issueNode, "", "unknown template function %q", name)
+		}
+	}
+}
+
+// walkTemplateNode calls fn for every function identifier in a parsed template,
+// along with the arguments it is called with.
+func walkTemplateNode(node parse.Node, fn func(ident string, args []parse.Node)) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			walkTemplateNode(child, fn)
+		}
+	case *parse.ActionNode:
+		walkTemplateNode(n.Pipe, fn)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			walkTemplateNode(cmd, fn)
+		}
+	case *parse.CommandNode:
+		if len(n.Args) > 0 {
+			if ident, ok := n.Args[0].(*parse.IdentifierNode); ok {
+				fn(ident.Ident, n.Args[1:])
+			}
+		}
+		for _, arg := range n.Args {
+			walkTemplateNode(arg, fn)
+		}
+	case *parse.IfNode:
+		walkBranchNode(&n.BranchNode, fn)
+	case *parse.RangeNode:
+		walkBranchNode(&n.BranchNode, fn)
+	case *parse.WithNode:
+		walkBranchNode(&n.BranchNode, fn)
+	}
+}
+
+func walkBranchNode(n *parse.BranchNode, fn func(ident string, args []parse.Node)) {
+	walkTemplateNode(n.Pipe, fn)
+	walkTemplateNode(n.List, fn)
+	walkTemplateNode(n.ElseList, fn)
+}
@@ -0,0 +1,477 @@
+package lint2
+
+import (
+	"strings"
+	"testing"
+)
+
+func findKotsMessage(messages []LintMessage, severity, substr string) *LintMessage {
+	for i := range messages {
+		if messages[i].Severity == severity && strings.Contains(messages[i].Message, substr) {
+			return &messages[i]
+		}
+	}
+	return nil
+}
+
+func TestLintKots_ValidManifests(t *testing.T) {
+	dir := t.TempDir()
+	app := writeTestFile(t, dir, "app.yaml", `apiVersion: kots.io/v1beta1
+kind: Application
+metadata:
+  name: my-app
+spec:
+  title: My App
+  statusInformers:
+    - deployment/web
+    - default/service/web
+  ports:
+    - serviceName: web
+      servicePort: 80
+`)
+	config := writeTestFile(t, dir, "config.yaml", `apiVersion: kots.io/v1beta1
+kind: Config
+metadata:
+  name: config
+spec:
+  groups:
+    - name: settings
+      title: Settings
+      items:
+        - name: hostname
+          type: text
+        - name: mode
+          type: select_one
+          default: small
+          items:
+            - name: small
+            - name: large
+`)
+	helmChart := writeTestFile(t, dir, "helmchart.yaml", `apiVersion: kots.io/v1beta2
+kind: HelmChart
+metadata:
+  name: web
+spec:
+  chart:
+    name: web
+    chartVersion: 1.0.0
+  values:
+    hostname: repl{{ ConfigOption "hostname" | lower }}
+    large: '{{repl ConfigOptionEquals "mode" "large" }}'
+  optionalValues:
+    - when: 'repl{{ IsAirgap }}'
+      values:
+        airgap: true
+`)
+
+	charts := []ChartWithMetadata{{Path: "/charts/web", Name: "web", Version: "1.0.0"}}
+	results, err := LintKots([]string{app, config, helmChart}, charts)
+	if err != nil {
+		t.Fatalf("LintKots() error = %v", err)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	for _, result := range results {
+		if !result.Success {
+			t.Errorf("expected %s to pass, got messages: %+v", result.Path, result.Messages)
+		}
+		if len(result.Messages) != 0 {
+			t.Errorf("expected no messages for %s, got: %+v", result.Path, result.Messages)
+		}
+	}
+}
+
+func TestLintKots_SkipsNonKotsFiles(t *testing.T) {
+	dir := t.TempDir()
+	deployment := writeTestFile(t, dir, "deployment.yaml", `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`)
+	notYAML := writeTestFile(t, dir, "notes.yaml", "this: is: not: valid: yaml")
+
+	results, err := LintKots([]string{deployment, notYAML}, nil)
+	if err != nil {
+		t.Fatalf("LintKots() error = %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("expected no results for non-KOTS files, got %+v", results)
+	}
+
+	kotsPaths, err := DiscoverKotsManifests([]string{deployment, notYAML})
+	if err != nil {
+		t.Fatalf("DiscoverKotsManifests() error = %v", err)
+	}
+	if len(kotsPaths) != 0 {
+		t.Errorf("expected no KOTS manifests, got %v", kotsPaths)
+	}
+}
+
+func TestLintKots_StructuralErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		severity string
+		wantMsg  string
+	}{
+		{
+			name: "missing metadata name",
+			content: `apiVersion: kots.io/v1beta1
+kind: Application
+spec:
+  title: App
+`,
+			severity: "ERROR",
+			wantMsg:  "missing metadata.name",
+		},
+		{
+			name: "missing application title",
+			content: `apiVersion: kots.io/v1beta1
+kind: Application
+metadata:
+  name: app
+spec: {}
+`,
+			severity: "WARNING",
+			wantMsg:  "missing spec.title",
+		},
+		{
+			name: "invalid status informer",
+			content: `apiVersion: kots.io/v1beta1
+kind: Application
+metadata:
+  name: app
+spec:
+  title: App
+  statusInformers:
+    - web
+`,
+			severity: "ERROR",
+			wantMsg:  "[namespace/]kind/name",
+		},
+		{
+			name: "invalid config item type",
+			content: `apiVersion: kots.io/v1beta1
+kind: Config
+metadata:
+  name: config
+spec:
+  groups:
+    - name: settings
+      items:
+        - name: hostname
+          type: string
+`,
+			severity: "ERROR",
+			wantMsg:  `unsupported type "string"`,
+		},
+		{
+			name: "duplicate config item",
+			content: `apiVersion: kots.io/v1beta1
+kind: Config
+metadata:
+  name: config
+spec:
+  groups:
+    - name: a
+      items:
+        - name: hostname
+          type: text
+    - name: b
+      items:
+        - name: hostname
+          type: text
+`,
+			severity: "ERROR",
+			wantMsg:  `duplicate item name "hostname"`,
+		},
+		{
+			name: "select_one default not in items",
+			content: `apiVersion: kots.io/v1beta1
+kind: Config
+metadata:
+  name: config
+spec:
+  groups:
+    - name: settings
+      items:
+        - name: mode
+          type: select_one
+          default: medium
+          items:
+            - name: small
+`,
+			severity: "ERROR",
+			wantMsg:  `default "medium"`,
+		},
+		{
+			name: "helmchart missing chartVersion",
+			content: `apiVersion: kots.io/v1beta2
+kind: HelmChart
+metadata:
+  name: web
+spec:
+  chart:
+    name: web
+`,
+			severity: "ERROR",
+			wantMsg:  "missing spec.chart.chartVersion",
+		},
+		{
+			name: "helmchart v1beta1 deprecated",
+			content: `apiVersion: kots.io/v1beta1
+kind: HelmChart
+metadata:
+  name: web
+spec:
+  chart:
+    name: web
+    chartVersion: 1.0.0
+`,
+			severity: "WARNING",
+			wantMsg:  "deprecated",
+		},
+		{
+			name: "lint config invalid level",
+			content: `apiVersion: kots.io/v1beta1
+kind: LintConfig
+metadata:
+  name: lint
+spec:
+  rules:
+    - name: application-icon
+      level: fatal
+`,
+			severity: "ERROR",
+			wantMsg:  `rule level "fatal"`,
+		},
+		{
+			name: "identity missing redirect uris",
+			content: `apiVersion: kots.io/v1beta1
+kind: Identity
+metadata:
+  name: identity
+spec:
+  identityIssuerURL: https://example.com
+`,
+			severity: "ERROR",
+			wantMsg:  "oidcRedirectUris",
+		},
+		{
+			name: "backup missing spec",
+			content: `apiVersion: velero.io/v1
+kind: Backup
+metadata:
+  name: backup
+`,
+			severity: "ERROR",
+			wantMsg:  "Backup is missing spec",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTestFile(t, t.TempDir(), "manifest.yaml", tt.content)
+
+			results, err := LintKots([]string{path}, nil)
+			if err != nil {
+				t.Fatalf("LintKots() error = %v", err)
+			}
+			if len(results) != 1 {
+				t.Fatalf("expected 1 result, got %d", len(results))
+			}
+
+			if findKotsMessage(results[0].Messages, tt.severity, tt.wantMsg) == nil {
+				t.Errorf("expected %s containing %q, got %+v", tt.severity, tt.wantMsg, results[0].Messages)
+			}
+			if tt.severity == "ERROR" && results[0].Success {
+				t.Error("expected Success=false when errors are reported")
+			}
+		})
+	}
+}
+
+func TestLintKots_ConfigReferences(t *testing.T) {
+	dir := t.TempDir()
+	config := writeTestFile(t, dir, "config.yaml", `apiVersion: kots.io/v1beta1
+kind: Config
+metadata:
+  name: config
+spec:
+  groups:
+    - name: settings
+      items:
+        - name: hostname
+          type: text
+        - name: size
+          type: select_one
+          items:
+            - name: small
+`)
+	helmChart := writeTestFile(t, dir, "helmchart.yaml", `apiVersion: kots.io/v1beta2
+kind: HelmChart
+metadata:
+  name: web
+spec:
+  chart:
+    name: web
+    chartVersion: 1.0.0
+  values:
+    hostname: repl{{ ConfigOption "hostname" }}
+    port: repl{{ ConfigOption "port" }}
+    settings: repl{{ ConfigOption "settings" }}
+    small: repl{{ ConfigOptionEquals "small" "1" }}
+`)
+
+	results, err := LintKots([]string{config, helmChart}, nil)
+	if err != nil {
+		t.Fatalf("LintKots() error = %v", err)
+	}
+
+	var helmResult *KotsFileResult
+	for i := range results {
+		if results[i].Path == helmChart {
+			helmResult = &results[i]
+		}
+	}
+	if helmResult == nil {
+		t.Fatal("expected a result for the HelmChart manifest")
+	}
+
+	msg := findKotsMessage(helmResult.Messages, "ERROR", `config item "port"`)
+	if msg == nil {
+		t.Fatalf("expected error for undefined config item, got %+v", helmResult.Messages)
+	}
+	if !strings.Contains(msg.Message, "line 11") {
+		t.Errorf("expected error on line 11, got %q", msg.Message)
+	}
+	if findKotsMessage(helmResult.Messages, "ERROR", `config item "hostname"`) != nil {
+		t.Error("did not expect an error for a defined config item")
+	}
+	if findKotsMessage(helmResult.Messages, "ERROR", `config item "small"`) != nil {
+		t.Error("did not expect an error for a child item")
+	}
+	if findKotsMessage(helmResult.Messages, "ERROR", `config item "settings"`) == nil {
+		t.Errorf("expected error for a reference to a group name, got %+v", helmResult.Messages)
+	}
+}
+
+func TestLintKots_ConfigReferencesWithoutConfig(t *testing.T) {
+	// Without any Config kind, references can't be checked and must not be reported
+	path := writeTestFile(t, t.TempDir(), "app.yaml", `apiVersion: kots.io/v1beta1
+kind: Application
+metadata:
+  name: app
+spec:
+  title: repl{{ ConfigOption "title" }}
+`)
+
+	results, err := LintKots([]string{path}, nil)
+	if err != nil {
+		t.Fatalf("LintKots() error = %v", err)
+	}
+	if len(results) != 1 || len(results[0].Messages) != 0 {
+		t.Errorf("expected no messages, got %+v", results)
+	}
+}
+
+func TestLintKots_TemplateFunctions(t *testing.T) {
+	path := writeTestFile(t, t.TempDir(), "app.yaml", `apiVersion: kots.io/v1beta1
+kind: Application
+metadata:
+  name: app
+spec:
+  title: repl{{ LicenseFieldValue "appName" | upper }}
+  icon: |
+    first line
+    {{repl ConfigOptin "icon" }}
+  releaseNotes: repl{{ Namespace
+`)
+
+	results, err := LintKots([]string{path}, nil)
+	if err != nil {
+		t.Fatalf("LintKots() error = %v", err)
+	}
+	messages := results[0].Messages
+
+	msg := findKotsMessage(messages, "WARNING", `unknown template function "ConfigOptin"`)
+	if msg == nil {
+		t.Fatalf("expected unknown function warning, got %+v", messages)
+	}
+	if !strings.Contains(msg.Message, "line 9") {
+		t.Errorf("expected warning on line 9, got %q", msg.Message)
+	}
+	if findKotsMessage(messages, "WARNING", `"LicenseFieldValue"`) != nil ||
+		findKotsMessage(messages, "WARNING", `"upper"`) != nil {
+		t.Errorf("did not expect warnings for known functions, got %+v", messages)
+	}
+	if !results[0].Success {
+		t.Error("unknown template functions should not fail linting")
+	}
+}
+
+func TestLintKots_TemplateFunctionReference(t *testing.T) {
+	path := writeTestFile(t, t.TempDir(), "app.yaml", `apiVersion: kots.io/v1beta1
+kind: Application
+metadata:
+  name: app
+spec:
+  title: repl{{ (Lookup "v1" "Namespace" "" "default").metadata.name }}
+  icon: repl{{ ReplicatedImageRegistry "registry.example.com" }}/repl{{ ReplicatedImageName "nginx:1.27" }}
+  releaseNotes: repl{{ KubernetesVersion }} repl{{ KubernetesMajorVersion }}.repl{{ KubernetesMinorVersion }}
+`)
+
+	results, err := LintKots([]string{path}, nil)
+	if err != nil {
+		t.Fatalf("LintKots() error = %v", err)
+	}
+	if msg := findKotsMessage(results[0].Messages, "WARNING", "unknown template function"); msg != nil {
+		t.Errorf("did not expect warnings for KOTS template functions, got %+v", results[0].Messages)
+	}
+}
+
+func TestLintKots_HelmChartMatching(t *testing.T) {
+	tests := []struct {
+		name     string
+		charts   []ChartWithMetadata
+		severity string
+		wantMsg  string
+	}{
+		{
+			name:     "version mismatch",
+			charts:   []ChartWithMetadata{{Name: "web", Version: "2.0.0"}},
+			severity: "ERROR",
+			wantMsg:  `chartVersion "1.0.0" does not match configured chart "web" (version 2.0.0)`,
+		},
+		{
+			name:     "chart not configured",
+			charts:   []ChartWithMetadata{{Name: "api", Version: "1.0.0"}},
+			severity: "WARNING",
+			wantMsg:  `chart "web" which is not configured`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTestFile(t, t.TempDir(), "helmchart.yaml", `apiVersion: kots.io/v1beta2
+kind: HelmChart
+metadata:
+  name: web
+spec:
+  chart:
+    name: web
+    chartVersion: 1.0.0
+`)
+
+			results, err := LintKots([]string{path}, tt.charts)
+			if err != nil {
+				t.Fatalf("LintKots() error = %v", err)
+			}
+			if findKotsMessage(results[0].Messages, tt.severity, tt.wantMsg) == nil {
+				t.Errorf("expected %s containing %q, got %+v", tt.severity, tt.wantMsg, results[0].Messages)
+			}
+		})
+	}
+}
@@ -0,0 +1,573 @@
+package lint2
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"sigs.k8s.io/yaml"
+)
+
+// Rules reported by the Kubernetes schema linter
+const (
+	kubeSchemaRuleRender     = "kube-render"
+	kubeSchemaRuleSchema     = "kube-schema"
+	kubeSchemaRuleRemoved    = "kube-api-removed"
+	kubeSchemaRuleDeprecated = "kube-api-deprecated"
+	kubeSchemaRuleNotServed  = "kube-api-not-served"
+)
+
+// kubeSchemaResource is the URL the schema definitions are registered under
+const kubeSchemaResource = "kube-schema.json"
+
+var kubeSchemaPrinter = message.NewPrinter(language.English)
+
+// KubeSchema validates objects against the OpenAPI schema of one Kubernetes minor
+// version. It is safe for concurrent use.
+type KubeSchema struct {
+	Version string
+
+	kinds  map[string]string // "apiVersion/Kind" -> definition name
+	groups map[string]bool   // API groups served by this version ("" for the core group)
+
+	mu       sync.Mutex
+	compiler *jsonschema.Compiler
+	compiled map[string]*jsonschema.Schema
+}
+
+// LoadKubeSchema loads the Kubernetes OpenAPI v2 document at path for the given
+// minor version. See ParseKubeSchema.
+func LoadKubeSchema(version, path string) (*KubeSchema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading Kubernetes %s schema: %w", version, err)
+	}
+	return ParseKubeSchema(version, data)
+}
+
+// ParseKubeSchema parses the Kubernetes OpenAPI v2 document for the given minor
+// version (see tools.Resolver.ReadKubeSchema).
+//
+// Objects are validated strictly: unknown fields are reported, as the API server
+// does with field validation set to Strict. Fields set to null are treated as unset.
+func ParseKubeSchema(version string, data []byte) (*KubeSchema, error) {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("parsing Kubernetes %s schema: %w", version, err)
+	}
+	root, _ := doc.(map[string]any)
+	definitions, _ := root["definitions"].(map[string]any)
+	if len(definitions) == 0 {
+		return nil, fmt.Errorf("schema for Kubernetes %s has no definitions", version)
+	}
+
+	s := &KubeSchema{
+		Version:  version,
+		kinds:    make(map[string]string),
+		groups:   make(map[string]bool),
+		compiled: make(map[string]*jsonschema.Schema),
+	}
+
+	for name, definition := range definitions {
+		schema, ok := definition.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		gvks, _ := schema["x-kubernetes-group-version-kind"].([]any)
+		for _, gvk := range gvks {
+			fields, _ := gvk.(map[string]any)
+			group, _ := fields["group"].(string)
+			apiVersion, _ := fields["version"].(string)
+			kind, _ := fields["kind"].(string)
+			if group != "" {
+				apiVersion = group + "/" + apiVersion
+			}
+			s.groups[group] = true
+			// Option types like DeleteOptions list every group version; only the
+			// definition whose name ends with the kind describes the kind itself
+			key := apiVersion + "/" + kind
+			if existing, ok := s.kinds[key]; !ok || !strings.HasSuffix(existing, "."+kind) {
+				s.kinds[key] = name
+			}
+		}
+
+		// Reject unknown fields in structured types. Maps are declared with
+		// additionalProperties and are left as is.
+		if _, ok := schema["properties"]; ok {
+			if _, ok := schema["additionalProperties"]; !ok {
+				schema["additionalProperties"] = false
+			}
+		}
+	}
+
+	// The OpenAPI document declares these as strings with a custom format, but the
+	// API server also accepts numbers for them
+	definitions["io.k8s.apimachinery.pkg.util.intstr.IntOrString"] = map[string]any{
+		"anyOf": []any{map[string]any{"type": "string"}, map[string]any{"type": "integer"}},
+	}
+	definitions["io.k8s.apimachinery.pkg.api.resource.Quantity"] = map[string]any{
+		"anyOf": []any{map[string]any{"type": "string"}, map[string]any{"type": "number"}},
+	}
+
+	s.compiler = jsonschema.NewCompiler()
+	s.compiler.DefaultDraft(jsonschema.Draft4)
+	if err := s.compiler.AddResource(kubeSchemaResource, map[string]any{"definitions": definitions}); err != nil {
+		return nil, fmt.Errorf("loading Kubernetes %s schema: %w", version, err)
+	}
+
+	return s, nil
+}
+
+// schemaFor returns the compiled schema for an apiVersion and kind, or nil when
+// the version does not serve it
+func (s *KubeSchema) schemaFor(apiVersion, kind string) (*jsonschema.Schema, error) {
+	name, ok := s.kinds[apiVersion+"/"+kind]
+	if !ok {
+		return nil, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if schema, ok := s.compiled[name]; ok {
+		return schema, nil
+	}
+	schema, err := s.compiler.Compile(kubeSchemaResource + "#/definitions/" + name)
+	if err != nil {
+		return nil, fmt.Errorf("compiling Kubernetes %s schema for %s: %w", s.Version, name, err)
+	}
+	s.compiled[name] = schema
+	return schema, nil
+}
+
+// kubeAPIDeprecation records when a built-in API stopped being recommended and served
+type kubeAPIDeprecation struct {
+	deprecatedIn string // minor version the API was deprecated in
+	removedIn    string // first minor version that no longer serves the API
+	replacement  string // apiVersion to migrate to ("" if there is none)
+}
+
+// kubeAPIDeprecations lists removed built-in APIs, keyed by "apiVersion/Kind". See
+// https://kubernetes.io/docs/reference/using-api/deprecation-guide/
+var kubeAPIDeprecations = map[string]kubeAPIDeprecation{
+	// Removed in 1.16
+	"extensions/v1beta1/Deployment":        {"1.9", "1.16", "apps/v1"},
+	"extensions/v1beta1/DaemonSet":         {"1.9", "1.16", "apps/v1"},
+	"extensions/v1beta1/ReplicaSet":        {"1.9", "1.16", "apps/v1"},
+	"extensions/v1beta1/NetworkPolicy":     {"1.9", "1.16", "networking.k8s.io/v1"},
+	"extensions/v1beta1/PodSecurityPolicy": {"1.10", "1.16", "policy/v1beta1"},
+	"apps/v1beta1/Deployment":              {"1.9", "1.16", "apps/v1"},
+	"apps/v1beta1/StatefulSet":             {"1.9", "1.16", "apps/v1"},
+	"apps/v1beta2/Deployment":              {"1.9", "1.16", "apps/v1"},
+	"apps/v1beta2/DaemonSet":               {"1.9", "1.16", "apps/v1"},
+	"apps/v1beta2/ReplicaSet":              {"1.9", "1.16", "apps/v1"},
+	"apps/v1beta2/StatefulSet":             {"1.9", "1.16", "apps/v1"},
+
+	// Removed in 1.22
+	"admissionregistration.k8s.io/v1beta1/MutatingWebhookConfiguration":   {"1.16", "1.22", "admissionregistration.k8s.io/v1"},
+	"admissionregistration.k8s.io/v1beta1/ValidatingWebhookConfiguration": {"1.16", "1.22", "admissionregistration.k8s.io/v1"},
+	"apiextensions.k8s.io/v1beta1/CustomResourceDefinition":               {"1.16", "1.22", "apiextensions.k8s.io/v1"},
+	"apiregistration.k8s.io/v1beta1/APIService":                           {"1.19", "1.22", "apiregistration.k8s.io/v1"},
+	"authentication.k8s.io/v1beta1/TokenReview":                           {"1.19", "1.22", "authentication.k8s.io/v1"},
+	"authorization.k8s.io/v1beta1/LocalSubjectAccessReview":               {"1.19", "1.22", "authorization.k8s.io/v1"},
+	"authorization.k8s.io/v1beta1/SelfSubjectAccessReview":                {"1.19", "1.22", "authorization.k8s.io/v1"},
+	"authorization.k8s.io/v1beta1/SubjectAccessReview":                    {"1.19", "1.22", "authorization.k8s.io/v1"},
+	"certificates.k8s.io/v1beta1/CertificateSigningRequest":               {"1.19", "1.22", "certificates.k8s.io/v1"},
+	"coordination.k8s.io/v1beta1/Lease":                                   {"1.19", "1.22", "coordination.k8s.io/v1"},
+	"extensions/v1beta1/Ingress":                                          {"1.14", "1.22", "networking.k8s.io/v1"},
+	"networking.k8s.io/v1beta1/Ingress":                                   {"1.19", "1.22", "networking.k8s.io/v1"},
+	"networking.k8s.io/v1beta1/IngressClass":                              {"1.19", "1.22", "networking.k8s.io/v1"},
+	"rbac.authorization.k8s.io/v1beta1/ClusterRole":                       {"1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	"rbac.authorization.k8s.io/v1beta1/ClusterRoleBinding":                {"1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	"rbac.authorization.k8s.io/v1beta1/Role":                              {"1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	"rbac.authorization.k8s.io/v1beta1/RoleBinding":                       {"1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	"scheduling.k8s.io/v1beta1/PriorityClass":                             {"1.14", "1.22", "scheduling.k8s.io/v1"},
+	"storage.k8s.io/v1beta1/CSIDriver":                                    {"1.19", "1.22", "storage.k8s.io/v1"},
+	"storage.k8s.io/v1beta1/CSINode":                                      {"1.17", "1.22", "storage.k8s.io/v1"},
+	"storage.k8s.io/v1beta1/StorageClass":                                 {"1.19", "1.22", "storage.k8s.io/v1"},
+	"storage.k8s.io/v1beta1/VolumeAttachment":                             {"1.19", "1.22", "storage.k8s.io/v1"},
+
+	// Removed in 1.25
+	"batch/v1beta1/CronJob":                       {"1.21", "1.25", "batch/v1"},
+	"discovery.k8s.io/v1beta1/EndpointSlice":      {"1.21", "1.25", "discovery.k8s.io/v1"},
+	"events.k8s.io/v1beta1/Event":                 {"1.21", "1.25", "events.k8s.io/v1"},
+	"autoscaling/v2beta1/HorizontalPodAutoscaler": {"1.22", "1.25", "autoscaling/v2"},
+	"policy/v1beta1/PodDisruptionBudget":          {"1.21", "1.25", "policy/v1"},
+	"policy/v1beta1/PodSecurityPolicy":            {"1.21", "1.25", ""},
+	"node.k8s.io/v1beta1/RuntimeClass":            {"1.20", "1.25", "node.k8s.io/v1"},
+
+	// Removed in 1.26
+	"flowcontrol.apiserver.k8s.io/v1beta1/FlowSchema":                 {"1.23", "1.26", "flowcontrol.apiserver.k8s.io/v1"},
+	"flowcontrol.apiserver.k8s.io/v1beta1/PriorityLevelConfiguration": {"1.23", "1.26", "flowcontrol.apiserver.k8s.io/v1"},
+	"autoscaling/v2beta2/HorizontalPodAutoscaler":                     {"1.23", "1.26", "autoscaling/v2"},
+
+	// Removed in 1.27
+	"storage.k8s.io/v1beta1/CSIStorageCapacity": {"1.24", "1.27", "storage.k8s.io/v1"},
+
+	// Removed in 1.29
+	"flowcontrol.apiserver.k8s.io/v1beta2/FlowSchema":                 {"1.26", "1.29", "flowcontrol.apiserver.k8s.io/v1"},
+	"flowcontrol.apiserver.k8s.io/v1beta2/PriorityLevelConfiguration": {"1.26", "1.29", "flowcontrol.apiserver.k8s.io/v1"},
+
+	// Removed in 1.32
+	"flowcontrol.apiserver.k8s.io/v1beta3/FlowSchema":                 {"1.29", "1.32", "flowcontrol.apiserver.k8s.io/v1"},
+	"flowcontrol.apiserver.k8s.io/v1beta3/PriorityLevelConfiguration": {"1.29", "1.32", "flowcontrol.apiserver.k8s.io/v1"},
+}
+
+// kubeMinor returns the minor number of a Kubernetes version like 1.31
+func kubeMinor(version string) int {
+	_, minor, _ := strings.Cut(version, ".")
+	n, _ := strconv.Atoi(minor)
+	return n
+}
+
+// LintKubeSchema renders a chart for each Kubernetes version and validates every
+// object it produces against that version's schema. Each version is rendered with
+// .Capabilities.KubeVersion set to it and with every values combination (see
+// HelmValuesMatrix). Checks performed:
+//   - The chart renders for the version, including its kubeVersion constraint
+//   - Built-in APIs that are removed in, or deprecated as of, the version
+//   - Built-in API versions the version does not serve
+//   - Objects match the schema of their kind
+//
+// Kinds the schema does not know about, like custom resources, are skipped.
+// Messages produced for only some versions name those versions.
+func LintKubeSchema(chartPath string, combinations []HelmValuesCombination, schemas []*KubeSchema, opts ...LintOption) (*LintResult, error) {
+	if _, err := os.Stat(chartPath); err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("chart path does not exist: %s", chartPath)
+		}
+		return nil, fmt.Errorf("failed to access chart path: %w", err)
+	}
+
+	if len(combinations) == 0 {
+		combinations = []HelmValuesCombination{{Name: "chart defaults"}}
+	}
+
+	result := &LintResult{Success: true}
+	byVersion := newMessageSet()
+
+	for _, schema := range schemas {
+		byCombination := newMessageSet()
+		for _, combination := range combinations {
+			messages, err := lintKubeSchemaCombination(chartPath, combination, schema)
+			if err != nil {
+				return nil, err
+			}
+			byCombination.add(combination.Name, messages)
+		}
+
+		messages := byCombination.list(len(combinations), func(msg *LintMessage, producedBy []string) {
+			msg.Values = strings.Join(producedBy, "; ")
+		})
+		byVersion.add(schema.Version, messages)
+	}
+
+	result.Messages = byVersion.list(len(schemas), func(msg *LintMessage, producedBy []string) {
+		msg.Message = fmt.Sprintf("%s (Kubernetes %s)", msg.Message, strings.Join(producedBy, ", "))
+	})
+	for _, msg := range result.Messages {
+		if msg.Severity == "ERROR" {
+			result.Success = false
+		}
+	}
+
+	// Messages point at the template that produced each object, relative to the chart
+	applyLintOptions(newLintOptions(opts), chartPath, result, true)
+
+	return result, nil
+}
+
+// lintKubeSchemaCombination renders the chart with one values combination for the
+// schema's Kubernetes version and validates the objects it produces
+func lintKubeSchemaCombination(chartPath string, combination HelmValuesCombination, schema *KubeSchema) ([]LintMessage, error) {
+	manifests, err := renderChartForKubeVersion(chartPath, combination, schema.Version)
+	if err != nil {
+		return []LintMessage{{
+			Severity: "ERROR",
+			Message:  fmt.Sprintf("failed to render chart: %v", err),
+			Rule:     kubeSchemaRuleRender,
+		}}, nil
+	}
+
+	var messages []LintMessage
+	for _, manifest := range manifests {
+		manifestMessages, err := lintKubeObject(manifest, schema)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, manifestMessages...)
+	}
+	return messages, nil
+}
+
+// renderedManifest is a single object rendered from a chart template
+type renderedManifest struct {
+	Source  string // template path relative to the chart, e.g. templates/deployment.yaml
+	Content string
+}
+
+var (
+	manifestSeparator = regexp.MustCompile(`(?m)^---[ \t]*$`)
+	manifestSource    = regexp.MustCompile(`(?m)^# Source: (.+)$`)
+)
+
+// renderChartForKubeVersion renders a chart the way `helm template` does, with
+// .Capabilities.KubeVersion set to the given minor version. An empty version
+// renders with helm's default capabilities.
+func renderChartForKubeVersion(chartPath string, combination HelmValuesCombination, version string) ([]renderedManifest, error) {
+	chart, err := loader.Load(chartPath)
+	if err != nil {
+		return nil, err
+	}
+
+	values := copyValues(combination.Values)
+	for _, valuesFile := range combination.ValuesFiles {
+		fileValues, err := chartutil.ReadValuesFile(valuesFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", displayPath(valuesFile), err)
+		}
+		values = mergeValues(values, fileValues)
+	}
+
+	cfg := &action.Configuration{Log: func(string, ...interface{}) {}}
+	client := action.NewInstall(cfg)
+	client.DryRun = true
+	client.ClientOnly = true
+	client.IncludeCRDs = true
+	client.ReleaseName = "release"
+	client.Namespace = "default"
+	if version != "" {
+		client.KubeVersion = &chartutil.KubeVersion{
+			Version: "v" + version + ".0",
+			Major:   "1",
+			Minor:   strconv.Itoa(kubeMinor(version)),
+		}
+	}
+
+	rel, err := client.Run(chart, values)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifests []renderedManifest
+	for _, doc := range manifestSeparator.Split(rel.Manifest, -1) {
+		if strings.TrimSpace(doc) == "" {
+			continue
+		}
+		source := ""
+		if match := manifestSource.FindStringSubmatch(doc); match != nil {
+			source = match[1]
+		}
+		manifests = append(manifests, renderedManifest{Source: chartRelativeSource(source), Content: doc})
+	}
+	for _, hook := range rel.Hooks {
+		manifests = append(manifests, renderedManifest{Source: chartRelativeSource(hook.Path), Content: hook.Manifest})
+	}
+	return manifests, nil
+}
+
+// chartRelativeSource strips the chart name from a rendered template path
+func chartRelativeSource(source string) string {
+	if _, rest, ok := strings.Cut(source, "/"); ok {
+		return rest
+	}
+	return source
+}
+
+// lintKubeObject checks the API version and schema of a rendered object
+func lintKubeObject(manifest renderedManifest, schema *KubeSchema) ([]LintMessage, error) {
+	data, err := yaml.YAMLToJSON([]byte(manifest.Content))
+	if err != nil {
+		return []LintMessage{{
+			Severity: "ERROR",
+			Path:     manifest.Source,
+			Message:  fmt.Sprintf("rendered manifest is not valid YAML: %v", err),
+			Rule:     kubeSchemaRuleRender,
+		}}, nil
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("parsing rendered manifest %s: %w", manifest.Source, err)
+	}
+	object, ok := doc.(map[string]any)
+	if !ok {
+		// Templates that render nothing but comments produce empty documents
+		return nil, nil
+	}
+
+	apiVersion, _ := object["apiVersion"].(string)
+	kind, _ := object["kind"].(string)
+	if apiVersion == "" || kind == "" {
+		// helm lint reports objects without apiVersion or kind
+		return nil, nil
+	}
+
+	name := kubeObjectName(object)
+
+	newMessage := func(severity, rule, format string, args ...interface{}) LintMessage {
+		return LintMessage{
+			Severity: severity,
+			Path:     manifest.Source,
+			Message:  fmt.Sprintf(format, args...),
+			Rule:     rule,
+		}
+	}
+
+	var messages []LintMessage
+	version := kubeMinor(schema.Version)
+	if deprecation, ok := kubeAPIDeprecations[apiVersion+"/"+kind]; ok {
+		migrate := "it has no replacement"
+		if deprecation.replacement != "" {
+			migrate = "use " + deprecation.replacement
+		}
+		if version >= kubeMinor(deprecation.removedIn) {
+			return []LintMessage{newMessage("ERROR", kubeSchemaRuleRemoved,
+				"%s uses %s, which was removed in Kubernetes %s; %s",
+				name, apiVersion, deprecation.removedIn, migrate)}, nil
+		}
+		if version >= kubeMinor(deprecation.deprecatedIn) {
+			messages = append(messages, newMessage("WARNING", kubeSchemaRuleDeprecated,
+				"%s uses %s, which is deprecated since Kubernetes %s and removed in %s; %s",
+				name, apiVersion, deprecation.deprecatedIn, deprecation.removedIn, migrate))
+		}
+	}
+
+	objectSchema, err := schema.schemaFor(apiVersion, kind)
+	if err != nil {
+		return nil, err
+	}
+	if objectSchema == nil {
+		group, _, hasGroup := strings.Cut(apiVersion, "/")
+		if !hasGroup {
+			group = ""
+		}
+		if schema.groups[group] {
+			messages = append(messages, newMessage("ERROR", kubeSchemaRuleNotServed,
+				"%s uses %s, which Kubernetes does not serve", name, apiVersion))
+		}
+		// Anything else is a custom resource, which has no bundled schema
+		return messages, nil
+	}
+
+	if err := objectSchema.Validate(dropNulls(object)); err != nil {
+		validationErr, ok := err.(*jsonschema.ValidationError)
+		if !ok {
+			return nil, err
+		}
+		for _, issue := range kubeSchemaIssues(validationErr) {
+			messages = append(messages, newMessage("ERROR", kubeSchemaRuleSchema, "%s: %s", name, issue))
+		}
+	}
+
+	return messages, nil
+}
+
+// kubeObjectName describes an object by kind and name for messages, e.g. Deployment "web"
+func kubeObjectName(object map[string]any) string {
+	kind, _ := object["kind"].(string)
+	if metadata, ok := object["metadata"].(map[string]any); ok {
+		if name, ok := metadata["name"].(string); ok && name != "" {
+			return fmt.Sprintf("%s %q", kind, name)
+		}
+	}
+	return kind
+}
+
+// kubeSchemaIssues flattens a validation error into one message per failed check,
+// each prefixed with the field it applies to
+func kubeSchemaIssues(err *jsonschema.ValidationError) []string {
+	var issues []string
+	var walk func(e *jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		issue := e.ErrorKind.LocalizedString(kubeSchemaPrinter)
+		if _, ok := e.ErrorKind.(*kind.AnyOf); ok {
+			// Fields accepting several types (IntOrString, Quantity) fail every
+			// alternative; report them as one type error
+			if typeErr := mergeTypeErrors(e.Causes); typeErr != nil {
+				issue = typeErr.LocalizedString(kubeSchemaPrinter)
+			}
+		} else if len(e.Causes) > 0 {
+			for _, cause := range e.Causes {
+				walk(cause)
+			}
+			return
+		}
+		if field := kubeFieldPath(e.InstanceLocation); field != "" {
+			issue = field + ": " + issue
+		}
+		issues = append(issues, issue)
+	}
+	walk(err)
+
+	sort.Strings(issues)
+	return issues
+}
+
+// mergeTypeErrors combines the type errors of anyOf alternatives, or returns nil if
+// any alternative failed for another reason
+func mergeTypeErrors(causes []*jsonschema.ValidationError) *kind.Type {
+	var merged *kind.Type
+	for _, cause := range causes {
+		// Alternatives wrap their errors in a group
+		for len(cause.Causes) == 1 {
+			cause = cause.Causes[0]
+		}
+		typeErr, ok := cause.ErrorKind.(*kind.Type)
+		if !ok || len(cause.Causes) > 0 {
+			return nil
+		}
+		if merged == nil {
+			merged = &kind.Type{Got: typeErr.Got}
+		}
+		merged.Want = append(merged.Want, typeErr.Want...)
+	}
+	return merged
+}
+
+// kubeFieldPath formats a JSON instance location the way kubectl reports fields,
+// e.g. spec.template.spec.containers[0].image
+func kubeFieldPath(location []string) string {
+	var path strings.Builder
+	for _, token := range location {
+		if _, err := strconv.Atoi(token); err == nil {
+			fmt.Fprintf(&path, "[%s]", token)
+			continue
+		}
+		if path.Len() > 0 {
+			path.WriteString(".")
+		}
+		path.WriteString(token)
+	}
+	return path.String()
+}
+
+// dropNulls removes fields set to null, which the API server treats as unset
+func dropNulls(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if field == nil {
+				delete(v, key)
+				continue
+			}
+			v[key] = dropNulls(field)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = dropNulls(item)
+		}
+	}
+	return value
+}
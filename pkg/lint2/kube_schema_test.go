@@ -0,0 +1,95 @@
+package lint2
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func loadTestKubeSchemas(t *testing.T, versions ...string) []*KubeSchema {
+	t.Helper()
+	var schemas []*KubeSchema
+	for _, version := range versions {
+		schema, err := LoadKubeSchema(version, filepath.Join("testdata", "kube-schema", "swagger.json"))
+		if err != nil {
+			t.Fatalf("LoadKubeSchema(%s) error = %v", version, err)
+		}
+		schemas = append(schemas, schema)
+	}
+	return schemas
+}
+
+func TestLintKubeSchema(t *testing.T) {
+	schemas := loadTestKubeSchemas(t, "1.24", "1.29")
+	combinations := []HelmValuesCombination{
+		{Name: "chart defaults"},
+		{Name: "boolean port", Values: map[string]interface{}{"targetPort": true}},
+	}
+
+	result, err := LintKubeSchema(filepath.Join("testdata", "charts", "kube-schema"), combinations, schemas)
+	if err != nil {
+		t.Fatalf("LintKubeSchema() error = %v", err)
+	}
+	if result.Success {
+		t.Error("expected lint to fail")
+	}
+
+	type finding struct {
+		Severity, Path, Message, Rule, Values string
+	}
+	var got []finding
+	for _, msg := range result.Messages {
+		got = append(got, finding{msg.Severity, msg.Path, msg.Message, msg.Rule, msg.Values})
+	}
+
+	// Objects are reported in the order helm installs them
+	want := []finding{
+		{"ERROR", "templates/deployment.yaml", `Deployment "web": spec.template.spec.containers[0]: additional properties 'imagePullPolcy' not allowed`, "kube-schema", ""},
+		{"ERROR", "templates/widget.yaml", `Deployment "legacy" uses apps/v1beta3, which Kubernetes does not serve`, "kube-api-not-served", ""},
+		{"WARNING", "templates/cronjob.yaml", `CronJob "backup" uses batch/v1beta1, which is deprecated since Kubernetes 1.21 and removed in 1.25; use batch/v1 (Kubernetes 1.24)`, "kube-api-deprecated", ""},
+		{"WARNING", "templates/cronjob.yaml", `CronJob "cleanup" uses batch/v1beta1, which is deprecated since Kubernetes 1.21 and removed in 1.25; use batch/v1 (Kubernetes 1.24)`, "kube-api-deprecated", ""},
+		{"ERROR", "templates/service.yaml", `Service "web": spec.ports[0].targetPort: got boolean, want string or integer`, "kube-schema", "boolean port"},
+		{"ERROR", "templates/cronjob.yaml", `CronJob "cleanup" uses batch/v1beta1, which was removed in Kubernetes 1.25; use batch/v1 (Kubernetes 1.29)`, "kube-api-removed", ""},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("messages =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestLintKubeSchema_RenderError(t *testing.T) {
+	schemas := loadTestKubeSchemas(t, "1.29")
+	combinations := []HelmValuesCombination{
+		{Name: "missing file", ValuesFiles: []string{filepath.Join(t.TempDir(), "missing.yaml")}},
+	}
+
+	result, err := LintKubeSchema(filepath.Join("testdata", "charts", "kube-schema"), combinations, schemas)
+	if err != nil {
+		t.Fatalf("LintKubeSchema() error = %v", err)
+	}
+	if result.Success || len(result.Messages) != 1 || result.Messages[0].Rule != "kube-render" {
+		t.Errorf("expected a single kube-render error, got %+v", result)
+	}
+}
+
+func TestLoadKubeSchema_NoDefinitions(t *testing.T) {
+	path := writeTestFile(t, t.TempDir(), "swagger.json", `{"swagger": "2.0", "paths": {}}`)
+	if _, err := LoadKubeSchema("1.31", path); err == nil {
+		t.Error("expected an error for a schema without definitions")
+	}
+}
+
+func TestKubeFieldPath(t *testing.T) {
+	tests := []struct {
+		location []string
+		want     string
+	}{
+		{location: nil, want: ""},
+		{location: []string{"spec", "replicas"}, want: "spec.replicas"},
+		{location: []string{"spec", "containers", "0", "ports", "1", "containerPort"}, want: "spec.containers[0].ports[1].containerPort"},
+	}
+	for _, tt := range tests {
+		if got := kubeFieldPath(tt.location); got != tt.want {
+			t.Errorf("kubeFieldPath(%v) = %q, want %q", tt.location, got, tt.want)
+		}
+	}
+}
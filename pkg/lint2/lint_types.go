@@ -8,6 +8,13 @@ type LintIssue interface {
 	GetField() string
 }
 
+// RuledLintIssue is implemented by lint issues that identify the check that produced them.
+// The rule is used for rule-based suppression and reported in structured output.
+type RuledLintIssue interface {
+	LintIssue
+	GetRule() string
+}
+
 // FileLintResult is the per-file result structure shared across all linting tools.
 // Info uses json:"info" — the troubleshoot.sh tools do not emit an info field,
 // so it will always be empty for preflight/support-bundle output.
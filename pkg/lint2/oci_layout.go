@@ -0,0 +1,94 @@
+package lint2
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/distribution/reference"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// annotationContainerdImageName is set by containerd, nerdctl and crane to the full
+// reference of an image in an OCI layout
+const annotationContainerdImageName = "io.containerd.image.name"
+
+// OCILayout maps image references to the digests of the images stored in a local OCI
+// image layout
+type OCILayout struct {
+	Path    string
+	digests map[string]string // normalized name:tag to digest
+}
+
+// LoadOCILayout reads the index of the OCI image layout in dir. Images are found by
+// their full reference (e.g. docker.io/library/nginx:1.27) in the
+// org.opencontainers.image.ref.name or io.containerd.image.name annotation; entries
+// named only by a tag are ignored, since the repository they belong to is unknown.
+func LoadOCILayout(dir string) (*OCILayout, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ocispec.ImageLayoutFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%s is not an OCI image layout: %s not found", dir, ocispec.ImageLayoutFile)
+		}
+		return nil, fmt.Errorf("failed to read OCI layout: %w", err)
+	}
+	var layout ocispec.ImageLayout
+	if err := json.Unmarshal(data, &layout); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ocispec.ImageLayoutFile, err)
+	}
+	if layout.Version != ocispec.ImageLayoutVersion {
+		return nil, fmt.Errorf("unsupported OCI image layout version %q", layout.Version)
+	}
+
+	data, err = os.ReadFile(filepath.Join(dir, ocispec.ImageIndexFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OCI layout index: %w", err)
+	}
+	var index ocispec.Index
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ocispec.ImageIndexFile, err)
+	}
+
+	result := &OCILayout{Path: dir, digests: make(map[string]string)}
+	for _, manifest := range index.Manifests {
+		for _, annotation := range []string{annotationContainerdImageName, ocispec.AnnotationRefName} {
+			if key, ok := normalizedTaggedImage(manifest.Annotations[annotation]); ok {
+				result.digests[key] = manifest.Digest.String()
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// Digest returns the digest of the image with the given tagged reference, if the
+// layout holds it
+func (l *OCILayout) Digest(image string) (string, bool) {
+	key, ok := normalizedTaggedImage(image)
+	if !ok {
+		return "", false
+	}
+	digest, ok := l.digests[key]
+	return digest, ok
+}
+
+// normalizedTaggedImage returns the fully qualified name:tag of an image reference
+// that has a tag and no digest
+func normalizedTaggedImage(image string) (string, bool) {
+	if image == "" {
+		return "", false
+	}
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return "", false
+	}
+	if _, ok := named.(reference.Digested); ok {
+		return "", false
+	}
+	tagged, ok := named.(reference.NamedTagged)
+	if !ok {
+		return "", false
+	}
+	return tagged.String(), true
+}
@@ -0,0 +1,85 @@
+package lint2
+
+import "github.com/replicatedhq/replicated/pkg/tools"
+
+// LintOptions contains optional settings shared by the Lint* functions.
+type LintOptions struct {
+	Suppressor *Suppressor
+	Strict     bool
+	Resolver   *tools.Resolver
+}
+
+// LintOption is a functional option for configuring a lint run.
+type LintOption func(*LintOptions)
+
+// WithSuppressor returns a LintOption that drops findings matching the
+// suppressor's ignore rules. Inline ignore comments are honored either way.
+func WithSuppressor(suppressor *Suppressor) LintOption {
+	return func(opts *LintOptions) {
+		opts.Suppressor = suppressor
+	}
+}
+
+// WithStrict returns a LintOption that treats warnings as errors. Promoted
+// messages keep their original severity in LintMessage.PromotedFrom.
+func WithStrict(strict bool) LintOption {
+	return func(opts *LintOptions) {
+		opts.Strict = strict
+	}
+}
+
+// WithResolver returns a LintOption that resolves the linter's tool binaries with
+// resolver, e.g. to download them through mirrors. By default a new resolver is used.
+func WithResolver(resolver *tools.Resolver) LintOption {
+	return func(opts *LintOptions) {
+		opts.Resolver = resolver
+	}
+}
+
+func newLintOptions(opts []LintOption) LintOptions {
+	var options LintOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// resolver returns the resolver to run tools with
+func (o LintOptions) resolver() *tools.Resolver {
+	if o.Resolver != nil {
+		return o.Resolver
+	}
+	return tools.NewResolver()
+}
+
+// ErrorResult returns a failed result for a resource that could not be linted, with
+// err reported as an ERROR under rule. Ignore rules, inline ignore comments and
+// strict mode apply to it like any other finding.
+func ErrorResult(path, rule string, err error, opts ...LintOption) *LintResult {
+	result := &LintResult{
+		Messages: []LintMessage{{Severity: "ERROR", Message: err.Error(), Rule: rule}},
+	}
+	applyLintOptions(newLintOptions(opts), path, result, false)
+	return result
+}
+
+// applyLintOptions post-processes a lint result: suppressed findings are dropped
+// first, so an ignored warning is never promoted by strict mode.
+func applyLintOptions(opts LintOptions, basePath string, result *LintResult, messagePathsRelative bool) {
+	applySuppression(opts, basePath, result, messagePathsRelative)
+	if opts.Strict {
+		applyStrict(result)
+	}
+}
+
+// applyStrict promotes warnings to errors and fails the result if any were promoted
+func applyStrict(result *LintResult) {
+	for i, msg := range result.Messages {
+		if msg.Severity != "WARNING" {
+			continue
+		}
+		result.Messages[i].PromotedFrom = msg.Severity
+		result.Messages[i].Severity = "ERROR"
+		result.Success = false
+	}
+}
@@ -0,0 +1,70 @@
+package lint2
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/replicatedhq/replicated/pkg/tools"
+)
+
+func TestApplyLintOptions_Strict(t *testing.T) {
+	s, err := NewSuppressor([]tools.IgnoreRule{{Message: "ignored warning"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := &LintResult{
+		Success: true,
+		Messages: []LintMessage{
+			{Severity: "WARNING", Message: "promoted warning"},
+			{Severity: "WARNING", Message: "ignored warning"},
+			{Severity: "INFO", Message: "info"},
+		},
+	}
+	opts := newLintOptions([]LintOption{WithSuppressor(s), WithStrict(true)})
+	applyLintOptions(opts, "", result, false)
+
+	if result.Success {
+		t.Error("expected strict mode to fail a result with warnings")
+	}
+	if len(result.Messages) != 2 {
+		t.Fatalf("expected suppressed warning to be dropped before promotion, got %+v", result.Messages)
+	}
+	if got := result.Messages[0]; got.Severity != "ERROR" || got.PromotedFrom != "WARNING" {
+		t.Errorf("expected warning promoted to error, got %+v", got)
+	}
+	if got := result.Messages[1]; got.Severity != "INFO" || got.PromotedFrom != "" {
+		t.Errorf("expected info to be left alone, got %+v", got)
+	}
+}
+
+func TestApplyLintOptions_NotStrict(t *testing.T) {
+	result := &LintResult{
+		Success:  true,
+		Messages: []LintMessage{{Severity: "WARNING", Message: "warning"}},
+	}
+	applyLintOptions(newLintOptions(nil), "", result, false)
+
+	if !result.Success || result.Messages[0].Severity != "WARNING" {
+		t.Errorf("expected warnings to pass without strict mode, got success=%v messages=%+v", result.Success, result.Messages)
+	}
+}
+
+func TestErrorResult(t *testing.T) {
+	result := ErrorResult("chart", "helm-values", errors.New("values do not render"))
+	if result.Success {
+		t.Error("expected error result to fail")
+	}
+	if len(result.Messages) != 1 || result.Messages[0].Rule != "helm-values" || result.Messages[0].Severity != "ERROR" {
+		t.Fatalf("unexpected messages: %+v", result.Messages)
+	}
+
+	s, err := NewSuppressor([]tools.IgnoreRule{{Rule: "helm-values"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result = ErrorResult("chart", "helm-values", errors.New("values do not render"), WithSuppressor(s))
+	if !result.Success || len(result.Messages) != 0 || result.Suppressed != 1 {
+		t.Errorf("expected ignored error to be suppressed, got %+v", result)
+	}
+}
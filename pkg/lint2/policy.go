@@ -0,0 +1,581 @@
+package lint2
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+)
+
+// policyRuleRender is reported when a resource cannot be rendered or parsed for
+// policy evaluation
+const policyRuleRender = "policy-render"
+
+// Sources policies are evaluated against
+const (
+	PolicySourceChart     = "chart"     // Objects rendered from Helm charts
+	PolicySourceManifest  = "manifest"  // KOTS kinds and other objects in the manifests globs
+	PolicySourcePreflight = "preflight" // Preflight specs
+)
+
+var policySources = map[string]bool{
+	PolicySourceChart:     true,
+	PolicySourceManifest:  true,
+	PolicySourcePreflight: true,
+}
+
+// policyRuleIDPattern keeps rule ids in the same form as the built-in rule ids, so
+// they can be used in ignore rules and baselines
+var policyRuleIDPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
+// policyFile is the format of a policy file
+type policyFile struct {
+	Rules []policyRuleSpec `yaml:"rules"`
+}
+
+// policyRuleSpec is a single rule as written in a policy file
+type policyRuleSpec struct {
+	ID                string      `yaml:"id"`
+	Severity          string      `yaml:"severity"` // error (default), warning or info
+	Match             policyMatch `yaml:"match"`
+	Expression        string      `yaml:"expression"`        // CEL expression that is true for compliant objects
+	Message           string      `yaml:"message"`           // Reported when the expression is false
+	MessageExpression string      `yaml:"messageExpression"` // CEL expression producing the message, overrides message
+}
+
+// policyMatch limits the objects a rule applies to. Empty lists match everything.
+type policyMatch struct {
+	Kinds   []string `yaml:"kinds"`
+	Sources []string `yaml:"sources"`
+}
+
+// policyRule is a compiled rule
+type policyRule struct {
+	id       string
+	severity string
+	message  string
+	kinds    map[string]bool
+	sources  map[string]bool
+
+	program        cel.Program
+	messageProgram cel.Program
+}
+
+// Policies are policy-as-code rules written in CEL. Each rule is evaluated against
+// every object rendered from a chart, found in the manifests or defined by a
+// preflight spec, and reports a finding for objects its expression is false for.
+// Policies are safe for concurrent use.
+type Policies struct {
+	rules []*policyRule
+}
+
+// newPolicyEnv returns the CEL environment rules are compiled in. Rules see:
+//   - object: the object being evaluated
+//   - podSpec: the pod spec of workloads (Pods, the pod template of Deployments,
+//     StatefulSets, Jobs and the like, and the job template of CronJobs), null otherwise
+//   - source: where the object came from: chart, manifest or preflight
+func newPolicyEnv() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable("object", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("podSpec", cel.DynType),
+		cel.Variable("source", cel.StringType),
+		cel.OptionalTypes(),
+		ext.Strings(),
+		ext.Lists(),
+		ext.Sets(),
+	)
+}
+
+// LoadPolicies loads and compiles every policy file (*.yaml, *.yml) in dir and its
+// subdirectories. Rule ids must be unique across files.
+func LoadPolicies(dir string) (*Policies, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading policies: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("policies path %s is not a directory", dir)
+	}
+
+	env, err := newPolicyEnv()
+	if err != nil {
+		return nil, fmt.Errorf("creating policy environment: %w", err)
+	}
+
+	policies := &Policies{}
+	ruleFiles := make(map[string]string) // rule id -> file that defines it
+
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".yaml", ".yml":
+		case ".rego":
+			return fmt.Errorf("%s: Rego policies are not supported; write rules as CEL expressions in a YAML policy file", displayPath(path))
+		default:
+			return nil
+		}
+
+		rules, err := loadPolicyFile(env, path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", displayPath(path), err)
+		}
+		for _, rule := range rules {
+			if other, ok := ruleFiles[rule.id]; ok {
+				return fmt.Errorf("%s: rule %q is already defined in %s", displayPath(path), rule.id, displayPath(other))
+			}
+			ruleFiles[rule.id] = path
+		}
+		policies.rules = append(policies.rules, rules...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+// loadPolicyFile parses and compiles the rules in a policy file
+func loadPolicyFile(env *cel.Env, path string) ([]*policyRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file policyFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("parsing policy file: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	rules := make([]*policyRule, 0, len(file.Rules))
+	for i, spec := range file.Rules {
+		rule, err := compilePolicyRule(env, spec)
+		if err != nil {
+			if spec.ID != "" {
+				return nil, fmt.Errorf("rules[%d] (%s): %w", i, spec.ID, err)
+			}
+			return nil, fmt.Errorf("rules[%d]: %w", i, err)
+		}
+		if seen[rule.id] {
+			return nil, fmt.Errorf("rules[%d]: duplicate rule id %q", i, rule.id)
+		}
+		seen[rule.id] = true
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// compilePolicyRule validates a rule and compiles its expressions
+func compilePolicyRule(env *cel.Env, spec policyRuleSpec) (*policyRule, error) {
+	if spec.ID == "" {
+		return nil, errors.New("id is required")
+	}
+	if !policyRuleIDPattern.MatchString(spec.ID) {
+		return nil, fmt.Errorf("invalid id %q: must be lowercase letters, digits and dashes", spec.ID)
+	}
+
+	rule := &policyRule{
+		id:      spec.ID,
+		message: spec.Message,
+		kinds:   make(map[string]bool, len(spec.Match.Kinds)),
+		sources: make(map[string]bool, len(spec.Match.Sources)),
+	}
+
+	switch strings.ToLower(spec.Severity) {
+	case "", "error":
+		rule.severity = "ERROR"
+	case "warning":
+		rule.severity = "WARNING"
+	case "info":
+		rule.severity = "INFO"
+	default:
+		return nil, fmt.Errorf("invalid severity %q: must be error, warning or info", spec.Severity)
+	}
+
+	for _, kind := range spec.Match.Kinds {
+		rule.kinds[kind] = true
+	}
+	for _, source := range spec.Match.Sources {
+		if !policySources[source] {
+			return nil, fmt.Errorf("invalid source %q in match.sources: must be chart, manifest or preflight", source)
+		}
+		rule.sources[source] = true
+	}
+
+	if strings.TrimSpace(spec.Expression) == "" {
+		return nil, errors.New("expression is required")
+	}
+	var err error
+	rule.program, err = compilePolicyExpression(env, spec.Expression, cel.BoolType)
+	if err != nil {
+		return nil, fmt.Errorf("expression: %w", err)
+	}
+	if spec.MessageExpression != "" {
+		rule.messageProgram, err = compilePolicyExpression(env, spec.MessageExpression, cel.StringType)
+		if err != nil {
+			return nil, fmt.Errorf("messageExpression: %w", err)
+		}
+	}
+
+	return rule, nil
+}
+
+// compilePolicyExpression compiles a CEL expression that must produce the given type.
+// Expressions typed dyn (e.g. a field of the object) are checked when evaluated.
+func compilePolicyExpression(env *cel.Env, expression string, want *cel.Type) (cel.Program, error) {
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	if got := ast.OutputType(); !got.IsExactType(want) && !got.IsExactType(cel.DynType) {
+		return nil, fmt.Errorf("must evaluate to %s, got %s", want, got)
+	}
+	return env.Program(ast)
+}
+
+// policyObject is an object policies are evaluated against
+type policyObject struct {
+	path   string // file the object is reported against
+	line   int    // 1-based line of the object in path (0 if unknown)
+	object map[string]any
+}
+
+// evaluate runs every rule matching the object and returns a message per violation.
+// Rules that fail to evaluate, e.g. because they read a field the object does not
+// have without checking has() first, are reported as errors.
+func (p *Policies) evaluate(source string, obj policyObject) []LintMessage {
+	kind, _ := obj.object["kind"].(string)
+	name := kubeObjectName(obj.object)
+	vars := map[string]any{
+		"object":  obj.object,
+		"podSpec": policyPodSpec(obj.object),
+		"source":  source,
+	}
+
+	var messages []LintMessage
+	for _, rule := range p.rules {
+		if len(rule.sources) > 0 && !rule.sources[source] {
+			continue
+		}
+		if len(rule.kinds) > 0 && !rule.kinds[kind] {
+			continue
+		}
+
+		newMessage := func(severity, format string, args ...interface{}) LintMessage {
+			return LintMessage{
+				Severity: severity,
+				Path:     obj.path,
+				Line:     obj.line,
+				Message:  fmt.Sprintf(format, args...),
+				Rule:     rule.id,
+			}
+		}
+
+		out, _, err := rule.program.Eval(vars)
+		if err != nil {
+			messages = append(messages, newMessage("ERROR", "%s: policy %s could not be evaluated: %v", name, rule.id, err))
+			continue
+		}
+		compliant, ok := out.Value().(bool)
+		if !ok {
+			messages = append(messages, newMessage("ERROR", "%s: policy %s must evaluate to a bool, got %s", name, rule.id, out.Type().TypeName()))
+			continue
+		}
+		if compliant {
+			continue
+		}
+
+		messages = append(messages, newMessage(rule.severity, "%s: %s", name, rule.violationMessage(vars)))
+	}
+	return messages
+}
+
+// violationMessage returns the message reported for an object the rule's expression
+// is false for
+func (r *policyRule) violationMessage(vars map[string]any) string {
+	if r.messageProgram != nil {
+		if out, _, err := r.messageProgram.Eval(vars); err == nil {
+			if message, ok := out.Value().(string); ok && message != "" {
+				return message
+			}
+		}
+	}
+	if r.message != "" {
+		return r.message
+	}
+	return fmt.Sprintf("violates policy %s", r.id)
+}
+
+// policyPodSpec returns the pod spec of a workload object, or nil if it has none
+func policyPodSpec(object map[string]any) any {
+	spec, _ := object["spec"].(map[string]any)
+	if spec == nil {
+		return nil
+	}
+	switch object["kind"] {
+	case "Pod":
+		return spec
+	case "CronJob":
+		spec = nestedMap(spec, "jobTemplate", "spec")
+	}
+	if podSpec := nestedMap(spec, "template", "spec"); podSpec != nil {
+		return podSpec
+	}
+	return nil
+}
+
+// nestedMap returns the map at the given keys below m, or nil
+func nestedMap(m map[string]any, keys ...string) map[string]any {
+	for _, key := range keys {
+		if m == nil {
+			return nil
+		}
+		m, _ = m[key].(map[string]any)
+	}
+	return m
+}
+
+// decodePolicyObjects parses each YAML document in data as an object. Documents
+// without apiVersion and kind are skipped.
+func decodePolicyObjects(data []byte, path string, withLines bool) ([]policyObject, error) {
+	var objects []policyObject
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var node yaml.Node
+		if err := decoder.Decode(&node); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objects, nil
+			}
+			return nil, err
+		}
+
+		var object map[string]any
+		if err := node.Decode(&object); err != nil {
+			// Not a mapping, e.g. a document with only a list
+			continue
+		}
+		apiVersion, _ := object["apiVersion"].(string)
+		kind, _ := object["kind"].(string)
+		if apiVersion == "" || kind == "" {
+			continue
+		}
+
+		obj := policyObject{path: path, object: object}
+		if withLines && len(node.Content) > 0 {
+			obj.line = node.Content[0].Line
+		}
+		objects = append(objects, obj)
+	}
+}
+
+// policyResult builds a result from policy messages, failing on any error
+func policyResult(messages []LintMessage) *LintResult {
+	result := &LintResult{Success: true, Messages: messages}
+	for _, msg := range messages {
+		if msg.Severity == "ERROR" {
+			result.Success = false
+		}
+	}
+	return result
+}
+
+// LintChartPolicies renders a chart with each values combination (see
+// HelmValuesMatrix) and evaluates the policies against every object it produces.
+// Messages produced by only some combinations name those combinations.
+func LintChartPolicies(chartPath string, combinations []HelmValuesCombination, policies *Policies, opts ...LintOption) (*LintResult, error) {
+	if _, err := os.Stat(chartPath); err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("chart path does not exist: %s", chartPath)
+		}
+		return nil, fmt.Errorf("failed to access chart path: %w", err)
+	}
+
+	if len(combinations) == 0 {
+		combinations = []HelmValuesCombination{{Name: "chart defaults"}}
+	}
+
+	byCombination := newMessageSet()
+	for _, combination := range combinations {
+		manifests, err := renderChartForKubeVersion(chartPath, combination, "")
+		if err != nil {
+			byCombination.add(combination.Name, []LintMessage{{
+				Severity: "ERROR",
+				Message:  fmt.Sprintf("failed to render chart: %v", err),
+				Rule:     policyRuleRender,
+			}})
+			continue
+		}
+
+		var messages []LintMessage
+		for _, manifest := range manifests {
+			objects, err := decodePolicyObjects([]byte(manifest.Content), manifest.Source, false)
+			if err != nil {
+				messages = append(messages, LintMessage{
+					Severity: "ERROR",
+					Path:     manifest.Source,
+					Message:  fmt.Sprintf("rendered manifest is not valid YAML: %v", err),
+					Rule:     policyRuleRender,
+				})
+				continue
+			}
+			for _, obj := range objects {
+				messages = append(messages, policies.evaluate(PolicySourceChart, obj)...)
+			}
+		}
+		byCombination.add(combination.Name, messages)
+	}
+
+	result := policyResult(byCombination.list(len(combinations), func(msg *LintMessage, producedBy []string) {
+		msg.Values = strings.Join(producedBy, "; ")
+	}))
+
+	// Messages point at the template that produced each object, relative to the chart
+	applyLintOptions(newLintOptions(opts), chartPath, result, true)
+
+	return result, nil
+}
+
+// LintManifestPolicies evaluates the policies against every object in a manifest
+// file. KOTS template functions are rendered with sample config values first (see
+// DiscoverConfigSampleValues).
+func LintManifestPolicies(path string, configValues map[string]string, policies *Policies, opts ...LintOption) (*LintResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest: %w", err)
+	}
+
+	// Line numbers only stay accurate if templates render on the line they are
+	// written on, so a manifest that fails to render is evaluated as written
+	content := string(data)
+	if rendered, err := renderKotsTemplate(content, configValues); err == nil {
+		content = rendered
+	}
+
+	var messages []LintMessage
+	objects, err := decodePolicyObjects([]byte(content), path, true)
+	if err != nil {
+		messages = append(messages, LintMessage{
+			Severity: "ERROR",
+			Path:     path,
+			Message:  fmt.Sprintf("failed to parse manifest: %v", err),
+			Rule:     policyRuleRender,
+		})
+	}
+	for _, obj := range objects {
+		messages = append(messages, policies.evaluate(PolicySourceManifest, obj)...)
+	}
+
+	result := policyResult(messages)
+	applyLintOptions(newLintOptions(opts), path, result, false)
+
+	return result, nil
+}
+
+// LintPreflightPolicies evaluates the policies against a preflight spec. Templated
+// specs are rendered with Helm's template engine, using the spec's values file and
+// the builder values of its chart's HelmChart manifest, as preflight lint does.
+func LintPreflightPolicies(preflight PreflightWithValues, helmChartManifests map[string]*HelmChartManifest, policies *Policies, opts ...LintOption) (*LintResult, error) {
+	data, err := os.ReadFile(preflight.SpecPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading preflight spec: %w", err)
+	}
+
+	result := policyResult(preflightPolicyMessages(data, preflight, helmChartManifests, policies))
+	applyLintOptions(newLintOptions(opts), preflight.SpecPath, result, false)
+
+	return result, nil
+}
+
+// preflightPolicyMessages renders and parses a preflight spec and evaluates the
+// policies against it
+func preflightPolicyMessages(data []byte, preflight PreflightWithValues, helmChartManifests map[string]*HelmChartManifest, policies *Policies) []LintMessage {
+	renderError := func(format string, args ...interface{}) []LintMessage {
+		return []LintMessage{{
+			Severity: "ERROR",
+			Path:     preflight.SpecPath,
+			Message:  fmt.Sprintf(format, args...),
+			Rule:     policyRuleRender,
+		}}
+	}
+
+	// Lines of a rendered spec do not correspond to the file, so they are not reported
+	templated := bytes.Contains(data, []byte("{{"))
+	if templated {
+		rendered, err := renderPreflightSpec(data, preflight, helmChartManifests)
+		if err != nil {
+			return renderError("failed to render preflight spec: %v", err)
+		}
+		data = rendered
+	}
+
+	objects, err := decodePolicyObjects(data, preflight.SpecPath, !templated)
+	if err != nil {
+		return renderError("failed to parse preflight spec: %v", err)
+	}
+	var messages []LintMessage
+	for _, obj := range objects {
+		messages = append(messages, policies.evaluate(PolicySourcePreflight, obj)...)
+	}
+	return messages
+}
+
+// renderPreflightSpec renders a templated preflight spec as a single chart template
+func renderPreflightSpec(data []byte, preflight PreflightWithValues, helmChartManifests map[string]*HelmChartManifest) ([]byte, error) {
+	values := map[string]interface{}{}
+	if preflight.ValuesPath != "" {
+		fileValues, err := chartutil.ReadValuesFile(preflight.ValuesPath)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", displayPath(preflight.ValuesPath), err)
+		}
+		values = fileValues
+	}
+	if manifest := FindHelmChartManifest(preflight.ChartName, preflight.ChartVersion, helmChartManifests); manifest != nil && manifest.BuilderValues != nil {
+		values = mergeValues(values, manifest.BuilderValues)
+	}
+
+	name := "templates/" + filepath.Base(preflight.SpecPath)
+	spec := &chart.Chart{
+		Metadata:  &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "preflight", Version: "0.0.0"},
+		Templates: []*chart.File{{Name: name, Data: data}},
+	}
+	renderValues, err := chartutil.ToRenderValues(spec, values, chartutil.ReleaseOptions{Name: "release", Namespace: "default"}, nil)
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := engine.Render(spec, renderValues)
+	if err != nil {
+		return nil, err
+	}
+
+	// A single template renders to a single output
+	outputs := make([]string, 0, len(rendered))
+	for _, output := range rendered {
+		outputs = append(outputs, output)
+	}
+	sort.Strings(outputs)
+	return []byte(strings.Join(outputs, "\n---\n")), nil
+}
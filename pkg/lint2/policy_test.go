@@ -0,0 +1,243 @@
+package lint2
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func loadTestPolicies(t *testing.T) *Policies {
+	t.Helper()
+	policies, err := LoadPolicies(filepath.Join("testdata", "policies"))
+	if err != nil {
+		t.Fatalf("LoadPolicies() error = %v", err)
+	}
+	return policies
+}
+
+type policyFinding struct {
+	Severity, Path string
+	Line           int
+	Message, Rule  string
+	Values         string
+}
+
+func policyFindings(result *LintResult) []policyFinding {
+	var findings []policyFinding
+	for _, msg := range result.Messages {
+		findings = append(findings, policyFinding{msg.Severity, msg.Path, msg.Line, msg.Message, msg.Rule, msg.Values})
+	}
+	return findings
+}
+
+func TestLoadPolicies(t *testing.T) {
+	policies := loadTestPolicies(t)
+
+	var ids []string
+	for _, rule := range policies.rules {
+		ids = append(ids, rule.id)
+	}
+	want := []string{"proxy-registry", "preflight-has-analyzers", "no-host-network", "resource-limits"}
+	if !reflect.DeepEqual(ids, want) {
+		t.Errorf("rule ids = %v, want %v", ids, want)
+	}
+}
+
+func TestLoadPolicies_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name:    "missing expression",
+			files:   map[string]string{"a.yaml": "rules:\n  - id: no-expression\n"},
+			wantErr: "rules[0] (no-expression): expression is required",
+		},
+		{
+			name:    "invalid id",
+			files:   map[string]string{"a.yaml": "rules:\n  - id: No_Spaces\n    expression: 'true'\n"},
+			wantErr: `invalid id "No_Spaces"`,
+		},
+		{
+			name:    "invalid severity",
+			files:   map[string]string{"a.yaml": "rules:\n  - id: a\n    severity: fatal\n    expression: 'true'\n"},
+			wantErr: `invalid severity "fatal"`,
+		},
+		{
+			name:    "invalid source",
+			files:   map[string]string{"a.yaml": "rules:\n  - id: a\n    match:\n      sources: [helm]\n    expression: 'true'\n"},
+			wantErr: `invalid source "helm"`,
+		},
+		{
+			name:    "expression does not compile",
+			files:   map[string]string{"a.yaml": "rules:\n  - id: a\n    expression: 'object.spec =='\n"},
+			wantErr: "rules[0] (a): expression:",
+		},
+		{
+			name:    "expression is not a bool",
+			files:   map[string]string{"a.yaml": "rules:\n  - id: a\n    expression: 'size(object)'\n"},
+			wantErr: "must evaluate to bool, got int",
+		},
+		{
+			name:    "unknown field",
+			files:   map[string]string{"a.yaml": "rules:\n  - id: a\n    expresion: 'true'\n"},
+			wantErr: "field expresion not found",
+		},
+		{
+			name: "duplicate id across files",
+			files: map[string]string{
+				"a.yaml": "rules:\n  - id: a\n    expression: 'true'\n",
+				"b.yaml": "rules:\n  - id: a\n    expression: 'false'\n",
+			},
+			wantErr: `rule "a" is already defined in`,
+		},
+		{
+			name:    "rego",
+			files:   map[string]string{"policy.rego": "package main\n"},
+			wantErr: "Rego policies are not supported",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				writeTestFile(t, dir, name, content)
+			}
+			_, err := LoadPolicies(dir)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadPolicies() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLintChartPolicies(t *testing.T) {
+	policies := loadTestPolicies(t)
+	combinations := []HelmValuesCombination{
+		{Name: "chart defaults"},
+		{Name: "host network", Values: map[string]interface{}{"hostNetwork": true}},
+	}
+
+	result, err := LintChartPolicies(filepath.Join("testdata", "charts", "policy"), combinations, policies)
+	if err != nil {
+		t.Fatalf("LintChartPolicies() error = %v", err)
+	}
+	if result.Success {
+		t.Error("expected lint to fail")
+	}
+
+	want := []policyFinding{
+		{"ERROR", "templates/deployment.yaml", 0, `Deployment "web": images must be pulled through proxy.example.com`, "proxy-registry", ""},
+		{"WARNING", "templates/deployment.yaml", 0, `Deployment "web": containers without resource limits: sidecar`, "resource-limits", ""},
+		{"ERROR", "templates/deployment.yaml", 0, `Deployment "web": hostNetwork is not allowed`, "no-host-network", "host network"},
+	}
+	if got := policyFindings(result); !reflect.DeepEqual(got, want) {
+		t.Errorf("messages =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestLintManifestPolicies(t *testing.T) {
+	policies := loadTestPolicies(t)
+	content := `apiVersion: kots.io/v1beta1
+kind: Config
+metadata:
+  name: config
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: debug
+spec:
+  hostNetwork: repl{{ ConfigOptionEquals "network" "host" }}
+  containers:
+    - name: debug
+      image: proxy.example.com/busybox:1.36
+      resources:
+        limits:
+          memory: 64Mi
+`
+	path := writeTestFile(t, t.TempDir(), "manifests.yaml", content)
+
+	result, err := LintManifestPolicies(path, map[string]string{"network": "host"}, policies)
+	if err != nil {
+		t.Fatalf("LintManifestPolicies() error = %v", err)
+	}
+	want := []policyFinding{
+		{"ERROR", path, 6, `Pod "debug": hostNetwork is not allowed`, "no-host-network", ""},
+	}
+	if got := policyFindings(result); !reflect.DeepEqual(got, want) {
+		t.Errorf("messages =\n%+v\nwant\n%+v", got, want)
+	}
+
+	// With the sample config value unset, the template renders hostNetwork: false
+	result, err = LintManifestPolicies(path, nil, policies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Success || len(result.Messages) != 0 {
+		t.Errorf("expected no findings, got %+v", result.Messages)
+	}
+}
+
+func TestLintPreflightPolicies(t *testing.T) {
+	policies := loadTestPolicies(t)
+	dir := t.TempDir()
+	spec := `apiVersion: troubleshoot.sh/v1beta3
+kind: Preflight
+metadata:
+  name: checks
+spec:
+  {{- if .Values.analyzers }}
+  analyzers:
+    - clusterVersion:
+        outcomes:
+          - pass:
+              message: ok
+  {{- end }}
+`
+	specPath := writeTestFile(t, dir, "preflight.yaml", spec)
+	valuesPath := writeTestFile(t, dir, "values.yaml", "analyzers: false\n")
+
+	preflight := PreflightWithValues{SpecPath: specPath, ValuesPath: valuesPath, ChartName: "app", ChartVersion: "1.0.0"}
+	result, err := LintPreflightPolicies(preflight, nil, policies)
+	if err != nil {
+		t.Fatalf("LintPreflightPolicies() error = %v", err)
+	}
+	want := []policyFinding{
+		{"ERROR", specPath, 0, `Preflight "checks": preflight spec has no analyzers`, "preflight-has-analyzers", ""},
+	}
+	if got := policyFindings(result); !reflect.DeepEqual(got, want) {
+		t.Errorf("messages =\n%+v\nwant\n%+v", got, want)
+	}
+
+	// HelmChart builder values are applied on top of the values file
+	manifests := map[string]*HelmChartManifest{
+		"app:1.0.0": {Name: "app", ChartVersion: "1.0.0", BuilderValues: map[string]interface{}{"analyzers": true}},
+	}
+	result, err = LintPreflightPolicies(preflight, manifests, policies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Success || len(result.Messages) != 0 {
+		t.Errorf("expected no findings, got %+v", result.Messages)
+	}
+}
+
+func TestPolicyEvaluate_Errors(t *testing.T) {
+	dir := t.TempDir()
+	content := "rules:\n  - id: replicas\n    expression: object.spec.replicas > 1\n"
+	writeTestFile(t, dir, "rules.yaml", content)
+	policies, err := LoadPolicies(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	object := map[string]any{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]any{"name": "settings"}}
+	messages := policies.evaluate(PolicySourceManifest, policyObject{path: "cm.yaml", object: object})
+	if len(messages) != 1 || messages[0].Severity != "ERROR" || messages[0].Rule != "replicas" ||
+		!strings.HasPrefix(messages[0].Message, `ConfigMap "settings": policy replicas could not be evaluated:`) {
+		t.Errorf("unexpected messages: %+v", messages)
+	}
+}
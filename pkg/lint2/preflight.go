@@ -32,9 +32,12 @@ func LintPreflight(
 	chartVersion string,
 	helmChartManifests map[string]*HelmChartManifest,
 	preflightVersion string,
+	opts ...LintOption,
 ) (*LintResult, error) {
+	options := newLintOptions(opts)
+
 	// Use resolver to get preflight binary
-	resolver := tools.NewResolver()
+	resolver := options.resolver()
 	preflightPath, err := resolver.Resolve(ctx, tools.ToolPreflight, preflightVersion)
 	if err != nil {
 		return nil, fmt.Errorf("resolving preflight: %w", err)
@@ -110,8 +113,8 @@ func LintPreflight(
 	// but we still want to parse and display the output
 	outputStr := string(output)
 
-	// Parse the JSON output
-	messages, parseErr := parsePreflightOutput(outputStr)
+	// Parse the JSON output, dropping suppressed findings
+	messages, suppressed, parseErr := parsePreflightOutput(outputStr, specPath, options.Suppressor)
 	if parseErr != nil {
 		// If we can't parse the output, return both the parse error and original error
 		if err != nil {
@@ -124,10 +127,12 @@ func LintPreflight(
 	// Exit code 0 = no errors, exit code 2 = validation errors
 	success := err == nil
 
-	return &LintResult{
-		Success:  success,
-		Messages: messages,
-	}, nil
+	result := newParsedLintResult(success, messages, suppressed)
+	if options.Strict {
+		applyStrict(result)
+	}
+
+	return result, nil
 }
 
 // isPreflightV1Beta3 checks if a preflight spec is apiVersion v1beta3
@@ -159,12 +164,14 @@ func isPreflightV1Beta3(specPath string) (bool, error) {
 	return hasPreflightKind && hasV1Beta3, nil
 }
 
-// parsePreflightOutput parses preflight lint JSON output into structured messages.
+// parsePreflightOutput parses preflight lint JSON output into structured messages and
+// splits off the findings the suppressor or inline ignore comments in specPath suppress.
 // Uses the common troubleshoot.sh JSON parsing infrastructure.
-func parsePreflightOutput(output string) ([]LintMessage, error) {
+func parsePreflightOutput(output string, specPath string, suppressor *Suppressor) ([]LintMessage, []LintMessage, error) {
 	result, err := parseLintJSON[PreflightLintIssue](output)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return convertLintOutputToMessages(result), nil
+	messages, suppressed := suppressor.split(specPath, convertLintOutputToMessages(result), false)
+	return messages, suppressed, nil
 }
@@ -240,7 +240,7 @@ func TestParsePreflightOutput(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := parsePreflightOutput(tt.output)
+			result, _, err := parsePreflightOutput(tt.output, "", nil)
 
 			if tt.wantErr {
 				if err == nil {
@@ -0,0 +1,395 @@
+package lint2
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/replicatedhq/replicated/pkg/tools"
+	"gopkg.in/yaml.v3"
+)
+
+// Kinds of resources in the release graph
+const (
+	ReleaseGraphChart         = "chart"          // Helm chart directories from the charts section
+	ReleaseGraphHelmChart     = "helmchart"      // Manifest files containing HelmChart custom resources
+	ReleaseGraphPreflight     = "preflight"      // Preflight specs from the preflights section
+	ReleaseGraphSupportBundle = "support-bundle" // Manifest files containing SupportBundle specs
+)
+
+// ReleaseGraphResult contains the release graph findings for a single resource.
+// Message paths of chart results are relative to the chart directory.
+type ReleaseGraphResult struct {
+	Path       string
+	Kind       string
+	Success    bool
+	Messages   []LintMessage
+	Suppressed int
+}
+
+// releaseGraphNode collects the findings for one resource in the graph
+type releaseGraphNode struct {
+	path     string
+	kind     string
+	relative bool
+	messages []LintMessage
+}
+
+func (n *releaseGraphNode) add(severity, rule, path string, line int, format string, args ...interface{}) {
+	n.messages = append(n.messages, LintMessage{
+		Severity: severity,
+		Path:     path,
+		Message:  fmt.Sprintf(format, args...),
+		Line:     line,
+		Rule:     rule,
+	})
+}
+
+// LintReleaseGraph checks that the charts, HelmChart custom resources, preflight specs
+// and support bundle specs in a release reference each other consistently. Unlike the
+// other linters, it looks at every resource together rather than one at a time.
+//
+// Checks performed:
+//   - Every chart has exactly one HelmChart with a matching name and chartVersion
+//   - No two charts share a name and version
+//   - HelmCharts reference a configured chart (orphans are warnings)
+//   - Preflight chartName/chartVersion references resolve to a configured chart
+//     (an error for v1beta3 specs, which are rendered with the chart's values)
+//   - SupportBundle specs in the manifests are shipped by a chart, matched by name
+//
+// Resources are returned in a stable order: charts, then manifests, then preflights.
+func LintReleaseGraph(config *tools.Config, opts ...LintOption) ([]ReleaseGraphResult, error) {
+	options := newLintOptions(opts)
+
+	var charts []ChartWithMetadata
+	if len(config.Charts) > 0 {
+		var err error
+		charts, err = GetChartsWithMetadataFromConfig(config)
+		if err != nil {
+			return nil, err
+		}
+	}
+	chartLookup, duplicateCharts := indexCharts(charts)
+
+	var manifestPaths []string
+	var helmCharts []*HelmChartManifest
+	if len(config.Manifests) > 0 {
+		var err error
+		manifestPaths, err = ExpandManifestGlobs(config.Manifests)
+		if err != nil {
+			return nil, fmt.Errorf("failed to expand manifest globs: %w", err)
+		}
+		helmCharts, err = discoverHelmChartManifests(config.Manifests)
+		if err != nil {
+			return nil, err
+		}
+	}
+	helmChartLookup, duplicateHelmCharts := indexHelmChartManifests(helmCharts)
+
+	var nodes []*releaseGraphNode
+	chartNodes := make(map[string]*releaseGraphNode)
+	for _, chart := range charts {
+		node := &releaseGraphNode{path: chart.Path, kind: ReleaseGraphChart, relative: true}
+		chartNodes[chart.Path] = node
+		nodes = append(nodes, node)
+	}
+
+	helmChartFiles := make(map[string]bool)
+	for _, helmChart := range helmCharts {
+		helmChartFiles[helmChart.FilePath] = true
+	}
+	manifestNodes := make(map[string]*releaseGraphNode)
+	var supportBundles []*releaseGraphNode
+	supportBundleSpecs := make(map[*releaseGraphNode][]supportBundleSpec)
+	for _, path := range manifestPaths {
+		if helmChartFiles[path] {
+			node := &releaseGraphNode{path: path, kind: ReleaseGraphHelmChart}
+			manifestNodes[path] = node
+			nodes = append(nodes, node)
+		} else if specs := supportBundleSpecsInFile(path); len(specs) > 0 {
+			node := &releaseGraphNode{path: path, kind: ReleaseGraphSupportBundle}
+			supportBundles = append(supportBundles, node)
+			supportBundleSpecs[node] = specs
+			nodes = append(nodes, node)
+		}
+	}
+
+	// Charts: duplicates, then the HelmChart each chart is installed with
+	for _, duplicate := range duplicateCharts {
+		_, versionLine := chartMetadataLines(duplicate.SecondPath)
+		chartNodes[duplicate.SecondPath].add("ERROR", "chart-duplicate", "Chart.yaml", versionLine,
+			"chart %q is also configured at %s; each chart name and version must be unique", duplicate.ChartKey, duplicate.FirstPath)
+	}
+
+	chartVersions := make(map[string][]string)
+	for key, chart := range chartLookup {
+		chartVersions[chart.Name] = append(chartVersions[chart.Name], chart.Version)
+		if helmChartLookup[key] != nil {
+			continue
+		}
+
+		// A HelmChart for another version of the chart is reported as a mismatch on the HelmChart
+		mismatched := false
+		for helmChartKey, helmChart := range helmChartLookup {
+			if helmChart.Name == chart.Name && !helmChart.extension && chartLookup[helmChartKey] == nil {
+				mismatched = true
+			}
+		}
+		if !mismatched {
+			nameLine, _ := chartMetadataLines(chart.Path)
+			chartNodes[chart.Path].add("ERROR", "helmchart-missing", "Chart.yaml", nameLine,
+				"chart %q has no HelmChart manifest (kind: HelmChart) in the configured manifests", key)
+		}
+	}
+
+	// HelmChart references: duplicates, version mismatches and orphans
+	for _, duplicate := range duplicateHelmCharts {
+		first := helmChartLookup[duplicate.ChartKey]
+		for _, helmChart := range helmCharts {
+			if helmChart.FilePath != duplicate.SecondFile || helmChart == first {
+				continue
+			}
+			if key := fmt.Sprintf("%s:%s", helmChart.Name, helmChart.ChartVersion); key == duplicate.ChartKey {
+				manifestNodes[helmChart.FilePath].add("ERROR", "helmchart-duplicate", "", helmChart.nameLine,
+					"chart %q is also referenced by %s:%d; each chart must have a single HelmChart", key, first.FilePath, first.nameLine)
+			}
+		}
+	}
+
+	for key, helmChart := range helmChartLookup {
+		node := manifestNodes[helmChart.FilePath]
+		if node == nil || helmChart.extension || len(charts) == 0 || chartLookup[key] != nil {
+			continue
+		}
+		if versions := chartVersions[helmChart.Name]; len(versions) > 0 {
+			sort.Strings(versions)
+			node.add("ERROR", "helmchart-version-mismatch", "", helmChart.versionLine,
+				"chartVersion %q does not match chart %q (version %s)", helmChart.ChartVersion, helmChart.Name, strings.Join(versions, ", "))
+			continue
+		}
+		node.add("WARNING", "helmchart-orphaned", "", helmChart.nameLine,
+			"HelmChart references chart %q which is not configured in the charts section", key)
+	}
+
+	// Support bundles only reach Helm and Embedded Cluster installs through a chart,
+	// so each spec in the manifests must be shipped by a chart under the same name
+	if len(charts) > 0 && len(supportBundles) > 0 {
+		shipped, shipsAll := chartSupportBundleNames(charts)
+		for _, node := range supportBundles {
+			if shipsAll || isInsideChart(node.path, charts) {
+				continue
+			}
+			for _, spec := range supportBundleSpecs[node] {
+				if shipped[spec.name] {
+					continue
+				}
+				node.add("WARNING", "support-bundle-orphaned", "", spec.line,
+					"SupportBundle %q is not shipped by any chart; add it to a chart template as a Secret labeled troubleshoot.sh/kind: support-bundle", spec.name)
+			}
+		}
+	}
+
+	// Preflights: chart references made in the preflights section
+	for _, preflight := range config.Preflights {
+		specPaths, err := DiscoverPreflightPaths(preflight.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to discover preflights from %s: %w", preflight.Path, err)
+		}
+		for _, specPath := range specPaths {
+			node := &releaseGraphNode{path: specPath, kind: ReleaseGraphPreflight}
+			nodes = append(nodes, node)
+			lintReleaseGraphPreflight(node, preflight, chartLookup, chartVersions)
+		}
+	}
+
+	results := make([]ReleaseGraphResult, 0, len(nodes))
+	for _, node := range nodes {
+		// Findings on a file are reported in line order, whatever order the checks ran in
+		sort.SliceStable(node.messages, func(i, j int) bool { return node.messages[i].Line < node.messages[j].Line })
+		result := &LintResult{
+			Success:  !hasErrorMessage(node.messages),
+			Messages: node.messages,
+		}
+		applyLintOptions(options, node.path, result, node.relative)
+
+		results = append(results, ReleaseGraphResult{
+			Path:       node.path,
+			Kind:       node.kind,
+			Success:    result.Success,
+			Messages:   result.Messages,
+			Suppressed: result.Suppressed,
+		})
+	}
+
+	return results, nil
+}
+
+// lintReleaseGraphPreflight checks the chart a preflight spec is configured with
+func lintReleaseGraphPreflight(node *releaseGraphNode, preflight tools.PreflightConfig, chartLookup map[string]*ChartWithMetadata, chartVersions map[string][]string) {
+	if preflight.ChartName == "" {
+		return
+	}
+	if preflight.ChartVersion == "" {
+		node.add("ERROR", "preflight-chart-missing", "", 0,
+			"preflight references chart %q without a chartVersion", preflight.ChartName)
+		return
+	}
+
+	key := fmt.Sprintf("%s:%s", preflight.ChartName, preflight.ChartVersion)
+	if chartLookup[key] != nil {
+		return
+	}
+
+	// v1beta3 specs are rendered with the chart's values, so resolving their chart
+	// fails without it; v1beta2 specs are linted anyway
+	severity := "WARNING"
+	_, err := resolvePreflightWithChart(node.path, preflight.ChartName, preflight.ChartVersion, chartLookup, nil)
+	var notFound *ChartNotFoundError
+	if errors.As(err, &notFound) {
+		severity = "ERROR"
+	}
+
+	if versions := chartVersions[preflight.ChartName]; len(versions) > 0 {
+		sort.Strings(versions)
+		node.add(severity, "preflight-chart-missing", "", 0,
+			"preflight references chart %q but the configured chart is version %s", key, strings.Join(versions, ", "))
+		return
+	}
+	node.add(severity, "preflight-chart-missing", "", 0,
+		"preflight references chart %q which is not configured in the charts section", key)
+}
+
+// supportBundleSpec is a SupportBundle document found in a manifest file
+type supportBundleSpec struct {
+	name string
+	line int
+}
+
+// supportBundleSpecsInFile returns the SupportBundle documents in a manifest file, in
+// file order. Files that cannot be parsed are skipped; the other linters report them.
+func supportBundleSpecsInFile(path string) []supportBundleSpec {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var specs []supportBundleSpec
+	forEachSupportBundle(data, func(root *yaml.Node) {
+		specs = append(specs, supportBundleSpec{
+			name: scalarValue(mappingValue(mappingValue(root, "metadata"), "name")),
+			line: root.Line,
+		})
+	})
+	return specs
+}
+
+// forEachSupportBundle calls fn with the root of every SupportBundle document in data.
+// Decoding stops at the first document that is not valid YAML.
+func forEachSupportBundle(data []byte, fn func(root *yaml.Node)) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yaml.Node
+		if err := decoder.Decode(&doc); err != nil {
+			return
+		}
+		if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+			continue
+		}
+		if scalarValue(mappingValue(doc.Content[0], "kind")) == "SupportBundle" {
+			fn(doc.Content[0])
+		}
+	}
+}
+
+// chartMetadataLines returns the lines of the name and version fields in Chart.yaml,
+// or 0 if they cannot be found
+func chartMetadataLines(chartPath string) (int, int) {
+	data, err := os.ReadFile(filepath.Join(chartPath, "Chart.yaml"))
+	if err != nil {
+		return 0, 0
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return 0, 0
+	}
+	nameLine, versionLine := 0, 0
+	if node := mappingValue(doc.Content[0], "name"); node != nil {
+		nameLine = node.Line
+	}
+	if node := mappingValue(doc.Content[0], "version"); node != nil {
+		versionLine = node.Line
+	}
+	return nameLine, versionLine
+}
+
+// chartSupportBundleNames returns the names of the support bundle specs the charts
+// render with their default values, either as a SupportBundle itself or inside a
+// Secret labeled troubleshoot.sh/kind: support-bundle. When a chart cannot be
+// rendered, shipsAll is true: it is assumed to ship every spec, and the helm linter
+// reports why it fails to render.
+func chartSupportBundleNames(charts []ChartWithMetadata) (names map[string]bool, shipsAll bool) {
+	names = make(map[string]bool)
+	add := func(root *yaml.Node) {
+		names[scalarValue(mappingValue(mappingValue(root, "metadata"), "name"))] = true
+	}
+
+	for _, chart := range charts {
+		manifests, err := renderChartForKubeVersion(chart.Path, HelmValuesCombination{}, "")
+		if err != nil {
+			return nil, true
+		}
+
+		for _, manifest := range manifests {
+			forEachSupportBundle([]byte(manifest.Content), add)
+
+			var doc yaml.Node
+			if err := yaml.Unmarshal([]byte(manifest.Content), &doc); err != nil || len(doc.Content) == 0 {
+				continue
+			}
+			root := doc.Content[0]
+			if scalarValue(mappingValue(root, "kind")) != "Secret" {
+				continue
+			}
+			labels := mappingValue(mappingValue(root, "metadata"), "labels")
+			if scalarValue(mappingValue(labels, "troubleshoot.sh/kind")) != "support-bundle" {
+				continue
+			}
+			for _, value := range secretValues(root) {
+				forEachSupportBundle(value, add)
+			}
+		}
+	}
+	return names, false
+}
+
+// secretValues returns the values of a Secret's stringData and, base64 decoded, data
+func secretValues(root *yaml.Node) [][]byte {
+	var values [][]byte
+	if stringData := mappingValue(root, "stringData"); stringData != nil && stringData.Kind == yaml.MappingNode {
+		for i := 1; i < len(stringData.Content); i += 2 {
+			values = append(values, []byte(stringData.Content[i].Value))
+		}
+	}
+	if data := mappingValue(root, "data"); data != nil && data.Kind == yaml.MappingNode {
+		for i := 1; i < len(data.Content); i += 2 {
+			if decoded, err := base64.StdEncoding.DecodeString(data.Content[i].Value); err == nil {
+				values = append(values, decoded)
+			}
+		}
+	}
+	return values
+}
+
+// isInsideChart reports whether path is inside one of the charts' directories
+func isInsideChart(path string, charts []ChartWithMetadata) bool {
+	for _, chart := range charts {
+		if strings.HasPrefix(path, chart.Path+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,230 @@
+package lint2
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/replicatedhq/replicated/pkg/tools"
+)
+
+func writeReleaseGraphChart(t *testing.T, dir, name, version string) string {
+	t.Helper()
+	writeTestFile(t, dir, filepath.Join(name, "Chart.yaml"), "apiVersion: v2\nname: "+name+"\nversion: "+version+"\n")
+	writeTestFile(t, dir, filepath.Join(name, "values.yaml"), "{}\n")
+	return filepath.Join(dir, name)
+}
+
+func writeReleaseGraphHelmChart(t *testing.T, dir, file, name, version string) {
+	t.Helper()
+	writeTestFile(t, dir, filepath.Join("manifests", file), `apiVersion: kots.io/v1beta2
+kind: HelmChart
+metadata:
+  name: `+name+`
+spec:
+  chart:
+    name: `+name+`
+    chartVersion: `+version+"\n")
+}
+
+func TestLintReleaseGraph(t *testing.T) {
+	dir := t.TempDir()
+	web := writeReleaseGraphChart(t, dir, "web", "1.0.0")
+	api := writeReleaseGraphChart(t, dir, "api", "2.0.0")
+	db := writeReleaseGraphChart(t, dir, "db", "1.0.0")
+	writeTestFile(t, dir, "db-copy/Chart.yaml", "apiVersion: v2\nname: db\nversion: 1.0.0\n")
+	dbCopy := filepath.Join(dir, "db-copy")
+
+	writeReleaseGraphHelmChart(t, dir, "web.yaml", "web", "0.9.0")
+	writeReleaseGraphHelmChart(t, dir, "db.yaml", "db", "1.0.0")
+	writeReleaseGraphHelmChart(t, dir, "db-again.yaml", "db", "1.0.0")
+	writeReleaseGraphHelmChart(t, dir, "worker.yaml", "worker", "1.0.0")
+	writeTestFile(t, dir, "manifests/support-bundle.yaml", `apiVersion: troubleshoot.sh/v1beta2
+kind: SupportBundle
+metadata:
+  name: support
+spec:
+  collectors: []
+`)
+	writeTestFile(t, dir, "preflights/v1beta3.yaml", "apiVersion: troubleshoot.sh/v1beta3\nkind: Preflight\nmetadata:\n  name: v3\n")
+	writeTestFile(t, dir, "preflights-v2/v1beta2.yaml", "apiVersion: troubleshoot.sh/v1beta2\nkind: Preflight\nmetadata:\n  name: v2\n")
+
+	config := &tools.Config{
+		Charts:    []tools.ChartConfig{{Path: web}, {Path: api}, {Path: db}, {Path: dbCopy}},
+		Manifests: []string{filepath.Join(dir, "manifests", "*.yaml")},
+		Preflights: []tools.PreflightConfig{
+			{Path: filepath.Join(dir, "preflights", "v1beta3.yaml"), ChartName: "api", ChartVersion: "1.5.0"},
+			{Path: filepath.Join(dir, "preflights-v2", "v1beta2.yaml"), ChartName: "missing", ChartVersion: "1.0.0"},
+		},
+	}
+
+	results, err := LintReleaseGraph(config)
+	if err != nil {
+		t.Fatalf("LintReleaseGraph() error = %v", err)
+	}
+
+	type finding struct {
+		Resource, Kind, Severity, Rule string
+		Line                           int
+	}
+	var got []finding
+	success := map[string]bool{}
+	for _, result := range results {
+		name, _ := filepath.Rel(dir, result.Path)
+		success[name] = result.Success
+		for _, msg := range result.Messages {
+			got = append(got, finding{name, result.Kind, msg.Severity, msg.Rule, msg.Line})
+		}
+	}
+	sort.Slice(got, func(i, j int) bool { return got[i].Resource < got[j].Resource })
+
+	want := []finding{
+		{"api", ReleaseGraphChart, "ERROR", "helmchart-missing", 2},
+		{"db-copy", ReleaseGraphChart, "ERROR", "chart-duplicate", 3},
+		{"manifests/db.yaml", ReleaseGraphHelmChart, "ERROR", "helmchart-duplicate", 7},
+		{"manifests/support-bundle.yaml", ReleaseGraphSupportBundle, "WARNING", "support-bundle-orphaned", 1},
+		{"manifests/web.yaml", ReleaseGraphHelmChart, "ERROR", "helmchart-version-mismatch", 8},
+		{"manifests/worker.yaml", ReleaseGraphHelmChart, "WARNING", "helmchart-orphaned", 7},
+		{"preflights-v2/v1beta2.yaml", ReleaseGraphPreflight, "WARNING", "preflight-chart-missing", 0},
+		{"preflights/v1beta3.yaml", ReleaseGraphPreflight, "ERROR", "preflight-chart-missing", 0},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findings =\n%+v\nwant\n%+v", got, want)
+	}
+
+	// The web chart is covered by the version mismatch reported on its HelmChart
+	if !success["web"] || !success["db"] || !success["manifests/db-again.yaml"] {
+		t.Errorf("expected web, db and the first db HelmChart to pass, got %v", success)
+	}
+	if !success["preflights-v2/v1beta2.yaml"] {
+		t.Error("expected a v1beta2 preflight with a missing chart to only warn")
+	}
+}
+
+func TestLintReleaseGraph_Consistent(t *testing.T) {
+	dir := t.TempDir()
+	web := writeReleaseGraphChart(t, dir, "web", "1.0.0")
+	writeTestFile(t, dir, "web/templates/support-bundle.yaml", `apiVersion: v1
+kind: Secret
+metadata:
+  name: support-bundle
+  labels:
+    troubleshoot.sh/kind: support-bundle
+stringData:
+  support-bundle-spec: |
+    apiVersion: troubleshoot.sh/v1beta2
+    kind: SupportBundle
+    metadata:
+      name: support
+`)
+	writeReleaseGraphHelmChart(t, dir, "web.yaml", "web", "1.0.0")
+	writeTestFile(t, dir, "manifests/support-bundle.yaml", "apiVersion: troubleshoot.sh/v1beta2\nkind: SupportBundle\nmetadata:\n  name: support\n")
+	// Embedded Cluster extensions install charts that are not packaged with the release
+	writeTestFile(t, dir, "manifests/ec.yaml", `apiVersion: embeddedcluster.replicated.com/v1beta1
+kind: Config
+spec:
+  extensions:
+    helmCharts:
+      - chart:
+          name: ingress-nginx
+          chartVersion: 4.11.0
+`)
+	writeTestFile(t, dir, "preflights/preflight.yaml", "apiVersion: troubleshoot.sh/v1beta3\nkind: Preflight\nmetadata:\n  name: web\n")
+
+	config := &tools.Config{
+		Charts:     []tools.ChartConfig{{Path: web}},
+		Manifests:  []string{filepath.Join(dir, "manifests", "*.yaml")},
+		Preflights: []tools.PreflightConfig{{Path: filepath.Join(dir, "preflights", "preflight.yaml"), ChartName: "web", ChartVersion: "1.0.0"}},
+	}
+
+	results, err := LintReleaseGraph(config)
+	if err != nil {
+		t.Fatalf("LintReleaseGraph() error = %v", err)
+	}
+	if len(results) != 5 {
+		t.Errorf("expected 5 resources, got %d", len(results))
+	}
+	for _, result := range results {
+		if !result.Success || len(result.Messages) > 0 {
+			t.Errorf("%s: expected no findings, got %+v", result.Path, result.Messages)
+		}
+	}
+}
+
+func TestLintReleaseGraph_SupportBundleMatching(t *testing.T) {
+	dir := t.TempDir()
+	web := writeReleaseGraphChart(t, dir, "web", "1.0.0")
+	writeTestFile(t, dir, "web/templates/support-bundle.yaml", "apiVersion: troubleshoot.sh/v1beta2\nkind: SupportBundle\nmetadata:\n  name: web-support\n")
+	api := writeReleaseGraphChart(t, dir, "api", "1.0.0")
+	writeTestFile(t, dir, "api/templates/support-bundle.yaml", `apiVersion: v1
+kind: Secret
+metadata:
+  name: api-support
+  labels:
+    troubleshoot.sh/kind: support-bundle
+data:
+  support-bundle-spec: YXBpVmVyc2lvbjogdHJvdWJsZXNob290LnNoL3YxYmV0YTIKa2luZDogU3VwcG9ydEJ1bmRsZQptZXRhZGF0YToKICBuYW1lOiBhcGktc3VwcG9ydAo=
+`)
+	writeReleaseGraphHelmChart(t, dir, "web.yaml", "web", "1.0.0")
+	writeReleaseGraphHelmChart(t, dir, "api.yaml", "api", "1.0.0")
+	writeTestFile(t, dir, "manifests/support-bundles.yaml", `apiVersion: troubleshoot.sh/v1beta2
+kind: SupportBundle
+metadata:
+  name: web-support
+---
+apiVersion: troubleshoot.sh/v1beta2
+kind: SupportBundle
+metadata:
+  name: db-support
+---
+apiVersion: troubleshoot.sh/v1beta2
+kind: SupportBundle
+metadata:
+  name: api-support
+`)
+
+	config := &tools.Config{
+		Charts:    []tools.ChartConfig{{Path: web}, {Path: api}},
+		Manifests: []string{filepath.Join(dir, "manifests", "*.yaml")},
+	}
+
+	results, err := LintReleaseGraph(config)
+	if err != nil {
+		t.Fatalf("LintReleaseGraph() error = %v", err)
+	}
+
+	// Charts shipping other support bundles do not cover the db spec
+	var messages []LintMessage
+	for _, result := range results {
+		messages = append(messages, result.Messages...)
+	}
+	if len(messages) != 1 || messages[0].Rule != "support-bundle-orphaned" || messages[0].Line != 6 ||
+		!strings.Contains(messages[0].Message, `"db-support"`) {
+		t.Errorf("expected only the db support bundle to be orphaned, got %+v", messages)
+	}
+}
+
+func TestLintReleaseGraph_IgnoreRules(t *testing.T) {
+	dir := t.TempDir()
+	api := writeReleaseGraphChart(t, dir, "api", "2.0.0")
+	writeTestFile(t, dir, "manifests/app.yaml", "apiVersion: kots.io/v1beta1\nkind: Application\nmetadata:\n  name: app\n")
+
+	suppressor, err := NewSuppressor([]tools.IgnoreRule{{Rule: "helmchart-missing"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &tools.Config{
+		Charts:    []tools.ChartConfig{{Path: api}},
+		Manifests: []string{filepath.Join(dir, "manifests", "*.yaml")},
+	}
+
+	results, err := LintReleaseGraph(config, WithSuppressor(suppressor))
+	if err != nil {
+		t.Fatalf("LintReleaseGraph() error = %v", err)
+	}
+	if len(results) != 1 || !results[0].Success || results[0].Suppressed != 1 {
+		t.Errorf("expected the missing HelmChart to be suppressed, got %+v", results)
+	}
+}
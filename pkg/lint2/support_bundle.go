@@ -17,9 +17,11 @@ type SupportBundleLintIssue struct {
 }
 
 // LintSupportBundle executes support-bundle lint on the given spec path and returns structured results
-func LintSupportBundle(ctx context.Context, specPath string, sbVersion string) (*LintResult, error) {
+func LintSupportBundle(ctx context.Context, specPath string, sbVersion string, opts ...LintOption) (*LintResult, error) {
+	options := newLintOptions(opts)
+
 	// Use resolver to get support-bundle binary
-	resolver := tools.NewResolver()
+	resolver := options.resolver()
 	sbPath, err := resolver.Resolve(ctx, tools.ToolSupportBundle, sbVersion)
 	if err != nil {
 		return nil, fmt.Errorf("resolving support-bundle: %w", err)
@@ -46,8 +48,8 @@ func LintSupportBundle(ctx context.Context, specPath string, sbVersion string) (
 	// but we still want to parse and display the output
 	outputStr := string(output)
 
-	// Parse the JSON output
-	messages, parseErr := parseSupportBundleOutput(outputStr)
+	// Parse the JSON output, dropping suppressed findings
+	messages, suppressed, parseErr := parseSupportBundleOutput(outputStr, specPath, options.Suppressor)
 	if parseErr != nil {
 		// If we can't parse the output, return both the parse error and original error
 		if err != nil {
@@ -60,18 +62,22 @@ func LintSupportBundle(ctx context.Context, specPath string, sbVersion string) (
 	// Exit code 0 = no errors, exit code 2 = validation errors
 	success := err == nil
 
-	return &LintResult{
-		Success:  success,
-		Messages: messages,
-	}, nil
+	result := newParsedLintResult(success, messages, suppressed)
+	if options.Strict {
+		applyStrict(result)
+	}
+
+	return result, nil
 }
 
-// parseSupportBundleOutput parses support-bundle lint JSON output into structured messages.
-// Uses the common troubleshoot.sh JSON parsing infrastructure.
-func parseSupportBundleOutput(output string) ([]LintMessage, error) {
+// parseSupportBundleOutput parses support-bundle lint JSON output into structured
+// messages and splits off the findings the suppressor or inline ignore comments in
+// specPath suppress. Uses the common troubleshoot.sh JSON parsing infrastructure.
+func parseSupportBundleOutput(output string, specPath string, suppressor *Suppressor) ([]LintMessage, []LintMessage, error) {
 	result, err := parseLintJSON[SupportBundleLintIssue](output)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return convertLintOutputToMessages(result), nil
+	messages, suppressed := suppressor.split(specPath, convertLintOutputToMessages(result), false)
+	return messages, suppressed, nil
 }
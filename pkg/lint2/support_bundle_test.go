@@ -240,7 +240,7 @@ func TestParseSupportBundleOutput(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := parseSupportBundleOutput(tt.output)
+			result, _, err := parseSupportBundleOutput(tt.output, "", nil)
 
 			if tt.wantErr {
 				if err == nil {
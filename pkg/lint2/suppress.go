@@ -0,0 +1,319 @@
+package lint2
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/replicatedhq/replicated/pkg/tools"
+)
+
+// compiledIgnoreRule is a tools.IgnoreRule with its message regex compiled and
+// its path made absolute
+type compiledIgnoreRule struct {
+	rule    string
+	path    string
+	message *regexp.Regexp
+}
+
+// Suppressor drops lint findings that match configured ignore rules or inline
+// ignore comments in the file a finding points at. A nil Suppressor only applies
+// inline ignore comments.
+type Suppressor struct {
+	rules []compiledIgnoreRule
+}
+
+// NewSuppressor compiles the ignore rules from a linter's config
+func NewSuppressor(rules []tools.IgnoreRule) (*Suppressor, error) {
+	s := &Suppressor{}
+	for i, rule := range rules {
+		compiled := compiledIgnoreRule{rule: rule.Rule}
+
+		if rule.Message != "" {
+			re, err := regexp.Compile(rule.Message)
+			if err != nil {
+				return nil, fmt.Errorf("invalid message regex in ignore[%d]: %w", i, err)
+			}
+			compiled.message = re
+		}
+
+		if rule.Path != "" {
+			absPath, err := filepath.Abs(rule.Path)
+			if err != nil {
+				return nil, fmt.Errorf("invalid path in ignore[%d]: %w", i, err)
+			}
+			compiled.path = absPath
+		}
+
+		s.rules = append(s.rules, compiled)
+	}
+	return s, nil
+}
+
+// Apply removes suppressed findings from messages and returns the remaining
+// messages with the number suppressed. basePath is the linted resource; messages
+// without a path apply to it. When messagePathsRelative is true, message paths are
+// relative to basePath (as with helm, which reports paths inside the chart).
+func (s *Suppressor) Apply(basePath string, messages []LintMessage, messagePathsRelative bool) ([]LintMessage, int) {
+	kept, suppressed := s.split(basePath, messages, messagePathsRelative)
+	return kept, len(suppressed)
+}
+
+// split divides messages into the findings to report and the suppressed findings,
+// with the same arguments as Apply
+func (s *Suppressor) split(basePath string, messages []LintMessage, messagePathsRelative bool) ([]LintMessage, []LintMessage) {
+	inline := make(map[string]*inlineIgnores)
+	kept := make([]LintMessage, 0, len(messages))
+	var suppressed []LintMessage
+
+	for _, msg := range messages {
+		path := msg.Path
+		switch {
+		case path == "":
+			path = basePath
+		case messagePathsRelative && !filepath.IsAbs(path):
+			path = filepath.Join(basePath, path)
+		}
+
+		ignores, ok := inline[path]
+		if !ok {
+			ignores = parseInlineIgnores(path)
+			inline[path] = ignores
+		}
+
+		if ignores.matches(msg) || s.matches(path, msg) {
+			suppressed = append(suppressed, msg)
+			continue
+		}
+		kept = append(kept, msg)
+	}
+
+	return kept, suppressed
+}
+
+// matches reports whether any configured ignore rule matches a finding in path
+func (s *Suppressor) matches(path string, msg LintMessage) bool {
+	if s == nil {
+		return false
+	}
+
+	for _, rule := range s.rules {
+		if rule.rule != "" && rule.rule != msg.Rule {
+			continue
+		}
+		if rule.message != nil && !rule.message.MatchString(msg.Message) {
+			continue
+		}
+		if rule.path != "" && !ignorePathMatches(rule.path, path) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// ignorePathMatches reports whether path matches an ignore rule's path. Glob
+// patterns are matched with doublestar; plain paths match the file itself and,
+// for directories, everything below it.
+func ignorePathMatches(pattern, path string) bool {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+
+	if ContainsGlob(pattern) {
+		matched, err := doublestar.PathMatch(pattern, absPath)
+		return err == nil && matched
+	}
+
+	return absPath == pattern || strings.HasPrefix(absPath, pattern+string(filepath.Separator))
+}
+
+// applySuppression runs the suppressor from opts over a lint result. A resource that
+// only failed because of suppressed errors is considered successful.
+func applySuppression(opts LintOptions, basePath string, result *LintResult, messagePathsRelative bool) {
+	hadErrors := hasErrorMessage(result.Messages)
+	result.Messages, result.Suppressed = opts.Suppressor.Apply(basePath, result.Messages, messagePathsRelative)
+	if !result.Success && hadErrors && !hasErrorMessage(result.Messages) {
+		result.Success = true
+	}
+}
+
+// newParsedLintResult builds the result of a tool run from the findings its parse
+// function kept and suppressed. A resource that only failed because of suppressed
+// errors is considered successful.
+func newParsedLintResult(success bool, messages, suppressed []LintMessage) *LintResult {
+	if !success && hasErrorMessage(suppressed) && !hasErrorMessage(messages) {
+		success = true
+	}
+	return &LintResult{
+		Success:    success,
+		Messages:   messages,
+		Suppressed: len(suppressed),
+	}
+}
+
+func hasErrorMessage(messages []LintMessage) bool {
+	for _, msg := range messages {
+		if msg.Severity == "ERROR" {
+			return true
+		}
+	}
+	return false
+}
+
+// inlineIgnorePattern matches inline ignore directives in YAML comments. Both the
+// replicated-lint- and the shorter repl-lint- prefixes are accepted:
+//
+//	# replicated-lint-ignore                  ignore findings on this line (trailing) or the next line
+//	# replicated-lint-ignore-next             ignore findings on the next line
+//	# replicated-lint-ignore-start / -end     ignore findings between the two comments
+//	# replicated-lint-ignore-file             ignore all findings in the file
+//
+// Any directive may be followed by a colon and a comma-separated list of rule IDs
+// to only ignore those rules, e.g. "# replicated-lint-ignore: application-title".
+//
+// Files with Helm template actions ({{ }}) are linted after rendering, so the line a
+// finding reports is a line of the rendered output. Only -file directives apply to them.
+var inlineIgnorePattern = regexp.MustCompile(`#\s*(?:replicated|repl)-lint-ignore(-next|-start|-end|-file)?\b(?:\s*:\s*([\w\-, ]+))?`)
+
+// inlineIgnores holds the inline ignore directives found in a single file.
+// A nil rule list means all rules.
+type inlineIgnores struct {
+	file     []ruleSet
+	lines    map[int][]ruleSet
+	rendered bool // the file is a Helm template; line directives don't apply
+}
+
+type ruleSet map[string]bool
+
+func (r ruleSet) contains(rule string) bool {
+	return r == nil || r[rule]
+}
+
+func parseRuleSet(list string) ruleSet {
+	list = strings.TrimSpace(list)
+	if list == "" {
+		return nil
+	}
+	rules := make(ruleSet)
+	for _, rule := range strings.Split(list, ",") {
+		if rule = strings.TrimSpace(rule); rule != "" {
+			rules[rule] = true
+		}
+	}
+	return rules
+}
+
+// parseInlineIgnores reads the inline ignore directives from a file. Unreadable
+// files (directories, removed temp files) have no directives.
+func parseInlineIgnores(path string) *inlineIgnores {
+	ignores := &inlineIgnores{lines: make(map[int][]ruleSet)}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return ignores
+	}
+	defer f.Close()
+
+	var pending []ruleSet // directives waiting for the next content line
+	var block []ruleSet   // open -start directives
+	lineNum := 0
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if !ignores.rendered && hasHelmTemplateAction(line) {
+			ignores.rendered = true
+		}
+
+		match := inlineIgnorePattern.FindStringSubmatchIndex(line)
+		if match == nil {
+			if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+			ignores.lines[lineNum] = append(ignores.lines[lineNum], block...)
+			ignores.lines[lineNum] = append(ignores.lines[lineNum], pending...)
+			pending = nil
+			continue
+		}
+
+		directive := ""
+		if match[2] >= 0 {
+			directive = line[match[2]:match[3]]
+		}
+		var rules ruleSet
+		if match[4] >= 0 {
+			rules = parseRuleSet(line[match[4]:match[5]])
+		}
+		trailing := strings.TrimSpace(line[:match[0]]) != ""
+
+		switch directive {
+		case "-file":
+			ignores.file = append(ignores.file, rules)
+		case "-start":
+			block = append(block, rules)
+		case "-end":
+			if len(block) > 0 {
+				block = block[:len(block)-1]
+			}
+		case "-next":
+			pending = append(pending, rules)
+		default:
+			if trailing {
+				ignores.lines[lineNum] = append(ignores.lines[lineNum], rules)
+			} else {
+				pending = append(pending, rules)
+			}
+		}
+
+		// A trailing directive still leaves this line subject to open blocks
+		if trailing {
+			ignores.lines[lineNum] = append(ignores.lines[lineNum], block...)
+		}
+	}
+
+	return ignores
+}
+
+// matches reports whether a finding is suppressed by an inline directive
+func (i *inlineIgnores) matches(msg LintMessage) bool {
+	for _, rules := range i.file {
+		if rules.contains(msg.Rule) {
+			return true
+		}
+	}
+	if msg.Line <= 0 || i.rendered {
+		return false
+	}
+	for _, rules := range i.lines[msg.Line] {
+		if rules.contains(msg.Rule) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasHelmTemplateAction reports whether line contains a Helm template action. KOTS
+// template delimiters (repl{{ and {{repl) are not Helm actions: KOTS manifests are
+// linted as written.
+func hasHelmTemplateAction(line string) bool {
+	for offset := 0; ; {
+		i := strings.Index(line[offset:], "{{")
+		if i < 0 {
+			return false
+		}
+		i += offset
+		if !strings.HasSuffix(line[:i], "repl") && !strings.HasPrefix(line[i+2:], "repl") {
+			return true
+		}
+		offset = i + 2
+	}
+}
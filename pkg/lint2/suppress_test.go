@@ -0,0 +1,190 @@
+package lint2
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/replicatedhq/replicated/pkg/tools"
+)
+
+func TestSuppressor_IgnoreRules(t *testing.T) {
+	dir := t.TempDir()
+	appPath := writeTestFile(t, dir, "manifests/app.yaml", "kind: Application\n")
+	legacyPath := writeTestFile(t, dir, "legacy/config.yaml", "kind: Config\n")
+
+	tests := []struct {
+		name string
+		rule tools.IgnoreRule
+		msg  LintMessage
+		want bool
+	}{
+		{"rule matches", tools.IgnoreRule{Rule: "application-title"}, LintMessage{Rule: "application-title", Path: appPath}, true},
+		{"rule differs", tools.IgnoreRule{Rule: "application-title"}, LintMessage{Rule: "application-ports", Path: appPath}, false},
+		{"message regex", tools.IgnoreRule{Message: "^icon .*recommended$"}, LintMessage{Message: "icon is recommended"}, true},
+		{"path glob", tools.IgnoreRule{Path: filepath.Join(dir, "manifests", "*.yaml")}, LintMessage{Path: appPath}, true},
+		{"path directory", tools.IgnoreRule{Path: filepath.Join(dir, "legacy")}, LintMessage{Path: legacyPath}, true},
+		{"path does not match", tools.IgnoreRule{Path: filepath.Join(dir, "legacy")}, LintMessage{Path: appPath}, false},
+		{"all fields must match", tools.IgnoreRule{Rule: "application-title", Path: filepath.Join(dir, "legacy")}, LintMessage{Rule: "application-title", Path: appPath}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewSuppressor([]tools.IgnoreRule{tt.rule})
+			if err != nil {
+				t.Fatalf("NewSuppressor() error = %v", err)
+			}
+			kept, suppressed := s.Apply(appPath, []LintMessage{tt.msg}, false)
+			if got := suppressed == 1; got != tt.want {
+				t.Errorf("suppressed = %v, want %v (kept %+v)", got, tt.want, kept)
+			}
+		})
+	}
+}
+
+func TestNewSuppressor_InvalidRegex(t *testing.T) {
+	if _, err := NewSuppressor([]tools.IgnoreRule{{Message: "(["}}); err == nil {
+		t.Error("expected error for invalid message regex")
+	}
+}
+
+func TestSuppressor_InlineIgnores(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTestFile(t, dir, "app.yaml", `apiVersion: kots.io/v1beta1
+kind: Application
+metadata:
+  name: app # replicated-lint-ignore
+spec:
+  # repl-lint-ignore-next: application-title
+  title: ""
+  # replicated-lint-ignore-start
+  icon: foo
+  ports: []
+  # replicated-lint-ignore-end
+  statusInformers: bad
+`)
+
+	messages := []LintMessage{
+		{Severity: "ERROR", Line: 4, Rule: "metadata-name", Message: "trailing"},
+		{Severity: "ERROR", Line: 7, Rule: "application-title", Message: "next line, listed rule"},
+		{Severity: "ERROR", Line: 7, Rule: "other-rule", Message: "next line, other rule"},
+		{Severity: "WARNING", Line: 9, Message: "in block"},
+		{Severity: "WARNING", Line: 10, Message: "in block"},
+		{Severity: "ERROR", Line: 12, Rule: "application-status-informers", Message: "after block"},
+		{Severity: "ERROR", Message: "no line"},
+	}
+
+	var s *Suppressor
+	kept, suppressed := s.Apply(path, messages, false)
+	if suppressed != 4 {
+		t.Errorf("suppressed = %d, want 4", suppressed)
+	}
+	want := []string{"next line, other rule", "after block", "no line"}
+	if len(kept) != len(want) {
+		t.Fatalf("kept %+v, want messages %v", kept, want)
+	}
+	for i, msg := range kept {
+		if msg.Message != want[i] {
+			t.Errorf("kept[%d] = %q, want %q", i, msg.Message, want[i])
+		}
+	}
+}
+
+func TestSuppressor_InlineIgnoreFile(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "templates/deployment.yaml", "# replicated-lint-ignore-file\nkind: Deployment\n")
+
+	// Helm reports message paths relative to the chart directory
+	kept, suppressed := (*Suppressor)(nil).Apply(dir, []LintMessage{
+		{Severity: "ERROR", Path: "templates/deployment.yaml", Message: "bad template"},
+		{Severity: "ERROR", Path: "values.yaml", Message: "bad values"},
+	}, true)
+	if suppressed != 1 || len(kept) != 1 || kept[0].Path != "values.yaml" {
+		t.Errorf("expected only the ignored file's finding to be suppressed, got kept=%+v suppressed=%d", kept, suppressed)
+	}
+}
+
+func TestSuppressor_InlineIgnoresInTemplates(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "templates/deployment.yaml", `# repl-lint-ignore-file: kube-deprecated
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Release.Name }} # replicated-lint-ignore
+`)
+	manifest := writeTestFile(t, dir, "manifests/config.yaml", `apiVersion: v1
+kind: ConfigMap
+data:
+  KEY: repl{{ ConfigOption "key" }} # replicated-lint-ignore
+`)
+
+	// Lines in a Helm template refer to the rendered output, so only -file directives apply
+	kept, suppressed := (*Suppressor)(nil).Apply(dir, []LintMessage{
+		{Severity: "ERROR", Path: "templates/deployment.yaml", Line: 5, Message: "rendered line"},
+		{Severity: "WARNING", Path: "templates/deployment.yaml", Rule: "kube-deprecated", Message: "whole file"},
+	}, true)
+	if suppressed != 1 || len(kept) != 1 || kept[0].Message != "rendered line" {
+		t.Errorf("expected only the file directive to apply to a template, got kept=%+v suppressed=%d", kept, suppressed)
+	}
+
+	// KOTS template functions don't make a manifest rendered
+	if _, suppressed := (*Suppressor)(nil).Apply(manifest, []LintMessage{{Severity: "ERROR", Line: 4, Message: "source line"}}, false); suppressed != 1 {
+		t.Errorf("expected the line directive to apply to a KOTS manifest, got suppressed=%d", suppressed)
+	}
+}
+
+func TestApplySuppression_Success(t *testing.T) {
+	s, err := NewSuppressor([]tools.IgnoreRule{{Message: "known error"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := &LintResult{
+		Success: false,
+		Messages: []LintMessage{
+			{Severity: "ERROR", Message: "known error"},
+			{Severity: "WARNING", Message: "still reported"},
+		},
+	}
+	applySuppression(newLintOptions([]LintOption{WithSuppressor(s)}), "", result, false)
+
+	if !result.Success {
+		t.Error("expected result to pass once its only error is suppressed")
+	}
+	if result.Suppressed != 1 || len(result.Messages) != 1 {
+		t.Errorf("expected 1 suppressed and 1 remaining message, got %d and %+v", result.Suppressed, result.Messages)
+	}
+}
+
+func TestParseHelmOutput_Suppression(t *testing.T) {
+	chartPath := t.TempDir()
+	writeTestFile(t, chartPath, "templates/deployment.yaml", "# replicated-lint-ignore-file\nkind: Deployment\n")
+
+	s, err := NewSuppressor([]tools.IgnoreRule{{Message: "^icon is recommended$"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output := `==> Linting ./my-chart
+[INFO] Chart.yaml: icon is recommended
+[ERROR] templates/deployment.yaml: unable to parse YAML
+[WARNING] chart directory is missing values.yaml
+
+1 chart(s) linted, 1 chart(s) failed
+`
+	messages, suppressed := parseHelmOutput(output, chartPath, s)
+	if len(suppressed) != 2 {
+		t.Errorf("expected the ignored message and the ignored file's finding to be suppressed, got %+v", suppressed)
+	}
+	if len(messages) != 1 || messages[0].Severity != "WARNING" {
+		t.Fatalf("expected only the warning to be reported, got %+v", messages)
+	}
+
+	// helm failed only because of the suppressed error
+	result := newParsedLintResult(false, messages, suppressed)
+	if !result.Success {
+		t.Error("expected result to pass once its only error is suppressed")
+	}
+	if result.Suppressed != 2 {
+		t.Errorf("Suppressed = %d, want 2", result.Suppressed)
+	}
+}
@@ -72,6 +72,14 @@ func formatLintMessage(issue LintIssue) string {
 	return msg
 }
 
+// lintIssueRule returns the rule of an issue, or "" if the issue doesn't carry one
+func lintIssueRule(issue LintIssue) string {
+	if ruled, ok := issue.(RuledLintIssue); ok {
+		return ruled.GetRule()
+	}
+	return ""
+}
+
 // convertLintOutputToMessages converts a LintOutput into LintMessages.
 func convertLintOutputToMessages[T LintIssue](result *LintOutput[T]) []LintMessage {
 	var messages []LintMessage
@@ -82,6 +90,9 @@ func convertLintOutputToMessages[T LintIssue](result *LintOutput[T]) []LintMessa
 				Severity: "ERROR",
 				Path:     fileResult.FilePath,
 				Message:  formatLintMessage(issue),
+				Line:     issue.GetLine(),
+				Column:   issue.GetColumn(),
+				Rule:     lintIssueRule(issue),
 			})
 		}
 		for _, issue := range fileResult.Warnings {
@@ -89,6 +100,9 @@ func convertLintOutputToMessages[T LintIssue](result *LintOutput[T]) []LintMessa
 				Severity: "WARNING",
 				Path:     fileResult.FilePath,
 				Message:  formatLintMessage(issue),
+				Line:     issue.GetLine(),
+				Column:   issue.GetColumn(),
+				Rule:     lintIssueRule(issue),
 			})
 		}
 		for _, issue := range fileResult.Info {
@@ -96,6 +110,9 @@ func convertLintOutputToMessages[T LintIssue](result *LintOutput[T]) []LintMessa
 				Severity: "INFO",
 				Path:     fileResult.FilePath,
 				Message:  formatLintMessage(issue),
+				Line:     issue.GetLine(),
+				Column:   issue.GetColumn(),
+				Rule:     lintIssueRule(issue),
 			})
 		}
 	}
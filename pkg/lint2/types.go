@@ -2,13 +2,19 @@ package lint2
 
 // LintResult represents the outcome of linting a chart
 type LintResult struct {
-	Success  bool
-	Messages []LintMessage
+	Success    bool
+	Messages   []LintMessage
+	Suppressed int // Findings dropped by ignore rules or inline ignore comments
 }
 
-// LintMessage represents a single finding from helm lint
+// LintMessage represents a single finding from a linter
 type LintMessage struct {
-	Severity string // "ERROR", "WARNING", "INFO"
-	Path     string // File path (if provided by helm)
-	Message  string // The lint message
+	Severity     string // "ERROR", "WARNING", "INFO"
+	Path         string // File path (if provided by the linter)
+	Message      string // The lint message
+	Line         int    // 1-based line number within Path (0 if unknown)
+	Column       int    // 1-based column within Line (0 if unknown)
+	Rule         string // Identifier of the check that produced the message (empty if unknown)
+	PromotedFrom string // Original severity when strict mode raised it to ERROR (empty otherwise)
+	Values       string // Values combinations that produced the message, when not all did (helm only)
 }
@@ -0,0 +1,314 @@
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/replicatedhq/replicated/pkg/lint2"
+)
+
+// ErrExitWithoutShutdown is returned by Run when the client sends exit without
+// requesting a shutdown first
+var ErrExitWithoutShutdown = errors.New("exit received before shutdown")
+
+// LintFunc lints the file at path and returns the messages reported for it, keyed by
+// the name of the linter that reported them. root is the workspace root the client
+// opened, or empty if it didn't name one.
+type LintFunc func(ctx context.Context, root, path string) (map[string][]lint2.LintMessage, error)
+
+// Server is a Language Server Protocol server that lints documents when they are
+// opened or saved and publishes the findings as diagnostics. Requests are handled one
+// at a time, in the order they are received.
+type Server struct {
+	lint    LintFunc
+	name    string
+	version string
+
+	out         io.Writer
+	root        string
+	initialized bool
+	shutdown    bool
+	documents   map[string]string // open document URI to its latest text
+}
+
+// NewServer returns a server that lints documents with lint. name and version
+// identify the server to the client.
+func NewServer(lint LintFunc, name, version string) *Server {
+	return &Server{
+		lint:      lint,
+		name:      name,
+		version:   version,
+		documents: make(map[string]string),
+	}
+}
+
+// Run serves the protocol over in and out until the client sends exit or closes in.
+func (s *Server) Run(ctx context.Context, in io.Reader, out io.Writer) error {
+	s.out = out
+	r := bufio.NewReader(in)
+
+	for {
+		body, err := readMessage(r)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		var msg message
+		if err := json.Unmarshal(body, &msg); err != nil {
+			if err := s.replyError(nil, codeParseError, fmt.Sprintf("invalid message: %v", err)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return ErrExitWithoutShutdown
+			}
+			return nil
+		}
+
+		if err := s.handle(ctx, &msg); err != nil {
+			return err
+		}
+	}
+}
+
+// handle dispatches a request or notification. Only errors writing to the client are
+// returned; everything else is reported to the client.
+func (s *Server) handle(ctx context.Context, msg *message) error {
+	isRequest := msg.ID != nil
+
+	if !s.initialized && msg.Method != "initialize" {
+		if isRequest {
+			return s.replyError(msg.ID, codeServerNotInitialized, "server is not initialized")
+		}
+		return nil
+	}
+
+	switch msg.Method {
+	case "initialize":
+		var params initializeParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return s.replyError(msg.ID, codeInvalidParams, fmt.Sprintf("invalid initialize params: %v", err))
+		}
+		s.root = workspaceRoot(params)
+		s.initialized = true
+		return s.reply(msg.ID, initializeResult{
+			Capabilities: serverCapabilities{
+				TextDocumentSync: textDocumentSyncOptions{
+					OpenClose: true,
+					Change:    textDocumentSyncFull,
+					Save:      saveOptions{IncludeText: false},
+				},
+			},
+			ServerInfo: serverInfo{Name: s.name, Version: s.version},
+		})
+
+	case "shutdown":
+		s.shutdown = true
+		return s.reply(msg.ID, nil)
+
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return s.logMessage(messageTypeError, fmt.Sprintf("invalid didOpen params: %v", err))
+		}
+		s.documents[params.TextDocument.URI] = params.TextDocument.Text
+		return s.lintDocument(ctx, params.TextDocument.URI)
+
+	case "textDocument/didChange":
+		// Documents are linted as saved on disk, so changes only update the text used
+		// to place diagnostics
+		var params didChangeParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return s.logMessage(messageTypeError, fmt.Sprintf("invalid didChange params: %v", err))
+		}
+		if n := len(params.ContentChanges); n > 0 {
+			s.documents[params.TextDocument.URI] = params.ContentChanges[n-1].Text
+		}
+		return nil
+
+	case "textDocument/didSave":
+		var params didSaveParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return s.logMessage(messageTypeError, fmt.Sprintf("invalid didSave params: %v", err))
+		}
+		if params.Text != nil {
+			s.documents[params.TextDocument.URI] = *params.Text
+		}
+		return s.lintDocument(ctx, params.TextDocument.URI)
+
+	case "textDocument/didClose":
+		var params didCloseParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return s.logMessage(messageTypeError, fmt.Sprintf("invalid didClose params: %v", err))
+		}
+		delete(s.documents, params.TextDocument.URI)
+		return s.publishDiagnostics(params.TextDocument.URI, []Diagnostic{})
+	}
+
+	if isRequest {
+		return s.replyError(msg.ID, codeMethodNotFound, fmt.Sprintf("method not supported: %s", msg.Method))
+	}
+	// Other notifications, e.g. initialized and $/ cancellations, need no handling
+	return nil
+}
+
+// lintDocument lints the document at uri and publishes its diagnostics. Lint failures
+// are logged to the client and leave the previous diagnostics in place.
+func (s *Server) lintDocument(ctx context.Context, uri string) error {
+	path, err := URIToPath(uri)
+	if err != nil {
+		return s.logMessage(messageTypeInfo, fmt.Sprintf("not linting %s: %v", uri, err))
+	}
+
+	messages, err := s.lint(ctx, s.root, path)
+	if err != nil {
+		return s.logMessage(messageTypeError, fmt.Sprintf("linting %s failed: %v", path, err))
+	}
+
+	// Documents saved without being opened are placed against their text on disk
+	text, ok := s.documents[uri]
+	if !ok {
+		if data, err := os.ReadFile(path); err == nil {
+			text = string(data)
+		}
+	}
+
+	return s.publishDiagnostics(uri, Diagnostics(messages, text))
+}
+
+// Diagnostics converts lint messages, keyed by linter, to diagnostics for a document
+// with the given text. Messages are placed on their line, starting at their column
+// when it is known, and run to the end of the line. Messages without a line are placed
+// on the first line.
+func Diagnostics(messages map[string][]lint2.LintMessage, text string) []Diagnostic {
+	linters := make([]string, 0, len(messages))
+	for linter := range messages {
+		linters = append(linters, linter)
+	}
+	sort.Strings(linters)
+
+	lines := strings.Split(text, "\n")
+	diagnostics := []Diagnostic{}
+	for _, linter := range linters {
+		for _, msg := range messages[linter] {
+			line := 0
+			if msg.Line > 0 {
+				line = msg.Line - 1
+			}
+			lineText := ""
+			if line < len(lines) {
+				lineText = strings.TrimSuffix(lines[line], "\r")
+			}
+
+			start := 0
+			if msg.Column > 0 {
+				start = utf16Offset(lineText, msg.Column-1)
+			}
+			end := utf16Offset(lineText, utf8.RuneCountInString(lineText))
+			if end < start {
+				end = start
+			}
+
+			message := msg.Message
+			if msg.Values != "" {
+				message = fmt.Sprintf("%s (values: %s)", message, msg.Values)
+			}
+
+			diagnostics = append(diagnostics, Diagnostic{
+				Range: Range{
+					Start: Position{Line: line, Character: start},
+					End:   Position{Line: line, Character: end},
+				},
+				Severity: diagnosticSeverity(msg.Severity),
+				Code:     msg.Rule,
+				Source:   linter,
+				Message:  message,
+			})
+		}
+	}
+	return diagnostics
+}
+
+// diagnosticSeverity maps a lint message severity to a diagnostic severity
+func diagnosticSeverity(severity string) int {
+	switch severity {
+	case "ERROR":
+		return SeverityError
+	case "WARNING":
+		return SeverityWarning
+	default:
+		return SeverityInformation
+	}
+}
+
+// utf16Offset returns the UTF-16 offset of the rune at index runes of line. Columns
+// past the end of the line are kept as they are, since the text may be out of date.
+func utf16Offset(line string, runes int) int {
+	offset := 0
+	for i, r := range []rune(line) {
+		if i == runes {
+			return offset
+		}
+		offset += len(utf16.Encode([]rune{r}))
+	}
+	return offset + runes - utf8.RuneCountInString(line)
+}
+
+// workspaceRoot returns the local path of the workspace the client opened, or empty
+// if it didn't name one. Only the first workspace folder is used.
+func workspaceRoot(params initializeParams) string {
+	uris := []string{}
+	for _, folder := range params.WorkspaceFolders {
+		uris = append(uris, folder.URI)
+	}
+	uris = append(uris, params.RootURI)
+
+	for _, uri := range uris {
+		if uri == "" {
+			continue
+		}
+		if path, err := URIToPath(uri); err == nil {
+			return path
+		}
+	}
+	return params.RootPath
+}
+
+func (s *Server) reply(id *json.RawMessage, result interface{}) error {
+	return writeMessage(s.out, response{JSONRPC: "2.0", ID: id, Result: result})
+}
+
+func (s *Server) replyError(id *json.RawMessage, code int, message string) error {
+	return writeMessage(s.out, errorResponse{JSONRPC: "2.0", ID: id, Error: responseError{Code: code, Message: message}})
+}
+
+func (s *Server) publishDiagnostics(uri string, diagnostics []Diagnostic) error {
+	return writeMessage(s.out, notification{
+		JSONRPC: "2.0",
+		Method:  "textDocument/publishDiagnostics",
+		Params:  publishDiagnosticsParams{URI: uri, Diagnostics: diagnostics},
+	})
+}
+
+func (s *Server) logMessage(messageType int, message string) error {
+	return writeMessage(s.out, notification{
+		JSONRPC: "2.0",
+		Method:  "window/logMessage",
+		Params:  logMessageParams{Type: messageType, Message: message},
+	})
+}
@@ -0,0 +1,212 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/replicatedhq/replicated/pkg/lint2"
+)
+
+// runServer sends the messages to a server and returns the messages it wrote
+func runServer(t *testing.T, lint LintFunc, messages ...string) ([]map[string]interface{}, error) {
+	t.Helper()
+
+	var in bytes.Buffer
+	for _, msg := range messages {
+		if err := writeMessage(&in, json.RawMessage(msg)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out bytes.Buffer
+	runErr := NewServer(lint, "replicated", "test").Run(context.Background(), &in, &out)
+
+	var written []map[string]interface{}
+	r := bufio.NewReader(&out)
+	for {
+		body, err := readMessage(r)
+		if err != nil {
+			break
+		}
+		var msg map[string]interface{}
+		if err := json.Unmarshal(body, &msg); err != nil {
+			t.Fatalf("server wrote invalid JSON: %v", err)
+		}
+		written = append(written, msg)
+	}
+	return written, runErr
+}
+
+func TestServer(t *testing.T) {
+	dir := t.TempDir()
+	docPath := filepath.Join(dir, "manifests", "app.yaml")
+	docURI := PathToURI(docPath)
+
+	var linted []string
+	lint := func(ctx context.Context, root, path string) (map[string][]lint2.LintMessage, error) {
+		if root != dir {
+			t.Errorf("root = %q, want %q", root, dir)
+		}
+		linted = append(linted, path)
+		return map[string][]lint2.LintMessage{
+			"kots": {{Severity: "ERROR", Message: "spec.title is required", Line: 2, Column: 3, Rule: "kots-application-title"}},
+		}, nil
+	}
+
+	text, _ := json.Marshal("kind: Application\nspec: {}\n")
+	written, err := runServer(t, lint,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"rootUri":"`+PathToURI(dir)+`"}}`,
+		`{"jsonrpc":"2.0","method":"initialized","params":{}}`,
+		`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":"`+docURI+`","languageId":"yaml","version":1,"text":`+string(text)+`}}}`,
+		`{"jsonrpc":"2.0","method":"textDocument/didSave","params":{"textDocument":{"uri":"`+docURI+`"}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"textDocument/hover","params":{}}`,
+		`{"jsonrpc":"2.0","method":"textDocument/didClose","params":{"textDocument":{"uri":"`+docURI+`"}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","method":"exit"}`,
+	)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(linted, []string{docPath, docPath}) {
+		t.Errorf("linted %v, want the document on open and save", linted)
+	}
+
+	var methods []string
+	for _, msg := range written {
+		if method, ok := msg["method"].(string); ok {
+			methods = append(methods, method)
+		} else {
+			methods = append(methods, "response")
+		}
+	}
+	wantMethods := []string{"response", "textDocument/publishDiagnostics", "textDocument/publishDiagnostics", "response", "textDocument/publishDiagnostics", "response"}
+	if !reflect.DeepEqual(methods, wantMethods) {
+		t.Fatalf("server wrote %v, want %v", methods, wantMethods)
+	}
+
+	capabilities := written[0]["result"].(map[string]interface{})["capabilities"].(map[string]interface{})
+	if sync := capabilities["textDocumentSync"].(map[string]interface{}); sync["openClose"] != true || sync["change"] != float64(1) {
+		t.Errorf("unexpected textDocumentSync %v", sync)
+	}
+
+	params := written[2]["params"].(map[string]interface{})
+	if params["uri"] != docURI {
+		t.Errorf("diagnostics published for %v, want %s", params["uri"], docURI)
+	}
+	diagnostics := params["diagnostics"].([]interface{})
+	if len(diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %v", diagnostics)
+	}
+	wantDiagnostic := map[string]interface{}{
+		"range": map[string]interface{}{
+			"start": map[string]interface{}{"line": float64(1), "character": float64(2)},
+			"end":   map[string]interface{}{"line": float64(1), "character": float64(8)},
+		},
+		"severity": float64(SeverityError),
+		"code":     "kots-application-title",
+		"source":   "kots",
+		"message":  "spec.title is required",
+	}
+	if !reflect.DeepEqual(diagnostics[0], wantDiagnostic) {
+		t.Errorf("diagnostic = %v, want %v", diagnostics[0], wantDiagnostic)
+	}
+
+	if errObj := written[3]["error"].(map[string]interface{}); errObj["code"] != float64(codeMethodNotFound) {
+		t.Errorf("expected hover to be rejected, got %v", written[3])
+	}
+
+	// Closing a document clears its diagnostics
+	if cleared := written[4]["params"].(map[string]interface{})["diagnostics"].([]interface{}); len(cleared) != 0 {
+		t.Errorf("expected diagnostics to be cleared on close, got %v", cleared)
+	}
+
+	if _, ok := written[5]["result"]; !ok {
+		t.Errorf("expected shutdown response to carry a null result, got %v", written[5])
+	}
+}
+
+func TestServer_LintError(t *testing.T) {
+	lint := func(ctx context.Context, root, path string) (map[string][]lint2.LintMessage, error) {
+		return nil, errors.New("invalid .replicated config")
+	}
+
+	uri := PathToURI(filepath.Join(t.TempDir(), "preflight.yaml"))
+	written, err := runServer(t, lint,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","method":"textDocument/didSave","params":{"textDocument":{"uri":"`+uri+`"}}}`,
+	)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(written) != 2 || written[1]["method"] != "window/logMessage" {
+		t.Fatalf("expected the lint error to be logged, got %v", written)
+	}
+	if message := written[1]["params"].(map[string]interface{})["message"].(string); !strings.Contains(message, "invalid .replicated config") {
+		t.Errorf("unexpected log message %q", message)
+	}
+}
+
+func TestServer_Lifecycle(t *testing.T) {
+	lint := func(ctx context.Context, root, path string) (map[string][]lint2.LintMessage, error) {
+		t.Error("no document should be linted")
+		return nil, nil
+	}
+
+	written, err := runServer(t, lint,
+		`{"jsonrpc":"2.0","id":1,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","method":"textDocument/didSave","params":{"textDocument":{"uri":"file:///app.yaml"}}}`,
+		`{"jsonrpc":"2.0","method":"exit"}`,
+	)
+	if !errors.Is(err, ErrExitWithoutShutdown) {
+		t.Errorf("Run() error = %v, want %v", err, ErrExitWithoutShutdown)
+	}
+	if len(written) != 1 || written[0]["error"].(map[string]interface{})["code"] != float64(codeServerNotInitialized) {
+		t.Errorf("expected requests before initialize to be rejected, got %v", written)
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	text := "spec:\r\n  title: \"日本語\" # 😀\n"
+	messages := map[string][]lint2.LintMessage{
+		"preflight": {
+			{Severity: "WARNING", Message: "no line", Rule: "r1"},
+			{Severity: "INFO", Message: "beyond the text", Line: 5, Column: 2},
+		},
+		"embedded-cluster": {
+			{Severity: "ERROR", Message: "bad title", Line: 2, Column: 10, Values: "worker"},
+		},
+	}
+
+	got := Diagnostics(messages, text)
+	want := []Diagnostic{
+		{Range: Range{Start: Position{1, 9}, End: Position{1, 19}}, Severity: SeverityError, Source: "embedded-cluster", Message: "bad title (values: worker)"},
+		{Range: Range{Start: Position{0, 0}, End: Position{0, 5}}, Severity: SeverityWarning, Code: "r1", Source: "preflight", Message: "no line"},
+		{Range: Range{Start: Position{4, 1}, End: Position{4, 1}}, Severity: SeverityInformation, Source: "preflight", Message: "beyond the text"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diagnostics() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestURIToPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dir with spaces", "app.yaml")
+	got, err := URIToPath(PathToURI(path))
+	if err != nil {
+		t.Fatalf("URIToPath() error = %v", err)
+	}
+	if got != path {
+		t.Errorf("URIToPath(PathToURI(%q)) = %q", path, got)
+	}
+
+	if _, err := URIToPath("untitled:Untitled-1"); err == nil {
+		t.Error("expected an error for a non-file URI")
+	}
+}
@@ -0,0 +1,407 @@
+package tools
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// BundleEnv is the environment variable naming a tools bundle that the resolver
+// installs tools from instead of downloading them
+const BundleEnv = "REPLICATED_TOOLS_BUNDLE"
+
+const (
+	// bundleManifestName is the first entry of a tools bundle archive
+	bundleManifestName = "manifest.json"
+
+	// bundleFormatVersion is the version of the tools bundle format
+	bundleFormatVersion = 1
+
+	// maxBundleManifestSize bounds the manifest read from a tools bundle
+	maxBundleManifestSize = 1 << 20 // 1 MiB
+
+	// importedBundleName records the last imported bundle in the tools cache
+	importedBundleName = "bundle.json"
+)
+
+// BundleManifest describes the contents of a tools bundle
+type BundleManifest struct {
+	Version  int           `json:"version"`
+	Platform string        `json:"platform"` // os-arch the binaries are built for
+	Tools    []BundleEntry `json:"tools"`
+}
+
+// BundleEntry is a tool binary or a Kubernetes schema in a tools bundle
+type BundleEntry struct {
+	Name    string `json:"name"` // tool name, or kube-schema for a Kubernetes schema
+	Version string `json:"version"`
+	Path    string `json:"path"` // path of the file in the archive
+	SHA256  string `json:"sha256"`
+}
+
+// BundleOptions selects what CreateBundle packages
+type BundleOptions struct {
+	Platform           Platform
+	Tools              map[string]string // tool name to version; latest is resolved from replicated.app
+	KubeSchemaVersions []string          // Kubernetes minor versions whose schemas are included
+}
+
+// Find returns the entry for a tool version. With version latest or empty, the
+// entry for the tool is returned if the bundle holds a single version of it.
+func (m *BundleManifest) Find(name, version string) (BundleEntry, bool) {
+	var found []BundleEntry
+	for _, entry := range m.Tools {
+		if entry.Name != name {
+			continue
+		}
+		if entry.Version == version {
+			return entry, true
+		}
+		found = append(found, entry)
+	}
+	if (version == "latest" || version == "") && len(found) == 1 {
+		return found[0], true
+	}
+	return BundleEntry{}, false
+}
+
+// CreateBundle downloads the tools and Kubernetes schemas in opts for opts.Platform,
+// verifying their checksums, and writes them to w as a gzipped tar archive. The
+// archive starts with a manifest recording the version and SHA-256 of each file.
+func (d *Downloader) CreateBundle(ctx context.Context, w io.Writer, opts BundleOptions) (*BundleManifest, error) {
+	staging, err := os.MkdirTemp("", "replicated-tools-bundle-*")
+	if err != nil {
+		return nil, fmt.Errorf("creating staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	manifest := &BundleManifest{Version: bundleFormatVersion, Platform: opts.Platform.String()}
+	stage := func(name, version, archivePath string, data []byte) error {
+		if err := writeCacheFile(filepath.Join(staging, filepath.FromSlash(archivePath)), bytes.NewReader(data), 0644, ""); err != nil {
+			return fmt.Errorf("staging %s %s: %w", name, version, err)
+		}
+		hash := sha256.Sum256(data)
+		manifest.Tools = append(manifest.Tools, BundleEntry{
+			Name:    name,
+			Version: version,
+			Path:    archivePath,
+			SHA256:  hex.EncodeToString(hash[:]),
+		})
+		return nil
+	}
+
+	names := make([]string, 0, len(opts.Tools))
+	for name := range opts.Tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		version := opts.Tools[name]
+		if version == "latest" || version == "" {
+			version, err = getLatestStableVersion(name)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get latest version for %s: %w", name, err)
+			}
+		}
+
+		fmt.Printf("Downloading %s %s for %s...\n", name, version, opts.Platform)
+		data, err := d.fetchBinary(ctx, name, version, opts.Platform)
+		if err != nil {
+			return nil, fmt.Errorf("downloading %s %s: %w", name, version, err)
+		}
+		archivePath := path.Join(name, version, opts.Platform.String(), opts.Platform.BinaryName(name))
+		if err := stage(name, version, archivePath, data); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, version := range opts.KubeSchemaVersions {
+		if _, ok := manifest.Find(KubeSchemaDir, version); ok {
+			continue
+		}
+		fmt.Printf("Downloading Kubernetes %s schema...\n", version)
+		data, err := d.FetchKubeSchema(version)
+		if err != nil {
+			return nil, fmt.Errorf("downloading Kubernetes %s schema: %w", version, err)
+		}
+		if err := stage(KubeSchemaDir, version, path.Join(KubeSchemaDir, version, "swagger.json"), data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writeBundle(w, manifest, staging); err != nil {
+		return nil, fmt.Errorf("writing bundle: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// writeBundle writes the manifest followed by the files it lists from dir
+func writeBundle(w io.Writer, manifest *BundleManifest, dir string) error {
+	gzWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzWriter)
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := tarWriter.WriteHeader(&tar.Header{Name: bundleManifestName, Mode: 0644, Size: int64(len(manifestData))}); err != nil {
+		return err
+	}
+	if _, err := tarWriter.Write(manifestData); err != nil {
+		return err
+	}
+
+	for _, entry := range manifest.Tools {
+		if err := writeBundleFile(tarWriter, entry, filepath.Join(dir, filepath.FromSlash(entry.Path))); err != nil {
+			return err
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+	return gzWriter.Close()
+}
+
+func writeBundleFile(tarWriter *tar.Writer, entry BundleEntry, filePath string) error {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if err := tarWriter.WriteHeader(&tar.Header{Name: entry.Path, Mode: int64(bundleEntryMode(entry)), Size: info.Size()}); err != nil {
+		return err
+	}
+	_, err = io.Copy(tarWriter, f)
+	return err
+}
+
+// ReadBundleManifest reads the manifest of a tools bundle
+func ReadBundleManifest(bundlePath string) (*BundleManifest, error) {
+	bundle, manifest, err := openBundle(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+	bundle.Close()
+
+	return manifest, nil
+}
+
+// ImportBundle installs every tool and Kubernetes schema in a tools bundle into the
+// tools cache, verifying each against the bundle's checksums. The bundle must be for
+// the current platform. Afterwards, "latest" resolves to the bundled version of a tool
+// when offline (REPLICATED_TOOLS_OFFLINE) or when replicated.app cannot be reached.
+func ImportBundle(bundlePath string) (*BundleManifest, error) {
+	unlock, err := lockCache()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	manifest, err := extractBundle(bundlePath, func(BundleEntry) bool { return true })
+	if err != nil {
+		return nil, err
+	}
+
+	cacheDir, err := GetCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeCacheFile(filepath.Join(cacheDir, importedBundleName), bytes.NewReader(data), 0644, ""); err != nil {
+		return nil, fmt.Errorf("recording imported bundle: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// extractBundle installs the bundle entries selected by include into the tools cache
+func extractBundle(bundlePath string, include func(BundleEntry) bool) (*BundleManifest, error) {
+	bundle, manifest, err := openBundle(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+	defer bundle.Close()
+
+	if current := CurrentPlatform().String(); manifest.Platform != current {
+		return nil, fmt.Errorf("tools bundle %s is for %s, not %s", bundlePath, manifest.Platform, current)
+	}
+
+	entries := make(map[string]BundleEntry)
+	for _, entry := range manifest.Tools {
+		if err := validateBundleEntry(entry); err != nil {
+			return nil, fmt.Errorf("invalid tools bundle %s: %w", bundlePath, err)
+		}
+		if include(entry) {
+			entries[entry.Path] = entry
+		}
+	}
+
+	for len(entries) > 0 {
+		header, err := bundle.tar.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading tools bundle: %w", err)
+		}
+		entry, ok := entries[header.Name]
+		if !ok {
+			continue
+		}
+
+		dest, err := bundleEntryCachePath(entry)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeCacheFile(dest, bundle.tar, bundleEntryMode(entry), entry.SHA256); err != nil {
+			return nil, fmt.Errorf("importing %s %s: %w", entry.Name, entry.Version, err)
+		}
+		delete(entries, header.Name)
+	}
+
+	if len(entries) > 0 {
+		missing := make([]string, 0, len(entries))
+		for entryPath := range entries {
+			missing = append(missing, entryPath)
+		}
+		sort.Strings(missing)
+		return nil, fmt.Errorf("tools bundle %s is missing %s", bundlePath, strings.Join(missing, ", "))
+	}
+
+	return manifest, nil
+}
+
+// bundleReader reads the entries of a tools bundle after its manifest
+type bundleReader struct {
+	file *os.File
+	gz   *gzip.Reader
+	tar  *tar.Reader
+}
+
+func (b *bundleReader) Close() {
+	b.gz.Close()
+	b.file.Close()
+}
+
+// openBundle opens a tools bundle and reads its manifest
+func openBundle(bundlePath string) (*bundleReader, *BundleManifest, error) {
+	f, err := os.Open(bundlePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("opening tools bundle: %w", err)
+	}
+	gzReader, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("tools bundle %s is not a gzipped tar archive: %w", bundlePath, err)
+	}
+	bundle := &bundleReader{file: f, gz: gzReader, tar: tar.NewReader(gzReader)}
+
+	header, err := bundle.tar.Next()
+	if err != nil || header.Name != bundleManifestName {
+		bundle.Close()
+		return nil, nil, fmt.Errorf("tools bundle %s has no %s", bundlePath, bundleManifestName)
+	}
+	var manifest BundleManifest
+	if err := json.NewDecoder(io.LimitReader(bundle.tar, maxBundleManifestSize)).Decode(&manifest); err != nil {
+		bundle.Close()
+		return nil, nil, fmt.Errorf("parsing tools bundle manifest: %w", err)
+	}
+	if manifest.Version != bundleFormatVersion {
+		bundle.Close()
+		return nil, nil, fmt.Errorf("unsupported tools bundle version %d", manifest.Version)
+	}
+
+	return bundle, &manifest, nil
+}
+
+// validateBundleEntry makes sure an entry can only be installed to its own cache path
+func validateBundleEntry(entry BundleEntry) error {
+	switch entry.Name {
+	case ToolHelm, ToolPreflight, ToolSupportBundle, ToolEmbeddedCluster:
+		if entry.Version == "" || entry.Version == "." || entry.Version == ".." || strings.ContainsAny(entry.Version, `/\`) {
+			return fmt.Errorf("invalid version %q for %s", entry.Version, entry.Name)
+		}
+	case KubeSchemaDir:
+		if !kubeMinorVersionPattern.MatchString(entry.Version) {
+			return fmt.Errorf("invalid Kubernetes version %q", entry.Version)
+		}
+	default:
+		return fmt.Errorf("unknown tool: %s", entry.Name)
+	}
+	if entry.SHA256 == "" {
+		return fmt.Errorf("no checksum for %s %s", entry.Name, entry.Version)
+	}
+	return nil
+}
+
+// bundleEntryCachePath returns where a bundle entry is installed in the tools cache
+func bundleEntryCachePath(entry BundleEntry) (string, error) {
+	if entry.Name == KubeSchemaDir {
+		return GetKubeSchemaPath(entry.Version)
+	}
+	return GetToolPath(entry.Name, entry.Version)
+}
+
+func bundleEntryMode(entry BundleEntry) os.FileMode {
+	if entry.Name == KubeSchemaDir {
+		return 0644
+	}
+	return 0755
+}
+
+// readImportedBundle returns the manifest of the last imported bundle, or nil if no
+// bundle was imported
+func readImportedBundle() (*BundleManifest, error) {
+	cacheDir, err := GetCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(cacheDir, importedBundleName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var manifest BundleManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("parsing imported bundle record: %w", err)
+	}
+	return &manifest, nil
+}
+
+// clearImportedBundle forgets the last imported bundle, so "latest" no longer
+// resolves to its versions
+func clearImportedBundle() error {
+	cacheDir, err := GetCacheDir()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(cacheDir, importedBundleName)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing imported bundle record: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package tools
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestBundle writes a tools bundle holding the given files (archive path to content)
+func writeTestBundle(t *testing.T, platform string, entries []BundleEntry, files map[string]string) string {
+	t.Helper()
+	staging := t.TempDir()
+	manifest := &BundleManifest{Version: bundleFormatVersion, Platform: platform}
+	for _, entry := range entries {
+		content := files[entry.Path]
+		if entry.SHA256 == "" {
+			hash := sha256.Sum256([]byte(content))
+			entry.SHA256 = hex.EncodeToString(hash[:])
+		}
+		manifest.Tools = append(manifest.Tools, entry)
+		filePath := filepath.Join(staging, filepath.FromSlash(entry.Path))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bundlePath := filepath.Join(t.TempDir(), "tools.tgz")
+	f, err := os.Create(bundlePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := writeBundle(f, manifest, staging); err != nil {
+		t.Fatalf("writeBundle() error = %v", err)
+	}
+	return bundlePath
+}
+
+func testBundleEntries() ([]BundleEntry, map[string]string) {
+	platform := CurrentPlatform()
+	helmPath := path.Join(ToolHelm, "3.14.4", platform.String(), platform.BinaryName(ToolHelm))
+	schemaPath := path.Join(KubeSchemaDir, "1.31", "swagger.json")
+	entries := []BundleEntry{
+		{Name: ToolHelm, Version: "3.14.4", Path: helmPath},
+		{Name: KubeSchemaDir, Version: "1.31", Path: schemaPath},
+	}
+	files := map[string]string{
+		helmPath:   "fake helm",
+		schemaPath: `{"definitions": {"io.k8s.api.core.v1.Pod": {}}}`,
+	}
+	return entries, files
+}
+
+func TestImportBundle(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv(BundleEnv, "")
+
+	entries, files := testBundleEntries()
+	bundlePath := writeTestBundle(t, CurrentPlatform().String(), entries, files)
+
+	manifest, err := ImportBundle(bundlePath)
+	if err != nil {
+		t.Fatalf("ImportBundle() error = %v", err)
+	}
+	if len(manifest.Tools) != 2 {
+		t.Errorf("expected 2 imported entries, got %+v", manifest.Tools)
+	}
+
+	helmPath, _ := GetToolPath(ToolHelm, "3.14.4")
+	info, err := os.Stat(helmPath)
+	if err != nil {
+		t.Fatalf("expected helm to be imported: %v", err)
+	}
+	if info.Mode().Perm()&0100 == 0 {
+		t.Errorf("expected helm to be executable, got mode %v", info.Mode())
+	}
+	schemaPath, _ := GetKubeSchemaPath("1.31")
+	if _, err := os.Stat(schemaPath); err != nil {
+		t.Errorf("expected the schema to be imported: %v", err)
+	}
+
+	// Offline, "latest" resolves to the imported version without contacting replicated.app
+	t.Setenv(OfflineEnv, "true")
+	resolver := NewResolver()
+	resolved, err := resolver.Resolve(context.Background(), ToolHelm, "latest")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if resolved != helmPath {
+		t.Errorf("Resolve() = %q, want %q", resolved, helmPath)
+	}
+	version, err := resolver.ResolveLatestVersion(context.Background(), ToolHelm)
+	if err != nil || version != "3.14.4" {
+		t.Errorf("ResolveLatestVersion() = %q, %v, want 3.14.4", version, err)
+	}
+
+	// Pruning with --force forgets the import
+	if _, _, err := PruneCachedTools(PruneOptions{KeepLatest: 1, Force: true}); err != nil {
+		t.Fatalf("PruneCachedTools() error = %v", err)
+	}
+	if _, err := resolver.ResolveLatestVersion(context.Background(), ToolHelm); err == nil || !strings.Contains(err.Error(), "no tools bundle has been imported") {
+		t.Errorf("expected the import to be forgotten after pruning, got %v", err)
+	}
+}
+
+func TestResolve_BundleEnv(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	entries, files := testBundleEntries()
+	t.Setenv(BundleEnv, writeTestBundle(t, CurrentPlatform().String(), entries, files))
+
+	resolver := NewResolver()
+	ctx := context.Background()
+
+	helmPath, err := resolver.Resolve(ctx, ToolHelm, "latest")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if data, err := os.ReadFile(helmPath); err != nil || string(data) != "fake helm" {
+		t.Errorf("expected helm to be installed from the bundle, got %q, %v", data, err)
+	}
+
+	if _, err := resolver.InstallKubeSchema(ctx, "1.31"); err != nil {
+		t.Errorf("InstallKubeSchema() error = %v", err)
+	}
+
+	// Tools missing from the bundle are never downloaded
+	if _, err := resolver.Resolve(ctx, ToolHelm, "3.15.0"); err == nil || !strings.Contains(err.Error(), "helm 3.15.0 is not in tools bundle") {
+		t.Errorf("expected a missing version to be reported, got %v", err)
+	}
+	if _, err := resolver.Resolve(ctx, ToolPreflight, "latest"); err == nil || !strings.Contains(err.Error(), "preflight is not in tools bundle") {
+		t.Errorf("expected a missing tool to be reported, got %v", err)
+	}
+	if _, err := resolver.InstallKubeSchema(ctx, "1.30"); err == nil {
+		t.Error("expected a missing schema to be reported")
+	}
+}
+
+func TestImportBundle_Invalid(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	entries, files := testBundleEntries()
+	other := Platform{OS: "plan9", Arch: "mips"}
+
+	tests := []struct {
+		name    string
+		bundle  func() string
+		wantErr string
+	}{
+		{
+			name:    "other platform",
+			bundle:  func() string { return writeTestBundle(t, other.String(), entries, files) },
+			wantErr: "is for plan9-mips",
+		},
+		{
+			name: "checksum mismatch",
+			bundle: func() string {
+				bad := append([]BundleEntry{}, entries...)
+				bad[0].SHA256 = strings.Repeat("0", 64)
+				return writeTestBundle(t, CurrentPlatform().String(), bad, files)
+			},
+			wantErr: "checksum mismatch",
+		},
+		{
+			name: "path outside the cache",
+			bundle: func() string {
+				bad := []BundleEntry{{Name: ToolHelm, Version: "../../../escape", Path: "helm"}}
+				return writeTestBundle(t, CurrentPlatform().String(), bad, map[string]string{"helm": "x"})
+			},
+			wantErr: "invalid version",
+		},
+		{
+			name: "not a bundle",
+			bundle: func() string {
+				p := filepath.Join(t.TempDir(), "tools.tgz")
+				if err := os.WriteFile(p, []byte("not a bundle"), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return p
+			},
+			wantErr: "not a gzipped tar archive",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ImportBundle(tt.bundle())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ImportBundle() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	helmPath, _ := GetToolPath(ToolHelm, "3.14.4")
+	if _, err := os.Stat(helmPath); !os.IsNotExist(err) {
+		t.Errorf("expected nothing to be imported from an invalid bundle, got %v", err)
+	}
+}
@@ -1,7 +1,10 @@
 package tools
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -26,6 +29,30 @@ func GetCacheDir() (string, error) {
 	return filepath.Join(home, ".replicated", "tools"), nil
 }
 
+// Platform is the operating system and architecture a tool binary is built for
+type Platform struct {
+	OS   string
+	Arch string
+}
+
+// CurrentPlatform returns the platform the CLI is running on
+func CurrentPlatform() Platform {
+	return Platform{OS: runtime.GOOS, Arch: runtime.GOARCH}
+}
+
+// String returns the platform as os-arch, e.g. darwin-arm64
+func (p Platform) String() string {
+	return fmt.Sprintf("%s-%s", p.OS, p.Arch)
+}
+
+// BinaryName returns the file name of a tool binary on the platform
+func (p Platform) BinaryName(name string) string {
+	if p.OS == "windows" {
+		return name + ".exe"
+	}
+	return name
+}
+
 // GetToolPath returns the cached path for a specific tool version
 // Example: ~/.replicated/tools/helm/3.14.4/darwin-arm64/helm
 func GetToolPath(name, version string) (string, error) {
@@ -34,14 +61,8 @@ func GetToolPath(name, version string) (string, error) {
 		return "", err
 	}
 
-	osArch := fmt.Sprintf("%s-%s", runtime.GOOS, runtime.GOARCH)
-
-	binaryName := name
-	if runtime.GOOS == "windows" {
-		binaryName = name + ".exe"
-	}
-
-	return filepath.Join(cacheDir, name, version, osArch, binaryName), nil
+	platform := CurrentPlatform()
+	return filepath.Join(cacheDir, name, version, platform.String(), platform.BinaryName(name)), nil
 }
 
 // IsCached checks if a tool version is already cached
@@ -66,3 +87,37 @@ func IsCached(name, version string) (bool, error) {
 
 	return true, nil
 }
+
+// writeCacheFile writes the content of r to path through a temporary file in the same
+// directory, so readers never see a partial file. When wantSHA256 is set the content
+// must match it, or nothing is written.
+func writeCacheFile(path string, r io.Reader, perm os.FileMode, wantSHA256 string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, hash), r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if wantSHA256 != "" {
+		if actual := hex.EncodeToString(hash.Sum(nil)); actual != wantSHA256 {
+			return fmt.Errorf("checksum mismatch: got %s, want %s", actual, wantSHA256)
+		}
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,39 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// cacheLockName is the lock file in the tools cache directory
+const cacheLockName = ".lock"
+
+// lockCache takes an exclusive lock on the tools cache directory and returns a function
+// that releases it. The lock is held while tools are installed into or removed from the
+// cache, so CI jobs sharing a cache directory never download the same tool at once or
+// prune a tool that is being installed. The operating system releases it if the
+// process dies.
+func lockCache() (func(), error) {
+	cacheDir, err := GetCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return nil, fmt.Errorf("creating cache directory: %w", err)
+	}
+
+	f, err := os.OpenFile(filepath.Join(cacheDir, cacheLockName), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("opening cache lock: %w", err)
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("locking tools cache: %w", err)
+	}
+
+	return func() {
+		_ = unlockFile(f)
+		f.Close()
+	}, nil
+}
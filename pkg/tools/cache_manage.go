@@ -0,0 +1,217 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver"
+)
+
+// CachedTool is a tool binary or Kubernetes schema in the tools cache
+type CachedTool struct {
+	Name     string    `json:"name"`
+	Version  string    `json:"version"`
+	Platform string    `json:"platform,omitempty"` // os-arch; empty for Kubernetes schemas
+	Path     string    `json:"path"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
+
+// cachedToolNames are the tools whose binaries are kept in the tools cache
+var cachedToolNames = []string{ToolHelm, ToolPreflight, ToolSupportBundle, ToolEmbeddedCluster}
+
+// ListCachedTools returns the tool binaries and Kubernetes schemas in the tools cache,
+// ordered by name and then from the newest version to the oldest. Other cache
+// contents, such as lint results, are not included.
+func ListCachedTools() ([]CachedTool, error) {
+	cacheDir, err := GetCacheDir()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []CachedTool
+	add := func(name, version, platform, path string) error {
+		info, err := os.Stat(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		result = append(result, CachedTool{
+			Name:     name,
+			Version:  version,
+			Platform: platform,
+			Path:     path,
+			Size:     info.Size(),
+			Modified: info.ModTime(),
+		})
+		return nil
+	}
+
+	// Binaries are cached as <name>/<version>/<os>-<arch>/<binary>
+	for _, name := range cachedToolNames {
+		versions, err := readDirNames(filepath.Join(cacheDir, name))
+		if err != nil {
+			return nil, err
+		}
+		for _, version := range versions {
+			platforms, err := readDirNames(filepath.Join(cacheDir, name, version))
+			if err != nil {
+				return nil, err
+			}
+			for _, platform := range platforms {
+				goos, _, _ := strings.Cut(platform, "-")
+				binaryPath := filepath.Join(cacheDir, name, version, platform, Platform{OS: goos}.BinaryName(name))
+				if err := add(name, version, platform, binaryPath); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	// Schemas are cached as kube-schema/<version>/swagger.json
+	versions, err := readDirNames(filepath.Join(cacheDir, KubeSchemaDir))
+	if err != nil {
+		return nil, err
+	}
+	for _, version := range versions {
+		if err := add(KubeSchemaDir, version, "", filepath.Join(cacheDir, KubeSchemaDir, version, "swagger.json")); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Name != result[j].Name {
+			return result[i].Name < result[j].Name
+		}
+		if c := compareToolVersions(result[i].Version, result[j].Version); c != 0 {
+			return c > 0
+		}
+		return result[i].Platform < result[j].Platform
+	})
+
+	return result, nil
+}
+
+// PruneOptions selects what PruneCachedTools removes
+type PruneOptions struct {
+	KeepLatest int               // newest versions of each tool (per platform) to keep
+	Pinned     map[string]string // tool name to version, as in repl-lint.tools
+	Force      bool              // also remove pinned and imported versions
+	DryRun     bool              // report what would be removed without removing it
+}
+
+// PruneCachedTools removes all but the KeepLatest newest versions of each tool (per
+// platform) and of the Kubernetes schemas from the tools cache. It returns what was
+// removed, and the old versions kept because they are pinned in the config or were
+// installed by the last imported bundle. With Force, those are removed too, along
+// with the record of the imported bundle, so "latest" no longer resolves to its
+// versions.
+func PruneCachedTools(opts PruneOptions) ([]CachedTool, []CachedTool, error) {
+	if opts.KeepLatest < 0 {
+		return nil, nil, fmt.Errorf("the number of versions to keep must not be negative")
+	}
+
+	unlock, err := lockCache()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer unlock()
+
+	cached, err := ListCachedTools()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pinned := make(map[string]bool)
+	for name, version := range opts.Pinned {
+		pinned[name+"@"+version] = true
+	}
+	imported, err := readImportedBundle()
+	if err != nil {
+		return nil, nil, err
+	}
+	if imported != nil {
+		for _, entry := range imported.Tools {
+			pinned[entry.Name+"@"+entry.Version] = true
+		}
+	}
+
+	// The list is ordered from the newest version down, so the first KeepLatest
+	// of each tool and platform are kept
+	kept := make(map[string]int)
+	var removed, skipped []CachedTool
+	for _, tool := range cached {
+		key := tool.Name + "/" + tool.Platform
+		if kept[key] < opts.KeepLatest {
+			kept[key]++
+			continue
+		}
+		if pinned[tool.Name+"@"+tool.Version] && !opts.Force {
+			skipped = append(skipped, tool)
+			continue
+		}
+		if !opts.DryRun {
+			// Remove the directory holding only this file, then its version directory if now empty
+			dir := filepath.Dir(tool.Path)
+			if err := os.RemoveAll(dir); err != nil {
+				return removed, skipped, fmt.Errorf("removing %s %s: %w", tool.Name, tool.Version, err)
+			}
+			if tool.Platform != "" {
+				_ = os.Remove(filepath.Dir(dir))
+			}
+		}
+		removed = append(removed, tool)
+	}
+
+	if opts.Force && !opts.DryRun {
+		if err := clearImportedBundle(); err != nil {
+			return removed, skipped, err
+		}
+	}
+
+	return removed, skipped, nil
+}
+
+// compareToolVersions compares two tool versions, newest first by semantic version.
+// Versions that are not semantic versions sort after those that are.
+func compareToolVersions(a, b string) int {
+	va, errA := semver.NewVersion(a)
+	vb, errB := semver.NewVersion(b)
+	switch {
+	case errA == nil && errB == nil:
+		return va.Compare(vb)
+	case errA == nil:
+		return 1
+	case errB == nil:
+		return -1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+// readDirNames returns the names of the directories in dir, or nothing if dir does not exist
+func readDirNames(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,206 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// writeCachedTool writes a fake tool binary to the tools cache
+func writeCachedTool(t *testing.T, name, version string, platform Platform) string {
+	t.Helper()
+	cacheDir, err := GetCacheDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(cacheDir, name, version, platform.String(), platform.BinaryName(name))
+	if name == KubeSchemaDir {
+		path = filepath.Join(cacheDir, KubeSchemaDir, version, "swagger.json")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(name+" "+version), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestListAndPruneCachedTools(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	linux := Platform{OS: "linux", Arch: "amd64"}
+	darwin := Platform{OS: "darwin", Arch: "arm64"}
+	for _, version := range []string{"3.9.0", "3.14.4", "3.10.1"} {
+		writeCachedTool(t, ToolHelm, version, linux)
+	}
+	writeCachedTool(t, ToolHelm, "3.9.0", darwin)
+	writeCachedTool(t, ToolPreflight, "0.123.9", linux)
+	writeCachedTool(t, KubeSchemaDir, "1.30", Platform{})
+	writeCachedTool(t, KubeSchemaDir, "1.31", Platform{})
+
+	cacheDir, _ := GetCacheDir()
+	if err := os.MkdirAll(filepath.Join(cacheDir, "lint-cache", "abc"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cached, err := ListCachedTools()
+	if err != nil {
+		t.Fatalf("ListCachedTools() error = %v", err)
+	}
+	var got []string
+	for _, tool := range cached {
+		got = append(got, tool.Name+" "+tool.Version+" "+tool.Platform)
+	}
+	want := []string{
+		"helm 3.14.4 linux-amd64",
+		"helm 3.10.1 linux-amd64",
+		"helm 3.9.0 darwin-arm64",
+		"helm 3.9.0 linux-amd64",
+		"kube-schema 1.31 ",
+		"kube-schema 1.30 ",
+		"preflight 0.123.9 linux-amd64",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ListCachedTools() = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ListCachedTools()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	// A dry run reports the old versions without removing them
+	removed, _, err := PruneCachedTools(PruneOptions{KeepLatest: 1, DryRun: true})
+	if err != nil {
+		t.Fatalf("PruneCachedTools() error = %v", err)
+	}
+	if len(removed) != 3 {
+		t.Errorf("expected 3 tools to be pruned, got %+v", removed)
+	}
+	if cached, _ := ListCachedTools(); len(cached) != len(want) {
+		t.Errorf("expected a dry run to leave the cache alone, got %+v", cached)
+	}
+
+	removed, _, err = PruneCachedTools(PruneOptions{KeepLatest: 1})
+	if err != nil {
+		t.Fatalf("PruneCachedTools() error = %v", err)
+	}
+	if len(removed) != 3 {
+		t.Errorf("expected 3 tools to be pruned, got %+v", removed)
+	}
+	cached, _ = ListCachedTools()
+	got = nil
+	for _, tool := range cached {
+		got = append(got, tool.Name+" "+tool.Version+" "+tool.Platform)
+	}
+	want = []string{
+		"helm 3.14.4 linux-amd64",
+		"helm 3.9.0 darwin-arm64",
+		"kube-schema 1.31 ",
+		"preflight 0.123.9 linux-amd64",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("after pruning, ListCachedTools() = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("after pruning, ListCachedTools()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+	if _, err := os.Stat(filepath.Join(cacheDir, ToolHelm, "3.10.1")); !os.IsNotExist(err) {
+		t.Errorf("expected the empty version directory to be removed, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(cacheDir, "lint-cache", "abc")); err != nil {
+		t.Errorf("expected lint results to be left alone: %v", err)
+	}
+}
+
+func TestPruneCachedTools_Pinned(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	linux := Platform{OS: "linux", Arch: "amd64"}
+	for _, version := range []string{"3.9.0", "3.10.1", "3.14.4"} {
+		writeCachedTool(t, ToolHelm, version, linux)
+	}
+	writeCachedTool(t, ToolPreflight, "0.123.9", linux)
+	writeCachedTool(t, ToolPreflight, "0.124.0", linux)
+
+	// preflight 0.123.9 was installed by an imported bundle
+	cacheDir, _ := GetCacheDir()
+	record := `{"version": 1, "platform": "linux-amd64", "tools": [{"name": "preflight", "version": "0.123.9"}]}`
+	if err := os.WriteFile(filepath.Join(cacheDir, importedBundleName), []byte(record), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := PruneOptions{KeepLatest: 1, Pinned: map[string]string{ToolHelm: "3.9.0"}}
+	removed, skipped, err := PruneCachedTools(opts)
+	if err != nil {
+		t.Fatalf("PruneCachedTools() error = %v", err)
+	}
+	if len(removed) != 1 || removed[0].Version != "3.10.1" {
+		t.Errorf("expected only helm 3.10.1 to be pruned, got %+v", removed)
+	}
+	if len(skipped) != 2 {
+		t.Errorf("expected the pinned and imported versions to be kept, got %+v", skipped)
+	}
+	if imported, err := readImportedBundle(); err != nil || imported == nil {
+		t.Errorf("expected the imported bundle to be remembered, got %+v, %v", imported, err)
+	}
+
+	opts.Force = true
+	removed, skipped, err = PruneCachedTools(opts)
+	if err != nil {
+		t.Fatalf("PruneCachedTools() error = %v", err)
+	}
+	if len(removed) != 2 || len(skipped) != 0 {
+		t.Errorf("expected --force to prune the pinned and imported versions, got removed %+v, kept %+v", removed, skipped)
+	}
+	if imported, err := readImportedBundle(); err != nil || imported != nil {
+		t.Errorf("expected the imported bundle to be forgotten, got %+v, %v", imported, err)
+	}
+}
+
+func TestLockCache(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	unlock, err := lockCache()
+	if err != nil {
+		t.Fatalf("lockCache() error = %v", err)
+	}
+
+	var mu sync.Mutex
+	var events []string
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		unlock, err := lockCache()
+		if err != nil {
+			t.Errorf("lockCache() error = %v", err)
+			return
+		}
+		mu.Lock()
+		events = append(events, "second")
+		mu.Unlock()
+		unlock()
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	mu.Lock()
+	events = append(events, "first")
+	mu.Unlock()
+	unlock()
+	<-done
+
+	if len(events) != 2 || events[0] != "first" {
+		t.Errorf("expected the second lock to wait for the first, got %v", events)
+	}
+}
@@ -58,11 +58,9 @@ func VerifyHelmChecksum(data []byte, archiveURL string) error {
 	return nil
 }
 
-// VerifyTroubleshootChecksum verifies preflight or support-bundle against checksums.txt
-func VerifyTroubleshootChecksum(data []byte, version, filename string) error {
-	// Troubleshoot provides a single checksums file for all binaries
-	checksumURL := fmt.Sprintf("https://github.com/replicatedhq/troubleshoot/releases/download/v%s/troubleshoot_%s_checksums.txt", version, version)
-
+// VerifyTroubleshootChecksum verifies preflight or support-bundle against checksums.txt.
+// Troubleshoot provides a single checksums file for all binaries of a release.
+func VerifyTroubleshootChecksum(data []byte, checksumURL, filename string) error {
 	// Download checksums file with timeout
 	resp, err := checksumHTTPClient.Get(checksumURL)
 	if err != nil {
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/bmatcuk/doublestar/v4"
@@ -12,46 +13,141 @@ import (
 )
 
 // ConfigParser handles parsing of .replicated config files
-type ConfigParser struct{}
+type ConfigParser struct {
+	sources     *configSources // where each merged value came from, when recorded by ResolveConfig
+	gitRequired bool           // whether ${git.*} variables fail outside a git repository
+}
+
+// ConfigParserOption configures a ConfigParser
+type ConfigParserOption func(*ConfigParser)
+
+// WithGitRequired makes ${git.*} variables without a default fail when the config file
+// is not in a git repository. Otherwise they expand to "" there, so that commands that
+// don't use them work outside a checkout.
+func WithGitRequired(required bool) ConfigParserOption {
+	return func(p *ConfigParser) {
+		p.gitRequired = required
+	}
+}
 
 // NewConfigParser creates a new config parser
-func NewConfigParser() *ConfigParser {
-	return &ConfigParser{}
+func NewConfigParser(opts ...ConfigParserOption) *ConfigParser {
+	p := &ConfigParser{}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
 }
 
 // FindAndParseConfig searches for a .replicated config file starting from the given path
 // and walking up the directory tree. If path is empty, starts from current directory.
 // Returns the parsed config or a default config if not found.
 func (p *ConfigParser) FindAndParseConfig(startPath string) (*Config, error) {
+	config, files, err := p.findAndMergeConfigs(startPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply defaults to the merged config
+	p.ApplyDefaults(config)
+
+	// Deduplicate resources (charts, preflights, manifests) gathered from several configs
+	if len(files) > 1 {
+		p.deduplicateResources(config)
+	}
+
+	return config, nil
+}
+
+// findAndMergeConfigs finds the config files for startPath like FindAndParseConfig
+// and merges them, without applying defaults. It returns the merged files, or an
+// empty config and no files when none are found.
+func (p *ConfigParser) findAndMergeConfigs(startPath string) (*Config, []string, error) {
 	if startPath == "" {
 		var err error
 		startPath, err = os.Getwd()
 		if err != nil {
-			return nil, fmt.Errorf("getting current directory: %w", err)
+			return nil, nil, fmt.Errorf("getting current directory: %w", err)
 		}
 	}
 
 	// Make absolute
 	absPath, err := filepath.Abs(startPath)
 	if err != nil {
-		return nil, fmt.Errorf("resolving absolute path: %w", err)
+		return nil, nil, fmt.Errorf("resolving absolute path: %w", err)
 	}
 
 	// If startPath is a file, parse it directly
+	var configPaths []string
 	info, err := os.Stat(absPath)
 	if err == nil && !info.IsDir() {
-		config, err := p.ParseConfigFile(absPath)
+		configPaths = []string{absPath}
+	} else {
+		// Collect all config files from current dir to root
+		configPaths = findConfigPaths(absPath)
+
+		// No config files found - use an empty config for auto-discovery mode
+		if len(configPaths) == 0 {
+			return &Config{}, nil, nil
+		}
+
+		// configPaths is ordered [child...parent], reverse to [parent...child]
+		slices.Reverse(configPaths)
+	}
+
+	// Add the files each config includes, merged before the config itself
+	configPaths, err = p.expandIncludes(configPaths)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var configs []*Config
+	for _, configPath := range configPaths {
+		config, err := p.ParseConfigFile(configPath)
 		if err != nil {
-			return nil, err
+			if len(configPaths) == 1 {
+				return nil, nil, err
+			}
+			return nil, nil, fmt.Errorf("parsing %s: %w", configPath, err)
 		}
-		// Apply defaults for single-file case
-		p.ApplyDefaults(config)
-		return config, nil
+		configs = append(configs, config)
 	}
 
-	// Collect all config files from current dir to root
+	// Merge all configs (later configs override earlier)
+	if p.sources != nil {
+		p.sources.files = configPaths
+	}
+	merged := p.mergeConfigs(configs)
+
+	// Includes have been merged in
+	merged.Include = nil
+
+	return merged, configPaths, nil
+}
+
+// FindConfigFiles returns the .replicated config files FindAndParseConfig reads for
+// startPath, in the order they are merged: from the outermost parent to the closest
+// child, with the files each config includes before it. If startPath is a file, only
+// that file and its includes are returned. No files are returned when none are found.
+func (p *ConfigParser) FindConfigFiles(startPath string) ([]string, error) {
+	absPath, err := filepath.Abs(startPath)
+	if err != nil {
+		return nil, fmt.Errorf("resolving absolute path: %w", err)
+	}
+
+	configPaths := []string{absPath}
+	if info, err := os.Stat(absPath); err != nil || info.IsDir() {
+		configPaths = findConfigPaths(absPath)
+		slices.Reverse(configPaths)
+	}
+	return p.expandIncludes(configPaths)
+}
+
+// findConfigPaths returns the config file in dir and in each of its parent
+// directories, ordered from dir up to the root
+func findConfigPaths(dir string) []string {
 	var configPaths []string
-	currentDir := absPath
+	currentDir := dir
 
 	for {
 		// Try .replicated first, then .replicated.yaml
@@ -79,44 +175,7 @@ func (p *ConfigParser) FindAndParseConfig(startPath string) (*Config, error) {
 		currentDir = parentDir
 	}
 
-	// No config files found - return default config for auto-discovery mode
-	if len(configPaths) == 0 {
-		defaultConfig := p.DefaultConfig()
-		return defaultConfig, nil
-	}
-
-	// If only one config, parse it and apply defaults
-	if len(configPaths) == 1 {
-		config, err := p.ParseConfigFile(configPaths[0])
-		if err != nil {
-			return nil, err
-		}
-		// Apply defaults to single config
-		p.ApplyDefaults(config)
-		return config, nil
-	}
-
-	// Multiple configs found - parse and merge them
-	// configPaths is ordered [child...parent], reverse to [parent...child]
-	var configs []*Config
-	for i := len(configPaths) - 1; i >= 0; i-- {
-		config, err := p.ParseConfigFile(configPaths[i])
-		if err != nil {
-			return nil, fmt.Errorf("parsing %s: %w", configPaths[i], err)
-		}
-		configs = append(configs, config)
-	}
-
-	// Merge all configs (later configs override earlier)
-	merged := p.mergeConfigs(configs)
-
-	// Apply defaults to merged config
-	p.ApplyDefaults(merged)
-
-	// Deduplicate resources (charts, preflights, manifests)
-	p.deduplicateResources(merged)
-
-	return merged, nil
+	return configPaths
 }
 
 // mergeConfigs merges multiple configs with later configs taking precedence
@@ -126,41 +185,47 @@ func (p *ConfigParser) FindAndParseConfig(startPath string) (*Config, error) {
 // - Scalar fields (override): appId, appSlug, releaseLabel - child wins
 // - Channel arrays (override): promoteToChannelIds, promoteToChannelNames - child replaces if non-empty
 // - Resource arrays (append): charts, preflights, manifests - accumulate from all configs
+// - Environments (override): merged by name, child fields override parent fields
+// - Include: already expanded into configs by expandIncludes, not merged
 // - ReplLint section (override): child settings override parent
 func (p *ConfigParser) mergeConfigs(configs []*Config) *Config {
 	if len(configs) == 0 {
 		return p.DefaultConfig()
 	}
 
-	if len(configs) == 1 {
-		return configs[0]
-	}
-
 	// Start with first config (most parent)
 	merged := configs[0]
+	p.sources.recorder(0).setAll(merged)
 
-	// Merge in each subsequent config (moving toward child)
+	// Merge in each subsequent config (moving toward child), recording the file each
+	// value comes from when p.sources is set
 	for i := 1; i < len(configs); i++ {
 		child := configs[i]
+		src := p.sources.recorder(i)
 
 		// Scalar fields: child overrides parent (if non-empty)
 		if child.AppId != "" {
 			merged.AppId = child.AppId
+			src.set("appId")
 		}
 		if child.AppSlug != "" {
 			merged.AppSlug = child.AppSlug
+			src.set("appSlug")
 		}
 		if child.ReleaseLabel != "" {
 			merged.ReleaseLabel = child.ReleaseLabel
+			src.set("releaseLabel")
 		}
 
 		// Channel arrays: child completely replaces parent (if non-empty)
 		// This is an override, not an append, because promotion targets are a decision
 		if len(child.PromoteToChannelIds) > 0 {
 			merged.PromoteToChannelIds = child.PromoteToChannelIds
+			src.set("promoteToChannelIds")
 		}
 		if len(child.PromoteToChannelNames) > 0 {
 			merged.PromoteToChannelNames = child.PromoteToChannelNames
+			src.set("promoteToChannelNames")
 		}
 
 		// Resource arrays: append child to parent
@@ -168,23 +233,55 @@ func (p *ConfigParser) mergeConfigs(configs []*Config) *Config {
 		merged.Charts = append(merged.Charts, child.Charts...)
 		merged.Preflights = append(merged.Preflights, child.Preflights...)
 		merged.Manifests = append(merged.Manifests, child.Manifests...)
+		src.appendEntries(len(child.Charts), "charts")
+		src.appendEntries(len(child.Preflights), "preflights")
+		src.appendEntries(len(child.Manifests), "manifests")
+
+		// Environments: merged by name, child fields override parent fields
+		for name, environment := range child.Environments {
+			if merged.Environments == nil {
+				merged.Environments = make(map[string]EnvironmentConfig)
+			}
+			merged.Environments[name] = mergeEnvironmentConfig(merged.Environments[name], environment, src.at("environments", name))
+		}
 
 		// Merge ReplLint section
 		if child.ReplLint != nil {
 			if merged.ReplLint == nil {
 				merged.ReplLint = child.ReplLint
+				src.at("repl-lint").setAll(child.ReplLint)
 			} else {
+				lint := src.at("repl-lint")
+
 				// Merge version (override if non-zero)
 				if child.ReplLint.Version != 0 {
 					merged.ReplLint.Version = child.ReplLint.Version
+					lint.set("version")
+				}
+
+				// Merge baseline (override if set)
+				if child.ReplLint.Baseline != "" {
+					merged.ReplLint.Baseline = child.ReplLint.Baseline
+					lint.set("baseline")
+				}
+
+				// Merge policies directory (override if set)
+				if child.ReplLint.Policies != "" {
+					merged.ReplLint.Policies = child.ReplLint.Policies
+					lint.set("policies")
 				}
 
 				// Merge linters (only override fields explicitly set in child)
-				merged.ReplLint.Linters.Helm = mergeLinterConfig(merged.ReplLint.Linters.Helm, child.ReplLint.Linters.Helm)
-				merged.ReplLint.Linters.Preflight = mergeLinterConfig(merged.ReplLint.Linters.Preflight, child.ReplLint.Linters.Preflight)
-				merged.ReplLint.Linters.SupportBundle = mergeLinterConfig(merged.ReplLint.Linters.SupportBundle, child.ReplLint.Linters.SupportBundle)
-				merged.ReplLint.Linters.EmbeddedCluster = mergeECLinterConfig(merged.ReplLint.Linters.EmbeddedCluster, child.ReplLint.Linters.EmbeddedCluster)
-				merged.ReplLint.Linters.Kots = mergeLinterConfig(merged.ReplLint.Linters.Kots, child.ReplLint.Linters.Kots)
+				linters := lint.at("linters")
+				merged.ReplLint.Linters.Helm = mergeLinterConfig(merged.ReplLint.Linters.Helm, child.ReplLint.Linters.Helm, linters.at("helm"))
+				merged.ReplLint.Linters.Preflight = mergeLinterConfig(merged.ReplLint.Linters.Preflight, child.ReplLint.Linters.Preflight, linters.at("preflight"))
+				merged.ReplLint.Linters.SupportBundle = mergeLinterConfig(merged.ReplLint.Linters.SupportBundle, child.ReplLint.Linters.SupportBundle, linters.at("support-bundle"))
+				merged.ReplLint.Linters.EmbeddedCluster = mergeECLinterConfig(merged.ReplLint.Linters.EmbeddedCluster, child.ReplLint.Linters.EmbeddedCluster, linters.at("embedded-cluster"))
+				merged.ReplLint.Linters.Kots = mergeLinterConfig(merged.ReplLint.Linters.Kots, child.ReplLint.Linters.Kots, linters.at("kots"))
+				merged.ReplLint.Linters.KubeSchema = mergeKubeSchemaLinterConfig(merged.ReplLint.Linters.KubeSchema, child.ReplLint.Linters.KubeSchema, linters.at("kube-schema"))
+				merged.ReplLint.Linters.Policy = mergeLinterConfig(merged.ReplLint.Linters.Policy, child.ReplLint.Linters.Policy, linters.at("policy"))
+				merged.ReplLint.Linters.ReleaseGraph = mergeLinterConfig(merged.ReplLint.Linters.ReleaseGraph, child.ReplLint.Linters.ReleaseGraph, linters.at("release-graph"))
+				merged.ReplLint.Linters.Image = mergeImageLinterConfig(merged.ReplLint.Linters.Image, child.ReplLint.Linters.Image, linters.at("image"))
 
 				// Merge tools map (child versions override parent)
 				if child.ReplLint.Tools != nil {
@@ -193,6 +290,18 @@ func (p *ConfigParser) mergeConfigs(configs []*Config) *Config {
 					}
 					for toolName, version := range child.ReplLint.Tools {
 						merged.ReplLint.Tools[toolName] = version
+						lint.set("tools", toolName)
+					}
+				}
+
+				// Merge tools mirrors (child base URLs override parent)
+				if child.ReplLint.ToolsMirror != nil {
+					if merged.ReplLint.ToolsMirror == nil {
+						merged.ReplLint.ToolsMirror = make(map[string]string)
+					}
+					for toolName, baseURL := range child.ReplLint.ToolsMirror {
+						merged.ReplLint.ToolsMirror[toolName] = baseURL
+						lint.set("tools-mirror", toolName)
 					}
 				}
 			}
@@ -209,7 +318,7 @@ func (p *ConfigParser) ParseConfigFile(path string) (*Config, error) {
 		return nil, fmt.Errorf("reading config file: %w", err)
 	}
 
-	config, err := p.ParseConfig(data)
+	config, err := p.parseConfig(data, filepath.Dir(path))
 	if err != nil {
 		return nil, err
 	}
@@ -223,13 +332,32 @@ func (p *ConfigParser) ParseConfigFile(path string) (*Config, error) {
 
 // ParseConfig parses config data from YAML
 // Does NOT apply defaults - caller should do that after merging
+// Variables are expanded relative to the working directory
 func (p *ConfigParser) ParseConfig(data []byte) (*Config, error) {
-	var config Config
+	return p.parseConfig(data, "")
+}
 
-	if err := yaml.Unmarshal(data, &config); err != nil {
+// parseConfig parses config data from YAML, expanding variables relative to dir, the
+// directory of the config file
+func (p *ConfigParser) parseConfig(data []byte, dir string) (*Config, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
 		return nil, fmt.Errorf("parsing config as YAML: %w", err)
 	}
 
+	// Expand ${...} variables before decoding and validating, so that the expanded
+	// values are decoded into their field types and checked
+	if err := interpolateConfig(&doc, dir, p.gitRequired); err != nil {
+		return nil, fmt.Errorf("expanding variables: %w", err)
+	}
+
+	var config Config
+	if doc.Kind != 0 {
+		if err := doc.Decode(&config); err != nil {
+			return nil, fmt.Errorf("parsing config as YAML: %w", err)
+		}
+	}
+
 	// Validate but don't apply defaults
 	if err := p.validateConfig(&config); err != nil {
 		return nil, fmt.Errorf("validating config: %w", err)
@@ -270,6 +398,20 @@ func (p *ConfigParser) ApplyDefaults(config *Config) {
 	if config.ReplLint.Linters.Kots.Disabled == nil {
 		config.ReplLint.Linters.Kots.Disabled = boolPtr(true) // off by default (opt-in)
 	}
+	if config.ReplLint.Linters.KubeSchema.Disabled == nil {
+		// off by default; listing versions to validate against opts in
+		config.ReplLint.Linters.KubeSchema.Disabled = boolPtr(len(config.ReplLint.Linters.KubeSchema.Versions) == 0)
+	}
+	if config.ReplLint.Linters.Policy.Disabled == nil {
+		// off by default; configuring a policies directory opts in
+		config.ReplLint.Linters.Policy.Disabled = boolPtr(config.ReplLint.Policies == "")
+	}
+	if config.ReplLint.Linters.ReleaseGraph.Disabled == nil {
+		config.ReplLint.Linters.ReleaseGraph.Disabled = boolPtr(true) // off by default (opt-in)
+	}
+	if config.ReplLint.Linters.Image.Disabled == nil {
+		config.ReplLint.Linters.Image.Disabled = boolPtr(true) // off by default (opt-in)
+	}
 
 	// Default version
 	if config.ReplLint.Version == 0 {
@@ -301,6 +443,11 @@ func (p *ConfigParser) validateConfig(config *Config) error {
 		if chart.Path == "" {
 			return fmt.Errorf("chart[%d]: path is required", i)
 		}
+		for j, values := range chart.Values {
+			if values == "" {
+				return fmt.Errorf("chart[%d]: values[%d] must not be empty", i, j)
+			}
+		}
 	}
 
 	// Validate preflight paths
@@ -325,6 +472,30 @@ func (p *ConfigParser) validateConfig(config *Config) error {
 		}
 	}
 
+	// Validate environment overlays
+	for name, environment := range config.Environments {
+		if name == "" {
+			return fmt.Errorf("environments: name must not be empty")
+		}
+		for i, chart := range environment.Charts {
+			if chart.Path == "" {
+				return fmt.Errorf("environments.%s.charts[%d]: path is required", name, i)
+			}
+			for j, values := range chart.Values {
+				if values == "" {
+					return fmt.Errorf("environments.%s.charts[%d]: values[%d] must not be empty", name, i, j)
+				}
+			}
+		}
+	}
+
+	// Validate includes
+	for i, include := range config.Include {
+		if include == "" {
+			return fmt.Errorf("include[%d]: path cannot be empty string", i)
+		}
+	}
+
 	// Validate glob patterns in all paths
 	if err := p.validateGlobPatterns(config); err != nil {
 		return err
@@ -348,35 +519,113 @@ func (p *ConfigParser) validateConfig(config *Config) error {
 		}
 	}
 
+	// Validate tool mirror base URLs
+	for toolName, baseURL := range config.ReplLint.ToolsMirror {
+		if !slices.Contains(MirrorTools, toolName) {
+			return fmt.Errorf("tools-mirror: unknown tool %q: must be one of %s", toolName, strings.Join(MirrorTools, ", "))
+		}
+		if err := validateMirrorURL(baseURL); err != nil {
+			return fmt.Errorf("tools-mirror.%s: invalid base URL %q: %w", toolName, baseURL, err)
+		}
+	}
+
+	// Validate Kubernetes versions for the schema linter
+	for i, version := range config.ReplLint.Linters.KubeSchema.Versions {
+		if !kubeMinorVersionPattern.MatchString(version) {
+			return fmt.Errorf("linters.kube-schema.versions[%d]: invalid Kubernetes version %q: must be a minor version like 1.31", i, version)
+		}
+	}
+
+	// Validate image linter rules
+	for rule, level := range config.ReplLint.Linters.Image.Rules {
+		if !slices.Contains(ImageLintRules, rule) {
+			return fmt.Errorf("linters.image.rules: unknown rule %q: must be one of %s", rule, strings.Join(ImageLintRules, ", "))
+		}
+		if !validImageRuleLevels[level] {
+			return fmt.Errorf("linters.image.rules.%s: invalid severity %q: must be error, warn, info or off", rule, level)
+		}
+	}
+	if hostname := config.ReplLint.Linters.Image.ProxyHostname; strings.Contains(hostname, "/") {
+		return fmt.Errorf("linters.image.proxy-hostname: %q must be a hostname without a scheme or path", hostname)
+	}
+
+	// Validate lint ignore rules
+	for _, linter := range config.ReplLint.Linters.named() {
+		for i, rule := range linter.config.Ignore {
+			if rule.Rule == "" && rule.Path == "" && rule.Message == "" {
+				return fmt.Errorf("linters.%s.ignore[%d]: at least one of rule, path or message is required", linter.name, i)
+			}
+			if rule.Message != "" {
+				if _, err := regexp.Compile(rule.Message); err != nil {
+					return fmt.Errorf("linters.%s.ignore[%d]: invalid message regex %q: %w", linter.name, i, rule.Message, err)
+				}
+			}
+			if ValidateGlobPattern(rule.Path) != nil {
+				return fmt.Errorf("linters.%s.ignore[%d]: invalid glob pattern %q in path", linter.name, i, rule.Path)
+			}
+		}
+	}
+
 	return nil
 }
 
+// namedLinterConfig pairs a linter's config key with its config
+type namedLinterConfig struct {
+	name   string
+	config *LinterConfig
+}
+
+// named returns each linter's config with its config key, in a stable order
+func (c *LintersConfig) named() []namedLinterConfig {
+	return []namedLinterConfig{
+		{"helm", &c.Helm},
+		{"preflight", &c.Preflight},
+		{"support-bundle", &c.SupportBundle},
+		{"embedded-cluster", &c.EmbeddedCluster.LinterConfig},
+		{"kots", &c.Kots},
+		{"kube-schema", &c.KubeSchema.LinterConfig},
+		{"policy", &c.Policy},
+		{"release-graph", &c.ReleaseGraph},
+		{"image", &c.Image.LinterConfig},
+	}
+}
+
 // validateGlobPatterns validates all glob patterns in the config for correct syntax.
 // This provides early validation before attempting to expand patterns during linting.
 func (p *ConfigParser) validateGlobPatterns(config *Config) error {
 	// Validate chart paths
 	for i, chart := range config.Charts {
-		if containsGlob(chart.Path) {
-			if !doublestar.ValidatePattern(chart.Path) {
-				return fmt.Errorf("invalid glob pattern in charts[%d].path %q: invalid glob syntax", i, chart.Path)
-			}
+		if ValidateGlobPattern(chart.Path) != nil {
+			return fmt.Errorf("invalid glob pattern in charts[%d].path %q: invalid glob syntax", i, chart.Path)
 		}
 	}
 
 	// Validate preflight paths
 	for i, preflight := range config.Preflights {
-		if containsGlob(preflight.Path) {
-			if !doublestar.ValidatePattern(preflight.Path) {
-				return fmt.Errorf("invalid glob pattern in preflights[%d].path %q: invalid glob syntax", i, preflight.Path)
-			}
+		if ValidateGlobPattern(preflight.Path) != nil {
+			return fmt.Errorf("invalid glob pattern in preflights[%d].path %q: invalid glob syntax", i, preflight.Path)
 		}
 	}
 
 	// Validate manifest patterns
 	for i, manifest := range config.Manifests {
-		if containsGlob(manifest) {
-			if !doublestar.ValidatePattern(manifest) {
-				return fmt.Errorf("invalid glob pattern in manifests[%d] %q: invalid glob syntax", i, manifest)
+		if ValidateGlobPattern(manifest) != nil {
+			return fmt.Errorf("invalid glob pattern in manifests[%d] %q: invalid glob syntax", i, manifest)
+		}
+	}
+
+	// Validate include patterns
+	for i, include := range config.Include {
+		if ValidateGlobPattern(include) != nil {
+			return fmt.Errorf("invalid glob pattern in include[%d] %q: invalid glob syntax", i, include)
+		}
+	}
+
+	// Validate environment chart paths
+	for name, environment := range config.Environments {
+		for i, chart := range environment.Charts {
+			if ValidateGlobPattern(chart.Path) != nil {
+				return fmt.Errorf("invalid glob pattern in environments.%s.charts[%d].path %q: invalid glob syntax", name, i, chart.Path)
 			}
 		}
 	}
@@ -384,6 +633,15 @@ func (p *ConfigParser) validateGlobPatterns(config *Config) error {
 	return nil
 }
 
+// ValidateGlobPattern checks the syntax of a path that may contain doublestar glob
+// wildcards. Paths without wildcards are always valid.
+func ValidateGlobPattern(pattern string) error {
+	if containsGlob(pattern) && !doublestar.ValidatePattern(pattern) {
+		return fmt.Errorf("invalid glob pattern %q", pattern)
+	}
+	return nil
+}
+
 // containsGlob checks if a path contains glob wildcards (* ? [ {)
 func containsGlob(path string) bool {
 	return strings.ContainsAny(path, "*?[{")
@@ -415,11 +673,9 @@ func (p *ConfigParser) resolvePaths(config *Config, configFilePath string) {
 	configDir := filepath.Dir(configFilePath)
 
 	// Resolve chart paths
-	for i := range config.Charts {
-		// Only resolve relative paths - leave absolute paths as-is
-		if !filepath.IsAbs(config.Charts[i].Path) {
-			config.Charts[i].Path = filepath.Join(configDir, config.Charts[i].Path)
-		}
+	resolveChartPaths(config.Charts, configDir)
+	for _, environment := range config.Environments {
+		resolveChartPaths(environment.Charts, configDir)
 	}
 
 	// Resolve preflight paths
@@ -438,30 +694,142 @@ func (p *ConfigParser) resolvePaths(config *Config, configFilePath string) {
 			config.Manifests[i] = filepath.Join(configDir, config.Manifests[i])
 		}
 	}
+
+	// Resolve include paths (may be glob patterns)
+	for i := range config.Include {
+		if !filepath.IsAbs(config.Include[i]) {
+			config.Include[i] = filepath.Join(configDir, config.Include[i])
+		}
+	}
+
+	// Resolve lint baseline path
+	if config.ReplLint != nil && config.ReplLint.Baseline != "" && !filepath.IsAbs(config.ReplLint.Baseline) {
+		config.ReplLint.Baseline = filepath.Join(configDir, config.ReplLint.Baseline)
+	}
+
+	// Resolve policies directory
+	if config.ReplLint != nil && config.ReplLint.Policies != "" && !filepath.IsAbs(config.ReplLint.Policies) {
+		config.ReplLint.Policies = filepath.Join(configDir, config.ReplLint.Policies)
+	}
+
+	// Resolve lint ignore paths (may be glob patterns)
+	if config.ReplLint != nil {
+		for _, linter := range config.ReplLint.Linters.named() {
+			for i := range linter.config.Ignore {
+				path := linter.config.Ignore[i].Path
+				if path != "" && !filepath.IsAbs(path) {
+					linter.config.Ignore[i].Path = filepath.Join(configDir, path)
+				}
+			}
+		}
+	}
+}
+
+// resolveChartPaths resolves relative chart and values paths against configDir
+func resolveChartPaths(charts []ChartConfig, configDir string) {
+	for i := range charts {
+		// Only resolve relative paths - leave absolute paths as-is
+		if !filepath.IsAbs(charts[i].Path) {
+			charts[i].Path = filepath.Join(configDir, charts[i].Path)
+		}
+		for j, values := range charts[i].Values {
+			if values != "" && !filepath.IsAbs(values) {
+				charts[i].Values[j] = filepath.Join(configDir, values)
+			}
+		}
+	}
 }
 
 // mergeLinterConfig merges two linter configs
 // Only overrides parent fields if child explicitly sets them (non-nil)
-func mergeLinterConfig(parent, child LinterConfig) LinterConfig {
+func mergeLinterConfig(parent, child LinterConfig, src sourceRecorder) LinterConfig {
 	result := parent
 
 	// Override disabled if child explicitly sets it
 	if child.Disabled != nil {
 		result.Disabled = child.Disabled
+		src.set("disabled")
+	}
+
+	// Override strict if child explicitly sets it
+	if child.Strict != nil {
+		result.Strict = child.Strict
+		src.set("strict")
+	}
+
+	// Ignore rules accumulate; copy so the parent's slice is never appended to
+	if len(child.Ignore) > 0 {
+		result.Ignore = append(append([]IgnoreRule{}, parent.Ignore...), child.Ignore...)
+		src.appendEntries(len(child.Ignore), "ignore")
 	}
 
 	return result
 }
 
-func mergeECLinterConfig(parent, child ECLinterConfig) ECLinterConfig {
+func mergeECLinterConfig(parent, child ECLinterConfig, src sourceRecorder) ECLinterConfig {
 	result := parent
-	result.LinterConfig = mergeLinterConfig(parent.LinterConfig, child.LinterConfig)
+	result.LinterConfig = mergeLinterConfig(parent.LinterConfig, child.LinterConfig, src)
 
 	if len(child.DisableChecks) > 0 {
 		result.DisableChecks = child.DisableChecks
+		src.set("disable-checks")
 	}
 	if child.BinaryPath != "" {
 		result.BinaryPath = child.BinaryPath
+		src.set("binary-path")
+	}
+
+	return result
+}
+
+// validImageRuleLevels are the severities an image linter rule may be set to
+var validImageRuleLevels = map[string]bool{
+	"error": true,
+	"warn":  true,
+	"info":  true,
+	"off":   true,
+}
+
+// mergeImageLinterConfig merges image linter configs. Rule severities are merged
+// per rule; the other settings are replaced when set in the child.
+func mergeImageLinterConfig(parent, child ImageLinterConfig, src sourceRecorder) ImageLinterConfig {
+	result := parent
+	result.LinterConfig = mergeLinterConfig(parent.LinterConfig, child.LinterConfig, src)
+
+	if len(child.Rules) > 0 {
+		result.Rules = make(map[string]string, len(parent.Rules)+len(child.Rules))
+		for rule, level := range parent.Rules {
+			result.Rules[rule] = level
+		}
+		for rule, level := range child.Rules {
+			result.Rules[rule] = level
+			src.set("rules", rule)
+		}
+	}
+	if len(child.AllowedRegistries) > 0 {
+		result.AllowedRegistries = child.AllowedRegistries
+		src.set("allowed-registries")
+	}
+	if child.ProxyHostname != "" {
+		result.ProxyHostname = child.ProxyHostname
+		src.set("proxy-hostname")
+	}
+
+	return result
+}
+
+func mergeKubeSchemaLinterConfig(parent, child KubeSchemaLinterConfig, src sourceRecorder) KubeSchemaLinterConfig {
+	result := parent
+	result.LinterConfig = mergeLinterConfig(parent.LinterConfig, child.LinterConfig, src)
+
+	if len(child.Versions) > 0 {
+		result.Versions = child.Versions
+		src.set("versions")
+	}
+
+	if child.Download != nil {
+		result.Download = child.Download
+		src.set("download")
 	}
 
 	return result
@@ -479,14 +847,12 @@ func isValidSemver(version string) bool {
 	// Remove leading 'v' if present
 	version = strings.TrimPrefix(version, "v")
 
-	// Basic semver regex pattern
-	// Matches: major.minor.patch with optional pre-release and build metadata
-	semverPattern := `^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`
-
-	matched, _ := regexp.MatchString(semverPattern, version)
-	return matched
+	return semverPattern.MatchString(version)
 }
 
+// semverPattern matches major.minor.patch with optional pre-release and build metadata
+var semverPattern = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
 // deduplicateResources removes duplicate entries from resource arrays
 // Deduplication is based on absolute paths (which have already been resolved)
 func (p *ConfigParser) deduplicateResources(config *Config) {
@@ -498,38 +864,47 @@ func (p *ConfigParser) deduplicateResources(config *Config) {
 	if len(config.Charts) > 0 {
 		seen := make(map[string]bool)
 		unique := make([]ChartConfig, 0, len(config.Charts))
-		for _, chart := range config.Charts {
+		var kept []int
+		for i, chart := range config.Charts {
 			if !seen[chart.Path] {
 				seen[chart.Path] = true
 				unique = append(unique, chart)
+				kept = append(kept, i)
 			}
 		}
 		config.Charts = unique
+		p.sources.keepEntries(kept, "charts")
 	}
 
 	// Deduplicate preflights by path
 	if len(config.Preflights) > 0 {
 		seen := make(map[string]bool)
 		unique := make([]PreflightConfig, 0, len(config.Preflights))
-		for _, preflight := range config.Preflights {
+		var kept []int
+		for i, preflight := range config.Preflights {
 			if !seen[preflight.Path] {
 				seen[preflight.Path] = true
 				unique = append(unique, preflight)
+				kept = append(kept, i)
 			}
 		}
 		config.Preflights = unique
+		p.sources.keepEntries(kept, "preflights")
 	}
 
 	// Deduplicate manifests (they are just strings)
 	if len(config.Manifests) > 0 {
 		seen := make(map[string]bool)
 		unique := make([]string, 0, len(config.Manifests))
-		for _, manifest := range config.Manifests {
+		var kept []int
+		for i, manifest := range config.Manifests {
 			if !seen[manifest] {
 				seen[manifest] = true
 				unique = append(unique, manifest)
+				kept = append(kept, i)
 			}
 		}
 		config.Manifests = unique
+		p.sources.keepEntries(kept, "manifests")
 	}
 }
@@ -0,0 +1,165 @@
+package tools
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// FindAndParseEnvironmentConfig finds and merges the config files for startPath like
+// FindAndParseConfig, and applies the environment SelectEnvironment picks for envName
+// and profile before defaults are filled in and resources are deduplicated, so an
+// environment's charts get the same treatment as the config's own.
+func (p *ConfigParser) FindAndParseEnvironmentConfig(startPath, envName, profile string) (*Config, error) {
+	config, _, err := p.findAndParseEnvironmentConfig(startPath, envName, profile)
+	return config, err
+}
+
+func (p *ConfigParser) findAndParseEnvironmentConfig(startPath, envName, profile string) (*Config, []string, error) {
+	config, files, err := p.findAndMergeConfigs(startPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	envName, err = p.SelectEnvironment(config, envName, profile)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := p.ApplyEnvironment(config, envName); err != nil {
+		return nil, nil, err
+	}
+
+	p.ApplyDefaults(config)
+	if len(files) > 1 {
+		p.deduplicateResources(config)
+	}
+
+	return config, files, nil
+}
+
+// SelectEnvironment returns the name of the environment to apply to config. An
+// explicit name must be defined in the config. Without one, the environment is the
+// one whose profile field or, failing that, whose name matches the active credentials
+// profile. It returns "" when no environment applies.
+func (p *ConfigParser) SelectEnvironment(config *Config, name, profile string) (string, error) {
+	if name != "" {
+		if _, ok := config.Environments[name]; !ok {
+			return "", &EnvironmentNotDefinedError{Name: name, Available: availableEnvironments(config)}
+		}
+		return name, nil
+	}
+
+	if profile == "" {
+		return "", nil
+	}
+
+	var matches []string
+	for envName, environment := range config.Environments {
+		if environment.Profile == profile {
+			matches = append(matches, envName)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		if _, ok := config.Environments[profile]; ok {
+			return profile, nil
+		}
+		return "", nil
+	case 1:
+		return matches[0], nil
+	default:
+		slices.Sort(matches)
+		return "", fmt.Errorf("environments %s all select profile %q, use --env to choose one", strings.Join(matches, ", "), profile)
+	}
+}
+
+// ApplyEnvironment overlays the named environment onto config. An empty name leaves
+// config unchanged. An environment's appSlug replaces the config's appId as well,
+// since the id belongs to the app in another vendor account.
+func (p *ConfigParser) ApplyEnvironment(config *Config, name string) error {
+	if name == "" {
+		return nil
+	}
+
+	environment, ok := config.Environments[name]
+	if !ok {
+		return &EnvironmentNotDefinedError{Name: name, Available: availableEnvironments(config)}
+	}
+
+	from := []string{"environments", name}
+	if environment.AppSlug != "" {
+		config.AppSlug = environment.AppSlug
+		config.AppId = ""
+		p.sources.replace([]string{"appSlug"}, append(from, "appSlug"))
+		p.sources.clear("appId")
+	}
+	if len(environment.PromoteToChannelNames) > 0 {
+		config.PromoteToChannelNames = environment.PromoteToChannelNames
+		config.PromoteToChannelIds = nil
+		p.sources.replace([]string{"promoteToChannelNames"}, append(from, "promoteToChannelNames"))
+		p.sources.clear("promoteToChannelIds")
+	}
+	if environment.ReleaseLabel != "" {
+		config.ReleaseLabel = environment.ReleaseLabel
+		p.sources.replace([]string{"releaseLabel"}, append(from, "releaseLabel"))
+	}
+	if len(environment.Charts) > 0 {
+		config.Charts = environment.Charts
+		p.sources.replaceEntries([]string{"charts"}, append(from, "charts"), len(environment.Charts))
+	}
+
+	return nil
+}
+
+// mergeEnvironmentConfig merges two definitions of the same environment
+// Only overrides parent fields the child sets
+func mergeEnvironmentConfig(parent, child EnvironmentConfig, src sourceRecorder) EnvironmentConfig {
+	result := parent
+
+	if child.Profile != "" {
+		result.Profile = child.Profile
+		src.set("profile")
+	}
+	if child.AppSlug != "" {
+		result.AppSlug = child.AppSlug
+		src.set("appSlug")
+	}
+	if len(child.PromoteToChannelNames) > 0 {
+		result.PromoteToChannelNames = child.PromoteToChannelNames
+		src.set("promoteToChannelNames")
+	}
+	if child.ReleaseLabel != "" {
+		result.ReleaseLabel = child.ReleaseLabel
+		src.set("releaseLabel")
+	}
+	if len(child.Charts) > 0 {
+		result.Charts = child.Charts
+		src.set("charts")
+	}
+
+	return result
+}
+
+// EnvironmentNotDefinedError reports an environment selected by name that the
+// config does not define
+type EnvironmentNotDefinedError struct {
+	Name      string
+	Available string // description of the defined environments, e.g. " (available: a, b)"
+}
+
+func (e *EnvironmentNotDefinedError) Error() string {
+	return fmt.Sprintf("environment %q is not defined in .replicated%s", e.Name, e.Available)
+}
+
+// availableEnvironments lists the environments a config defines, for error messages
+func availableEnvironments(config *Config) string {
+	if len(config.Environments) == 0 {
+		return " (no environments are defined)"
+	}
+	names := make([]string, 0, len(config.Environments))
+	for name := range config.Environments {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return " (available: " + strings.Join(names, ", ") + ")"
+}
@@ -0,0 +1,197 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConfigParser_SelectEnvironment(t *testing.T) {
+	config := &Config{
+		Environments: map[string]EnvironmentConfig{
+			"staging":    {AppSlug: "my-app-staging"},
+			"production": {AppSlug: "my-app", Profile: "prod-account"},
+		},
+	}
+	parser := NewConfigParser()
+
+	tests := []struct {
+		name    string
+		env     string
+		profile string
+		want    string
+		wantErr string
+	}{
+		{name: "explicit environment", env: "production", profile: "staging", want: "production"},
+		{name: "profile matches environment name", profile: "staging", want: "staging"},
+		{name: "profile matches profile field", profile: "prod-account", want: "production"},
+		{name: "no matching profile", profile: "other", want: ""},
+		{name: "no selection", want: ""},
+		{name: "unknown environment", env: "qa", wantErr: `environment "qa" is not defined in .replicated (available: production, staging)`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parser.SelectEnvironment(config, tt.env, tt.profile)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("SelectEnvironment() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SelectEnvironment() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("SelectEnvironment() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigParser_SelectEnvironment_AmbiguousProfile(t *testing.T) {
+	config := &Config{
+		Environments: map[string]EnvironmentConfig{
+			"a": {Profile: "shared"},
+			"b": {Profile: "shared"},
+		},
+	}
+	_, err := NewConfigParser().SelectEnvironment(config, "", "shared")
+	if err == nil || !strings.Contains(err.Error(), "environments a, b all select profile") {
+		t.Errorf("SelectEnvironment() error = %v, want ambiguous profile error", err)
+	}
+}
+
+func TestConfigParser_ApplyEnvironment(t *testing.T) {
+	config := &Config{
+		AppId:                 "app-id",
+		AppSlug:               "my-app",
+		PromoteToChannelIds:   []string{"channel-id"},
+		PromoteToChannelNames: []string{"Unstable"},
+		ReleaseLabel:          "1.0.0",
+		Charts:                []ChartConfig{{Path: "/repo/chart"}},
+		Environments: map[string]EnvironmentConfig{
+			"production": {
+				AppSlug:               "my-app-prod",
+				PromoteToChannelNames: []string{"Stable"},
+				Charts:                []ChartConfig{{Path: "/repo/prod-chart"}},
+			},
+		},
+	}
+
+	if err := NewConfigParser().ApplyEnvironment(config, "production"); err != nil {
+		t.Fatalf("ApplyEnvironment() error = %v", err)
+	}
+	if config.AppSlug != "my-app-prod" || config.AppId != "" {
+		t.Errorf("app = %q/%q, want my-app-prod with no appId", config.AppSlug, config.AppId)
+	}
+	if !reflect.DeepEqual(config.PromoteToChannelNames, []string{"Stable"}) || config.PromoteToChannelIds != nil {
+		t.Errorf("channels = %v/%v, want [Stable] with no ids", config.PromoteToChannelNames, config.PromoteToChannelIds)
+	}
+	if config.ReleaseLabel != "1.0.0" {
+		t.Errorf("ReleaseLabel = %q, want unchanged 1.0.0", config.ReleaseLabel)
+	}
+	if !reflect.DeepEqual(config.Charts, []ChartConfig{{Path: "/repo/prod-chart"}}) {
+		t.Errorf("Charts = %v, want the environment's charts", config.Charts)
+	}
+}
+
+func TestFindAndParseConfig_MergesEnvironments(t *testing.T) {
+	root := t.TempDir()
+	child := filepath.Join(root, "app")
+	if err := os.MkdirAll(child, 0755); err != nil {
+		t.Fatal(err)
+	}
+	parent := `environments:
+  staging:
+    appSlug: my-app-staging
+    promoteToChannelNames: [Beta]
+  production:
+    appSlug: my-app
+`
+	childData := `environments:
+  staging:
+    releaseLabel: staging-label
+    charts:
+      - path: ./chart
+`
+	if err := os.WriteFile(filepath.Join(root, ".replicated"), []byte(parent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(child, ".replicated"), []byte(childData), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := NewConfigParser().FindAndParseConfig(child)
+	if err != nil {
+		t.Fatalf("FindAndParseConfig() error = %v", err)
+	}
+
+	want := EnvironmentConfig{
+		AppSlug:               "my-app-staging",
+		PromoteToChannelNames: []string{"Beta"},
+		ReleaseLabel:          "staging-label",
+		Charts:                []ChartConfig{{Path: filepath.Join(child, "chart")}},
+	}
+	if !reflect.DeepEqual(config.Environments["staging"], want) {
+		t.Errorf("Environments[staging] = %+v, want %+v", config.Environments["staging"], want)
+	}
+	if config.Environments["production"].AppSlug != "my-app" {
+		t.Errorf("Environments[production].AppSlug = %q, want my-app", config.Environments["production"].AppSlug)
+	}
+}
+
+func TestParseConfig_EnvironmentChartPathRequired(t *testing.T) {
+	_, err := NewConfigParser().ParseConfig([]byte("environments:\n  staging:\n    charts:\n      - chartVersion: 1.0.0\n"))
+	if err == nil || !strings.Contains(err.Error(), "environments.staging.charts[0]: path is required") {
+		t.Errorf("ParseConfig() error = %v, want missing path error", err)
+	}
+}
+
+func TestFindAndParseEnvironmentConfig(t *testing.T) {
+	root := t.TempDir()
+	child := filepath.Join(root, "app")
+	if err := os.MkdirAll(child, 0755); err != nil {
+		t.Fatal(err)
+	}
+	parent := `appSlug: my-app
+charts:
+  - path: ./app/base
+`
+	childData := `environments:
+  staging:
+    appSlug: my-app-staging
+    charts:
+      - path: ./chart
+      - path: ./chart
+`
+	if err := os.WriteFile(filepath.Join(root, ".replicated"), []byte(parent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(child, ".replicated"), []byte(childData), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	parser := NewConfigParser()
+	config, err := parser.FindAndParseEnvironmentConfig(child, "staging", "")
+	if err != nil {
+		t.Fatalf("FindAndParseEnvironmentConfig() error = %v", err)
+	}
+	if config.AppSlug != "my-app-staging" {
+		t.Errorf("AppSlug = %q, want my-app-staging", config.AppSlug)
+	}
+
+	// The environment's charts are deduplicated and defaults are applied after it
+	want := []ChartConfig{{Path: filepath.Join(child, "chart")}}
+	if !reflect.DeepEqual(config.Charts, want) {
+		t.Errorf("Charts = %+v, want %+v", config.Charts, want)
+	}
+	if config.ReplLint == nil || config.ReplLint.Tools[ToolHelm] != "latest" {
+		t.Errorf("expected defaults to be applied, got %+v", config.ReplLint)
+	}
+
+	if _, err := parser.FindAndParseEnvironmentConfig(child, "production", ""); err == nil || !strings.Contains(err.Error(), `environment "production" is not defined`) {
+		t.Errorf("expected an undefined environment to fail, got %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"gopkg.in/yaml.v3"
+)
+
+// expandIncludes returns configPaths with the files each one includes inserted before
+// it, recursively, so that mergeConfigs lets a config override what it includes. A
+// file included more than once is merged at its first position only. Include cycles,
+// and included paths without wildcards that do not exist, are errors.
+func (p *ConfigParser) expandIncludes(configPaths []string) ([]string, error) {
+	var expanded []string
+	seen := make(map[string]bool)
+
+	var expand func(path string, stack []string) error
+	expand = func(path string, stack []string) error {
+		if slices.Contains(stack, path) {
+			return fmt.Errorf("include cycle: %s", strings.Join(append(stack, path), " -> "))
+		}
+		if seen[path] {
+			return nil
+		}
+
+		includes, err := readConfigIncludes(path, p.gitRequired)
+		if err != nil {
+			return err
+		}
+		for _, include := range includes {
+			if err := expand(include, append(stack, path)); err != nil {
+				return err
+			}
+		}
+
+		seen[path] = true
+		expanded = append(expanded, path)
+		return nil
+	}
+
+	for _, path := range configPaths {
+		if err := expand(path, nil); err != nil {
+			return nil, err
+		}
+	}
+	return expanded, nil
+}
+
+// ConfigSyntaxError reports a config file that is not valid YAML, found while reading
+// the files it includes
+type ConfigSyntaxError struct {
+	Path string
+	Err  error
+}
+
+func (e *ConfigSyntaxError) Error() string {
+	return fmt.Sprintf("%s: parsing config as YAML: %v", e.Path, e.Err)
+}
+
+func (e *ConfigSyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// readConfigIncludes returns the files a config file includes, as absolute paths in
+// the order listed, with globs expanded in lexical order. A file that is not valid
+// YAML is a *ConfigSyntaxError.
+func readConfigIncludes(path string, gitRequired bool) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+
+	var config struct {
+		Include []string `yaml:"include"`
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, &ConfigSyntaxError{Path: path, Err: err}
+	}
+
+	dir := filepath.Dir(path)
+	vars := &configVariables{dir: dir, gitRequired: gitRequired}
+	var includes []string
+	for i, include := range config.Include {
+		include, err := vars.expand(include)
+		if err != nil {
+			return nil, fmt.Errorf("%s: include[%d]: %w", path, i, err)
+		}
+		if include == "" {
+			return nil, fmt.Errorf("%s: include[%d]: path cannot be empty string", path, i)
+		}
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(dir, include)
+		}
+
+		if !containsGlob(include) {
+			if _, err := os.Stat(include); err != nil {
+				return nil, fmt.Errorf("%s: include[%d]: %w", path, i, err)
+			}
+			includes = append(includes, include)
+			continue
+		}
+
+		matches, err := doublestar.FilepathGlob(include, doublestar.WithFilesOnly())
+		if err != nil {
+			return nil, fmt.Errorf("%s: include[%d]: invalid glob pattern %q: %w", path, i, include, err)
+		}
+		slices.Sort(matches)
+		includes = append(includes, matches...)
+	}
+	return includes, nil
+}
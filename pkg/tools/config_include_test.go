@@ -0,0 +1,163 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeConfigFiles writes files relative to root, creating their directories
+func writeConfigFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFindAndParseConfig_Include(t *testing.T) {
+	root := t.TempDir()
+	writeConfigFiles(t, root, map[string]string{
+		"shared/lint.yaml": `repl-lint:
+  linters:
+    helm:
+      strict: true
+      ignore:
+        - rule: shared-rule
+  tools:
+    helm: 3.14.4
+    preflight: 0.100.0
+`,
+		"shared/tools/helm.yaml":      "repl-lint:\n  tools:\n    helm: 3.15.0\n",
+		"shared/tools/preflight.yaml": "repl-lint:\n  tools:\n    preflight: 0.101.0\n",
+		"app/.replicated": `include:
+  - ../shared/lint.yaml
+  - ../shared/tools/*.yaml
+appSlug: my-app
+charts:
+  - path: ./chart
+repl-lint:
+  tools:
+    support-bundle: 0.102.0
+`,
+	})
+
+	parser := NewConfigParser()
+	app := filepath.Join(root, "app")
+
+	files, err := parser.FindConfigFiles(app)
+	if err != nil {
+		t.Fatalf("FindConfigFiles() error = %v", err)
+	}
+	want := []string{
+		filepath.Join(root, "shared", "lint.yaml"),
+		filepath.Join(root, "shared", "tools", "helm.yaml"),
+		filepath.Join(root, "shared", "tools", "preflight.yaml"),
+		filepath.Join(app, ".replicated"),
+	}
+	if len(files) < len(want) || !reflect.DeepEqual(files[len(files)-len(want):], want) {
+		t.Errorf("FindConfigFiles() = %v, want to end with %v", files, want)
+	}
+
+	config, err := parser.FindAndParseConfig(app)
+	if err != nil {
+		t.Fatalf("FindAndParseConfig() error = %v", err)
+	}
+	wantTools := map[string]string{ToolHelm: "3.15.0", ToolPreflight: "0.101.0", ToolSupportBundle: "0.102.0"}
+	if !reflect.DeepEqual(config.ReplLint.Tools, wantTools) {
+		t.Errorf("Tools = %v, want %v", config.ReplLint.Tools, wantTools)
+	}
+	if !config.ReplLint.Linters.Helm.IsStrict() || len(config.ReplLint.Linters.Helm.Ignore) != 1 {
+		t.Errorf("helm linter = %+v, want strict with the shared ignore rule", config.ReplLint.Linters.Helm)
+	}
+	if config.AppSlug != "my-app" || len(config.Charts) != 1 {
+		t.Errorf("appSlug, charts = %q, %v, want my-app and one chart", config.AppSlug, config.Charts)
+	}
+	if config.Include != nil {
+		t.Errorf("Include = %v, want nil once merged", config.Include)
+	}
+
+	_, provenance, err := parser.ResolveConfig(app, "", "")
+	if err != nil {
+		t.Fatalf("ResolveConfig() error = %v", err)
+	}
+	if got := provenance.Sources["repl-lint.tools.helm"]; got != filepath.Join(root, "shared", "tools", "helm.yaml") {
+		t.Errorf("Sources[repl-lint.tools.helm] = %q, want the included helm.yaml", got)
+	}
+	if got := provenance.Sources["repl-lint.linters.helm.ignore[0]"]; got != filepath.Join(root, "shared", "lint.yaml") {
+		t.Errorf("Sources[repl-lint.linters.helm.ignore[0]] = %q, want the included lint.yaml", got)
+	}
+}
+
+func TestFindAndParseConfig_IncludedOnce(t *testing.T) {
+	root := t.TempDir()
+	writeConfigFiles(t, root, map[string]string{
+		"shared.yaml": "repl-lint:\n  linters:\n    helm:\n      ignore:\n        - rule: shared-rule\n",
+		"a.yaml":      "include: [shared.yaml]\n",
+		"b.yaml":      "include: [shared.yaml]\n",
+		".replicated": "include: [a.yaml, b.yaml]\n",
+	})
+
+	config, err := NewConfigParser().FindAndParseConfig(filepath.Join(root, ".replicated"))
+	if err != nil {
+		t.Fatalf("FindAndParseConfig() error = %v", err)
+	}
+	if got := len(config.ReplLint.Linters.Helm.Ignore); got != 1 {
+		t.Errorf("len(Ignore) = %d, want 1 from the file included twice", got)
+	}
+}
+
+func TestFindAndParseConfig_IncludeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name: "cycle",
+			files: map[string]string{
+				".replicated": "include: [a.yaml]\n",
+				"a.yaml":      "include: [b.yaml]\n",
+				"b.yaml":      "include: [a.yaml]\n",
+			},
+			wantErr: "include cycle: ",
+		},
+		{
+			name:    "missing file",
+			files:   map[string]string{".replicated": "include: [missing.yaml]\n"},
+			wantErr: "include[0]: stat ",
+		},
+		{
+			name: "invalid YAML",
+			files: map[string]string{
+				".replicated": "include: [a.yaml]\n",
+				"a.yaml":      "include: [b.yaml\n",
+			},
+			wantErr: "a.yaml: parsing config as YAML: yaml: line 1",
+		},
+		{
+			name:    "empty path",
+			files:   map[string]string{".replicated": "include: [\"\"]\n"},
+			wantErr: "include[0]: path cannot be empty string",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeConfigFiles(t, root, tt.files)
+
+			_, err := NewConfigParser().FindAndParseConfig(filepath.Join(root, ".replicated"))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("FindAndParseConfig() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,326 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"gopkg.in/yaml.v3"
+)
+
+// configVariablePattern matches ${name}, ${name:-default} and ${name:?message}, and
+// the $${ escape for a literal ${
+var configVariablePattern = regexp.MustCompile(`\$\$\{|\$\{([^}:]*)(?::([-?])([^}]*))?\}`)
+
+// envVariableName matches the names of environment variables
+var envVariableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// configVariables resolves the variables of one config file. Git and chart values
+// are only looked up when a variable refers to them.
+type configVariables struct {
+	dir    string // directory of the config file; relative chart paths and git are resolved from it
+	charts []ChartConfig
+
+	// gitRequired makes git values that cannot be read an error rather than empty
+	gitRequired bool
+	gitLoaded   bool
+	gitErr      error
+	gitValues   map[string]string
+
+	chartsLoaded  bool
+	chartVersions map[string]string
+	chartErrs     []string
+}
+
+// interpolateConfig expands the variables in every scalar of the config document
+// node, except map keys, before it is decoded, so that fields of any type can use
+// them. Chart paths are expanded first so that ${chart.<name>.version} can read them.
+// dir is the directory of the config file, or "" to use the working directory.
+func interpolateConfig(doc *yaml.Node, dir string, gitRequired bool) error {
+	vars := &configVariables{dir: dir, gitRequired: gitRequired}
+
+	var charts []ChartConfig
+	for i, chart := range configChartNodes(doc) {
+		path, err := vars.expand(chart.Value)
+		if err != nil {
+			return fmt.Errorf("charts[%d].path: %w", i, err)
+		}
+		charts = append(charts, ChartConfig{Path: path})
+	}
+	vars.charts = charts
+
+	return vars.interpolate(doc, "")
+}
+
+// configChartNodes returns the path nodes of the top-level charts of a config document
+func configChartNodes(doc *yaml.Node) []*yaml.Node {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil
+	}
+	charts := mappingValue(doc.Content[0], "charts")
+	if charts == nil || charts.Kind != yaml.SequenceNode {
+		return nil
+	}
+	var paths []*yaml.Node
+	for _, chart := range charts.Content {
+		if path := mappingValue(chart, "path"); path != nil && path.Kind == yaml.ScalarNode {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// mappingValue returns the value of key in a mapping node, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// interpolate expands the variables in the scalars of node, naming field in errors.
+// Aliases are skipped; the node they refer to is expanded where it is defined.
+func (vars *configVariables) interpolate(node *yaml.Node, field string) error {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			if err := vars.interpolate(child, field); err != nil {
+				return err
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if err := vars.interpolate(node.Content[i+1], joinConfigField(field, node.Content[i].Value)); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			if err := vars.interpolate(child, fmt.Sprintf("%s[%d]", field, i)); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		expanded, err := vars.expand(node.Value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", field, err)
+		}
+		if expanded == node.Value {
+			return nil
+		}
+		node.Value = expanded
+		// A plain scalar was resolved as a string because of the variable; resolve
+		// it again so that, for example, ${DISABLED:-false} decodes into a bool
+		if node.Style&(yaml.TaggedStyle|yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+			node.Tag = ""
+		}
+	}
+	return nil
+}
+
+// joinConfigField appends a key to a config field path
+func joinConfigField(field, key string) string {
+	if field == "" {
+		return key
+	}
+	return field + "." + key
+}
+
+// expand replaces the variables in s
+func (vars *configVariables) expand(s string) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+
+	var expandErr error
+	expanded := configVariablePattern.ReplaceAllStringFunc(s, func(match string) string {
+		if expandErr != nil {
+			return ""
+		}
+		if match == "$${" {
+			return "${"
+		}
+
+		m := configVariablePattern.FindStringSubmatch(match)
+		name, operator, operand := strings.TrimSpace(m[1]), m[2], m[3]
+		if err := validateConfigVariable(name); err != nil {
+			expandErr = err
+			return ""
+		}
+		// A default also covers values that cannot be read, such as git outside a repository
+		value, err := vars.lookup(name)
+		if err != nil && operator != "-" {
+			// Git values are only required by commands that use them, such as release create
+			if strings.HasPrefix(name, "git.") && !vars.gitRequired {
+				return ""
+			}
+			expandErr = err
+			return ""
+		}
+		if value != "" {
+			return value
+		}
+
+		// Like a shell, an unset variable is empty unless ${NAME:?} requires it
+		switch operator {
+		case "-":
+			return operand
+		case "?":
+			if operand == "" {
+				operand = "is required"
+			}
+			expandErr = fmt.Errorf("variable %s %s", name, operand)
+		}
+		return ""
+	})
+	if expandErr != nil {
+		return "", expandErr
+	}
+	return expanded, nil
+}
+
+// validateConfigVariable checks that name is an environment variable, a git value or
+// a chart version
+func validateConfigVariable(name string) error {
+	switch {
+	case strings.HasPrefix(name, "git."):
+		if name != "git.sha" && name != "git.branch" && name != "git.tag" {
+			return fmt.Errorf("unknown variable %s: must be git.sha, git.branch or git.tag", name)
+		}
+	case strings.HasPrefix(name, "chart."):
+		chartName, ok := strings.CutSuffix(strings.TrimPrefix(name, "chart."), ".version")
+		if !ok || chartName == "" {
+			return fmt.Errorf("unknown variable %s: must be chart.<name>.version", name)
+		}
+	case !envVariableName.MatchString(name):
+		return fmt.Errorf("invalid variable name %q", name)
+	}
+	return nil
+}
+
+// lookup returns the value of a valid variable, empty when it is not set. An error
+// is returned when the value cannot be read.
+func (vars *configVariables) lookup(name string) (string, error) {
+	switch {
+	case strings.HasPrefix(name, "git."):
+		if err := vars.loadGit(); err != nil {
+			return "", fmt.Errorf("variable %s: %w", name, err)
+		}
+		// git.tag is empty when HEAD is not tagged
+		return vars.gitValues[name], nil
+
+	case strings.HasPrefix(name, "chart."):
+		chartName := strings.TrimSuffix(strings.TrimPrefix(name, "chart."), ".version")
+		vars.loadCharts()
+		version, ok := vars.chartVersions[chartName]
+		if !ok {
+			message := fmt.Sprintf("variable %s: no chart named %q in charts", name, chartName)
+			if len(vars.chartErrs) > 0 {
+				message += " (" + strings.Join(vars.chartErrs, "; ") + ")"
+			}
+			return "", fmt.Errorf("%s", message)
+		}
+		return version, nil
+
+	default:
+		return os.Getenv(name), nil
+	}
+}
+
+// loadGit reads the git values of the repository containing the config file
+func (vars *configVariables) loadGit() error {
+	if vars.gitLoaded {
+		return vars.gitErr
+	}
+	vars.gitLoaded = true
+
+	dir := vars.dir
+	if dir == "" {
+		dir = "."
+	}
+	sha, branch, _, err := GitSHABranch(dir)
+	if err != nil {
+		vars.gitErr = err
+		return err
+	}
+	tag, err := GitTag(dir)
+	if err != nil {
+		vars.gitErr = err
+		return err
+	}
+	vars.gitValues = map[string]string{
+		"git.sha":    sha,
+		"git.branch": branch,
+		"git.tag":    tag,
+	}
+	return nil
+}
+
+// loadCharts reads the name and version of each chart directory in charts. Charts
+// that cannot be read are recorded for error messages.
+func (vars *configVariables) loadCharts() {
+	if vars.chartsLoaded {
+		return
+	}
+	vars.chartsLoaded = true
+	vars.chartVersions = make(map[string]string)
+
+	for _, chart := range vars.charts {
+		path := chart.Path
+		if !filepath.IsAbs(path) && vars.dir != "" {
+			path = filepath.Join(vars.dir, path)
+		}
+
+		dirs := []string{path}
+		if containsGlob(path) {
+			matches, err := doublestar.FilepathGlob(path)
+			if err != nil {
+				vars.chartErrs = append(vars.chartErrs, fmt.Sprintf("%s: %v", chart.Path, err))
+				continue
+			}
+			dirs = matches
+		}
+
+		for _, dir := range dirs {
+			name, version, err := readChartNameVersion(dir)
+			if err != nil {
+				vars.chartErrs = append(vars.chartErrs, fmt.Sprintf("%s: %v", dir, err))
+				continue
+			}
+			vars.chartVersions[name] = version
+		}
+	}
+}
+
+// readChartNameVersion reads the name and version from a chart directory's Chart.yaml
+func readChartNameVersion(dir string) (name, version string, err error) {
+	data, err := os.ReadFile(filepath.Join(dir, "Chart.yaml"))
+	if err != nil {
+		// Some charts use the .yml extension
+		var ymlErr error
+		data, ymlErr = os.ReadFile(filepath.Join(dir, "Chart.yml"))
+		if ymlErr != nil {
+			return "", "", fmt.Errorf("reading Chart.yaml: %w", err)
+		}
+	}
+
+	var chart struct {
+		Name    string `yaml:"name"`
+		Version string `yaml:"version"`
+	}
+	if err := yaml.Unmarshal(data, &chart); err != nil {
+		return "", "", fmt.Errorf("parsing Chart.yaml: %w", err)
+	}
+	if chart.Name == "" {
+		return "", "", fmt.Errorf("chart name is empty in Chart.yaml")
+	}
+	return chart.Name, chart.Version, nil
+}
@@ -0,0 +1,242 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestParseConfig_Interpolation(t *testing.T) {
+	t.Setenv("APP_SLUG", "my-app")
+	t.Setenv("EMPTY_VAR", "")
+	t.Setenv("HELM_VERSION", "3.14.4")
+
+	tests := []struct {
+		name    string
+		config  string
+		check   func(*testing.T, *Config)
+		wantErr string
+	}{
+		{
+			name:   "environment variables",
+			config: "appSlug: ${APP_SLUG}\nreleaseLabel: v-${APP_SLUG}-1\nrepl-lint:\n  tools:\n    helm: ${HELM_VERSION}\n",
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.AppSlug != "my-app" || cfg.ReleaseLabel != "v-my-app-1" {
+					t.Errorf("appSlug, releaseLabel = %q, %q, want my-app, v-my-app-1", cfg.AppSlug, cfg.ReleaseLabel)
+				}
+				if cfg.ReplLint.Tools[ToolHelm] != "3.14.4" {
+					t.Errorf("helm version = %q, want 3.14.4", cfg.ReplLint.Tools[ToolHelm])
+				}
+			},
+		},
+		{
+			name:   "defaults",
+			config: "releaseLabel: ${UNSET_VAR:-0.0.0}\nappSlug: ${EMPTY_VAR:-fallback}\nappId: ${UNSET_VAR:-}\n",
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.ReleaseLabel != "0.0.0" || cfg.AppSlug != "fallback" || cfg.AppId != "" {
+					t.Errorf("got %q, %q, %q, want 0.0.0, fallback and empty", cfg.ReleaseLabel, cfg.AppSlug, cfg.AppId)
+				}
+			},
+		},
+		{
+			name:   "typed fields",
+			config: "repl-lint:\n  version: ${LINT_VERSION:-1}\n  linters:\n    helm:\n      disabled: ${HELM_DISABLED:-true}\n",
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.ReplLint.Version != 1 {
+					t.Errorf("repl-lint.version = %d, want 1", cfg.ReplLint.Version)
+				}
+				if disabled := cfg.ReplLint.Linters.Helm.Disabled; disabled == nil || !*disabled {
+					t.Errorf("repl-lint.linters.helm.disabled = %v, want true", disabled)
+				}
+			},
+		},
+		{
+			name:   "escaped",
+			config: "releaseLabel: $${APP_SLUG}\n",
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.ReleaseLabel != "${APP_SLUG}" {
+					t.Errorf("releaseLabel = %q, want ${APP_SLUG}", cfg.ReleaseLabel)
+				}
+			},
+		},
+		{
+			name:   "environment overlays",
+			config: "environments:\n  staging:\n    appSlug: ${APP_SLUG}-staging\n",
+			check: func(t *testing.T, cfg *Config) {
+				if got := cfg.Environments["staging"].AppSlug; got != "my-app-staging" {
+					t.Errorf("environments.staging.appSlug = %q, want my-app-staging", got)
+				}
+			},
+		},
+		{
+			name:   "unset variable",
+			config: "releaseLabel: ${UNSET_VAR}\n",
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.ReleaseLabel != "" {
+					t.Errorf("releaseLabel = %q, want empty", cfg.ReleaseLabel)
+				}
+			},
+		},
+		{
+			name:    "required variable",
+			config:  "releaseLabel: ${UNSET_VAR:?must be set by CI}\n",
+			wantErr: "releaseLabel: variable UNSET_VAR must be set by CI",
+		},
+		{
+			name:    "required variable is empty",
+			config:  "charts:\n  - path: ./chart\n    appVersion: ${EMPTY_VAR:?}\n",
+			wantErr: "charts[0].appVersion: variable EMPTY_VAR is required",
+		},
+		{
+			name:    "unknown git value",
+			config:  "releaseLabel: ${git.commit}\n",
+			wantErr: "unknown variable git.commit",
+		},
+		{
+			name:    "invalid name",
+			config:  "releaseLabel: ${not a name:-x}\n",
+			wantErr: `invalid variable name "not a name"`,
+		},
+		{
+			name:    "expanded value is validated",
+			config:  "repl-lint:\n  tools:\n    helm: ${APP_SLUG}\n",
+			wantErr: `invalid version "my-app" for tool "helm"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := NewConfigParser().ParseConfig([]byte(tt.config))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseConfig() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseConfig() error = %v", err)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestParseConfigFile_ChartVersionVariable(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "charts", "app"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "charts", "app", "Chart.yaml"), []byte("name: my-chart\nversion: 1.2.3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(dir, ".replicated")
+	config := "releaseLabel: ${chart.my-chart.version}\ncharts:\n  - path: ./charts/*\n    appVersion: ${chart.my-chart.version}\n"
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	parser := NewConfigParser()
+	cfg, err := parser.ParseConfigFile(configPath)
+	if err != nil {
+		t.Fatalf("ParseConfigFile() error = %v", err)
+	}
+	if cfg.ReleaseLabel != "1.2.3" || cfg.Charts[0].AppVersion != "1.2.3" {
+		t.Errorf("releaseLabel, appVersion = %q, %q, want 1.2.3", cfg.ReleaseLabel, cfg.Charts[0].AppVersion)
+	}
+
+	if err := os.WriteFile(configPath, []byte("releaseLabel: ${chart.other.version}\ncharts:\n  - path: ./charts/*\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = parser.ParseConfigFile(configPath)
+	if err == nil || !strings.Contains(err.Error(), `no chart named "other" in charts`) {
+		t.Errorf("ParseConfigFile() error = %v, want unknown chart error", err)
+	}
+}
+
+func TestParseConfigFile_GitVariables(t *testing.T) {
+	t.Setenv("GITHUB_BRANCH_NAME", "")
+
+	dir := t.TempDir()
+	repository, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repository.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(dir, ".replicated")
+	config := "releaseLabel: ${git.branch}-${git.sha}\nappSlug: ${git.tag:-untagged}\nappId: ${git.tag}\n"
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := worktree.Add(".replicated"); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := worktree.Commit("initial", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := repository.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parser := NewConfigParser()
+	cfg, err := parser.ParseConfigFile(configPath)
+	if err != nil {
+		t.Fatalf("ParseConfigFile() error = %v", err)
+	}
+	if want := head.Name().Short() + "-" + hash.String()[:7]; cfg.ReleaseLabel != want {
+		t.Errorf("releaseLabel = %q, want %q", cfg.ReleaseLabel, want)
+	}
+	if cfg.AppSlug != "untagged" || cfg.AppId != "" {
+		t.Errorf("appSlug, appId = %q, %q, want untagged and empty", cfg.AppSlug, cfg.AppId)
+	}
+
+	if _, err := repository.CreateTag("v1.0.0", hash, &git.CreateTagOptions{
+		Tagger:  &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+		Message: "v1.0.0",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(configPath, []byte("releaseLabel: ${git.tag}\nappSlug: ${git.branch}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = parser.ParseConfigFile(configPath)
+	if err != nil {
+		t.Fatalf("ParseConfigFile() error = %v", err)
+	}
+	if cfg.ReleaseLabel != "v1.0.0" || cfg.AppSlug != head.Name().Short() {
+		t.Errorf("releaseLabel, appSlug = %q, %q, want v1.0.0, %s", cfg.ReleaseLabel, cfg.AppSlug, head.Name().Short())
+	}
+}
+
+func TestParseConfigFile_GitVariablesOutsideRepository(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, ".replicated")
+	if err := os.WriteFile(configPath, []byte("appSlug: my-app\nreleaseLabel: ${git.sha}\nappId: ${git.tag:-none}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Commands that don't need git values work outside a checkout
+	cfg, err := NewConfigParser().ParseConfigFile(configPath)
+	if err != nil {
+		t.Fatalf("ParseConfigFile() error = %v", err)
+	}
+	if cfg.AppSlug != "my-app" || cfg.ReleaseLabel != "" || cfg.AppId != "none" {
+		t.Errorf("appSlug, releaseLabel, appId = %q, %q, %q, want my-app, empty and none", cfg.AppSlug, cfg.ReleaseLabel, cfg.AppId)
+	}
+
+	_, err = NewConfigParser(WithGitRequired(true)).ParseConfigFile(configPath)
+	if err == nil || !strings.Contains(err.Error(), "releaseLabel: variable git.sha") {
+		t.Errorf("ParseConfigFile() error = %v, want git error", err)
+	}
+}
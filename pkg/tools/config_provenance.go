@@ -0,0 +1,298 @@
+package tools
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultConfigSource is the source of config values filled in by ApplyDefaults
+const DefaultConfigSource = "(default)"
+
+// ConfigProvenance records which config file contributed each value of a merged config
+type ConfigProvenance struct {
+	Files []string // config files, from the outermost parent to the closest child
+
+	// Sources maps a field, e.g. repl-lint.tools.helm or charts[0], to the file it
+	// came from. Chart, preflight, manifest and ignore rule entries are recorded as a
+	// whole; lists that a child replaces rather than appends to are recorded by the
+	// list's field.
+	Sources map[string]string
+}
+
+// ResolveConfig returns the config FindAndParseEnvironmentConfig returns for
+// startPath, envName and profile, and the file each value came from
+func (p *ConfigParser) ResolveConfig(startPath, envName, profile string) (*Config, *ConfigProvenance, error) {
+	recording := &ConfigParser{sources: newConfigSources(), gitRequired: p.gitRequired}
+	config, files, err := recording.findAndParseEnvironmentConfig(startPath, envName, profile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	node, err := configToNode(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	provenance := &ConfigProvenance{
+		Files:   files,
+		Sources: make(map[string]string),
+	}
+	walkConfigSources(node, nil, func(location []string, _ *yaml.Node) {
+		provenance.Sources[configFieldPath(location)] = recording.sources.source(location)
+	})
+
+	return config, provenance, nil
+}
+
+// ConfigYAML encodes a config as YAML. With provenance, each value is annotated with
+// a comment naming the file it came from.
+func ConfigYAML(config *Config, provenance *ConfigProvenance) ([]byte, error) {
+	node, err := configToNode(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if provenance != nil {
+		walkConfigSources(node, nil, func(location []string, annotated *yaml.Node) {
+			if source, ok := provenance.Sources[configFieldPath(location)]; ok {
+				annotated.LineComment = source
+			}
+		})
+	}
+
+	var sb strings.Builder
+	encoder := yaml.NewEncoder(&sb)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return nil, fmt.Errorf("encoding config: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("encoding config: %w", err)
+	}
+	return []byte(sb.String()), nil
+}
+
+// ConfigValue returns a config as the JSON value of its YAML encoding, so that its
+// keys match the .replicated file
+func ConfigValue(config *Config) (map[string]any, error) {
+	return configToValue(config)
+}
+
+// configToNode encodes a config as a YAML mapping node
+func configToNode(config *Config) (*yaml.Node, error) {
+	var node yaml.Node
+	if err := node.Encode(config); err != nil {
+		return nil, fmt.Errorf("encoding config: %w", err)
+	}
+	return &node, nil
+}
+
+// configToValue encodes a config as the JSON value of its YAML encoding
+func configToValue(config *Config) (map[string]any, error) {
+	node, err := configToNode(config)
+	if err != nil {
+		return nil, err
+	}
+	value, _ := configValue(node).(map[string]any)
+	return value, nil
+}
+
+// isAppendedConfigList reports whether the list at location accumulates entries from
+// every config file in mergeConfigs, rather than being replaced by the child
+func isAppendedConfigList(location []string) bool {
+	switch len(location) {
+	case 1:
+		return location[0] == "charts" || location[0] == "preflights" || location[0] == "manifests"
+	case 4:
+		return location[0] == "repl-lint" && location[1] == "linters" && location[3] == "ignore"
+	}
+	return false
+}
+
+// walkConfigSources calls fn for every value of a config node that has a source of
+// its own: scalars, replaced lists as a whole, and each entry of appended lists.
+// fn receives the node to annotate, which is the key node for lists and mappings.
+func walkConfigSources(node *yaml.Node, location []string, fn func(location []string, annotated *yaml.Node)) {
+	if node.Kind == yaml.DocumentNode {
+		for _, content := range node.Content {
+			walkConfigSources(content, location, fn)
+		}
+		return
+	}
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		fieldLocation := append(location[:len(location):len(location)], key.Value)
+
+		switch value.Kind {
+		case yaml.MappingNode:
+			walkConfigSources(value, fieldLocation, fn)
+		case yaml.SequenceNode:
+			if !isAppendedConfigList(fieldLocation) {
+				fn(fieldLocation, key)
+				continue
+			}
+			for j, entry := range value.Content {
+				entryLocation := append(fieldLocation[:len(fieldLocation):len(fieldLocation)], strconv.Itoa(j))
+				annotated := entry
+				if entry.Kind == yaml.MappingNode && len(entry.Content) > 0 {
+					// Annotate the entry's first key, the line the entry starts on
+					annotated = entry.Content[0]
+				}
+				fn(entryLocation, annotated)
+			}
+		default:
+			fn(fieldLocation, value)
+		}
+	}
+}
+
+// configSources records the file each value of a config came from, as
+// mergeConfigs, ApplyEnvironment and deduplicateResources build it
+type configSources struct {
+	files   []string            // config files, in the order they are merged
+	fields  map[string]string   // field, e.g. repl-lint.tools.helm, to the file that set it
+	entries map[string][]string // appended list, e.g. charts, to the file of each entry
+}
+
+func newConfigSources() *configSources {
+	return &configSources{
+		fields:  make(map[string]string),
+		entries: make(map[string][]string),
+	}
+}
+
+// recorder returns a recorder for the values the i-th config file sets below location
+func (s *configSources) recorder(i int, location ...string) sourceRecorder {
+	if s == nil || i >= len(s.files) {
+		return sourceRecorder{}
+	}
+	return sourceRecorder{sources: s, file: s.files[i], location: location}
+}
+
+// source returns the file the value at location of the config came from
+func (s *configSources) source(location []string) string {
+	last := len(location) - 1
+	if last > 0 && isAppendedConfigList(location[:last]) {
+		files := s.entries[configFieldPath(location[:last])]
+		if index, err := strconv.Atoi(location[last]); err == nil && index < len(files) {
+			return files[index]
+		}
+		return DefaultConfigSource
+	}
+	if file, ok := s.fields[configFieldPath(location)]; ok {
+		return file
+	}
+	return DefaultConfigSource
+}
+
+// replace records that the value at location was replaced by the value at from,
+// for example when an environment's appSlug overrides the config's
+func (s *configSources) replace(location, from []string) {
+	if s == nil {
+		return
+	}
+	if file, ok := s.fields[configFieldPath(from)]; ok {
+		s.fields[configFieldPath(location)] = file
+	}
+}
+
+// replaceEntries records that the entries of the appended list at location were
+// replaced by the n entries of the list at from
+func (s *configSources) replaceEntries(location, from []string, n int) {
+	if s == nil {
+		return
+	}
+	files := make([]string, n)
+	for i := range files {
+		files[i] = s.fields[configFieldPath(from)]
+	}
+	s.entries[configFieldPath(location)] = files
+}
+
+// clear forgets the source of the value at location, which was removed
+func (s *configSources) clear(location ...string) {
+	if s == nil {
+		return
+	}
+	delete(s.fields, configFieldPath(location))
+}
+
+// keepEntries records that only the entries at the given indexes of the appended list
+// at location were kept
+func (s *configSources) keepEntries(kept []int, location ...string) {
+	if s == nil {
+		return
+	}
+	key := configFieldPath(location)
+	files := make([]string, 0, len(kept))
+	for _, i := range kept {
+		if i < len(s.entries[key]) {
+			files = append(files, s.entries[key][i])
+		}
+	}
+	s.entries[key] = files
+}
+
+// sourceRecorder records the values one config file sets below a field of the config.
+// The zero value records nothing.
+type sourceRecorder struct {
+	sources  *configSources
+	file     string
+	location []string
+}
+
+// at returns a recorder for the values below a field of this one
+func (r sourceRecorder) at(location ...string) sourceRecorder {
+	r.location = append(r.location[:len(r.location):len(r.location)], location...)
+	return r
+}
+
+// set records that the file set the value at location
+func (r sourceRecorder) set(location ...string) {
+	if r.sources == nil {
+		return
+	}
+	r.sources.fields[configFieldPath(r.at(location...).location)] = r.file
+}
+
+// appendEntries records that the file appended n entries to the list at location
+func (r sourceRecorder) appendEntries(n int, location ...string) {
+	if r.sources == nil {
+		return
+	}
+	key := configFieldPath(r.at(location...).location)
+	for i := 0; i < n; i++ {
+		r.sources.entries[key] = append(r.sources.entries[key], r.file)
+	}
+}
+
+// setAll records that the file set every value of value, which is taken as a whole
+func (r sourceRecorder) setAll(value any) {
+	if r.sources == nil {
+		return
+	}
+	var node yaml.Node
+	if err := node.Encode(value); err != nil {
+		return
+	}
+	walkConfigSources(&node, r.location, func(location []string, annotated *yaml.Node) {
+		// repl-lint.version is encoded even when unset
+		if annotated.Tag == "!!int" && annotated.Value == "0" {
+			return
+		}
+		last := len(location) - 1
+		if last > 0 && isAppendedConfigList(location[:last]) {
+			key := configFieldPath(location[:last])
+			r.sources.entries[key] = append(r.sources.entries[key], r.file)
+			return
+		}
+		r.sources.fields[configFieldPath(location)] = r.file
+	})
+}
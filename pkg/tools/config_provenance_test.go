@@ -0,0 +1,164 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigParser_ResolveConfig(t *testing.T) {
+	root := t.TempDir()
+	child := filepath.Join(root, "app")
+	if err := os.MkdirAll(child, 0755); err != nil {
+		t.Fatal(err)
+	}
+	parentConfig := filepath.Join(root, ".replicated")
+	childConfig := filepath.Join(child, ".replicated")
+	parent := `appSlug: parent-app
+releaseLabel: parent-label
+promoteToChannelNames: [Unstable]
+charts:
+  - path: ./app/chart
+manifests:
+  - ./manifests/*.yaml
+repl-lint:
+  tools:
+    helm: 3.14.4
+    preflight: 0.100.0
+  linters:
+    helm:
+      ignore:
+        - rule: parent-rule
+`
+	childData := `appSlug: child-app
+charts:
+  - path: ./chart
+    chartVersion: 2.0.0
+  - path: ./other-chart
+repl-lint:
+  tools:
+    helm: 3.15.0
+  linters:
+    helm:
+      strict: true
+      ignore:
+        - rule: child-rule
+`
+	if err := os.WriteFile(parentConfig, []byte(parent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(childConfig, []byte(childData), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, provenance, err := NewConfigParser().ResolveConfig(child, "", "")
+	if err != nil {
+		t.Fatalf("ResolveConfig() error = %v", err)
+	}
+
+	// The duplicate chart from the child is dropped, keeping the parent's entry
+	if len(config.Charts) != 2 {
+		t.Fatalf("len(Charts) = %d, want 2", len(config.Charts))
+	}
+	if len(provenance.Files) < 2 || provenance.Files[len(provenance.Files)-1] != childConfig {
+		t.Errorf("Files = %v, want to end with %s", provenance.Files, childConfig)
+	}
+
+	want := map[string]string{
+		"appSlug":                                     childConfig,
+		"releaseLabel":                                parentConfig,
+		"promoteToChannelNames":                       parentConfig,
+		"charts[0]":                                   parentConfig,
+		"charts[1]":                                   childConfig,
+		"manifests[0]":                                parentConfig,
+		"repl-lint.tools.helm":                        childConfig,
+		"repl-lint.tools.preflight":                   parentConfig,
+		"repl-lint.tools.support-bundle":              DefaultConfigSource,
+		"repl-lint.version":                           DefaultConfigSource,
+		"repl-lint.linters.helm.strict":               childConfig,
+		"repl-lint.linters.helm.disabled":             DefaultConfigSource,
+		"repl-lint.linters.helm.ignore[0]":            parentConfig,
+		"repl-lint.linters.helm.ignore[1]":            childConfig,
+		"repl-lint.linters.embedded-cluster.disabled": DefaultConfigSource,
+	}
+	for field, source := range want {
+		if got := provenance.Sources[field]; got != source {
+			t.Errorf("Sources[%q] = %q, want %q", field, got, source)
+		}
+	}
+	if _, ok := provenance.Sources["charts[0].path"]; ok {
+		t.Error("Sources has charts[0].path, want chart entries recorded as a whole")
+	}
+
+	data, err := ConfigYAML(config, provenance)
+	if err != nil {
+		t.Fatalf("ConfigYAML() error = %v", err)
+	}
+	for _, line := range []string{
+		"appSlug: child-app # " + childConfig,
+		"helm: 3.15.0 # " + childConfig,
+		"version: 1 # " + DefaultConfigSource,
+	} {
+		if !strings.Contains(string(data), line) {
+			t.Errorf("ConfigYAML() missing line %q in\n%s", line, data)
+		}
+	}
+}
+
+func TestConfigParser_ResolveConfig_Environment(t *testing.T) {
+	root := t.TempDir()
+	child := filepath.Join(root, "app")
+	if err := os.MkdirAll(child, 0755); err != nil {
+		t.Fatal(err)
+	}
+	parentConfig := filepath.Join(root, ".replicated")
+	childConfig := filepath.Join(child, ".replicated")
+	parent := `appId: app-id
+appSlug: app
+charts:
+  - path: ./app/chart
+environments:
+  staging:
+    appSlug: app-staging
+    charts:
+      - path: ./app/staging-chart
+`
+	childData := `releaseLabel: v1
+environments:
+  staging:
+    releaseLabel: staging
+`
+	if err := os.WriteFile(parentConfig, []byte(parent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(childConfig, []byte(childData), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, provenance, err := NewConfigParser().ResolveConfig(child, "staging", "")
+	if err != nil {
+		t.Fatalf("ResolveConfig() error = %v", err)
+	}
+	if config.AppSlug != "app-staging" || config.AppId != "" || config.ReleaseLabel != "staging" {
+		t.Errorf("expected the staging environment to be applied, got %+v", config)
+	}
+
+	want := map[string]string{
+		"appSlug":      parentConfig,
+		"releaseLabel": childConfig,
+		"charts[0]":    parentConfig,
+	}
+	for field, source := range want {
+		if got := provenance.Sources[field]; got != source {
+			t.Errorf("Sources[%q] = %q, want %q", field, got, source)
+		}
+	}
+	if _, ok := provenance.Sources["appId"]; ok {
+		t.Error("Sources has appId, want it cleared by the environment")
+	}
+
+	if _, _, err := NewConfigParser().ResolveConfig(child, "production", ""); err == nil {
+		t.Error("expected an undefined environment to be an error")
+	}
+}
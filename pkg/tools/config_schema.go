@@ -0,0 +1,572 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"gopkg.in/yaml.v3"
+)
+
+// configSchemaResource is the URL the config schema is registered under
+const configSchemaResource = "replicated-config.schema.json"
+
+var configSchemaPrinter = message.NewPrinter(language.English)
+
+// ConfigIssue is a problem found in a .replicated config file
+type ConfigIssue struct {
+	Path    string `json:"path"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Field   string `json:"field,omitempty"` // e.g. repl-lint.linters.helm.disabled
+	Message string `json:"message"`
+}
+
+// String formats the issue as path:line:column: field: message
+func (i ConfigIssue) String() string {
+	var sb strings.Builder
+	sb.WriteString(i.Path)
+	if i.Line > 0 {
+		fmt.Fprintf(&sb, ":%d:%d", i.Line, i.Column)
+	}
+	sb.WriteString(": ")
+	if i.Field != "" {
+		sb.WriteString(i.Field + ": ")
+	}
+	sb.WriteString(i.Message)
+	return sb.String()
+}
+
+// ConfigSchema returns the JSON Schema of the .replicated config file, generated from
+// Config and the types it contains
+func ConfigSchema() map[string]any {
+	schema := typeSchema(reflect.TypeOf(Config{}))
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = "Replicated project configuration (.replicated)"
+	return schema
+}
+
+// ConfigSchemaJSON returns the indented JSON encoding of ConfigSchema
+func ConfigSchemaJSON() ([]byte, error) {
+	data, err := json.MarshalIndent(ConfigSchema(), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding config schema: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// typeSchema returns the schema of a config type from its yaml field tags. Structs
+// do not allow keys they do not declare.
+func typeSchema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var schema map[string]any
+	switch t.Kind() {
+	case reflect.Struct:
+		properties := make(map[string]any)
+		addStructProperties(t, properties)
+		schema = map[string]any{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+	case reflect.Map:
+		schema = map[string]any{
+			"type":                 "object",
+			"additionalProperties": typeSchema(t.Elem()),
+		}
+	case reflect.Slice:
+		schema = map[string]any{
+			"type":  "array",
+			"items": typeSchema(t.Elem()),
+		}
+	case reflect.String:
+		schema = map[string]any{"type": "string"}
+	case reflect.Bool:
+		schema = map[string]any{"type": "boolean"}
+	case reflect.Int:
+		schema = map[string]any{"type": "integer"}
+	default:
+		panic(fmt.Sprintf("no config schema for %s", t))
+	}
+
+	if refine, ok := configSchemaRefinements[t]; ok {
+		refine(schema)
+	}
+	return schema
+}
+
+// addStructProperties adds the schema of each yaml field of t to properties,
+// including the fields of inlined structs
+func addStructProperties(t reflect.Type, properties map[string]any) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, options, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if strings.Contains(options, "inline") {
+			addStructProperties(field.Type, properties)
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		properties[name] = typeSchema(field.Type)
+	}
+}
+
+// configSchemaRefinements add the constraints validateConfig checks that the field
+// types alone do not express
+var configSchemaRefinements = map[reflect.Type]func(schema map[string]any){
+	reflect.TypeOf(ChartConfig{}): func(schema map[string]any) {
+		schema["required"] = []string{"path"}
+		setSchemaAt(schema, "minLength", 1, "path")
+		setSchemaAt(schema, "minLength", 1, "values", "items")
+	},
+	reflect.TypeOf(PreflightConfig{}): func(schema map[string]any) {
+		schema["required"] = []string{"path"}
+		schema["dependentRequired"] = map[string]any{
+			"chartName":    []string{"chartVersion"},
+			"chartVersion": []string{"chartName"},
+		}
+		setSchemaAt(schema, "minLength", 1, "path")
+	},
+	reflect.TypeOf(Config{}): func(schema map[string]any) {
+		setSchemaAt(schema, "minLength", 1, "manifests", "items")
+		setSchemaAt(schema, "minLength", 1, "include", "items")
+	},
+	reflect.TypeOf(ReplLintConfig{}): func(schema map[string]any) {
+		setSchemaAt(schema, "minimum", 0, "version")
+		setSchemaAt(schema, "pattern", toolVersionSchemaPattern, "tools", "additionalProperties")
+		mirrors := make(map[string]any, len(MirrorTools))
+		for _, name := range MirrorTools {
+			mirrors[name] = map[string]any{"type": "string", "minLength": 1}
+		}
+		setSchemaAt(schema, "properties", mirrors, "tools-mirror")
+		setSchemaAt(schema, "additionalProperties", false, "tools-mirror")
+	},
+	reflect.TypeOf(IgnoreRule{}): func(schema map[string]any) {
+		schema["minProperties"] = 1
+	},
+	reflect.TypeOf(KubeSchemaLinterConfig{}): func(schema map[string]any) {
+		setSchemaAt(schema, "pattern", kubeMinorVersionPattern.String(), "versions", "items")
+	},
+	reflect.TypeOf(ImageLinterConfig{}): func(schema map[string]any) {
+		rules := make(map[string]any, len(ImageLintRules))
+		for _, rule := range ImageLintRules {
+			rules[rule] = map[string]any{"enum": []string{"error", "warn", "info", "off"}}
+		}
+		setSchemaAt(schema, "properties", rules, "rules")
+		setSchemaAt(schema, "additionalProperties", false, "rules")
+	},
+}
+
+// toolVersionSchemaPattern matches the tool versions isValidSemver accepts, and latest
+var toolVersionSchemaPattern = "^(latest|v?" + strings.TrimSuffix(strings.TrimPrefix(semverPattern.String(), "^"), "$") + ")$"
+
+// setSchemaAt sets a keyword in the schema of a nested property. Each step of path is
+// a property name, or "items" or "additionalProperties" for the element schema.
+func setSchemaAt(schema map[string]any, keyword string, value any, path ...string) {
+	for _, step := range path {
+		switch step {
+		case "items", "additionalProperties":
+			schema = schema[step].(map[string]any)
+		default:
+			schema = schema["properties"].(map[string]any)[step].(map[string]any)
+		}
+	}
+	schema[keyword] = value
+}
+
+var (
+	compileConfigSchemaOnce sync.Once
+	compiledConfigSchema    *jsonschema.Schema
+	compileConfigSchemaErr  error
+)
+
+// compileConfigSchema compiles ConfigSchema for validation
+func compileConfigSchema() (*jsonschema.Schema, error) {
+	compileConfigSchemaOnce.Do(func() {
+		// Round trip through JSON so the compiler sees plain JSON values
+		data, err := ConfigSchemaJSON()
+		if err != nil {
+			compileConfigSchemaErr = err
+			return
+		}
+		doc, err := jsonschema.UnmarshalJSON(strings.NewReader(string(data)))
+		if err != nil {
+			compileConfigSchemaErr = fmt.Errorf("parsing config schema: %w", err)
+			return
+		}
+		compiler := jsonschema.NewCompiler()
+		if err := compiler.AddResource(configSchemaResource, doc); err != nil {
+			compileConfigSchemaErr = fmt.Errorf("loading config schema: %w", err)
+			return
+		}
+		compiledConfigSchema, compileConfigSchemaErr = compiler.Compile(configSchemaResource)
+	})
+	return compiledConfigSchema, compileConfigSchemaErr
+}
+
+// yamlErrorLine matches the line number yaml.v3 reports in syntax errors
+var yamlErrorLine = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// ValidateConfigFile checks a single .replicated config file without merging it with
+// its parents. Unknown keys, values of the wrong type and invalid glob patterns are
+// reported with their line and column; other problems that would make parsing fail
+// are reported for the whole file. An error is returned only if the file cannot be
+// read or the schema cannot be compiled.
+func (p *ConfigParser) ValidateConfigFile(path string) ([]ConfigIssue, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		issue := ConfigIssue{Path: path, Message: strings.TrimPrefix(err.Error(), "yaml: ")}
+		if m := yamlErrorLine.FindStringSubmatch(err.Error()); m != nil {
+			issue.Line, _ = strconv.Atoi(m[1])
+			issue.Column = 1
+			issue.Message = m[2]
+		}
+		return []ConfigIssue{issue}, nil
+	}
+	if len(doc.Content) == 0 {
+		// An empty file is an empty config
+		return nil, nil
+	}
+
+	// Variables are expanded before the schema is checked, like when the file is parsed,
+	// so that ${DISABLED:-false} is a bool
+	if err := interpolateConfig(&doc, filepath.Dir(path), p.gitRequired); err != nil {
+		return []ConfigIssue{{Path: path, Message: fmt.Sprintf("expanding variables: %v", err)}}, nil
+	}
+	root := doc.Content[0]
+
+	schema, err := compileConfigSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []ConfigIssue
+	if err := schema.Validate(configValue(root)); err != nil {
+		validationErr, ok := err.(*jsonschema.ValidationError)
+		if !ok {
+			return nil, fmt.Errorf("validating config: %w", err)
+		}
+		issues = append(issues, configSchemaIssues(path, root, validationErr)...)
+	}
+	issues = append(issues, configGlobIssues(path, root)...)
+	if len(issues) > 0 {
+		sortConfigIssues(issues)
+		return issues, nil
+	}
+
+	// The schema covers the structure; anything else the parser rejects, such as an
+	// invalid regular expression, is reported without a position
+	if _, err := p.parseConfig(data, filepath.Dir(path)); err != nil {
+		issues = append(issues, ConfigIssue{Path: path, Message: err.Error()})
+	}
+	return issues, nil
+}
+
+// configValue converts a YAML node to the JSON value the schema validates
+func configValue(node *yaml.Node) any {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil
+		}
+		return configValue(node.Content[0])
+	case yaml.AliasNode:
+		return configValue(node.Alias)
+	case yaml.MappingNode:
+		value := make(map[string]any, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			value[node.Content[i].Value] = configValue(node.Content[i+1])
+		}
+		return value
+	case yaml.SequenceNode:
+		value := make([]any, 0, len(node.Content))
+		for _, item := range node.Content {
+			value = append(value, configValue(item))
+		}
+		return value
+	}
+
+	switch node.ShortTag() {
+	case "!!null":
+		return nil
+	case "!!bool", "!!int", "!!float":
+		var value any
+		if err := node.Decode(&value); err == nil {
+			return value
+		}
+	}
+	return node.Value
+}
+
+// configNodeAt returns the node at a JSON instance location in root, and the key
+// node of the last mapping entry on the way. Missing locations return the closest
+// node that exists.
+func configNodeAt(root *yaml.Node, location []string) (key, value *yaml.Node) {
+	value = root
+	for _, token := range location {
+		for value.Kind == yaml.AliasNode {
+			value = value.Alias
+		}
+		switch value.Kind {
+		case yaml.MappingNode:
+			found := false
+			for i := 0; i+1 < len(value.Content); i += 2 {
+				if value.Content[i].Value == token {
+					key, value = value.Content[i], value.Content[i+1]
+					found = true
+					break
+				}
+			}
+			if !found {
+				return key, value
+			}
+		case yaml.SequenceNode:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(value.Content) {
+				return key, value
+			}
+			key, value = nil, value.Content[index]
+		default:
+			return key, value
+		}
+	}
+	return key, value
+}
+
+// configFieldPath formats a JSON instance location as a config field, e.g.
+// charts[0].values[1]
+func configFieldPath(location []string) string {
+	var path strings.Builder
+	for _, token := range location {
+		if _, err := strconv.Atoi(token); err == nil {
+			fmt.Fprintf(&path, "[%s]", token)
+			continue
+		}
+		if path.Len() > 0 {
+			path.WriteString(".")
+		}
+		path.WriteString(token)
+	}
+	return path.String()
+}
+
+// configSchemaIssues flattens a validation error into one issue per failed check
+func configSchemaIssues(path string, root *yaml.Node, err *jsonschema.ValidationError) []ConfigIssue {
+	var issues []ConfigIssue
+	add := func(location []string, atKey bool, message string) {
+		key, value := configNodeAt(root, location)
+		node := value
+		if atKey && key != nil {
+			node = key
+		}
+		issues = append(issues, ConfigIssue{
+			Path:    path,
+			Line:    node.Line,
+			Column:  node.Column,
+			Field:   configFieldPath(location),
+			Message: message,
+		})
+	}
+
+	var walk func(e *jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		location := e.InstanceLocation
+		switch e.ErrorKind.(type) {
+		case *kind.Pattern, *kind.Enum:
+			// Values with variables are checked once they are expanded
+			if _, value := configNodeAt(root, location); strings.Contains(value.Value, "${") {
+				return
+			}
+		}
+		switch k := e.ErrorKind.(type) {
+		case *kind.AdditionalProperties:
+			message := "unknown key"
+			if keys := configSchemaKeys(location); len(keys) > 0 {
+				message += ", must be one of " + strings.Join(keys, ", ")
+			}
+			for _, property := range k.Properties {
+				add(append(slices.Clone(location), property), true, message)
+			}
+			return
+		case *kind.Required:
+			for _, property := range k.Missing {
+				add(location, false, fmt.Sprintf("%s is required", property))
+			}
+			return
+		case *kind.DependentRequired:
+			add(append(slices.Clone(location), k.Prop), true,
+				fmt.Sprintf("%s is required when %s is set", strings.Join(k.Missing, ", "), k.Prop))
+			return
+		case *kind.Enum:
+			add(location, false, fmt.Sprintf("must be one of %s", joinEnum(k.Want)))
+			return
+		case *kind.Type:
+			add(location, false, fmt.Sprintf("must be %s, got %s", strings.Join(k.Want, " or "), k.Got))
+			return
+		case *kind.MinLength:
+			add(location, false, "must not be empty")
+			return
+		case *kind.Minimum:
+			add(location, false, fmt.Sprintf("must be at least %v", k.Want))
+			return
+		case *kind.MinProperties:
+			// Only ignore rules require properties
+			add(location, false, "at least one of rule, path or message is required")
+			return
+		case *kind.Pattern:
+			message := fmt.Sprintf("%q does not match %s", k.Got, k.Want)
+			switch k.Want {
+			case toolVersionSchemaPattern:
+				message = fmt.Sprintf("invalid version %q: must be semantic version (e.g., 1.2.3) or 'latest'", k.Got)
+			case kubeMinorVersionPattern.String():
+				message = fmt.Sprintf("invalid Kubernetes version %q: must be a minor version like 1.31", k.Got)
+			}
+			add(location, false, message)
+			return
+		}
+		if len(e.Causes) > 0 {
+			for _, cause := range e.Causes {
+				walk(cause)
+			}
+			return
+		}
+		add(location, false, e.ErrorKind.LocalizedString(configSchemaPrinter))
+	}
+	walk(err)
+
+	return issues
+}
+
+// configSchemaKeys returns the keys the config schema declares for the object at a
+// JSON instance location, sorted
+func configSchemaKeys(location []string) []string {
+	schema := ConfigSchema()
+	for _, token := range location {
+		next, _ := schema["items"].(map[string]any)
+		if properties, ok := schema["properties"].(map[string]any); ok {
+			next, _ = properties[token].(map[string]any)
+		} else if additional, ok := schema["additionalProperties"].(map[string]any); ok {
+			next = additional
+		}
+		if next == nil {
+			return nil
+		}
+		schema = next
+	}
+
+	properties, _ := schema["properties"].(map[string]any)
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// joinEnum formats the allowed values of an enum for a message
+func joinEnum(values []any) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = fmt.Sprintf("%v", value)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// configGlobIssues reports the path patterns in the config that are not valid globs
+func configGlobIssues(path string, root *yaml.Node) []ConfigIssue {
+	var issues []ConfigIssue
+	check := func(pattern any, location ...string) {
+		s, ok := pattern.(string)
+		if !ok || strings.Contains(s, "${") || ValidateGlobPattern(s) == nil {
+			return
+		}
+		_, node := configNodeAt(root, location)
+		issues = append(issues, ConfigIssue{
+			Path:    path,
+			Line:    node.Line,
+			Column:  node.Column,
+			Field:   configFieldPath(location),
+			Message: fmt.Sprintf("invalid glob pattern %q", s),
+		})
+	}
+
+	value, _ := configValue(root).(map[string]any)
+	for i, chart := range asSlice(value["charts"]) {
+		chart, _ := chart.(map[string]any)
+		check(chart["path"], "charts", strconv.Itoa(i), "path")
+	}
+	for i, preflight := range asSlice(value["preflights"]) {
+		preflight, _ := preflight.(map[string]any)
+		check(preflight["path"], "preflights", strconv.Itoa(i), "path")
+	}
+	for i, include := range asSlice(value["include"]) {
+		check(include, "include", strconv.Itoa(i))
+	}
+	for i, manifest := range asSlice(value["manifests"]) {
+		check(manifest, "manifests", strconv.Itoa(i))
+	}
+	environments, _ := value["environments"].(map[string]any)
+	for name, environment := range environments {
+		environment, _ := environment.(map[string]any)
+		for i, chart := range asSlice(environment["charts"]) {
+			chart, _ := chart.(map[string]any)
+			check(chart["path"], "environments", name, "charts", strconv.Itoa(i), "path")
+		}
+	}
+	replLint, _ := value["repl-lint"].(map[string]any)
+	linters, _ := replLint["linters"].(map[string]any)
+	for name, linter := range linters {
+		linter, _ := linter.(map[string]any)
+		for i, rule := range asSlice(linter["ignore"]) {
+			rule, _ := rule.(map[string]any)
+			check(rule["path"], "repl-lint", "linters", name, "ignore", strconv.Itoa(i), "path")
+		}
+	}
+
+	return issues
+}
+
+// asSlice returns value as a slice, or nil if it is not one
+func asSlice(value any) []any {
+	s, _ := value.([]any)
+	return s
+}
+
+// sortConfigIssues orders issues by position, then by message
+func sortConfigIssues(issues []ConfigIssue) {
+	slices.SortStableFunc(issues, func(a, b ConfigIssue) int {
+		if a.Line != b.Line {
+			return a.Line - b.Line
+		}
+		if a.Column != b.Column {
+			return a.Column - b.Column
+		}
+		return strings.Compare(a.Message, b.Message)
+	})
+}
@@ -0,0 +1,179 @@
+package tools
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigSchemaJSON_MatchesPublishedSchema(t *testing.T) {
+	got, err := ConfigSchemaJSON()
+	if err != nil {
+		t.Fatalf("ConfigSchemaJSON() error = %v", err)
+	}
+	want, err := os.ReadFile(filepath.Join("..", "..", "docs", "replicated-config.schema.json"))
+	if err != nil {
+		t.Fatalf("reading published schema: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("docs/replicated-config.schema.json is out of date, regenerate it with: replicated config schema > docs/replicated-config.schema.json")
+	}
+}
+
+func TestConfigParser_ValidateConfigFile(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   []string // issue strings without the path prefix
+	}{
+		{
+			name: "valid config",
+			config: `appSlug: my-app
+charts:
+  - path: ./charts/*
+    chartVersion: 1.0.0
+repl-lint:
+  version: 1
+  linters:
+    helm:
+      disabled: false
+      ignore:
+        - path: "charts/**/*.yaml"
+  tools:
+    helm: 3.14.4
+`,
+		},
+		{
+			name: "variables are checked once expanded",
+			config: `releaseLabel: ${RELEASE_LABEL:-dev}
+repl-lint:
+  tools:
+    helm: ${HELM_VERSION:-3.14.4}
+  linters:
+    helm:
+      disabled: ${HELM_DISABLED:-false}
+`,
+		},
+		{
+			name:   "empty file",
+			config: "",
+		},
+		{
+			name: "unknown keys",
+			config: `appSlug: my-app
+chart:
+  - path: ./chart
+repl-lint:
+  linters:
+    helm:
+      disable: true
+`,
+			want: []string{
+				"2:1: chart: unknown key, must be one of appId, appSlug, charts, environments, include, manifests, preflights, promoteToChannelIds, promoteToChannelNames, releaseLabel, repl-lint",
+				"7:7: repl-lint.linters.helm.disable: unknown key, must be one of disabled, ignore, strict",
+			},
+		},
+		{
+			name: "type errors",
+			config: `appSlug: [my-app]
+repl-lint:
+  linters:
+    helm:
+      disabled: "yes"
+`,
+			want: []string{
+				"1:10: appSlug: must be string, got array",
+				"5:17: repl-lint.linters.helm.disabled: must be boolean, got string",
+			},
+		},
+		{
+			name: "missing chart path",
+			config: `charts:
+  - chartVersion: 1.0.0
+`,
+			want: []string{"2:5: charts[0]: path is required"},
+		},
+		{
+			name: "bad globs",
+			config: `charts:
+  - path: "./charts/[abc"
+manifests:
+  - "./manifests/{a,b"
+`,
+			want: []string{
+				`2:11: charts[0].path: invalid glob pattern "./charts/[abc"`,
+				`4:5: manifests[0]: invalid glob pattern "./manifests/{a,b"`,
+			},
+		},
+		{
+			name: "bad tool version",
+			config: `repl-lint:
+  tools:
+    helm: three
+`,
+			want: []string{`3:11: repl-lint.tools.helm: invalid version "three": must be semantic version (e.g., 1.2.3) or 'latest'`},
+		},
+		{
+			name:   "syntax error",
+			config: "appSlug: my-app\n  charts: [\n",
+			want:   []string{"2:1: mapping values are not allowed in this context"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".replicated")
+			if err := os.WriteFile(path, []byte(tt.config), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			issues, err := NewConfigParser().ValidateConfigFile(path)
+			if err != nil {
+				t.Fatalf("ValidateConfigFile() error = %v", err)
+			}
+
+			var got []string
+			for _, issue := range issues {
+				got = append(got, strings.TrimPrefix(issue.String(), path+":"))
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("issues =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestConfigParser_FindConfigFiles(t *testing.T) {
+	root := t.TempDir()
+	child := filepath.Join(root, "app")
+	if err := os.MkdirAll(child, 0755); err != nil {
+		t.Fatal(err)
+	}
+	parentConfig := filepath.Join(root, ".replicated")
+	childConfig := filepath.Join(child, ".replicated.yaml")
+	for _, path := range []string{parentConfig, childConfig} {
+		if err := os.WriteFile(path, []byte("appSlug: my-app\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	parser := NewConfigParser()
+
+	paths, err := parser.FindConfigFiles(child)
+	if err != nil {
+		t.Fatalf("FindConfigFiles() error = %v", err)
+	}
+	if len(paths) < 2 || paths[len(paths)-2] != parentConfig || paths[len(paths)-1] != childConfig {
+		t.Errorf("FindConfigFiles() = %v, want to end with [%s %s]", paths, parentConfig, childConfig)
+	}
+
+	paths, err = parser.FindConfigFiles(childConfig)
+	if err != nil {
+		t.Fatalf("FindConfigFiles() error = %v", err)
+	}
+	if len(paths) != 1 || paths[0] != childConfig {
+		t.Errorf("FindConfigFiles(file) = %v, want [%s]", paths, childConfig)
+	}
+}
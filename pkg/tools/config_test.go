@@ -3,6 +3,7 @@ package tools
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -625,6 +626,37 @@ repl-lint:
 		}
 	})
 
+	t.Run("relative chart values paths resolved to absolute", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		configPath := filepath.Join(tmpDir, ".replicated")
+
+		configData := []byte(`charts:
+  - path: ./charts/app
+    values:
+      - ./ci/values-ha.yaml
+      - /abs/values-airgap.yaml
+repl-lint:
+`)
+		if err := os.WriteFile(configPath, configData, 0644); err != nil {
+			t.Fatalf("writing test config: %v", err)
+		}
+
+		config, err := parser.ParseConfigFile(configPath)
+		if err != nil {
+			t.Fatalf("ParseConfigFile() error = %v", err)
+		}
+
+		want := []string{filepath.Join(tmpDir, "ci/values-ha.yaml"), "/abs/values-airgap.yaml"}
+		if len(config.Charts) != 1 || len(config.Charts[0].Values) != len(want) {
+			t.Fatalf("unexpected charts %+v", config.Charts)
+		}
+		for i, values := range config.Charts[0].Values {
+			if values != want[i] {
+				t.Errorf("charts[0].values[%d] = %q, want %q", i, values, want[i])
+			}
+		}
+	})
+
 	t.Run("relative preflight paths resolved to absolute", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		configPath := filepath.Join(tmpDir, ".replicated")
@@ -812,6 +844,58 @@ repl-lint:
 			t.Errorf("expected 0 charts, got %d", len(config.Charts))
 		}
 	})
+
+	t.Run("relative lint baseline path resolved to absolute", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		configPath := filepath.Join(tmpDir, ".replicated")
+
+		configData := []byte(`repl-lint:
+  baseline: .replicated-lint-baseline.json
+`)
+		if err := os.WriteFile(configPath, configData, 0644); err != nil {
+			t.Fatalf("writing test config: %v", err)
+		}
+
+		config, err := parser.ParseConfigFile(configPath)
+		if err != nil {
+			t.Fatalf("ParseConfigFile() error = %v", err)
+		}
+
+		expectedPath := filepath.Join(tmpDir, ".replicated-lint-baseline.json")
+		if config.ReplLint.Baseline != expectedPath {
+			t.Errorf("ReplLint.Baseline = %q, want %q", config.ReplLint.Baseline, expectedPath)
+		}
+	})
+
+	t.Run("relative policies path resolved to absolute and opts in", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		configPath := filepath.Join(tmpDir, ".replicated")
+
+		configData := []byte(`repl-lint:
+  policies: ./policies
+`)
+		if err := os.WriteFile(configPath, configData, 0644); err != nil {
+			t.Fatalf("writing test config: %v", err)
+		}
+
+		config, err := parser.ParseConfigFile(configPath)
+		if err != nil {
+			t.Fatalf("ParseConfigFile() error = %v", err)
+		}
+
+		expectedPath := filepath.Join(tmpDir, "policies")
+		if config.ReplLint.Policies != expectedPath {
+			t.Errorf("ReplLint.Policies = %q, want %q", config.ReplLint.Policies, expectedPath)
+		}
+
+		parser.ApplyDefaults(config)
+		if !config.ReplLint.Linters.Policy.IsEnabled() {
+			t.Error("Policy should be enabled when a policies directory is set")
+		}
+		if parser.DefaultConfig().ReplLint.Linters.Policy.IsEnabled() {
+			t.Error("Policy should be disabled by default")
+		}
+	})
 }
 
 func TestConfigParser_MonorepoEndToEnd(t *testing.T) {
@@ -1470,6 +1554,15 @@ func TestApplyDefaults_LinterDefaults(t *testing.T) {
 		if config.ReplLint.Linters.EmbeddedCluster.IsEnabled() {
 			t.Error("EmbeddedCluster should be disabled by default (opt-in)")
 		}
+		if config.ReplLint.Linters.KubeSchema.IsEnabled() {
+			t.Error("KubeSchema should be disabled by default (opt-in)")
+		}
+		if config.ReplLint.Linters.ReleaseGraph.IsEnabled() {
+			t.Error("ReleaseGraph should be disabled by default (opt-in)")
+		}
+		if config.ReplLint.Linters.Image.IsEnabled() {
+			t.Error("Image should be disabled by default (opt-in)")
+		}
 	})
 
 	t.Run("ApplyDefaults fills linter defaults on existing repl-lint with no linters set", func(t *testing.T) {
@@ -1521,7 +1614,7 @@ func TestMergeECLinterConfig(t *testing.T) {
 	t.Run("child Disabled overrides parent", func(t *testing.T) {
 		parent := ECLinterConfig{LinterConfig: LinterConfig{Disabled: boolPtr(true)}}
 		child := ECLinterConfig{LinterConfig: LinterConfig{Disabled: boolPtr(false)}}
-		result := mergeECLinterConfig(parent, child)
+		result := mergeECLinterConfig(parent, child, sourceRecorder{})
 		if result.IsEnabled() != true {
 			t.Error("child disabled:false should override parent disabled:true")
 		}
@@ -1530,7 +1623,7 @@ func TestMergeECLinterConfig(t *testing.T) {
 	t.Run("nil child Disabled preserves parent", func(t *testing.T) {
 		parent := ECLinterConfig{LinterConfig: LinterConfig{Disabled: boolPtr(true)}}
 		child := ECLinterConfig{}
-		result := mergeECLinterConfig(parent, child)
+		result := mergeECLinterConfig(parent, child, sourceRecorder{})
 		if result.IsEnabled() != false {
 			t.Error("nil child Disabled should not override parent disabled:true")
 		}
@@ -1539,7 +1632,7 @@ func TestMergeECLinterConfig(t *testing.T) {
 	t.Run("child DisableChecks overrides parent", func(t *testing.T) {
 		parent := ECLinterConfig{DisableChecks: []string{"check-a"}}
 		child := ECLinterConfig{DisableChecks: []string{"check-b", "check-c"}}
-		result := mergeECLinterConfig(parent, child)
+		result := mergeECLinterConfig(parent, child, sourceRecorder{})
 		if len(result.DisableChecks) != 2 || result.DisableChecks[0] != "check-b" {
 			t.Errorf("DisableChecks = %v, want [check-b check-c]", result.DisableChecks)
 		}
@@ -1548,7 +1641,7 @@ func TestMergeECLinterConfig(t *testing.T) {
 	t.Run("empty child DisableChecks preserves parent", func(t *testing.T) {
 		parent := ECLinterConfig{DisableChecks: []string{"check-a"}}
 		child := ECLinterConfig{}
-		result := mergeECLinterConfig(parent, child)
+		result := mergeECLinterConfig(parent, child, sourceRecorder{})
 		if len(result.DisableChecks) != 1 || result.DisableChecks[0] != "check-a" {
 			t.Errorf("DisableChecks = %v, want [check-a]", result.DisableChecks)
 		}
@@ -1557,7 +1650,7 @@ func TestMergeECLinterConfig(t *testing.T) {
 	t.Run("child BinaryPath overrides parent", func(t *testing.T) {
 		parent := ECLinterConfig{BinaryPath: "/old/path"}
 		child := ECLinterConfig{BinaryPath: "/new/path"}
-		result := mergeECLinterConfig(parent, child)
+		result := mergeECLinterConfig(parent, child, sourceRecorder{})
 		if result.BinaryPath != "/new/path" {
 			t.Errorf("BinaryPath = %q, want /new/path", result.BinaryPath)
 		}
@@ -1566,7 +1659,7 @@ func TestMergeECLinterConfig(t *testing.T) {
 	t.Run("empty child BinaryPath preserves parent", func(t *testing.T) {
 		parent := ECLinterConfig{BinaryPath: "/old/path"}
 		child := ECLinterConfig{}
-		result := mergeECLinterConfig(parent, child)
+		result := mergeECLinterConfig(parent, child, sourceRecorder{})
 		if result.BinaryPath != "/old/path" {
 			t.Errorf("BinaryPath = %q, want /old/path", result.BinaryPath)
 		}
@@ -1593,3 +1686,252 @@ repl-lint:
 		t.Errorf("ParseConfigFile() unexpected error for preflight without chart reference: %v", err)
 	}
 }
+
+func TestMergeLinterConfig_IgnoreRulesAccumulate(t *testing.T) {
+	parent := LinterConfig{Ignore: []IgnoreRule{{Rule: "application-title"}}}
+	child := LinterConfig{Ignore: []IgnoreRule{{Path: "charts/legacy/**"}}}
+
+	result := mergeLinterConfig(parent, child, sourceRecorder{})
+	if len(result.Ignore) != 2 || result.Ignore[0].Rule != "application-title" || result.Ignore[1].Path != "charts/legacy/**" {
+		t.Errorf("Ignore = %+v, want parent rules followed by child rules", result.Ignore)
+	}
+	if len(parent.Ignore) != 1 {
+		t.Errorf("merge modified parent ignore rules: %+v", parent.Ignore)
+	}
+}
+
+func TestParseConfig_IgnoreRules(t *testing.T) {
+	parser := NewConfigParser()
+
+	t.Run("relative ignore paths resolved against config dir", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		configPath := filepath.Join(tmpDir, ".replicated")
+		configData := []byte(`repl-lint:
+  linters:
+    kots:
+      ignore:
+        - rule: application-title
+          path: manifests/*.yaml
+    embedded-cluster:
+      ignore:
+        - message: "deprecated"
+`)
+		if err := os.WriteFile(configPath, configData, 0644); err != nil {
+			t.Fatalf("writing test config: %v", err)
+		}
+
+		config, err := parser.ParseConfigFile(configPath)
+		if err != nil {
+			t.Fatalf("ParseConfigFile() error = %v", err)
+		}
+
+		kotsIgnore := config.ReplLint.Linters.Kots.Ignore
+		if len(kotsIgnore) != 1 || kotsIgnore[0].Path != filepath.Join(tmpDir, "manifests/*.yaml") {
+			t.Errorf("Kots.Ignore = %+v, want path resolved against %s", kotsIgnore, tmpDir)
+		}
+		ecIgnore := config.ReplLint.Linters.EmbeddedCluster.Ignore
+		if len(ecIgnore) != 1 || ecIgnore[0].Message != "deprecated" {
+			t.Errorf("EmbeddedCluster.Ignore = %+v, want message rule", ecIgnore)
+		}
+	})
+
+	invalid := []struct {
+		name   string
+		ignore string
+	}{
+		{"empty rule", "        - {}\n"},
+		{"invalid message regex", "        - message: \"([\"\n"},
+		{"invalid path glob", "        - path: \"charts/[\"\n"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			configPath := filepath.Join(tmpDir, ".replicated")
+			configData := []byte("repl-lint:\n  linters:\n    helm:\n      ignore:\n" + tt.ignore)
+			if err := os.WriteFile(configPath, configData, 0644); err != nil {
+				t.Fatalf("writing test config: %v", err)
+			}
+
+			if _, err := parser.ParseConfigFile(configPath); err == nil {
+				t.Error("ParseConfigFile() expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestMergeLinterConfig_Strict(t *testing.T) {
+	boolPtr := func(b bool) *bool { return &b }
+
+	parent := LinterConfig{Strict: boolPtr(true)}
+	if result := mergeLinterConfig(parent, LinterConfig{}, sourceRecorder{}); !result.IsStrict() {
+		t.Error("nil child Strict should preserve parent strict:true")
+	}
+	if result := mergeLinterConfig(parent, LinterConfig{Strict: boolPtr(false)}, sourceRecorder{}); result.IsStrict() {
+		t.Error("child strict:false should override parent strict:true")
+	}
+	if (LinterConfig{}).IsStrict() {
+		t.Error("nil Strict should not be strict")
+	}
+}
+
+func TestParseConfig_KubeSchema(t *testing.T) {
+	parser := NewConfigParser()
+
+	config, err := parser.ParseConfig([]byte("repl-lint:\n  linters:\n    kube-schema:\n      versions: [1.29, 1.30]\n"))
+	if err != nil {
+		t.Fatalf("ParseConfig() error = %v", err)
+	}
+	// Unquoted versions keep their trailing zeros
+	if got := config.ReplLint.Linters.KubeSchema.Versions; len(got) != 2 || got[0] != "1.29" || got[1] != "1.30" {
+		t.Errorf("Versions = %v, want [1.29 1.30]", got)
+	}
+
+	// Listing versions opts in unless the linter is explicitly disabled
+	parser.ApplyDefaults(config)
+	if !config.ReplLint.Linters.KubeSchema.IsEnabled() {
+		t.Error("KubeSchema should be enabled when versions are set")
+	}
+	config, err = parser.ParseConfig([]byte("repl-lint:\n  linters:\n    kube-schema:\n      disabled: true\n      versions: [\"1.31\"]\n"))
+	if err != nil {
+		t.Fatalf("ParseConfig() error = %v", err)
+	}
+	parser.ApplyDefaults(config)
+	if config.ReplLint.Linters.KubeSchema.IsEnabled() {
+		t.Error("KubeSchema should stay disabled (user set disabled: true)")
+	}
+
+	for _, version := range []string{"1.31.2", "v1.31", "2.0", "latest", ""} {
+		data := []byte("repl-lint:\n  linters:\n    kube-schema:\n      versions: [\"" + version + "\"]\n")
+		if _, err := parser.ParseConfig(data); err == nil {
+			t.Errorf("ParseConfig() with version %q expected error, got nil", version)
+		}
+	}
+}
+
+func TestMergeKubeSchemaLinterConfig(t *testing.T) {
+	parent := KubeSchemaLinterConfig{Versions: []string{"1.29"}}
+
+	if result := mergeKubeSchemaLinterConfig(parent, KubeSchemaLinterConfig{}, sourceRecorder{}); len(result.Versions) != 1 || result.Versions[0] != "1.29" {
+		t.Errorf("empty child Versions should preserve parent, got %v", result.Versions)
+	}
+	child := KubeSchemaLinterConfig{Versions: []string{"1.30", "1.31"}}
+	if result := mergeKubeSchemaLinterConfig(parent, child, sourceRecorder{}); len(result.Versions) != 2 || result.Versions[0] != "1.30" {
+		t.Errorf("child Versions should override parent, got %v", result.Versions)
+	}
+
+	if !parent.IsDownloadEnabled() {
+		t.Error("unset Download should default to downloading")
+	}
+	parent.Download = boolPtr(true)
+	if result := mergeKubeSchemaLinterConfig(parent, KubeSchemaLinterConfig{}, sourceRecorder{}); !result.IsDownloadEnabled() {
+		t.Error("unset child Download should preserve parent")
+	}
+	if result := mergeKubeSchemaLinterConfig(parent, KubeSchemaLinterConfig{Download: boolPtr(false)}, sourceRecorder{}); result.IsDownloadEnabled() {
+		t.Error("child Download should override parent")
+	}
+}
+
+func TestParseConfig_Image(t *testing.T) {
+	parser := NewConfigParser()
+
+	config, err := parser.ParseConfig([]byte(`repl-lint:
+  linters:
+    image:
+      disabled: false
+      rules:
+        latest-tag: error
+        require-digest: warn
+      allowed-registries: [registry.example.com]
+      proxy-hostname: proxy.example.com
+`))
+	if err != nil {
+		t.Fatalf("ParseConfig() error = %v", err)
+	}
+	image := config.ReplLint.Linters.Image
+	if !image.IsEnabled() || image.Rules["latest-tag"] != "error" || image.Rules["require-digest"] != "warn" {
+		t.Errorf("Image = %+v", image)
+	}
+	if len(image.AllowedRegistries) != 1 || image.ProxyHostname != "proxy.example.com" {
+		t.Errorf("Image = %+v", image)
+	}
+
+	for _, data := range []string{
+		"rules:\n        latest: error\n",
+		"rules:\n        latest-tag: fatal\n",
+		"proxy-hostname: https://proxy.example.com\n",
+	} {
+		if _, err := parser.ParseConfig([]byte("repl-lint:\n  linters:\n    image:\n      " + data)); err == nil {
+			t.Errorf("ParseConfig() with %q expected error, got nil", data)
+		}
+	}
+}
+
+func TestMergeImageLinterConfig(t *testing.T) {
+	parent := ImageLinterConfig{
+		Rules:             map[string]string{"latest-tag": "error", "no-tag": "off"},
+		AllowedRegistries: []string{"registry.example.com"},
+		ProxyHostname:     "proxy.example.com",
+	}
+
+	result := mergeImageLinterConfig(parent, ImageLinterConfig{Rules: map[string]string{"no-tag": "warn"}}, sourceRecorder{})
+	if result.Rules["latest-tag"] != "error" || result.Rules["no-tag"] != "warn" {
+		t.Errorf("child rules should be merged over parent rules, got %v", result.Rules)
+	}
+	if parent.Rules["no-tag"] != "off" {
+		t.Error("merging should not modify the parent rules")
+	}
+	if len(result.AllowedRegistries) != 1 || result.ProxyHostname != "proxy.example.com" {
+		t.Errorf("unset child settings should preserve parent, got %+v", result)
+	}
+}
+
+func TestParseConfig_ToolsMirror(t *testing.T) {
+	parser := NewConfigParser()
+
+	config, err := parser.ParseConfig([]byte(`repl-lint:
+  tools-mirror:
+    helm: https://artifactory.example.com/helm/
+    kube-schema: https://artifactory.example.com/kubernetes
+`))
+	if err != nil {
+		t.Fatalf("ParseConfig() error = %v", err)
+	}
+	if got := config.ReplLint.ToolsMirror[ToolHelm]; got != "https://artifactory.example.com/helm/" {
+		t.Errorf("ToolsMirror[helm] = %q", got)
+	}
+
+	for _, data := range []string{
+		"repl-lint:\n  tools-mirror:\n    kubectl: https://artifactory.example.com\n",
+		"repl-lint:\n  tools-mirror:\n    helm: artifactory.example.com/helm\n",
+		"repl-lint:\n  tools-mirror:\n    helm: ftp://artifactory.example.com/helm\n",
+		"repl-lint:\n  tools-mirror:\n    helm: https://artifactory.example.com/helm?token=x\n",
+	} {
+		if _, err := parser.ParseConfig([]byte(data)); err == nil {
+			t.Errorf("ParseConfig(%q) expected error, got nil", data)
+		}
+	}
+}
+
+func TestFindAndParseConfig_MergesToolsMirror(t *testing.T) {
+	root := t.TempDir()
+	child := filepath.Join(root, "app")
+	if err := os.MkdirAll(child, 0755); err != nil {
+		t.Fatal(err)
+	}
+	parent := "repl-lint:\n  tools-mirror:\n    helm: https://parent.example.com/helm\n    preflight: https://parent.example.com/gh\n"
+	if err := os.WriteFile(filepath.Join(root, ".replicated"), []byte(parent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(child, ".replicated"), []byte("repl-lint:\n  tools-mirror:\n    helm: https://child.example.com/helm\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := NewConfigParser().FindAndParseConfig(child)
+	if err != nil {
+		t.Fatalf("FindAndParseConfig() error = %v", err)
+	}
+	want := map[string]string{ToolHelm: "https://child.example.com/helm", ToolPreflight: "https://parent.example.com/gh"}
+	if !reflect.DeepEqual(config.ReplLint.ToolsMirror, want) {
+		t.Errorf("ToolsMirror = %v, want %v", config.ReplLint.ToolsMirror, want)
+	}
+}
@@ -10,9 +10,6 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"os"
-	"path/filepath"
-	"runtime"
 	"strings"
 	"time"
 )
@@ -31,15 +28,23 @@ const (
 // Downloader handles downloading tool binaries
 type Downloader struct {
 	httpClient *http.Client
+	mirrors    map[string]string // tool name to mirror base URL (see WithDownloadMirrors)
 }
 
+// DownloaderOption configures a Downloader
+type DownloaderOption func(*Downloader)
+
 // NewDownloader creates a new downloader with timeout
-func NewDownloader() *Downloader {
-	return &Downloader{
+func NewDownloader(opts ...DownloaderOption) *Downloader {
+	d := &Downloader{
 		httpClient: &http.Client{
 			Timeout: downloadTimeout,
 		},
 	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
 }
 
 // Download downloads a tool to the cache directory with checksum verification
@@ -63,36 +68,54 @@ func (d *Downloader) downloadExact(ctx context.Context, name, version string) er
 		return err
 	}
 
+	binaryData, err := d.fetchBinary(ctx, name, version, CurrentPlatform())
+	if err != nil {
+		return err
+	}
+
+	// Write binary only after successful download, verification, and extraction.
+	// It is written to a temp file and renamed, so other runs never see a partial binary.
+	if err := writeCacheFile(cachePath, bytes.NewReader(binaryData), 0755, ""); err != nil {
+		return fmt.Errorf("writing binary: %w", err)
+	}
+
+	return nil
+}
+
+// fetchBinary downloads the archive of a tool version for a platform, verifies its
+// checksum and returns the extracted binary
+func (d *Downloader) fetchBinary(ctx context.Context, name, version string, platform Platform) ([]byte, error) {
 	// Download binary and get checksum info
 	var archiveData []byte
 	var checksumURL, checksumFilename string
+	var err error
 
 	switch name {
 	case ToolHelm:
-		archiveData, checksumURL, err = d.downloadHelmArchive(version)
+		archiveData, checksumURL, err = d.downloadHelmArchive(version, platform)
 	case ToolPreflight:
-		archiveData, checksumURL, checksumFilename, err = d.downloadPreflightArchive(version)
+		archiveData, checksumURL, checksumFilename, err = d.downloadPreflightArchive(version, platform)
 	case ToolSupportBundle:
-		archiveData, checksumURL, checksumFilename, err = d.downloadSupportBundleArchive(version)
+		archiveData, checksumURL, checksumFilename, err = d.downloadSupportBundleArchive(version, platform)
 	case ToolEmbeddedCluster:
-		archiveData, err = d.downloadECArchive(version)
+		archiveData, err = d.downloadECArchive(version, platform)
 	default:
-		return fmt.Errorf("unknown tool: %s", name)
+		return nil, fmt.Errorf("unknown tool: %s", name)
 	}
 
 	if err != nil {
-		return fmt.Errorf("downloading: %w", err)
+		return nil, fmt.Errorf("downloading: %w", err)
 	}
 
 	// Verify checksum
 	switch name {
 	case ToolHelm:
 		if err := VerifyHelmChecksum(archiveData, checksumURL); err != nil {
-			return fmt.Errorf("checksum verification failed: %w", err)
+			return nil, fmt.Errorf("checksum verification failed: %w", err)
 		}
 	case ToolPreflight, ToolSupportBundle:
-		if err := VerifyTroubleshootChecksum(archiveData, version, checksumFilename); err != nil {
-			return fmt.Errorf("checksum verification failed: %w", err)
+		if err := VerifyTroubleshootChecksum(archiveData, checksumURL, checksumFilename); err != nil {
+			return nil, fmt.Errorf("checksum verification failed: %w", err)
 		}
 	case ToolEmbeddedCluster:
 		// TODO: add checksum verification once the EC release process publishes
@@ -101,56 +124,42 @@ func (d *Downloader) downloadExact(ctx context.Context, name, version string) er
 
 	// Extract binary from archive
 	var binaryData []byte
-	binaryName := name
-	if runtime.GOOS == "windows" {
-		binaryName = name + ".exe"
-	}
+	binaryName := platform.BinaryName(name)
 
 	switch name {
 	case ToolHelm:
-		if runtime.GOOS == "windows" {
-			binaryData, err = extractFromZip(archiveData, "windows-"+runtime.GOARCH+"/helm.exe")
+		if platform.OS == "windows" {
+			binaryData, err = extractFromZip(archiveData, "windows-"+platform.Arch+"/helm.exe")
 		} else {
-			binaryData, err = extractFromTarGz(archiveData, runtime.GOOS+"-"+runtime.GOARCH+"/helm")
+			binaryData, err = extractFromTarGz(archiveData, platform.OS+"-"+platform.Arch+"/helm")
 		}
 	case ToolPreflight, ToolSupportBundle:
 		// Windows troubleshoot uses .zip, others use .tar.gz
-		if runtime.GOOS == "windows" {
+		if platform.OS == "windows" {
 			binaryData, err = extractFromZip(archiveData, binaryName)
 		} else {
 			binaryData, err = extractFromTarGz(archiveData, binaryName)
 		}
 	case ToolEmbeddedCluster:
 		var ecBinaryName string
-		switch runtime.GOOS {
+		switch platform.OS {
 		case "darwin":
 			ecBinaryName = "cli-darwin-all"
 			binaryData, err = extractFromTarGz(archiveData, ecBinaryName)
 		case "windows":
-			ecBinaryName = fmt.Sprintf("cli-windows-%s.exe", runtime.GOARCH)
+			ecBinaryName = fmt.Sprintf("cli-windows-%s.exe", platform.Arch)
 			binaryData, err = extractFromZip(archiveData, ecBinaryName)
 		default:
-			ecBinaryName = fmt.Sprintf("cli-%s-%s", runtime.GOOS, runtime.GOARCH)
+			ecBinaryName = fmt.Sprintf("cli-%s-%s", platform.OS, platform.Arch)
 			binaryData, err = extractFromTarGz(archiveData, ecBinaryName)
 		}
 	}
 
 	if err != nil {
-		return fmt.Errorf("extracting binary: %w", err)
-	}
-
-	// Create directory only after successful download, verification, and extraction
-	dir := filepath.Dir(cachePath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("creating cache directory: %w", err)
+		return nil, fmt.Errorf("extracting binary: %w", err)
 	}
 
-	// Write binary
-	if err := os.WriteFile(cachePath, binaryData, 0755); err != nil {
-		return fmt.Errorf("writing binary: %w", err)
-	}
-
-	return nil
+	return binaryData, nil
 }
 
 // replicatedPingResponse represents the response from replicated.app/ping
@@ -277,15 +286,16 @@ func (d *Downloader) downloadWithRetry(url string) ([]byte, error) {
 }
 
 // downloadHelmArchive downloads the helm archive and returns archive data + checksum URL
-func (d *Downloader) downloadHelmArchive(version string) ([]byte, string, error) {
-	platformOS := runtime.GOOS
-	platformArch := runtime.GOARCH
+func (d *Downloader) downloadHelmArchive(version string, platform Platform) ([]byte, string, error) {
+	platformOS := platform.OS
+	platformArch := platform.Arch
+	baseURL := d.baseURL(ToolHelm, helmDownloadURL)
 
 	var url string
 	if platformOS == "windows" {
-		url = fmt.Sprintf("https://get.helm.sh/helm-v%s-windows-%s.zip", version, platformArch)
+		url = fmt.Sprintf("%s/helm-v%s-windows-%s.zip", baseURL, version, platformArch)
 	} else {
-		url = fmt.Sprintf("https://get.helm.sh/helm-v%s-%s-%s.tar.gz", version, platformOS, platformArch)
+		url = fmt.Sprintf("%s/helm-v%s-%s-%s.tar.gz", baseURL, version, platformOS, platformArch)
 	}
 
 	// Download archive with retry
@@ -299,9 +309,10 @@ func (d *Downloader) downloadHelmArchive(version string) ([]byte, string, error)
 }
 
 // downloadPreflightArchive downloads the preflight archive
-func (d *Downloader) downloadPreflightArchive(version string) ([]byte, string, string, error) {
-	platformOS := runtime.GOOS
-	platformArch := runtime.GOARCH
+func (d *Downloader) downloadPreflightArchive(version string, platform Platform) ([]byte, string, string, error) {
+	platformOS := platform.OS
+	platformArch := platform.Arch
+	baseURL := d.baseURL(ToolPreflight, troubleshootDownloadURL)
 
 	// Troubleshoot uses different naming
 	// Windows uses .zip, others use .tar.gz
@@ -314,7 +325,7 @@ func (d *Downloader) downloadPreflightArchive(version string) ([]byte, string, s
 		filename = fmt.Sprintf("preflight_%s_%s.tar.gz", platformOS, platformArch)
 	}
 
-	url := fmt.Sprintf("https://github.com/replicatedhq/troubleshoot/releases/download/v%s/%s", version, filename)
+	url := fmt.Sprintf("%s/v%s/%s", baseURL, version, filename)
 
 	// Download archive with retry
 	data, err := d.downloadWithRetry(url)
@@ -323,15 +334,16 @@ func (d *Downloader) downloadPreflightArchive(version string) ([]byte, string, s
 	}
 
 	// For troubleshoot, we need the checksums.txt URL and the filename to look up
-	checksumURL := fmt.Sprintf("https://github.com/replicatedhq/troubleshoot/releases/download/v%s/troubleshoot_%s_checksums.txt", version, version)
+	checksumURL := fmt.Sprintf("%s/v%s/troubleshoot_%s_checksums.txt", baseURL, version, version)
 
 	return data, checksumURL, filename, nil
 }
 
 // downloadSupportBundleArchive downloads the support-bundle archive
-func (d *Downloader) downloadSupportBundleArchive(version string) ([]byte, string, string, error) {
-	platformOS := runtime.GOOS
-	platformArch := runtime.GOARCH
+func (d *Downloader) downloadSupportBundleArchive(version string, platform Platform) ([]byte, string, string, error) {
+	platformOS := platform.OS
+	platformArch := platform.Arch
+	baseURL := d.baseURL(ToolSupportBundle, troubleshootDownloadURL)
 
 	// Troubleshoot uses different naming
 	// Windows uses .zip, others use .tar.gz
@@ -344,7 +356,7 @@ func (d *Downloader) downloadSupportBundleArchive(version string) ([]byte, strin
 		filename = fmt.Sprintf("support-bundle_%s_%s.tar.gz", platformOS, platformArch)
 	}
 
-	url := fmt.Sprintf("https://github.com/replicatedhq/troubleshoot/releases/download/v%s/%s", version, filename)
+	url := fmt.Sprintf("%s/v%s/%s", baseURL, version, filename)
 
 	// Download archive with retry
 	data, err := d.downloadWithRetry(url)
@@ -353,17 +365,17 @@ func (d *Downloader) downloadSupportBundleArchive(version string) ([]byte, strin
 	}
 
 	// For troubleshoot, we need the checksums.txt URL and the filename to look up
-	checksumURL := fmt.Sprintf("https://github.com/replicatedhq/troubleshoot/releases/download/v%s/troubleshoot_%s_checksums.txt", version, version)
+	checksumURL := fmt.Sprintf("%s/v%s/troubleshoot_%s_checksums.txt", baseURL, version, version)
 
 	return data, checksumURL, filename, nil
 }
 
-// downloadECArchive downloads the embedded-cluster CLI archive from S3.
+// downloadECArchive downloads the embedded-cluster CLI archive from S3 or its mirror.
 // URL pattern: https://tf-embedded-cluster-binaries.s3.us-east-1.amazonaws.com/releases/{version}-{os}.tgz
-func (d *Downloader) downloadECArchive(version string) ([]byte, error) {
-	archiveName := ecArchiveName(version)
+func (d *Downloader) downloadECArchive(version string, platform Platform) ([]byte, error) {
+	archiveName := ecArchiveName(version, platform)
 
-	url := fmt.Sprintf("https://tf-embedded-cluster-binaries.s3.us-east-1.amazonaws.com/releases/%s", archiveName)
+	url := fmt.Sprintf("%s/%s", d.baseURL(ToolEmbeddedCluster, ecDownloadURL), archiveName)
 
 	data, err := d.downloadWithRetry(url)
 	if err != nil {
@@ -373,14 +385,14 @@ func (d *Downloader) downloadECArchive(version string) ([]byte, error) {
 	return data, nil
 }
 
-func ecArchiveName(version string) string {
-	switch runtime.GOOS {
+func ecArchiveName(version string, platform Platform) string {
+	switch platform.OS {
 	case "darwin":
 		return fmt.Sprintf("%s-darwin-all.tgz", version)
 	case "windows":
-		return fmt.Sprintf("%s-windows-%s.zip", version, runtime.GOARCH)
+		return fmt.Sprintf("%s-windows-%s.zip", version, platform.Arch)
 	default:
-		return fmt.Sprintf("%s-%s-%s.tgz", version, runtime.GOOS, runtime.GOARCH)
+		return fmt.Sprintf("%s-%s-%s.tgz", version, platform.OS, platform.Arch)
 	}
 }
 
@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// GitSHABranch returns the short SHA of HEAD, the current branch and whether the
+// worktree has changes, for the git repository containing path. On GitHub Actions
+// the branch is read from GITHUB_BRANCH_NAME.
+func GitSHABranch(path string) (sha string, branch string, dirty bool, err error) {
+	rev := "HEAD"
+	repository, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return "", "", false, fmt.Errorf("git open '%q' failed: %w", path, err)
+	}
+	h, err := repository.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return "", "", false, fmt.Errorf("git resolve revision '%q' failed: %w", rev, err)
+	}
+	head, err := repository.Head()
+	if err != nil {
+		return "", "", false, fmt.Errorf("git resolve HEAD failed: %w", err)
+	}
+
+	worktree, err := repository.Worktree()
+	if err != nil {
+		return "", "", false, fmt.Errorf("git get worktree failed: %w", err)
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return "", "", false, fmt.Errorf("git get status failed: %w", err)
+	}
+
+	branchName := head.Name().Short()
+
+	// for GH Actions, prefer env branch
+	envBranch := os.Getenv("GITHUB_BRANCH_NAME")
+	if envBranch != "" {
+		branchName = envBranch
+	}
+
+	return h.String()[0:7], branchName, !status.IsClean(), nil
+}
+
+// GitTag returns the tag pointing at HEAD in the git repository containing path. If
+// several tags do, the first in lexical order is returned. It returns "" when HEAD
+// is not tagged.
+func GitTag(path string) (string, error) {
+	repository, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return "", fmt.Errorf("git open '%q' failed: %w", path, err)
+	}
+	head, err := repository.Head()
+	if err != nil {
+		return "", fmt.Errorf("git resolve HEAD failed: %w", err)
+	}
+
+	tags, err := repository.Tags()
+	if err != nil {
+		return "", fmt.Errorf("git list tags failed: %w", err)
+	}
+	var names []string
+	err = tags.ForEach(func(ref *plumbing.Reference) error {
+		hash := ref.Hash()
+		// Annotated tags point at a tag object rather than the commit
+		if tag, err := repository.TagObject(hash); err == nil {
+			hash = tag.Target
+		}
+		if hash == head.Hash() {
+			names = append(names, ref.Name().Short())
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("git list tags failed: %w", err)
+	}
+
+	if len(names) == 0 {
+		return "", nil
+	}
+	slices.Sort(names)
+	return names[0], nil
+}
@@ -0,0 +1,154 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+const (
+	// KubeSchemaDir is the directory under the tools cache that holds Kubernetes OpenAPI schemas
+	KubeSchemaDir = "kube-schema"
+
+	// kubeSchemaURL is the OpenAPI v2 document each Kubernetes release branch
+	// publishes for its built-in APIs, below kubeSchemaDownloadURL or its mirror
+	kubeSchemaURL = "%s/release-%s/api/openapi-spec/swagger.json"
+)
+
+// kubeMinorVersionPattern matches a Kubernetes minor version such as 1.31
+var kubeMinorVersionPattern = regexp.MustCompile(`^1\.(0|[1-9]\d*)$`)
+
+// GetKubeSchemaPath returns the cached path of the OpenAPI schema for a Kubernetes minor version
+// Example: ~/.replicated/tools/kube-schema/1.31/swagger.json
+func GetKubeSchemaPath(version string) (string, error) {
+	cacheDir, err := GetCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(cacheDir, KubeSchemaDir, version, "swagger.json"), nil
+}
+
+// DownloadKubeSchema downloads the OpenAPI schema for a Kubernetes minor version to
+// the cache directory
+func (d *Downloader) DownloadKubeSchema(ctx context.Context, version string) error {
+	cachePath, err := GetKubeSchemaPath(version)
+	if err != nil {
+		return err
+	}
+
+	data, err := d.FetchKubeSchema(version)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temp file first so concurrent runs never read a partial schema
+	if err := writeCacheFile(cachePath, bytes.NewReader(data), 0644, ""); err != nil {
+		return fmt.Errorf("writing schema: %w", err)
+	}
+
+	return nil
+}
+
+// FetchKubeSchema downloads and checks the OpenAPI schema for a Kubernetes minor version
+func (d *Downloader) FetchKubeSchema(version string) ([]byte, error) {
+	if !kubeMinorVersionPattern.MatchString(version) {
+		return nil, fmt.Errorf("invalid Kubernetes version %q", version)
+	}
+
+	data, err := d.downloadWithRetry(fmt.Sprintf(kubeSchemaURL, d.baseURL(KubeSchemaDir, kubeSchemaDownloadURL), version))
+	if err != nil {
+		return nil, fmt.Errorf("downloading: %w", err)
+	}
+
+	// Make sure a truncated or error response is never cached
+	var schema struct {
+		Definitions map[string]json.RawMessage `json:"definitions"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("parsing schema: %w", err)
+	}
+	if len(schema.Definitions) == 0 {
+		return nil, fmt.Errorf("schema has no definitions")
+	}
+
+	return data, nil
+}
+
+// ReadKubeSchema returns the OpenAPI schema for a Kubernetes minor version from the
+// tools cache, so no network access is needed once it has been installed with
+// InstallKubeSchema or imported from a tools bundle. A schema that is not cached is
+// installed from the tools bundle, if set, or downloaded, unless downloads have been
+// turned off with WithKubeSchemaDownload or the resolver is offline.
+func (r *Resolver) ReadKubeSchema(ctx context.Context, version string) ([]byte, error) {
+	cached, err := isKubeSchemaCached(version)
+	if err != nil {
+		return nil, err
+	}
+	if !cached && r.bundlePath == "" && (!r.kubeSchemaDownload || r.offline) {
+		return nil, fmt.Errorf("the Kubernetes %s schema is not in the tools cache; install it with "+
+			"\"replicated tools install kube-schema@%s\" or import a tools bundle that includes it", version, version)
+	}
+
+	schemaPath, err := r.InstallKubeSchema(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(schemaPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading Kubernetes %s schema: %w", version, err)
+	}
+	return data, nil
+}
+
+// isKubeSchemaCached reports whether the schema for a Kubernetes minor version is in the cache
+func isKubeSchemaCached(version string) (bool, error) {
+	schemaPath, err := GetKubeSchemaPath(version)
+	if err != nil {
+		return false, fmt.Errorf("getting cache path: %w", err)
+	}
+	info, err := os.Stat(schemaPath)
+	return err == nil && !info.IsDir(), nil
+}
+
+// InstallKubeSchema returns the path to the cached OpenAPI schema for a Kubernetes
+// minor version, installing it from the tools bundle or downloading it if not cached
+func (r *Resolver) InstallKubeSchema(ctx context.Context, version string) (string, error) {
+	schemaPath, err := GetKubeSchemaPath(version)
+	if err != nil {
+		return "", fmt.Errorf("getting cache path: %w", err)
+	}
+
+	if info, err := os.Stat(schemaPath); err == nil && !info.IsDir() {
+		return schemaPath, nil
+	}
+
+	unlock, err := lockCache()
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	// Another run may have downloaded it while we waited for the lock
+	if info, err := os.Stat(schemaPath); err == nil && !info.IsDir() {
+		return schemaPath, nil
+	}
+
+	if r.bundlePath != "" {
+		if err := r.installFromBundle(KubeSchemaDir, version); err != nil {
+			return "", err
+		}
+		return schemaPath, nil
+	}
+
+	fmt.Printf("Downloading Kubernetes %s schema...\n", version)
+	if err := r.downloader.DownloadKubeSchema(ctx, version); err != nil {
+		return "", fmt.Errorf("downloading Kubernetes %s schema: %w", version, err)
+	}
+
+	return schemaPath, nil
+}
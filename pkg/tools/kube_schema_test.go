@@ -0,0 +1,83 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInstallKubeSchema_Cached(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	schemaPath, err := GetKubeSchemaPath("1.31")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(home, ".replicated", "tools", "kube-schema", "1.31", "swagger.json"); schemaPath != want {
+		t.Errorf("GetKubeSchemaPath() = %q, want %q", schemaPath, want)
+	}
+	if err := os.MkdirAll(filepath.Dir(schemaPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(schemaPath, []byte(`{"definitions": {}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A cached schema is returned without downloading, so this works offline
+	resolved, err := NewResolver().InstallKubeSchema(context.Background(), "1.31")
+	if err != nil {
+		t.Fatalf("InstallKubeSchema() error = %v", err)
+	}
+	if resolved != schemaPath {
+		t.Errorf("InstallKubeSchema() = %q, want %q", resolved, schemaPath)
+	}
+}
+
+func TestDownloadKubeSchema_InvalidVersion(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if err := NewDownloader().DownloadKubeSchema(context.Background(), "latest"); err == nil {
+		t.Error("expected an error for an invalid version")
+	}
+}
+
+func TestReadKubeSchema_NotCached(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv(BundleEnv, "")
+
+	// Schemas that are not cached are not downloaded when downloads are off or offline
+	for name, resolver := range map[string]*Resolver{
+		"download off": NewResolver(WithKubeSchemaDownload(false)),
+		"offline":      NewResolver(WithOffline(true)),
+	} {
+		_, err := resolver.ReadKubeSchema(context.Background(), "1.29")
+		if err == nil || !strings.Contains(err.Error(), "is not in the tools cache") {
+			t.Errorf("%s: expected a not cached error, got %v", name, err)
+		}
+	}
+
+	// An installed schema is read without downloading
+	schemaPath, err := GetKubeSchemaPath("1.29")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(schemaPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(schemaPath, []byte(`{"definitions": {}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	data, err := NewResolver(WithOffline(true)).ReadKubeSchema(context.Background(), "1.29")
+	if err != nil {
+		t.Fatalf("ReadKubeSchema() error = %v", err)
+	}
+	if string(data) != `{"definitions": {}}` {
+		t.Errorf("ReadKubeSchema() = %q, want the installed schema", data)
+	}
+}
@@ -0,0 +1,70 @@
+package tools
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Upstream base URLs tools are downloaded from. A mirror replaces the base URL of a
+// tool; the path below it stays the same as upstream, so a mirror only has to proxy
+// (or copy) the upstream layout.
+const (
+	// helmDownloadURL serves helm-v<version>-<os>-<arch>.tar.gz and its .sha256sum
+	helmDownloadURL = "https://get.helm.sh"
+
+	// troubleshootDownloadURL serves v<version>/<archive> and
+	// v<version>/troubleshoot_<version>_checksums.txt for preflight and support-bundle
+	troubleshootDownloadURL = "https://github.com/replicatedhq/troubleshoot/releases/download"
+
+	// ecDownloadURL serves <version>-<os>-<arch>.tgz embedded-cluster archives
+	ecDownloadURL = "https://tf-embedded-cluster-binaries.s3.us-east-1.amazonaws.com/releases"
+
+	// kubeSchemaDownloadURL serves release-<version>/api/openapi-spec/swagger.json
+	kubeSchemaDownloadURL = "https://raw.githubusercontent.com/kubernetes/kubernetes"
+)
+
+// MirrorTools are the tools a mirror base URL can be configured for
+var MirrorTools = []string{ToolHelm, ToolPreflight, ToolSupportBundle, ToolEmbeddedCluster, KubeSchemaDir}
+
+// WithDownloadMirrors makes the downloader fetch tools and their checksum files from
+// base URLs, keyed by tool name, instead of upstream. Local lint passes the
+// repl-lint.tools-mirror section of the .replicated config.
+func WithDownloadMirrors(mirrors map[string]string) DownloaderOption {
+	return func(d *Downloader) {
+		d.mirrors = make(map[string]string, len(mirrors))
+		for name, baseURL := range mirrors {
+			d.mirrors[name] = strings.TrimRight(baseURL, "/")
+		}
+	}
+}
+
+// WithMirrors makes the resolver download tools through mirrors, as WithDownloadMirrors
+func WithMirrors(mirrors map[string]string) ResolverOption {
+	return func(r *Resolver) {
+		WithDownloadMirrors(mirrors)(r.downloader)
+	}
+}
+
+// baseURL returns the base URL to download a tool from: its mirror, or upstream
+func (d *Downloader) baseURL(name, upstream string) string {
+	if mirror := d.mirrors[name]; mirror != "" {
+		return mirror
+	}
+	return upstream
+}
+
+// validateMirrorURL checks that a mirror base URL is an absolute http(s) URL
+func validateMirrorURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("must be an http or https URL")
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("must not have a query or fragment")
+	}
+	return nil
+}
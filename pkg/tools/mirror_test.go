@@ -0,0 +1,114 @@
+package tools
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func testTarGz(t *testing.T, name, content string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gzWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzWriter)
+	if err := tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(content))}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tarWriter.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	tarWriter.Close()
+	gzWriter.Close()
+	return buf.Bytes()
+}
+
+func sha256Hex(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+func TestDownloadFromMirror(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		t.Skip("the mirror serves linux-style tar.gz archives")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	platform := CurrentPlatform()
+	helmArchive := testTarGz(t, platform.String()+"/helm", "mirrored helm")
+	preflightFile := fmt.Sprintf("preflight_%s_%s.tar.gz", platform.OS, platform.Arch)
+	preflightArchive := testTarGz(t, "preflight", "mirrored preflight")
+
+	files := map[string]string{
+		fmt.Sprintf("/helm/helm-v3.14.4-%s.tar.gz", platform):           string(helmArchive),
+		fmt.Sprintf("/helm/helm-v3.14.4-%s.tar.gz.sha256sum", platform): sha256Hex(helmArchive) + "  helm.tar.gz\n",
+		fmt.Sprintf("/helm/helm-v3.14.5-%s.tar.gz", platform):           string(helmArchive),
+		fmt.Sprintf("/helm/helm-v3.14.5-%s.tar.gz.sha256sum", platform): strings.Repeat("0", 64) + "  helm.tar.gz\n",
+		"/gh/v0.123.9/" + preflightFile:                                 string(preflightArchive),
+		"/gh/v0.123.9/troubleshoot_0.123.9_checksums.txt":               sha256Hex(preflightArchive) + "  " + preflightFile + "\n",
+		"/k8s/release-1.31/api/openapi-spec/swagger.json":               `{"definitions": {"io.k8s.api.core.v1.Pod": {}}}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, content)
+	}))
+	defer server.Close()
+
+	d := NewDownloader(WithDownloadMirrors(map[string]string{
+		ToolHelm:      server.URL + "/helm/",
+		ToolPreflight: server.URL + "/gh",
+		KubeSchemaDir: server.URL + "/k8s",
+	}))
+	ctx := context.Background()
+
+	if err := d.downloadExact(ctx, ToolHelm, "3.14.4"); err != nil {
+		t.Fatalf("downloading helm from the mirror: %v", err)
+	}
+	helmPath, _ := GetToolPath(ToolHelm, "3.14.4")
+	if data, _ := os.ReadFile(helmPath); string(data) != "mirrored helm" {
+		t.Errorf("cached helm = %q, want the mirrored binary", data)
+	}
+
+	// Checksums are verified against the mirrored checksum files
+	if err := d.downloadExact(ctx, ToolHelm, "3.14.5"); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("expected a checksum mismatch, got %v", err)
+	}
+
+	data, err := d.fetchBinary(ctx, ToolPreflight, "0.123.9", platform)
+	if err != nil {
+		t.Fatalf("downloading preflight from the mirror: %v", err)
+	}
+	if string(data) != "mirrored preflight" {
+		t.Errorf("preflight = %q, want the mirrored binary", data)
+	}
+
+	if err := d.DownloadKubeSchema(ctx, "1.31"); err != nil {
+		t.Fatalf("downloading the Kubernetes schema from the mirror: %v", err)
+	}
+
+	// Mirrors belong to the downloader they were passed to
+	if got := d.baseURL(ToolHelm, helmDownloadURL); got != server.URL+"/helm" {
+		t.Errorf("baseURL() = %q, want the mirror without a trailing slash", got)
+	}
+	if got := NewDownloader().baseURL(ToolHelm, helmDownloadURL); got != helmDownloadURL {
+		t.Errorf("baseURL() = %q, want upstream", got)
+	}
+	resolver := NewResolver(WithMirrors(map[string]string{ToolHelm: server.URL + "/helm"}))
+	if got := resolver.downloader.baseURL(ToolHelm, helmDownloadURL); got != server.URL+"/helm" {
+		t.Errorf("resolver baseURL() = %q, want the mirror", got)
+	}
+}
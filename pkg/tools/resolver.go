@@ -4,24 +4,62 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strconv"
 )
 
+// OfflineEnv is the environment variable that, when true, makes the resolver resolve
+// "latest" to the versions in the last imported tools bundle instead of asking
+// replicated.app, and never download Kubernetes schemas
+const OfflineEnv = "REPLICATED_TOOLS_OFFLINE"
+
 // Resolver resolves tool binaries, downloading and caching as needed
 type Resolver struct {
-	downloader *Downloader
+	downloader         *Downloader
+	bundlePath         string // tools bundle to install from instead of downloading (REPLICATED_TOOLS_BUNDLE)
+	kubeSchemaDownload bool   // download Kubernetes schemas that are not cached
+	offline            bool   // resolve "latest" from the imported bundle and skip schema downloads (REPLICATED_TOOLS_OFFLINE)
+}
+
+// ResolverOption configures a Resolver
+type ResolverOption func(*Resolver)
+
+// WithKubeSchemaDownload sets whether ReadKubeSchema downloads Kubernetes schemas that
+// are not cached. It does by default.
+func WithKubeSchemaDownload(download bool) ResolverOption {
+	return func(r *Resolver) {
+		r.kubeSchemaDownload = download
+	}
+}
+
+// WithOffline makes "latest" resolve to the versions in the last imported tools
+// bundle without contacting replicated.app
+func WithOffline(offline bool) ResolverOption {
+	return func(r *Resolver) {
+		r.offline = offline
+	}
 }
 
 // NewResolver creates a new tool resolver
-func NewResolver() *Resolver {
-	return &Resolver{
-		downloader: NewDownloader(),
+func NewResolver(opts ...ResolverOption) *Resolver {
+	offline, _ := strconv.ParseBool(os.Getenv(OfflineEnv))
+	r := &Resolver{
+		downloader:         NewDownloader(),
+		bundlePath:         os.Getenv(BundleEnv),
+		kubeSchemaDownload: true,
+		offline:            offline,
+	}
+	for _, opt := range opts {
+		opt(r)
 	}
+	return r
 }
 
-// ResolveLatestVersion fetches the latest stable version for a tool from replicated.app/ping
-// without downloading it. Useful for displaying version information.
+// ResolveLatestVersion returns the version "latest" resolves to for a tool, without
+// downloading it: the version in the tools bundle when one is set, the version in the
+// last imported bundle when offline, and otherwise the latest stable version from
+// replicated.app/ping. Useful for displaying version information.
 func (r *Resolver) ResolveLatestVersion(ctx context.Context, name string) (string, error) {
-	latestVersion, err := getLatestStableVersion(name)
+	latestVersion, err := r.latestVersion(name)
 	if err != nil {
 		return "", fmt.Errorf("failed to get latest version for %s: %w", name, err)
 	}
@@ -31,9 +69,19 @@ func (r *Resolver) ResolveLatestVersion(ctx context.Context, name string) (strin
 
 // Resolve returns the path to a tool binary, downloading if not cached
 func (r *Resolver) Resolve(ctx context.Context, name, version string) (string, error) {
+	return r.resolve(ctx, name, version, true)
+}
+
+// Install returns the path to a tool binary like Resolve, but fails instead of falling
+// back to the latest stable version when the requested version cannot be downloaded
+func (r *Resolver) Install(ctx context.Context, name, version string) (string, error) {
+	return r.resolve(ctx, name, version, false)
+}
+
+func (r *Resolver) resolve(ctx context.Context, name, version string, fallback bool) (string, error) {
 	// If version is "latest" or empty, fetch the latest stable version from replicated.app/ping
 	if version == "latest" || version == "" {
-		latestVersion, err := getLatestStableVersion(name)
+		latestVersion, err := r.latestVersion(name)
 		if err != nil {
 			return "", fmt.Errorf("failed to get latest version for %s: %w", name, err)
 		}
@@ -57,9 +105,35 @@ func (r *Resolver) Resolve(ctx context.Context, name, version string) (string, e
 		return toolPath, nil
 	}
 
+	// Hold the cache lock while installing, so concurrent runs sharing the cache
+	// download each tool once
+	unlock, err := lockCache()
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	// Another run may have installed it while we waited for the lock
+	if cached, err := IsCached(name, version); err == nil && cached {
+		return toolPath, nil
+	}
+
+	// Not cached - install it from the tools bundle, never falling back to the network
+	if r.bundlePath != "" {
+		if err := r.installFromBundle(name, version); err != nil {
+			return "", err
+		}
+		return toolPath, nil
+	}
+
 	// Not cached - download it
 	fmt.Printf("Downloading %s %s...\n", name, version)
-	actualVersion, err := r.downloader.Download(ctx, name, version)
+	actualVersion := version
+	if fallback {
+		actualVersion, err = r.downloader.Download(ctx, name, version)
+	} else {
+		err = r.downloader.downloadExact(ctx, name, version)
+	}
 	if err != nil {
 		return "", fmt.Errorf("downloading %s %s: %w", name, version, err)
 	}
@@ -79,3 +153,67 @@ func (r *Resolver) Resolve(ctx context.Context, name, version string) (string, e
 
 	return toolPath, nil
 }
+
+// latestVersion resolves "latest" for a tool: to its version in the tools bundle when
+// one is set, else to the latest stable version from replicated.app/ping. The last
+// imported bundle is used instead when offline, or when replicated.app cannot be
+// reached.
+func (r *Resolver) latestVersion(name string) (string, error) {
+	if r.bundlePath != "" {
+		manifest, err := ReadBundleManifest(r.bundlePath)
+		if err != nil {
+			return "", err
+		}
+		entry, ok := manifest.Find(name, "latest")
+		if !ok {
+			return "", fmt.Errorf("%s is not in tools bundle %s", name, r.bundlePath)
+		}
+		return entry.Version, nil
+	}
+
+	if r.offline {
+		imported, err := readImportedBundle()
+		if err != nil {
+			return "", err
+		}
+		if imported == nil {
+			return "", fmt.Errorf("no tools bundle has been imported; import one with \"replicated tools import\" or unset %s", OfflineEnv)
+		}
+		entry, ok := imported.Find(name, "latest")
+		if !ok {
+			return "", fmt.Errorf("%s is not in the imported tools bundle", name)
+		}
+		return entry.Version, nil
+	}
+
+	version, err := getLatestStableVersion(name)
+	if err == nil {
+		return version, nil
+	}
+
+	// replicated.app cannot be reached, so fall back to what was imported for this machine
+	if imported, importedErr := readImportedBundle(); importedErr == nil && imported != nil {
+		if entry, ok := imported.Find(name, "latest"); ok {
+			return entry.Version, nil
+		}
+	}
+	return "", err
+}
+
+// installFromBundle installs a single tool version (or Kubernetes schema) from the
+// tools bundle into the cache
+func (r *Resolver) installFromBundle(name, version string) error {
+	manifest, err := ReadBundleManifest(r.bundlePath)
+	if err != nil {
+		return err
+	}
+	want, ok := manifest.Find(name, version)
+	if !ok {
+		return fmt.Errorf("%s %s is not in tools bundle %s", name, version, r.bundlePath)
+	}
+
+	if _, err := extractBundle(r.bundlePath, func(entry BundleEntry) bool { return entry == want }); err != nil {
+		return fmt.Errorf("installing %s %s from tools bundle: %w", name, version, err)
+	}
+	return nil
+}
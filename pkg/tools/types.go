@@ -2,6 +2,7 @@ package tools
 
 // Config represents the parsed .replicated configuration file
 type Config struct {
+	Include               []string          `yaml:"include,omitempty"` // Config files or globs merged before this one, relative to it
 	AppId                 string            `yaml:"appId,omitempty"`
 	AppSlug               string            `yaml:"appSlug,omitempty"`
 	PromoteToChannelIds   []string          `yaml:"promoteToChannelIds,omitempty"`
@@ -11,13 +12,28 @@ type Config struct {
 	ReleaseLabel          string            `yaml:"releaseLabel,omitempty"`
 	Manifests             []string          `yaml:"manifests,omitempty"`
 	ReplLint              *ReplLintConfig   `yaml:"repl-lint,omitempty"`
+
+	// Environments are named overlays of the release target, selected with --env or
+	// by the active credentials profile
+	Environments map[string]EnvironmentConfig `yaml:"environments,omitempty"`
+}
+
+// EnvironmentConfig overrides where a config releases to, for one vendor account.
+// Fields that are set replace the config's own; charts replace the whole chart list.
+type EnvironmentConfig struct {
+	Profile               string        `yaml:"profile,omitempty"` // Credentials profile that selects the environment, if not the environment's name
+	AppSlug               string        `yaml:"appSlug,omitempty"`
+	PromoteToChannelNames []string      `yaml:"promoteToChannelNames,omitempty"`
+	ReleaseLabel          string        `yaml:"releaseLabel,omitempty"`
+	Charts                []ChartConfig `yaml:"charts,omitempty"`
 }
 
 // ChartConfig represents a chart entry in the config
 type ChartConfig struct {
-	Path         string `yaml:"path"`
-	ChartVersion string `yaml:"chartVersion,omitempty"`
-	AppVersion   string `yaml:"appVersion,omitempty"`
+	Path         string   `yaml:"path"`
+	ChartVersion string   `yaml:"chartVersion,omitempty"`
+	AppVersion   string   `yaml:"appVersion,omitempty"`
+	Values       []string `yaml:"values,omitempty"` // Values files to lint the chart with, each in its own combination
 }
 
 // PreflightConfig represents a preflight entry in the config
@@ -30,25 +46,42 @@ type PreflightConfig struct {
 
 // ReplLintConfig is the lint configuration section
 type ReplLintConfig struct {
-	Version int               `yaml:"version"`
-	Linters LintersConfig     `yaml:"linters"`
-	Tools   map[string]string `yaml:"tools,omitempty"`
+	Version     int               `yaml:"version"`
+	Linters     LintersConfig     `yaml:"linters"`
+	Tools       map[string]string `yaml:"tools,omitempty"`
+	ToolsMirror map[string]string `yaml:"tools-mirror,omitempty"` // Tool name to the base URL of a mirror to download it from
+	Baseline    string            `yaml:"baseline,omitempty"`     // Path to a baseline file of known findings to suppress
+	Policies    string            `yaml:"policies,omitempty"`     // Directory of CEL policy rules evaluated by the policy linter
 }
 
 // LintersConfig contains configuration for each linter
 type LintersConfig struct {
-	Helm            LinterConfig   `yaml:"helm"`
-	Preflight       LinterConfig   `yaml:"preflight"`
-	SupportBundle   LinterConfig   `yaml:"support-bundle"`
-	EmbeddedCluster ECLinterConfig `yaml:"embedded-cluster"`
-	Kots            LinterConfig   `yaml:"kots"`
+	Helm            LinterConfig           `yaml:"helm"`
+	Preflight       LinterConfig           `yaml:"preflight"`
+	SupportBundle   LinterConfig           `yaml:"support-bundle"`
+	EmbeddedCluster ECLinterConfig         `yaml:"embedded-cluster"`
+	Kots            LinterConfig           `yaml:"kots"`
+	KubeSchema      KubeSchemaLinterConfig `yaml:"kube-schema"`
+	Policy          LinterConfig           `yaml:"policy"`
+	ReleaseGraph    LinterConfig           `yaml:"release-graph"`
+	Image           ImageLinterConfig      `yaml:"image"`
 }
 
 // LinterConfig represents the configuration for a single linter.
 // nil Disabled is a transient parse state — ApplyDefaults always fills it in
 // before IsEnabled is called.
 type LinterConfig struct {
-	Disabled *bool `yaml:"disabled,omitempty"`
+	Disabled *bool        `yaml:"disabled,omitempty"`
+	Strict   *bool        `yaml:"strict,omitempty"` // Treat warnings as errors
+	Ignore   []IgnoreRule `yaml:"ignore,omitempty"`
+}
+
+// IgnoreRule suppresses lint findings. Every field that is set must match for a
+// finding to be ignored; at least one field is required.
+type IgnoreRule struct {
+	Rule    string `yaml:"rule,omitempty"`    // Exact rule ID, e.g. "application-title"
+	Path    string `yaml:"path,omitempty"`    // File, directory or doublestar glob
+	Message string `yaml:"message,omitempty"` // Regular expression matched against the message
 }
 
 // IsEnabled returns true if the linter is not disabled.
@@ -57,6 +90,12 @@ func (c LinterConfig) IsEnabled() bool {
 	return c.Disabled == nil || !*c.Disabled
 }
 
+// IsStrict returns true if warnings from the linter should be treated as errors.
+// nil is treated as not strict.
+func (c LinterConfig) IsStrict() bool {
+	return c.Strict != nil && *c.Strict
+}
+
 // DefaultECDisableChecks are the checker IDs disabled by default when running EC lint.
 var DefaultECDisableChecks = []string{"helmchart-archive", "ecconfig-helmchart-archive"}
 
@@ -77,6 +116,41 @@ func (c ECLinterConfig) GetDisableChecks() []string {
 	return DefaultECDisableChecks
 }
 
+// KubeSchemaLinterConfig is the linter config for the Kubernetes schema linter.
+// It embeds LinterConfig and adds the Kubernetes versions to validate against.
+type KubeSchemaLinterConfig struct {
+	LinterConfig `yaml:",inline"`
+	Versions     []string `yaml:"versions,omitempty"` // Kubernetes minor versions, e.g. "1.31"
+	Download     *bool    `yaml:"download,omitempty"` // Download schemas that are not in the tools cache
+}
+
+// IsDownloadEnabled returns true if schemas that are not in the tools cache should be
+// downloaded. nil is treated as true.
+func (c KubeSchemaLinterConfig) IsDownloadEnabled() bool {
+	return c.Download == nil || *c.Download
+}
+
+// ImageLintRules are the rules of the image linter, in the order they are checked
+var ImageLintRules = []string{
+	"latest-tag",
+	"no-tag",
+	"insecure-registry",
+	"unqualified-name",
+	"invalid-syntax",
+	"require-digest",
+	"allowed-registries",
+	"proxy-registry",
+}
+
+// ImageLinterConfig is the linter config for the image linter.
+// It embeds LinterConfig and adds the severity of each rule and the rule settings.
+type ImageLinterConfig struct {
+	LinterConfig      `yaml:",inline"`
+	Rules             map[string]string `yaml:"rules,omitempty"`              // Rule ID to severity: error, warn, info or off
+	AllowedRegistries []string          `yaml:"allowed-registries,omitempty"` // Registries (optionally with a path prefix) images may come from
+	ProxyHostname     string            `yaml:"proxy-hostname,omitempty"`     // Replicated proxy registry hostname images must be pulled through
+}
+
 // Default tool versions - kept for backward compatibility in tests
 // In production, "latest" is used to fetch the most recent stable version from GitHub
 const (